@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/etcdutil"
+	"github.com/gardener/etcd-backup-restore/pkg/member"
+	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+
+	"github.com/go-logr/logr"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	runtimelog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// memberReplacePollInterval is the interval in which the catch-up of the new member is checked.
+const memberReplacePollInterval = 5 * time.Second
+
+// NewMemberCommand creates a cobra command for managing the members of the etcd cluster.
+func NewMemberCommand(ctx context.Context) *cobra.Command {
+	memberCmd := &cobra.Command{
+		Use:   "member",
+		Short: "manages the members of the etcd cluster",
+	}
+	memberCmd.AddCommand(newMemberReplaceCommand(ctx))
+	return memberCmd
+}
+
+// newMemberReplaceCommand creates a cobra command for replacing a permanently failed member.
+func newMemberReplaceCommand(ctx context.Context) *cobra.Command {
+	opts := newMemberReplaceOptions()
+	replaceCmd := &cobra.Command{
+		Use:   "replace",
+		Short: "replaces a permanently failed member of the etcd cluster",
+		Long: `Removes the member with the given name from the etcd cluster, cleans up its member lease, and adds it back
+with its peer URLs from the etcd configuration as a learner, which is promoted once it has caught up with the leader.
+It refuses to remove the member if the other members are not healthy enough to keep the quorum of the cluster.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			logger := logrus.NewEntry(logrus.New()).WithField("actor", "member-replace")
+			runtimelog.SetLogger(logr.New(runtimelog.NullLogSink{}))
+			if err := opts.validate(); err != nil {
+				logger.Fatalf("failed to validate the options: %v", err)
+			}
+
+			replacement := &member.Replacement{
+				Control:        member.NewMemberControlForMember(opts.etcdConnectionConfig, opts.name, opts.namespace),
+				ClientFactory:  etcdutil.NewFactory(*opts.etcdConnectionConfig),
+				Name:           opts.name,
+				Namespace:      opts.namespace,
+				MaxApplyLag:    opts.learnerMaxApplyLag,
+				CatchUpTimeout: opts.learnerCatchUpTimeout.Duration,
+				PollInterval:   memberReplacePollInterval,
+				Logger:         logger,
+			}
+			if clientSet, err := miscellaneous.GetKubernetesClientSetOrError(); err != nil {
+				logger.Warnf("Will not clean up the member lease, since no Kubernetes client could be created: %v", err)
+			} else {
+				replacement.Client = clientSet
+			}
+
+			if err := replacement.Run(ctx); err != nil {
+				logger.Fatalf("Failed to replace member %s: %v", opts.name, err)
+			}
+		},
+	}
+
+	opts.addFlags(replaceCmd.Flags())
+	return replaceCmd
+}
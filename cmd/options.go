@@ -7,6 +7,7 @@ package cmd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 
 	"github.com/gardener/etcd-backup-restore/pkg/compressor"
@@ -152,6 +153,50 @@ func (c *compactOptions) validate() error {
 	return c.compactorConfig.Validate()
 }
 
+type squashOptions struct {
+	snapstoreConfig     *brtypes.SnapstoreConfig
+	compressionConfig   *compressor.CompressionConfig
+	squasherConfig      *brtypes.SquasherConfig
+	deltaSnapshotFormat string
+}
+
+// newSquashOptions returns the squash options.
+func newSquashOptions() *squashOptions {
+	return &squashOptions{
+		snapstoreConfig:     snapstore.NewSnapstoreConfig(),
+		compressionConfig:   compressor.NewCompressorConfig(),
+		squasherConfig:      brtypes.NewSquasherConfig(),
+		deltaSnapshotFormat: brtypes.DefaultDeltaSnapshotFormat,
+	}
+}
+
+// AddFlags adds the flags to flagset.
+func (c *squashOptions) addFlags(fs *flag.FlagSet) {
+	c.snapstoreConfig.AddFlags(fs)
+	c.compressionConfig.AddFlags(fs)
+	c.squasherConfig.AddFlags(fs)
+	fs.StringVar(&c.deltaSnapshotFormat, "delta-snapshot-format", c.deltaSnapshotFormat, "format of the squashed delta snapshots: 'json' for JSON event arrays, 'binary' for length-prefixed protobuf encoded events, which older versions cannot read")
+}
+
+// Validate validates the config.
+func (c *squashOptions) validate() error {
+	if err := c.snapstoreConfig.Validate(); err != nil {
+		return err
+	}
+	if err := c.compressionConfig.Validate(); err != nil {
+		return err
+	}
+	if c.deltaSnapshotFormat != brtypes.DeltaSnapshotFormatJSON && c.deltaSnapshotFormat != brtypes.DeltaSnapshotFormatBinary {
+		return fmt.Errorf("invalid delta snapshot format: %s", c.deltaSnapshotFormat)
+	}
+	return c.squasherConfig.Validate()
+}
+
+// complete completes the config.
+func (c *squashOptions) complete() {
+	c.snapstoreConfig.Complete()
+}
+
 type restorerOptions struct {
 	restorationConfig *brtypes.RestorationConfig
 	snapstoreConfig   *brtypes.SnapstoreConfig
@@ -318,3 +363,41 @@ func (c *copierOptions) complete() {
 	c.snapstoreConfig.Complete()
 	c.sourceSnapStoreConfig.MergeWith(c.snapstoreConfig)
 }
+
+type memberReplaceOptions struct {
+	etcdConnectionConfig  *brtypes.EtcdConnectionConfig
+	name                  string
+	namespace             string
+	learnerMaxApplyLag    uint64
+	learnerCatchUpTimeout wrappers.Duration
+}
+
+// newMemberReplaceOptions returns the member replace options.
+func newMemberReplaceOptions() *memberReplaceOptions {
+	return &memberReplaceOptions{
+		etcdConnectionConfig:  brtypes.NewEtcdConnectionConfig(),
+		namespace:             os.Getenv("POD_NAMESPACE"),
+		learnerMaxApplyLag:    brtypes.DefaultLearnerMaxApplyLag,
+		learnerCatchUpTimeout: wrappers.Duration{Duration: brtypes.DefaultLearnerCatchUpTimeout},
+	}
+}
+
+// AddFlags adds the flags to flagset.
+func (c *memberReplaceOptions) addFlags(fs *flag.FlagSet) {
+	c.etcdConnectionConfig.AddFlags(fs)
+	fs.StringVar(&c.name, "name", c.name, "name of the etcd member to replace, which is also the name of its pod")
+	fs.StringVar(&c.namespace, "namespace", c.namespace, "namespace of the member lease of the etcd member to replace")
+	fs.Uint64Var(&c.learnerMaxApplyLag, "learner-max-apply-lag", c.learnerMaxApplyLag, "maximum number of raft entries the new member may lag behind the leader to be promoted to a voting member")
+	fs.DurationVar(&c.learnerCatchUpTimeout.Duration, "learner-catch-up-timeout", c.learnerCatchUpTimeout.Duration, "time period within which the new member has to catch up with the leader")
+}
+
+// Validate validates the config.
+func (c *memberReplaceOptions) validate() error {
+	if c.name == "" {
+		return errors.New("name of the etcd member to replace is required")
+	}
+	if c.learnerCatchUpTimeout.Duration <= 0 {
+		return errors.New("learner catch-up timeout should be greater than zero")
+	}
+	return c.etcdConnectionConfig.Validate()
+}
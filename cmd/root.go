@@ -29,6 +29,8 @@ from previously taken snapshot.`,
 	RootCmd.AddCommand(NewSnapshotCommand(ctx),
 		NewRestoreCommand(ctx),
 		NewCompactCommand(ctx),
+		NewSquashCommand(ctx),
+		NewMemberCommand(ctx),
 		NewInitializeCommand(ctx),
 		NewServerCommand(ctx),
 		NewCopyCommand(ctx))
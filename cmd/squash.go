@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/gardener/etcd-backup-restore/pkg/snapshot/squasher"
+	"github.com/gardener/etcd-backup-restore/pkg/snapstore"
+
+	"github.com/go-logr/logr"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	runtimelog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// NewSquashCommand creates a cobra command for squashing delta snapshots.
+func NewSquashCommand(ctx context.Context) *cobra.Command {
+	opts := newSquashOptions()
+	squashCmd := &cobra.Command{
+		Use:   "squash",
+		Short: "squashes consecutive incremental snapshots in etcd backup into single incremental snapshots",
+		Long:  "Merges runs of consecutive delta snapshots of the latest full snapshot into single delta snapshots, without restoring etcd.",
+		Run: func(_ *cobra.Command, _ []string) {
+			logger := logrus.NewEntry(logrus.New())
+			runtimelog.SetLogger(logr.New(runtimelog.NullLogSink{}))
+			if err := opts.validate(); err != nil {
+				logger.Fatalf("failed to validate the options: %v", err)
+			}
+			opts.complete()
+
+			store, err := snapstore.GetSnapstore(opts.snapstoreConfig)
+			if err != nil {
+				logger.Fatalf("failed to create snapstore from configured storage provider: %v", err)
+			}
+
+			sq := squasher.NewSquasher(store, opts.squasherConfig, opts.compressionConfig, opts.deltaSnapshotFormat, logger)
+			snaps, err := sq.Squash(ctx)
+			if err != nil {
+				logger.Fatalf("Failed to squash delta snapshots: %v", err)
+			}
+			for _, snap := range snaps {
+				logger.Infof("Squashed delta snapshot name : %v", snap.SnapName)
+			}
+		},
+	}
+
+	opts.addFlags(squashCmd.Flags())
+	return squashCmd
+}
@@ -0,0 +1,1123 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v5.29.3
+// source: backuprestore.proto
+
+package v1alpha1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ValidationMode is the mode used to validate the etcd data directory.
+type ValidationMode int32
+
+const (
+	ValidationMode_VALIDATION_MODE_UNSPECIFIED ValidationMode = 0
+	ValidationMode_VALIDATION_MODE_FULL        ValidationMode = 1
+	ValidationMode_VALIDATION_MODE_SANITY      ValidationMode = 2
+)
+
+// Enum value maps for ValidationMode.
+var (
+	ValidationMode_name = map[int32]string{
+		0: "VALIDATION_MODE_UNSPECIFIED",
+		1: "VALIDATION_MODE_FULL",
+		2: "VALIDATION_MODE_SANITY",
+	}
+	ValidationMode_value = map[string]int32{
+		"VALIDATION_MODE_UNSPECIFIED": 0,
+		"VALIDATION_MODE_FULL":        1,
+		"VALIDATION_MODE_SANITY":      2,
+	}
+)
+
+func (x ValidationMode) Enum() *ValidationMode {
+	p := new(ValidationMode)
+	*p = x
+	return p
+}
+
+func (x ValidationMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ValidationMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_backuprestore_proto_enumTypes[0].Descriptor()
+}
+
+func (ValidationMode) Type() protoreflect.EnumType {
+	return &file_backuprestore_proto_enumTypes[0]
+}
+
+func (x ValidationMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ValidationMode.Descriptor instead.
+func (ValidationMode) EnumDescriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{0}
+}
+
+// InitializationStatus is the state of the data directory initialization.
+type InitializationStatus int32
+
+const (
+	InitializationStatus_INITIALIZATION_STATUS_UNSPECIFIED InitializationStatus = 0
+	InitializationStatus_INITIALIZATION_STATUS_NEW         InitializationStatus = 1
+	InitializationStatus_INITIALIZATION_STATUS_PROGRESS    InitializationStatus = 2
+	InitializationStatus_INITIALIZATION_STATUS_SUCCESSFUL  InitializationStatus = 3
+	InitializationStatus_INITIALIZATION_STATUS_FAILED      InitializationStatus = 4
+)
+
+// Enum value maps for InitializationStatus.
+var (
+	InitializationStatus_name = map[int32]string{
+		0: "INITIALIZATION_STATUS_UNSPECIFIED",
+		1: "INITIALIZATION_STATUS_NEW",
+		2: "INITIALIZATION_STATUS_PROGRESS",
+		3: "INITIALIZATION_STATUS_SUCCESSFUL",
+		4: "INITIALIZATION_STATUS_FAILED",
+	}
+	InitializationStatus_value = map[string]int32{
+		"INITIALIZATION_STATUS_UNSPECIFIED": 0,
+		"INITIALIZATION_STATUS_NEW":         1,
+		"INITIALIZATION_STATUS_PROGRESS":    2,
+		"INITIALIZATION_STATUS_SUCCESSFUL":  3,
+		"INITIALIZATION_STATUS_FAILED":      4,
+	}
+)
+
+func (x InitializationStatus) Enum() *InitializationStatus {
+	p := new(InitializationStatus)
+	*p = x
+	return p
+}
+
+func (x InitializationStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (InitializationStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_backuprestore_proto_enumTypes[1].Descriptor()
+}
+
+func (InitializationStatus) Type() protoreflect.EnumType {
+	return &file_backuprestore_proto_enumTypes[1]
+}
+
+func (x InitializationStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use InitializationStatus.Descriptor instead.
+func (InitializationStatus) EnumDescriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{1}
+}
+
+// Snapshot is the metadata of a snapshot in the snapstore.
+type Snapshot struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Kind                   string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	SnapDir                string                 `protobuf:"bytes,2,opt,name=snap_dir,json=snapDir,proto3" json:"snap_dir,omitempty"`
+	SnapName               string                 `protobuf:"bytes,3,opt,name=snap_name,json=snapName,proto3" json:"snap_name,omitempty"`
+	Prefix                 string                 `protobuf:"bytes,4,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	CompressionSuffix      string                 `protobuf:"bytes,5,opt,name=compression_suffix,json=compressionSuffix,proto3" json:"compression_suffix,omitempty"`
+	StartRevision          int64                  `protobuf:"varint,6,opt,name=start_revision,json=startRevision,proto3" json:"start_revision,omitempty"`
+	LastRevision           int64                  `protobuf:"varint,7,opt,name=last_revision,json=lastRevision,proto3" json:"last_revision,omitempty"`
+	CreatedOn              *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_on,json=createdOn,proto3" json:"created_on,omitempty"`
+	ImmutabilityExpiryTime *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=immutability_expiry_time,json=immutabilityExpiryTime,proto3" json:"immutability_expiry_time,omitempty"`
+	IsChunk                bool                   `protobuf:"varint,10,opt,name=is_chunk,json=isChunk,proto3" json:"is_chunk,omitempty"`
+	IsFinal                bool                   `protobuf:"varint,11,opt,name=is_final,json=isFinal,proto3" json:"is_final,omitempty"`
+	VersionId              string                 `protobuf:"bytes,12,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	mi := &file_backuprestore_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Snapshot) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Snapshot) GetSnapDir() string {
+	if x != nil {
+		return x.SnapDir
+	}
+	return ""
+}
+
+func (x *Snapshot) GetSnapName() string {
+	if x != nil {
+		return x.SnapName
+	}
+	return ""
+}
+
+func (x *Snapshot) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *Snapshot) GetCompressionSuffix() string {
+	if x != nil {
+		return x.CompressionSuffix
+	}
+	return ""
+}
+
+func (x *Snapshot) GetStartRevision() int64 {
+	if x != nil {
+		return x.StartRevision
+	}
+	return 0
+}
+
+func (x *Snapshot) GetLastRevision() int64 {
+	if x != nil {
+		return x.LastRevision
+	}
+	return 0
+}
+
+func (x *Snapshot) GetCreatedOn() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedOn
+	}
+	return nil
+}
+
+func (x *Snapshot) GetImmutabilityExpiryTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ImmutabilityExpiryTime
+	}
+	return nil
+}
+
+func (x *Snapshot) GetIsChunk() bool {
+	if x != nil {
+		return x.IsChunk
+	}
+	return false
+}
+
+func (x *Snapshot) GetIsFinal() bool {
+	if x != nil {
+		return x.IsFinal
+	}
+	return false
+}
+
+func (x *Snapshot) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+type TriggerFullSnapshotRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// final marks the snapshot as the final full snapshot.
+	Final         bool `protobuf:"varint,1,opt,name=final,proto3" json:"final,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerFullSnapshotRequest) Reset() {
+	*x = TriggerFullSnapshotRequest{}
+	mi := &file_backuprestore_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerFullSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerFullSnapshotRequest) ProtoMessage() {}
+
+func (x *TriggerFullSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerFullSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*TriggerFullSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TriggerFullSnapshotRequest) GetFinal() bool {
+	if x != nil {
+		return x.Final
+	}
+	return false
+}
+
+type TriggerDeltaSnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerDeltaSnapshotRequest) Reset() {
+	*x = TriggerDeltaSnapshotRequest{}
+	mi := &file_backuprestore_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerDeltaSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerDeltaSnapshotRequest) ProtoMessage() {}
+
+func (x *TriggerDeltaSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerDeltaSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*TriggerDeltaSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{2}
+}
+
+type ListSnapshotsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// include_all includes snapshots tagged to be excluded from listing.
+	IncludeAll    bool `protobuf:"varint,1,opt,name=include_all,json=includeAll,proto3" json:"include_all,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSnapshotsRequest) Reset() {
+	*x = ListSnapshotsRequest{}
+	mi := &file_backuprestore_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSnapshotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSnapshotsRequest) ProtoMessage() {}
+
+func (x *ListSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*ListSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListSnapshotsRequest) GetIncludeAll() bool {
+	if x != nil {
+		return x.IncludeAll
+	}
+	return false
+}
+
+type ListSnapshotsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Snapshots     []*Snapshot            `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSnapshotsResponse) Reset() {
+	*x = ListSnapshotsResponse{}
+	mi := &file_backuprestore_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSnapshotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSnapshotsResponse) ProtoMessage() {}
+
+func (x *ListSnapshotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSnapshotsResponse.ProtoReflect.Descriptor instead.
+func (*ListSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListSnapshotsResponse) GetSnapshots() []*Snapshot {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+type GetLatestSnapshotsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestSnapshotsRequest) Reset() {
+	*x = GetLatestSnapshotsRequest{}
+	mi := &file_backuprestore_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestSnapshotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestSnapshotsRequest) ProtoMessage() {}
+
+func (x *GetLatestSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*GetLatestSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{5}
+}
+
+type GetLatestSnapshotsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	FullSnapshot   *Snapshot              `protobuf:"bytes,1,opt,name=full_snapshot,json=fullSnapshot,proto3" json:"full_snapshot,omitempty"`
+	DeltaSnapshots []*Snapshot            `protobuf:"bytes,2,rep,name=delta_snapshots,json=deltaSnapshots,proto3" json:"delta_snapshots,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetLatestSnapshotsResponse) Reset() {
+	*x = GetLatestSnapshotsResponse{}
+	mi := &file_backuprestore_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestSnapshotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestSnapshotsResponse) ProtoMessage() {}
+
+func (x *GetLatestSnapshotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestSnapshotsResponse.ProtoReflect.Descriptor instead.
+func (*GetLatestSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetLatestSnapshotsResponse) GetFullSnapshot() *Snapshot {
+	if x != nil {
+		return x.FullSnapshot
+	}
+	return nil
+}
+
+func (x *GetLatestSnapshotsResponse) GetDeltaSnapshots() []*Snapshot {
+	if x != nil {
+		return x.DeltaSnapshots
+	}
+	return nil
+}
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_backuprestore_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{7}
+}
+
+// Status is the state of the sidecar.
+type Status struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// healthy is true when the sidecar reports itself healthy on /healthz.
+	Healthy bool `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	// backup_leader is true when this sidecar is the backup leader and runs the snapshotter.
+	BackupLeader         bool                 `protobuf:"varint,2,opt,name=backup_leader,json=backupLeader,proto3" json:"backup_leader,omitempty"`
+	InitializationStatus InitializationStatus `protobuf:"varint,3,opt,name=initialization_status,json=initializationStatus,proto3,enum=etcdbr.v1alpha1.InitializationStatus" json:"initialization_status,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	mi := &file_backuprestore_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Status) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *Status) GetBackupLeader() bool {
+	if x != nil {
+		return x.BackupLeader
+	}
+	return false
+}
+
+func (x *Status) GetInitializationStatus() InitializationStatus {
+	if x != nil {
+		return x.InitializationStatus
+	}
+	return InitializationStatus_INITIALIZATION_STATUS_UNSPECIFIED
+}
+
+type StartRestoreRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// mode defaults to full validation when unspecified.
+	Mode ValidationMode `protobuf:"varint,1,opt,name=mode,proto3,enum=etcdbr.v1alpha1.ValidationMode" json:"mode,omitempty"`
+	// fail_below_revision is the minimum etcd revision below which validation fails.
+	FailBelowRevision int64 `protobuf:"varint,2,opt,name=fail_below_revision,json=failBelowRevision,proto3" json:"fail_below_revision,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *StartRestoreRequest) Reset() {
+	*x = StartRestoreRequest{}
+	mi := &file_backuprestore_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartRestoreRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartRestoreRequest) ProtoMessage() {}
+
+func (x *StartRestoreRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartRestoreRequest.ProtoReflect.Descriptor instead.
+func (*StartRestoreRequest) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StartRestoreRequest) GetMode() ValidationMode {
+	if x != nil {
+		return x.Mode
+	}
+	return ValidationMode_VALIDATION_MODE_UNSPECIFIED
+}
+
+func (x *StartRestoreRequest) GetFailBelowRevision() int64 {
+	if x != nil {
+		return x.FailBelowRevision
+	}
+	return 0
+}
+
+type GetRestoreStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRestoreStatusRequest) Reset() {
+	*x = GetRestoreStatusRequest{}
+	mi := &file_backuprestore_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRestoreStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRestoreStatusRequest) ProtoMessage() {}
+
+func (x *GetRestoreStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRestoreStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetRestoreStatusRequest) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{10}
+}
+
+type RestoreStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        InitializationStatus   `protobuf:"varint,1,opt,name=status,proto3,enum=etcdbr.v1alpha1.InitializationStatus" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreStatus) Reset() {
+	*x = RestoreStatus{}
+	mi := &file_backuprestore_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreStatus) ProtoMessage() {}
+
+func (x *RestoreStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreStatus.ProtoReflect.Descriptor instead.
+func (*RestoreStatus) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RestoreStatus) GetStatus() InitializationStatus {
+	if x != nil {
+		return x.Status
+	}
+	return InitializationStatus_INITIALIZATION_STATUS_UNSPECIFIED
+}
+
+type TriggerDefragmentationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerDefragmentationRequest) Reset() {
+	*x = TriggerDefragmentationRequest{}
+	mi := &file_backuprestore_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerDefragmentationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerDefragmentationRequest) ProtoMessage() {}
+
+func (x *TriggerDefragmentationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerDefragmentationRequest.ProtoReflect.Descriptor instead.
+func (*TriggerDefragmentationRequest) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{12}
+}
+
+type TriggerDefragmentationResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// snapshot is the full snapshot taken after the defragmentation, if the sidecar is the backup leader.
+	Snapshot      *Snapshot `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerDefragmentationResponse) Reset() {
+	*x = TriggerDefragmentationResponse{}
+	mi := &file_backuprestore_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerDefragmentationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerDefragmentationResponse) ProtoMessage() {}
+
+func (x *TriggerDefragmentationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerDefragmentationResponse.ProtoReflect.Descriptor instead.
+func (*TriggerDefragmentationResponse) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *TriggerDefragmentationResponse) GetSnapshot() *Snapshot {
+	if x != nil {
+		return x.Snapshot
+	}
+	return nil
+}
+
+type WatchEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_backuprestore_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{14}
+}
+
+// Event is an operation event emitted by the sidecar.
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Operation     string                 `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	Phase         string                 `protobuf:"bytes,2,opt,name=phase,proto3" json:"phase,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Snapshot      *Snapshot              `protobuf:"bytes,4,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_backuprestore_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_backuprestore_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_backuprestore_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Event) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *Event) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *Event) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Event) GetSnapshot() *Snapshot {
+	if x != nil {
+		return x.Snapshot
+	}
+	return nil
+}
+
+func (x *Event) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_backuprestore_proto protoreflect.FileDescriptor
+
+const file_backuprestore_proto_rawDesc = "" +
+	"\n" +
+	"\x13backuprestore.proto\x12\x0fetcdbr.v1alpha1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xcf\x03\n" +
+	"\bSnapshot\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x19\n" +
+	"\bsnap_dir\x18\x02 \x01(\tR\asnapDir\x12\x1b\n" +
+	"\tsnap_name\x18\x03 \x01(\tR\bsnapName\x12\x16\n" +
+	"\x06prefix\x18\x04 \x01(\tR\x06prefix\x12-\n" +
+	"\x12compression_suffix\x18\x05 \x01(\tR\x11compressionSuffix\x12%\n" +
+	"\x0estart_revision\x18\x06 \x01(\x03R\rstartRevision\x12#\n" +
+	"\rlast_revision\x18\a \x01(\x03R\flastRevision\x129\n" +
+	"\n" +
+	"created_on\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedOn\x12T\n" +
+	"\x18immutability_expiry_time\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x16immutabilityExpiryTime\x12\x19\n" +
+	"\bis_chunk\x18\n" +
+	" \x01(\bR\aisChunk\x12\x19\n" +
+	"\bis_final\x18\v \x01(\bR\aisFinal\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\f \x01(\tR\tversionId\"2\n" +
+	"\x1aTriggerFullSnapshotRequest\x12\x14\n" +
+	"\x05final\x18\x01 \x01(\bR\x05final\"\x1d\n" +
+	"\x1bTriggerDeltaSnapshotRequest\"7\n" +
+	"\x14ListSnapshotsRequest\x12\x1f\n" +
+	"\vinclude_all\x18\x01 \x01(\bR\n" +
+	"includeAll\"P\n" +
+	"\x15ListSnapshotsResponse\x127\n" +
+	"\tsnapshots\x18\x01 \x03(\v2\x19.etcdbr.v1alpha1.SnapshotR\tsnapshots\"\x1b\n" +
+	"\x19GetLatestSnapshotsRequest\"\xa0\x01\n" +
+	"\x1aGetLatestSnapshotsResponse\x12>\n" +
+	"\rfull_snapshot\x18\x01 \x01(\v2\x19.etcdbr.v1alpha1.SnapshotR\ffullSnapshot\x12B\n" +
+	"\x0fdelta_snapshots\x18\x02 \x03(\v2\x19.etcdbr.v1alpha1.SnapshotR\x0edeltaSnapshots\"\x12\n" +
+	"\x10GetStatusRequest\"\xa3\x01\n" +
+	"\x06Status\x12\x18\n" +
+	"\ahealthy\x18\x01 \x01(\bR\ahealthy\x12#\n" +
+	"\rbackup_leader\x18\x02 \x01(\bR\fbackupLeader\x12Z\n" +
+	"\x15initialization_status\x18\x03 \x01(\x0e2%.etcdbr.v1alpha1.InitializationStatusR\x14initializationStatus\"z\n" +
+	"\x13StartRestoreRequest\x123\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x1f.etcdbr.v1alpha1.ValidationModeR\x04mode\x12.\n" +
+	"\x13fail_below_revision\x18\x02 \x01(\x03R\x11failBelowRevision\"\x19\n" +
+	"\x17GetRestoreStatusRequest\"N\n" +
+	"\rRestoreStatus\x12=\n" +
+	"\x06status\x18\x01 \x01(\x0e2%.etcdbr.v1alpha1.InitializationStatusR\x06status\"\x1f\n" +
+	"\x1dTriggerDefragmentationRequest\"W\n" +
+	"\x1eTriggerDefragmentationResponse\x125\n" +
+	"\bsnapshot\x18\x01 \x01(\v2\x19.etcdbr.v1alpha1.SnapshotR\bsnapshot\"\x14\n" +
+	"\x12WatchEventsRequest\"\xbc\x01\n" +
+	"\x05Event\x12\x1c\n" +
+	"\toperation\x18\x01 \x01(\tR\toperation\x12\x14\n" +
+	"\x05phase\x18\x02 \x01(\tR\x05phase\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x125\n" +
+	"\bsnapshot\x18\x04 \x01(\v2\x19.etcdbr.v1alpha1.SnapshotR\bsnapshot\x12.\n" +
+	"\x04time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x04time*g\n" +
+	"\x0eValidationMode\x12\x1f\n" +
+	"\x1bVALIDATION_MODE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14VALIDATION_MODE_FULL\x10\x01\x12\x1a\n" +
+	"\x16VALIDATION_MODE_SANITY\x10\x02*\xc8\x01\n" +
+	"\x14InitializationStatus\x12%\n" +
+	"!INITIALIZATION_STATUS_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19INITIALIZATION_STATUS_NEW\x10\x01\x12\"\n" +
+	"\x1eINITIALIZATION_STATUS_PROGRESS\x10\x02\x12$\n" +
+	" INITIALIZATION_STATUS_SUCCESSFUL\x10\x03\x12 \n" +
+	"\x1cINITIALIZATION_STATUS_FAILED\x10\x042\xe4\x06\n" +
+	"\rBackupRestore\x12]\n" +
+	"\x13TriggerFullSnapshot\x12+.etcdbr.v1alpha1.TriggerFullSnapshotRequest\x1a\x19.etcdbr.v1alpha1.Snapshot\x12_\n" +
+	"\x14TriggerDeltaSnapshot\x12,.etcdbr.v1alpha1.TriggerDeltaSnapshotRequest\x1a\x19.etcdbr.v1alpha1.Snapshot\x12^\n" +
+	"\rListSnapshots\x12%.etcdbr.v1alpha1.ListSnapshotsRequest\x1a&.etcdbr.v1alpha1.ListSnapshotsResponse\x12m\n" +
+	"\x12GetLatestSnapshots\x12*.etcdbr.v1alpha1.GetLatestSnapshotsRequest\x1a+.etcdbr.v1alpha1.GetLatestSnapshotsResponse\x12G\n" +
+	"\tGetStatus\x12!.etcdbr.v1alpha1.GetStatusRequest\x1a\x17.etcdbr.v1alpha1.Status\x12T\n" +
+	"\fStartRestore\x12$.etcdbr.v1alpha1.StartRestoreRequest\x1a\x1e.etcdbr.v1alpha1.RestoreStatus\x12\\\n" +
+	"\x10GetRestoreStatus\x12(.etcdbr.v1alpha1.GetRestoreStatusRequest\x1a\x1e.etcdbr.v1alpha1.RestoreStatus\x12y\n" +
+	"\x16TriggerDefragmentation\x12..etcdbr.v1alpha1.TriggerDefragmentationRequest\x1a/.etcdbr.v1alpha1.TriggerDefragmentationResponse\x12L\n" +
+	"\vWatchEvents\x12#.etcdbr.v1alpha1.WatchEventsRequest\x1a\x16.etcdbr.v1alpha1.Event0\x01BCZAgithub.com/gardener/etcd-backup-restore/pkg/api/v1alpha1;v1alpha1b\x06proto3"
+
+var (
+	file_backuprestore_proto_rawDescOnce sync.Once
+	file_backuprestore_proto_rawDescData []byte
+)
+
+func file_backuprestore_proto_rawDescGZIP() []byte {
+	file_backuprestore_proto_rawDescOnce.Do(func() {
+		file_backuprestore_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_backuprestore_proto_rawDesc), len(file_backuprestore_proto_rawDesc)))
+	})
+	return file_backuprestore_proto_rawDescData
+}
+
+var file_backuprestore_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_backuprestore_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_backuprestore_proto_goTypes = []any{
+	(ValidationMode)(0),                    // 0: etcdbr.v1alpha1.ValidationMode
+	(InitializationStatus)(0),              // 1: etcdbr.v1alpha1.InitializationStatus
+	(*Snapshot)(nil),                       // 2: etcdbr.v1alpha1.Snapshot
+	(*TriggerFullSnapshotRequest)(nil),     // 3: etcdbr.v1alpha1.TriggerFullSnapshotRequest
+	(*TriggerDeltaSnapshotRequest)(nil),    // 4: etcdbr.v1alpha1.TriggerDeltaSnapshotRequest
+	(*ListSnapshotsRequest)(nil),           // 5: etcdbr.v1alpha1.ListSnapshotsRequest
+	(*ListSnapshotsResponse)(nil),          // 6: etcdbr.v1alpha1.ListSnapshotsResponse
+	(*GetLatestSnapshotsRequest)(nil),      // 7: etcdbr.v1alpha1.GetLatestSnapshotsRequest
+	(*GetLatestSnapshotsResponse)(nil),     // 8: etcdbr.v1alpha1.GetLatestSnapshotsResponse
+	(*GetStatusRequest)(nil),               // 9: etcdbr.v1alpha1.GetStatusRequest
+	(*Status)(nil),                         // 10: etcdbr.v1alpha1.Status
+	(*StartRestoreRequest)(nil),            // 11: etcdbr.v1alpha1.StartRestoreRequest
+	(*GetRestoreStatusRequest)(nil),        // 12: etcdbr.v1alpha1.GetRestoreStatusRequest
+	(*RestoreStatus)(nil),                  // 13: etcdbr.v1alpha1.RestoreStatus
+	(*TriggerDefragmentationRequest)(nil),  // 14: etcdbr.v1alpha1.TriggerDefragmentationRequest
+	(*TriggerDefragmentationResponse)(nil), // 15: etcdbr.v1alpha1.TriggerDefragmentationResponse
+	(*WatchEventsRequest)(nil),             // 16: etcdbr.v1alpha1.WatchEventsRequest
+	(*Event)(nil),                          // 17: etcdbr.v1alpha1.Event
+	(*timestamppb.Timestamp)(nil),          // 18: google.protobuf.Timestamp
+}
+var file_backuprestore_proto_depIdxs = []int32{
+	18, // 0: etcdbr.v1alpha1.Snapshot.created_on:type_name -> google.protobuf.Timestamp
+	18, // 1: etcdbr.v1alpha1.Snapshot.immutability_expiry_time:type_name -> google.protobuf.Timestamp
+	2,  // 2: etcdbr.v1alpha1.ListSnapshotsResponse.snapshots:type_name -> etcdbr.v1alpha1.Snapshot
+	2,  // 3: etcdbr.v1alpha1.GetLatestSnapshotsResponse.full_snapshot:type_name -> etcdbr.v1alpha1.Snapshot
+	2,  // 4: etcdbr.v1alpha1.GetLatestSnapshotsResponse.delta_snapshots:type_name -> etcdbr.v1alpha1.Snapshot
+	1,  // 5: etcdbr.v1alpha1.Status.initialization_status:type_name -> etcdbr.v1alpha1.InitializationStatus
+	0,  // 6: etcdbr.v1alpha1.StartRestoreRequest.mode:type_name -> etcdbr.v1alpha1.ValidationMode
+	1,  // 7: etcdbr.v1alpha1.RestoreStatus.status:type_name -> etcdbr.v1alpha1.InitializationStatus
+	2,  // 8: etcdbr.v1alpha1.TriggerDefragmentationResponse.snapshot:type_name -> etcdbr.v1alpha1.Snapshot
+	2,  // 9: etcdbr.v1alpha1.Event.snapshot:type_name -> etcdbr.v1alpha1.Snapshot
+	18, // 10: etcdbr.v1alpha1.Event.time:type_name -> google.protobuf.Timestamp
+	3,  // 11: etcdbr.v1alpha1.BackupRestore.TriggerFullSnapshot:input_type -> etcdbr.v1alpha1.TriggerFullSnapshotRequest
+	4,  // 12: etcdbr.v1alpha1.BackupRestore.TriggerDeltaSnapshot:input_type -> etcdbr.v1alpha1.TriggerDeltaSnapshotRequest
+	5,  // 13: etcdbr.v1alpha1.BackupRestore.ListSnapshots:input_type -> etcdbr.v1alpha1.ListSnapshotsRequest
+	7,  // 14: etcdbr.v1alpha1.BackupRestore.GetLatestSnapshots:input_type -> etcdbr.v1alpha1.GetLatestSnapshotsRequest
+	9,  // 15: etcdbr.v1alpha1.BackupRestore.GetStatus:input_type -> etcdbr.v1alpha1.GetStatusRequest
+	11, // 16: etcdbr.v1alpha1.BackupRestore.StartRestore:input_type -> etcdbr.v1alpha1.StartRestoreRequest
+	12, // 17: etcdbr.v1alpha1.BackupRestore.GetRestoreStatus:input_type -> etcdbr.v1alpha1.GetRestoreStatusRequest
+	14, // 18: etcdbr.v1alpha1.BackupRestore.TriggerDefragmentation:input_type -> etcdbr.v1alpha1.TriggerDefragmentationRequest
+	16, // 19: etcdbr.v1alpha1.BackupRestore.WatchEvents:input_type -> etcdbr.v1alpha1.WatchEventsRequest
+	2,  // 20: etcdbr.v1alpha1.BackupRestore.TriggerFullSnapshot:output_type -> etcdbr.v1alpha1.Snapshot
+	2,  // 21: etcdbr.v1alpha1.BackupRestore.TriggerDeltaSnapshot:output_type -> etcdbr.v1alpha1.Snapshot
+	6,  // 22: etcdbr.v1alpha1.BackupRestore.ListSnapshots:output_type -> etcdbr.v1alpha1.ListSnapshotsResponse
+	8,  // 23: etcdbr.v1alpha1.BackupRestore.GetLatestSnapshots:output_type -> etcdbr.v1alpha1.GetLatestSnapshotsResponse
+	10, // 24: etcdbr.v1alpha1.BackupRestore.GetStatus:output_type -> etcdbr.v1alpha1.Status
+	13, // 25: etcdbr.v1alpha1.BackupRestore.StartRestore:output_type -> etcdbr.v1alpha1.RestoreStatus
+	13, // 26: etcdbr.v1alpha1.BackupRestore.GetRestoreStatus:output_type -> etcdbr.v1alpha1.RestoreStatus
+	15, // 27: etcdbr.v1alpha1.BackupRestore.TriggerDefragmentation:output_type -> etcdbr.v1alpha1.TriggerDefragmentationResponse
+	17, // 28: etcdbr.v1alpha1.BackupRestore.WatchEvents:output_type -> etcdbr.v1alpha1.Event
+	20, // [20:29] is the sub-list for method output_type
+	11, // [11:20] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_backuprestore_proto_init() }
+func file_backuprestore_proto_init() {
+	if File_backuprestore_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_backuprestore_proto_rawDesc), len(file_backuprestore_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_backuprestore_proto_goTypes,
+		DependencyIndexes: file_backuprestore_proto_depIdxs,
+		EnumInfos:         file_backuprestore_proto_enumTypes,
+		MessageInfos:      file_backuprestore_proto_msgTypes,
+	}.Build()
+	File_backuprestore_proto = out.File
+	file_backuprestore_proto_goTypes = nil
+	file_backuprestore_proto_depIdxs = nil
+}
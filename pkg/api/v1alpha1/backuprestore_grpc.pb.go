@@ -0,0 +1,457 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: backuprestore.proto
+
+package v1alpha1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BackupRestore_TriggerFullSnapshot_FullMethodName    = "/etcdbr.v1alpha1.BackupRestore/TriggerFullSnapshot"
+	BackupRestore_TriggerDeltaSnapshot_FullMethodName   = "/etcdbr.v1alpha1.BackupRestore/TriggerDeltaSnapshot"
+	BackupRestore_ListSnapshots_FullMethodName          = "/etcdbr.v1alpha1.BackupRestore/ListSnapshots"
+	BackupRestore_GetLatestSnapshots_FullMethodName     = "/etcdbr.v1alpha1.BackupRestore/GetLatestSnapshots"
+	BackupRestore_GetStatus_FullMethodName              = "/etcdbr.v1alpha1.BackupRestore/GetStatus"
+	BackupRestore_StartRestore_FullMethodName           = "/etcdbr.v1alpha1.BackupRestore/StartRestore"
+	BackupRestore_GetRestoreStatus_FullMethodName       = "/etcdbr.v1alpha1.BackupRestore/GetRestoreStatus"
+	BackupRestore_TriggerDefragmentation_FullMethodName = "/etcdbr.v1alpha1.BackupRestore/TriggerDefragmentation"
+	BackupRestore_WatchEvents_FullMethodName            = "/etcdbr.v1alpha1.BackupRestore/WatchEvents"
+)
+
+// BackupRestoreClient is the client API for BackupRestore service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BackupRestore is the control-plane API of the etcd-backup-restore sidecar.
+// It is served next to the HTTP API and shares its handlers.
+type BackupRestoreClient interface {
+	// TriggerFullSnapshot takes an out-of-schedule full snapshot.
+	TriggerFullSnapshot(ctx context.Context, in *TriggerFullSnapshotRequest, opts ...grpc.CallOption) (*Snapshot, error)
+	// TriggerDeltaSnapshot takes an out-of-schedule delta snapshot.
+	TriggerDeltaSnapshot(ctx context.Context, in *TriggerDeltaSnapshotRequest, opts ...grpc.CallOption) (*Snapshot, error)
+	// ListSnapshots lists the snapshots present in the snapstore.
+	ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error)
+	// GetLatestSnapshots returns the latest full snapshot and the delta snapshots taken after it.
+	GetLatestSnapshots(ctx context.Context, in *GetLatestSnapshotsRequest, opts ...grpc.CallOption) (*GetLatestSnapshotsResponse, error)
+	// GetStatus returns the health and the state of the sidecar.
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error)
+	// StartRestore starts the validation of the etcd data directory and restores it from the snapstore if required.
+	StartRestore(ctx context.Context, in *StartRestoreRequest, opts ...grpc.CallOption) (*RestoreStatus, error)
+	// GetRestoreStatus returns the progress of the restoration started with StartRestore.
+	GetRestoreStatus(ctx context.Context, in *GetRestoreStatusRequest, opts ...grpc.CallOption) (*RestoreStatus, error)
+	// TriggerDefragmentation defragments the data directories of all etcd cluster members.
+	TriggerDefragmentation(ctx context.Context, in *TriggerDefragmentationRequest, opts ...grpc.CallOption) (*TriggerDefragmentationResponse, error)
+	// WatchEvents streams the operation events emitted by the sidecar.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type backupRestoreClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBackupRestoreClient(cc grpc.ClientConnInterface) BackupRestoreClient {
+	return &backupRestoreClient{cc}
+}
+
+func (c *backupRestoreClient) TriggerFullSnapshot(ctx context.Context, in *TriggerFullSnapshotRequest, opts ...grpc.CallOption) (*Snapshot, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Snapshot)
+	err := c.cc.Invoke(ctx, BackupRestore_TriggerFullSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backupRestoreClient) TriggerDeltaSnapshot(ctx context.Context, in *TriggerDeltaSnapshotRequest, opts ...grpc.CallOption) (*Snapshot, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Snapshot)
+	err := c.cc.Invoke(ctx, BackupRestore_TriggerDeltaSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backupRestoreClient) ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSnapshotsResponse)
+	err := c.cc.Invoke(ctx, BackupRestore_ListSnapshots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backupRestoreClient) GetLatestSnapshots(ctx context.Context, in *GetLatestSnapshotsRequest, opts ...grpc.CallOption) (*GetLatestSnapshotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetLatestSnapshotsResponse)
+	err := c.cc.Invoke(ctx, BackupRestore_GetLatestSnapshots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backupRestoreClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, BackupRestore_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backupRestoreClient) StartRestore(ctx context.Context, in *StartRestoreRequest, opts ...grpc.CallOption) (*RestoreStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreStatus)
+	err := c.cc.Invoke(ctx, BackupRestore_StartRestore_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backupRestoreClient) GetRestoreStatus(ctx context.Context, in *GetRestoreStatusRequest, opts ...grpc.CallOption) (*RestoreStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreStatus)
+	err := c.cc.Invoke(ctx, BackupRestore_GetRestoreStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backupRestoreClient) TriggerDefragmentation(ctx context.Context, in *TriggerDefragmentationRequest, opts ...grpc.CallOption) (*TriggerDefragmentationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TriggerDefragmentationResponse)
+	err := c.cc.Invoke(ctx, BackupRestore_TriggerDefragmentation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backupRestoreClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BackupRestore_ServiceDesc.Streams[0], BackupRestore_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BackupRestore_WatchEventsClient = grpc.ServerStreamingClient[Event]
+
+// BackupRestoreServer is the server API for BackupRestore service.
+// All implementations must embed UnimplementedBackupRestoreServer
+// for forward compatibility.
+//
+// BackupRestore is the control-plane API of the etcd-backup-restore sidecar.
+// It is served next to the HTTP API and shares its handlers.
+type BackupRestoreServer interface {
+	// TriggerFullSnapshot takes an out-of-schedule full snapshot.
+	TriggerFullSnapshot(context.Context, *TriggerFullSnapshotRequest) (*Snapshot, error)
+	// TriggerDeltaSnapshot takes an out-of-schedule delta snapshot.
+	TriggerDeltaSnapshot(context.Context, *TriggerDeltaSnapshotRequest) (*Snapshot, error)
+	// ListSnapshots lists the snapshots present in the snapstore.
+	ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error)
+	// GetLatestSnapshots returns the latest full snapshot and the delta snapshots taken after it.
+	GetLatestSnapshots(context.Context, *GetLatestSnapshotsRequest) (*GetLatestSnapshotsResponse, error)
+	// GetStatus returns the health and the state of the sidecar.
+	GetStatus(context.Context, *GetStatusRequest) (*Status, error)
+	// StartRestore starts the validation of the etcd data directory and restores it from the snapstore if required.
+	StartRestore(context.Context, *StartRestoreRequest) (*RestoreStatus, error)
+	// GetRestoreStatus returns the progress of the restoration started with StartRestore.
+	GetRestoreStatus(context.Context, *GetRestoreStatusRequest) (*RestoreStatus, error)
+	// TriggerDefragmentation defragments the data directories of all etcd cluster members.
+	TriggerDefragmentation(context.Context, *TriggerDefragmentationRequest) (*TriggerDefragmentationResponse, error)
+	// WatchEvents streams the operation events emitted by the sidecar.
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedBackupRestoreServer()
+}
+
+// UnimplementedBackupRestoreServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBackupRestoreServer struct{}
+
+func (UnimplementedBackupRestoreServer) TriggerFullSnapshot(context.Context, *TriggerFullSnapshotRequest) (*Snapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerFullSnapshot not implemented")
+}
+func (UnimplementedBackupRestoreServer) TriggerDeltaSnapshot(context.Context, *TriggerDeltaSnapshotRequest) (*Snapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerDeltaSnapshot not implemented")
+}
+func (UnimplementedBackupRestoreServer) ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSnapshots not implemented")
+}
+func (UnimplementedBackupRestoreServer) GetLatestSnapshots(context.Context, *GetLatestSnapshotsRequest) (*GetLatestSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatestSnapshots not implemented")
+}
+func (UnimplementedBackupRestoreServer) GetStatus(context.Context, *GetStatusRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedBackupRestoreServer) StartRestore(context.Context, *StartRestoreRequest) (*RestoreStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartRestore not implemented")
+}
+func (UnimplementedBackupRestoreServer) GetRestoreStatus(context.Context, *GetRestoreStatusRequest) (*RestoreStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRestoreStatus not implemented")
+}
+func (UnimplementedBackupRestoreServer) TriggerDefragmentation(context.Context, *TriggerDefragmentationRequest) (*TriggerDefragmentationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerDefragmentation not implemented")
+}
+func (UnimplementedBackupRestoreServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedBackupRestoreServer) mustEmbedUnimplementedBackupRestoreServer() {}
+func (UnimplementedBackupRestoreServer) testEmbeddedByValue()                       {}
+
+// UnsafeBackupRestoreServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BackupRestoreServer will
+// result in compilation errors.
+type UnsafeBackupRestoreServer interface {
+	mustEmbedUnimplementedBackupRestoreServer()
+}
+
+func RegisterBackupRestoreServer(s grpc.ServiceRegistrar, srv BackupRestoreServer) {
+	// If the following call pancis, it indicates UnimplementedBackupRestoreServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BackupRestore_ServiceDesc, srv)
+}
+
+func _BackupRestore_TriggerFullSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerFullSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupRestoreServer).TriggerFullSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupRestore_TriggerFullSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupRestoreServer).TriggerFullSnapshot(ctx, req.(*TriggerFullSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BackupRestore_TriggerDeltaSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerDeltaSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupRestoreServer).TriggerDeltaSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupRestore_TriggerDeltaSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupRestoreServer).TriggerDeltaSnapshot(ctx, req.(*TriggerDeltaSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BackupRestore_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupRestoreServer).ListSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupRestore_ListSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupRestoreServer).ListSnapshots(ctx, req.(*ListSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BackupRestore_GetLatestSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLatestSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupRestoreServer).GetLatestSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupRestore_GetLatestSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupRestoreServer).GetLatestSnapshots(ctx, req.(*GetLatestSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BackupRestore_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupRestoreServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupRestore_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupRestoreServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BackupRestore_StartRestore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartRestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupRestoreServer).StartRestore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupRestore_StartRestore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupRestoreServer).StartRestore(ctx, req.(*StartRestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BackupRestore_GetRestoreStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRestoreStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupRestoreServer).GetRestoreStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupRestore_GetRestoreStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupRestoreServer).GetRestoreStatus(ctx, req.(*GetRestoreStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BackupRestore_TriggerDefragmentation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerDefragmentationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackupRestoreServer).TriggerDefragmentation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BackupRestore_TriggerDefragmentation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BackupRestoreServer).TriggerDefragmentation(ctx, req.(*TriggerDefragmentationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BackupRestore_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BackupRestoreServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BackupRestore_WatchEventsServer = grpc.ServerStreamingServer[Event]
+
+// BackupRestore_ServiceDesc is the grpc.ServiceDesc for BackupRestore service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BackupRestore_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "etcdbr.v1alpha1.BackupRestore",
+	HandlerType: (*BackupRestoreServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "TriggerFullSnapshot",
+			Handler:    _BackupRestore_TriggerFullSnapshot_Handler,
+		},
+		{
+			MethodName: "TriggerDeltaSnapshot",
+			Handler:    _BackupRestore_TriggerDeltaSnapshot_Handler,
+		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _BackupRestore_ListSnapshots_Handler,
+		},
+		{
+			MethodName: "GetLatestSnapshots",
+			Handler:    _BackupRestore_GetLatestSnapshots_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _BackupRestore_GetStatus_Handler,
+		},
+		{
+			MethodName: "StartRestore",
+			Handler:    _BackupRestore_StartRestore_Handler,
+		},
+		{
+			MethodName: "GetRestoreStatus",
+			Handler:    _BackupRestore_GetRestoreStatus_Handler,
+		},
+		{
+			MethodName: "TriggerDefragmentation",
+			Handler:    _BackupRestore_TriggerDefragmentation_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _BackupRestore_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "backuprestore.proto",
+}
@@ -43,14 +43,18 @@ func CompressSnapshot(data io.ReadCloser, compressionPolicy string) (io.ReadClos
 	go func() {
 		var err error
 		var n int64
-		defer pWriter.CloseWithError(err)
-		defer gWriter.Close()
 		defer data.Close()
 		n, err = io.Copy(gWriter, data)
 		if err != nil {
 			logger.Errorf("compression failed: %v", err)
+			// the read error must reach the reader, a closed compression stream would look like a complete snapshot
+			pWriter.CloseWithError(err)
 			return
 		}
+		if err = gWriter.Close(); err != nil {
+			logger.Errorf("compression failed: %v", err)
+		}
+		pWriter.CloseWithError(err)
 		logger.Infof("Total written bytes: %v", n)
 	}()
 
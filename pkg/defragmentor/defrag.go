@@ -6,9 +6,11 @@ package defragmentor
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/gardener/etcd-backup-restore/pkg/etcdutil"
+	"github.com/gardener/etcd-backup-restore/pkg/etcdutil/client"
 	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
@@ -46,11 +48,11 @@ func (d *defragmentorJob) Run() {
 	}
 	defer clientMaintenance.Close()
 
-	client, err := clientFactory.NewCluster()
+	clientCluster, err := clientFactory.NewCluster()
 	if err != nil {
 		d.logger.Warnf("failed to create etcd cluster client")
 	}
-	defer client.Close()
+	defer clientCluster.Close()
 
 	ticker := time.NewTicker(brtypes.DefragRetryPeriod)
 	defer ticker.Stop()
@@ -61,37 +63,66 @@ waitLoop:
 		case <-d.ctx.Done():
 			return
 		case <-ticker.C:
-			etcdEndpoints, err := miscellaneous.GetAllEtcdEndpoints(d.ctx, client, d.etcdConnectionConfig, d.logger)
-			if err != nil {
-				d.logger.Errorf("failed to get endpoints of all members of etcd cluster: %v", err)
+			if err := defragmentCluster(d.ctx, clientMaintenance, clientCluster, d.etcdConnectionConfig, d.logger); err != nil {
+				d.logger.Warnf("failed to defrag data with error: %v", err)
 				continue
 			}
-			d.logger.Infof("All etcd members endPoints: %v", etcdEndpoints)
-
-			isClusterHealthy, err := miscellaneous.IsEtcdClusterHealthy(d.ctx, clientMaintenance, d.etcdConnectionConfig, etcdEndpoints, d.logger)
-			if err != nil {
-				d.logger.Errorf("failed to defrag as all members of etcd cluster are not healthy: %v", err)
-				continue
-			}
-
-			if isClusterHealthy {
-				d.logger.Infof("Starting the defragmentation as all members of etcd cluster are in healthy state")
-				err = etcdutil.DefragmentData(d.ctx, clientMaintenance, client, etcdEndpoints, d.etcdConnectionConfig.DefragTimeout.Duration, d.logger)
-				if err != nil {
-					d.logger.Warnf("failed to defrag data with error: %v", err)
-					continue
+			if d.callback != nil {
+				if _, err = d.callback(d.ctx, false); err != nil {
+					d.logger.Warnf("defragmentation callback failed with error: %v", err)
 				}
-				if d.callback != nil {
-					if _, err = d.callback(d.ctx, false); err != nil {
-						d.logger.Warnf("defragmentation callback failed with error: %v", err)
-					}
-				}
-				break waitLoop
 			}
+			break waitLoop
 		}
 	}
 }
 
+// Defragment defragments the data directory of each etcd member once, provided all members of the etcd cluster are healthy.
+// If a callback is given, it is invoked after a successful defragmentation and the snapshot it returns is passed on to the caller.
+func Defragment(ctx context.Context, etcdConnectionConfig *brtypes.EtcdConnectionConfig, callback CallbackFunc, logger *logrus.Entry) (*brtypes.Snapshot, error) {
+	clientFactory := etcdutil.NewFactory(*etcdConnectionConfig)
+
+	clientMaintenance, err := clientFactory.NewMaintenance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd maintenance client: %v", err)
+	}
+	defer clientMaintenance.Close()
+
+	clientCluster, err := clientFactory.NewCluster()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd cluster client: %v", err)
+	}
+	defer clientCluster.Close()
+
+	if err := defragmentCluster(ctx, clientMaintenance, clientCluster, etcdConnectionConfig, logger); err != nil {
+		return nil, err
+	}
+	if callback == nil {
+		return nil, nil
+	}
+	return callback(ctx, false)
+}
+
+// defragmentCluster defragments all members of the etcd cluster if all of them are in healthy state.
+func defragmentCluster(ctx context.Context, clientMaintenance client.MaintenanceCloser, clientCluster client.ClusterCloser, etcdConnectionConfig *brtypes.EtcdConnectionConfig, logger *logrus.Entry) error {
+	etcdEndpoints, err := miscellaneous.GetAllEtcdEndpoints(ctx, clientCluster, etcdConnectionConfig, logger)
+	if err != nil {
+		return fmt.Errorf("failed to get endpoints of all members of etcd cluster: %v", err)
+	}
+	logger.Infof("All etcd members endPoints: %v", etcdEndpoints)
+
+	isClusterHealthy, err := miscellaneous.IsEtcdClusterHealthy(ctx, clientMaintenance, etcdConnectionConfig, etcdEndpoints, logger)
+	if err != nil {
+		return fmt.Errorf("failed to defrag as all members of etcd cluster are not healthy: %v", err)
+	}
+	if !isClusterHealthy {
+		return fmt.Errorf("failed to defrag as all members of etcd cluster are not healthy")
+	}
+
+	logger.Infof("Starting the defragmentation as all members of etcd cluster are in healthy state")
+	return etcdutil.DefragmentData(ctx, clientMaintenance, clientCluster, etcdEndpoints, etcdConnectionConfig.DefragTimeout.Duration, logger)
+}
+
 // DefragDataPeriodically defragments the data directory of each etcd member.
 func DefragDataPeriodically(ctx context.Context, etcdConnectionConfig *brtypes.EtcdConnectionConfig, defragmentationSchedule cron.Schedule, callback CallbackFunc, logger *logrus.Entry) {
 	defragmentorJob := NewDefragmentorJob(ctx, etcdConnectionConfig, logger, callback)
@@ -19,7 +19,10 @@ import (
 	"github.com/gardener/etcd-backup-restore/pkg/errors"
 	"github.com/gardener/etcd-backup-restore/pkg/etcdutil/client"
 	"github.com/gardener/etcd-backup-restore/pkg/metrics"
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
+	"github.com/gardener/etcd-backup-restore/pkg/snapshot/pagediff"
 	"github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -154,11 +157,13 @@ func PerformDefragmentation(defragCtx context.Context, client client.Maintenance
 	start := time.Now()
 	if _, err := client.Defragment(defragCtx, endpoint); err != nil {
 		metrics.DefragmentationDurationSeconds.With(prometheus.Labels{metrics.LabelSucceeded: metrics.ValueSucceededFalse, metrics.LabelEndPoint: endpoint}).Observe(time.Since(start).Seconds())
+		notifier.Notify(notifier.Event{Type: notifier.EventDefragmentationFailed, Data: notifier.EventData{Endpoint: endpoint, Error: err.Error(), DurationSeconds: time.Since(start).Seconds()}})
 		logger.Errorf("failed to defragment etcd member[%s] with error: %v", endpoint, err)
 		return err
 	}
 
 	metrics.DefragmentationDurationSeconds.With(prometheus.Labels{metrics.LabelSucceeded: metrics.ValueSucceededTrue, metrics.LabelEndPoint: endpoint}).Observe(time.Since(start).Seconds())
+	notifier.Notify(notifier.Event{Type: notifier.EventDefragmentationSucceeded, Data: notifier.EventData{Endpoint: endpoint, DurationSeconds: time.Since(start).Seconds()}})
 	logger.Infof("Finished defragmenting etcd member[%s]", endpoint)
 	// Since below request for status races with other etcd operations. So, size returned in
 	// status might vary from the precise size just after defragmentation.
@@ -267,13 +272,53 @@ func GetEtcdEndPointsSorted(ctx context.Context, clientMaintenance client.Mainte
 //  3. compress the full snapshot(if compression is enabled)
 //  4. finally, save the full snapshot to object store(if configured).
 func TakeAndSaveFullSnapshot(ctx context.Context, client client.MaintenanceCloser, store brtypes.SnapStore, tempDir string, lastRevision int64, cc *compressor.CompressionConfig, suffix string, isFinal bool, logger *logrus.Entry) (*brtypes.Snapshot, error) {
+	snapshot, _, err := takeAndSaveSnapshot(ctx, client, store, tempDir, lastRevision, cc, suffix, isFinal, nil, false, logger)
+	return snapshot, err
+}
+
+// TakeAndSaveFullSnapshotWithPageHashes takes and saves a full snapshot like TakeAndSaveFullSnapshot. It also returns
+// the hashes of the pages of the full snapshot, against which page diff snapshots can be taken.
+func TakeAndSaveFullSnapshotWithPageHashes(ctx context.Context, client client.MaintenanceCloser, store brtypes.SnapStore, tempDir string, lastRevision int64, cc *compressor.CompressionConfig, suffix string, isFinal bool, logger *logrus.Entry) (*brtypes.Snapshot, *pagediff.Hashes, error) {
+	return takeAndSaveSnapshot(ctx, client, store, tempDir, lastRevision, cc, suffix, isFinal, nil, true, logger)
+}
+
+// TakeAndSavePageDiffSnapshot takes a full snapshot of etcd like TakeAndSaveFullSnapshot, but only saves the pages
+// which changed since the base full snapshot with the given page hashes, as page diff snapshot.
+func TakeAndSavePageDiffSnapshot(ctx context.Context, client client.MaintenanceCloser, store brtypes.SnapStore, tempDir string, lastRevision int64, cc *compressor.CompressionConfig, suffix string, base *brtypes.Snapshot, baseHashes *pagediff.Hashes, logger *logrus.Entry) (*brtypes.Snapshot, error) {
+	snapshot, _, err := takeAndSaveSnapshot(ctx, client, store, tempDir, lastRevision, cc, suffix, false, &pageDiffBase{snapshot: base, hashes: baseHashes}, false, logger)
+	return snapshot, err
+}
+
+// pageDiffBase is the full snapshot a page diff snapshot is taken against.
+type pageDiffBase struct {
+	snapshot *brtypes.Snapshot
+	hashes   *pagediff.Hashes
+}
+
+// takeAndSaveSnapshot takes a full snapshot of etcd and saves it, or the page diff snapshot against the given base.
+// The hashes of the pages of a full snapshot are returned if requested.
+func takeAndSaveSnapshot(ctx context.Context, client client.MaintenanceCloser, store brtypes.SnapStore, tempDir string, lastRevision int64, cc *compressor.CompressionConfig, suffix string, isFinal bool, base *pageDiffBase, hashPages bool, logger *logrus.Entry) (snapshot *brtypes.Snapshot, hashes *pagediff.Hashes, err error) {
+	kind := brtypes.SnapshotKindFull
+	if base != nil {
+		kind = brtypes.SnapshotKindPageDiff
+	}
+	ctx, span := tracing.Start(ctx, "etcdutil.TakeAndSaveFullSnapshot", tracing.AttributeSnapshotKind.String(kind), tracing.AttributeSnapshotLastRevision.Int64(lastRevision))
+	defer func() {
+		span.SetAttributes(tracing.SnapshotAttributes(snapshot)...)
+		tracing.End(span, err)
+	}()
+
 	startTime := time.Now()
-	rc, err := client.Snapshot(ctx)
+	// the span ends once the snapshot has been streamed from etcd.
+	snapshotCtx, snapshotSpan := tracing.Start(ctx, "etcd.Snapshot", tracing.AttributeSnapshotLastRevision.Int64(lastRevision))
+	rc, err := client.Snapshot(snapshotCtx)
 	if err != nil {
-		return nil, &errors.EtcdError{
+		tracing.End(snapshotSpan, err)
+		return nil, nil, &errors.EtcdError{
 			Message: fmt.Sprintf("failed to create etcd snapshot: %v", err),
 		}
 	}
+	rc = tracing.NewSpanReadCloser(rc, snapshotSpan)
 	defer rc.Close()
 	timeTaken := time.Since(startTime)
 	logger.Infof("Total time taken by Snapshot API: %f seconds.", timeTaken.Seconds())
@@ -298,33 +343,88 @@ func TakeAndSaveFullSnapshot(ctx context.Context, client client.MaintenanceClose
 
 	// check the integrity of full snapshot before compression and upload to object store.
 	// for more info: https://github.com/gardener/etcd-backup-restore/issues/778
-	if snapshotData, err = checkFullSnapshotIntegrity(rc, snapshotTempDBPath, logger); err != nil {
+	if snapshotData, err = checkFullSnapshotIntegrity(ctx, rc, snapshotTempDBPath, logger); err != nil {
 		logger.Errorf("verification of full snapshot SHA256 hash has failed: %v", err)
-		return nil, err
+		return nil, nil, err
 	}
 	logger.Info("full snapshot SHA256 hash has been successfully verified.")
 
+	if hashPages {
+		if hashes, err = hashSnapshotPages(snapshotData); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var startRevision int64
+	if base != nil {
+		startRevision = base.snapshot.LastRevision
+		if snapshotData, err = diffSnapshotPages(snapshotData, snapshotTempDBPath, base, logger); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if cc.Enabled {
-		snapshotData, err = compressor.CompressSnapshot(snapshotData, cc.CompressionPolicy)
+		// the span ends once the compressor has consumed the snapshot data.
+		_, compressionSpan := tracing.Start(ctx, "compressor.CompressSnapshot", tracing.AttributeCompressionPolicy.String(cc.CompressionPolicy))
+		snapshotData, err = compressor.CompressSnapshot(tracing.NewSpanReadCloser(snapshotData, compressionSpan), cc.CompressionPolicy)
 		if err != nil {
-			return nil, fmt.Errorf("unable to obtain reader for compressed file: %v", err)
+			tracing.End(compressionSpan, err)
+			return nil, nil, fmt.Errorf("unable to obtain reader for compressed file: %v", err)
 		}
 	}
 
 	logger.Infof("Successfully opened snapshot reader on etcd")
 
 	// save the snapshot to the store.
-	snapshot, err := saveSnapshotToStore(store, snapshotData, startTime, brtypes.SnapshotKindFull, lastRevision, suffix, isFinal, logger)
+	snapshot, err = saveSnapshotToStore(store, snapshotData, startTime, kind, startRevision, lastRevision, suffix, isFinal, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hashes != nil {
+		hashes.BaseName = snapshot.SnapName
+	}
+
+	return snapshot, hashes, nil
+}
+
+// hashSnapshotPages computes the hashes of the pages of the verified full snapshot and rewinds it afterwards.
+func hashSnapshotPages(snapshotData io.ReadCloser) (*pagediff.Hashes, error) {
+	db, ok := snapshotData.(io.ReadSeeker)
+	if !ok {
+		return nil, fmt.Errorf("unable to hash pages of full snapshot: snapshot data is not seekable")
+	}
+	hashes, err := pagediff.ComputeHashes(db, "")
 	if err != nil {
+		return nil, fmt.Errorf("unable to hash pages of full snapshot: %v", err)
+	}
+	if _, err := db.Seek(0, io.SeekStart); err != nil {
 		return nil, err
 	}
+	return hashes, nil
+}
 
-	return snapshot, nil
+// diffSnapshotPages returns a reader of the page diff snapshot of the verified full snapshot against the base.
+// The full snapshot is closed once the page diff snapshot has been read.
+func diffSnapshotPages(snapshotData io.ReadCloser, snapshotTempDBPath string, base *pageDiffBase, logger *logrus.Entry) (io.ReadCloser, error) {
+	info, err := os.Stat(snapshotTempDBPath)
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		defer snapshotData.Close()
+		changed, err := pagediff.Write(pw, snapshotData, info.Size(), base.hashes)
+		if err == nil {
+			logger.Infof("%d of %d pages changed since full snapshot %s", changed, (info.Size()+int64(base.hashes.PageSize())-1)/int64(base.hashes.PageSize()), base.snapshot.SnapName)
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
 }
 
 // checkFullSnapshotIntegrity verifies the integrity of the full snapshot by comparing
 // the appended SHA256 hash of the full snapshot with the calculated SHA256 hash of the full snapshot data.
-func checkFullSnapshotIntegrity(snapshotData io.ReadCloser, snapTempDBFilePath string, logger *logrus.Entry) (io.ReadCloser, error) {
+func checkFullSnapshotIntegrity(ctx context.Context, snapshotData io.ReadCloser, snapTempDBFilePath string, logger *logrus.Entry) (_ io.ReadCloser, err error) {
 	logger.Info("checking the full snapshot integrity with the help of SHA256")
 
 	// If previous temp db file already exist then remove it.
@@ -344,6 +444,9 @@ func checkFullSnapshotIntegrity(snapshotData io.ReadCloser, snapTempDBFilePath s
 		return nil, err
 	}
 
+	_, span := tracing.Start(ctx, "etcdutil.VerifySnapshotHash")
+	defer func() { tracing.End(span, err) }()
+
 	lastOffset, err := db.Seek(0, io.SeekEnd)
 	if err != nil {
 		return nil, err
@@ -396,13 +499,14 @@ func checkFullSnapshotIntegrity(snapshotData io.ReadCloser, snapTempDBFilePath s
 }
 
 // saveSnapshotToStore save the snapshot to object store
-func saveSnapshotToStore(store brtypes.SnapStore, rc io.ReadCloser, startTime time.Time, snapshotKind string, lastRevision int64, suffix string, isFinal bool, logger *logrus.Entry) (*brtypes.Snapshot, error) {
-	snapshot := snapstore.NewSnapshot(snapshotKind, 0, lastRevision, suffix, isFinal)
+func saveSnapshotToStore(store brtypes.SnapStore, rc io.ReadCloser, startTime time.Time, snapshotKind string, startRevision, lastRevision int64, suffix string, isFinal bool, logger *logrus.Entry) (*brtypes.Snapshot, error) {
+	snapshot := snapstore.NewSnapshot(snapshotKind, startRevision, lastRevision, suffix, isFinal)
 
 	// save the snapshot to object store
 	if err := store.Save(*snapshot, rc); err != nil {
 		timeTaken := time.Since(startTime)
 		metrics.SnapshotDurationSeconds.With(prometheus.Labels{metrics.LabelKind: snapshot.Kind, metrics.LabelSucceeded: metrics.ValueSucceededFalse}).Observe(timeTaken.Seconds())
+		notifier.Notify(notifier.Event{Type: notifier.EventSnapshotFailed, Data: notifier.EventData{Kind: snapshot.Kind, SnapName: snapshot.SnapName, LastRevision: snapshot.LastRevision, Error: err.Error(), DurationSeconds: timeTaken.Seconds()}})
 		return nil, &errors.SnapstoreError{
 			Message: fmt.Sprintf("failed to save snapshot: %v", err),
 		}
@@ -410,6 +514,7 @@ func saveSnapshotToStore(store brtypes.SnapStore, rc io.ReadCloser, startTime ti
 
 	timeTaken := time.Since(startTime)
 	metrics.SnapshotDurationSeconds.With(prometheus.Labels{metrics.LabelKind: snapshot.Kind, metrics.LabelSucceeded: metrics.ValueSucceededTrue}).Observe(timeTaken.Seconds())
+	notifier.Notify(notifier.Event{Type: notifier.EventSnapshotSucceeded, Data: notifier.EventData{Kind: snapshot.Kind, SnapName: snapshot.SnapName, SnapDir: snapshot.SnapDir, StartRevision: snapshot.StartRevision, LastRevision: snapshot.LastRevision, DurationSeconds: timeTaken.Seconds()}})
 	logger.Infof("Total time to save %s snapshot: %f seconds.", snapshot.Kind, timeTaken.Seconds())
 	return snapshot, nil
 }
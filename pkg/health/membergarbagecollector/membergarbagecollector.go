@@ -14,6 +14,7 @@ import (
 	"github.com/gardener/etcd-backup-restore/pkg/etcdutil"
 	etcdclient "github.com/gardener/etcd-backup-restore/pkg/etcdutil/client"
 	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
 	utils "github.com/gardener/etcd-backup-restore/pkg/snapstore"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 	"github.com/gardener/etcd-backup-restore/pkg/wrappers"
@@ -159,6 +160,7 @@ func (mgc *MemberGarbageCollector) RemoveSuperfluousMembers(ctx context.Context,
 				}
 			}
 			mgc.logger.Info("Removed superfluous member ", member.Name, ":", member.ID)
+			notifier.Notify(notifier.Event{Type: notifier.EventMemberRemoved, Data: notifier.EventData{Member: member.Name}})
 		}
 	}
 	return nil
@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package initializer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/initializer/validator"
+	"github.com/gardener/etcd-backup-restore/pkg/member"
+	"github.com/gardener/etcd-backup-restore/pkg/metrics"
+	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"go.etcd.io/etcd/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// restoreClusterIfRequested takes part in the restoration of all members of the cluster, if it is requested with the
+// miscellaneous.ClusterRestoreAnnotationKey annotation of the etcd StatefulSet and the member has not taken part yet.
+// It returns true if the member has restored the cluster or has joined the restored cluster.
+func (e *EtcdInitializer) restoreClusterIfRequested(ctx context.Context, clientSet client.Client, m member.Control) (bool, error) {
+	logger := e.Logger.WithField("actor", "cluster-restore")
+	podName, err := miscellaneous.GetEnvVarOrError("POD_NAME")
+	if err != nil {
+		return false, err
+	}
+	podNamespace, err := miscellaneous.GetEnvVarOrError("POD_NAMESPACE")
+	if err != nil {
+		return false, err
+	}
+
+	coordinator, err := member.NewClusterRestoreCoordinator(ctx, clientSet, podName, podNamespace, logger)
+	if err != nil {
+		return false, err
+	}
+	if coordinator == nil {
+		return false, nil
+	}
+	pending, err := coordinator.IsPending(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !pending {
+		logger.Infof("Member already took part in cluster restoration %s", coordinator.RestoreID())
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, clusterRestoreTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if coordinator.IsSeed() && coordinator.Source() == miscellaneous.ClusterRestoreSourceDataDir {
+		err = e.recoverClusterSeedFromDataDir(podName, logger)
+	} else if coordinator.IsSeed() {
+		err = e.restoreClusterSeed(podName, coordinator, logger)
+	} else {
+		err = e.joinRestoredCluster(ctx, coordinator, m, logger)
+	}
+	if err == nil {
+		err = coordinator.MarkJoined(ctx)
+	}
+	if err != nil {
+		metrics.RestorationDurationSeconds.With(prometheus.Labels{metrics.LabelRestorationKind: metrics.ValueRestoreCluster, metrics.LabelSucceeded: metrics.ValueSucceededFalse}).Observe(time.Since(start).Seconds())
+		notifier.Notify(notifier.Event{Type: notifier.EventRestorationFailed, Data: notifier.EventData{Kind: metrics.ValueRestoreCluster, Error: err.Error(), DurationSeconds: time.Since(start).Seconds()}})
+		return false, err
+	}
+	metrics.RestorationDurationSeconds.With(prometheus.Labels{metrics.LabelRestorationKind: metrics.ValueRestoreCluster, metrics.LabelSucceeded: metrics.ValueSucceededTrue}).Observe(time.Since(start).Seconds())
+	notifier.Notify(notifier.Event{Type: notifier.EventRestorationSucceeded, Data: notifier.EventData{Kind: metrics.ValueRestoreCluster, DurationSeconds: time.Since(start).Seconds()}})
+	return true, nil
+}
+
+// restoreClusterSeed restores the data directory of the seed from the latest snapshots as a single member cluster
+// with a fresh cluster token, which the other members join as learners.
+func (e *EtcdInitializer) restoreClusterSeed(podName string, coordinator *member.ClusterRestoreCoordinator, logger *logrus.Entry) error {
+	if miscellaneous.IsBackupBucketEmpty(e.Config.SnapstoreConfig, e.Logger) {
+		return fmt.Errorf("no snapshot found to restore the cluster from")
+	}
+
+	peerURLs, err := miscellaneous.GetMemberPeerURLs(miscellaneous.GetConfigFilePath())
+	if err != nil {
+		return fmt.Errorf("unable to get the peer URLs of member %s: %w", podName, err)
+	}
+	initialCluster := make([]string, 0, len(peerURLs))
+	for _, peerURL := range peerURLs {
+		initialCluster = append(initialCluster, fmt.Sprintf("%s=%s", podName, peerURL))
+	}
+
+	restoreOptions := e.Config.RestoreOptions.DeepCopy()
+	restoreOptions.Config.Name = podName
+	restoreOptions.Config.InitialCluster = strings.Join(initialCluster, ",")
+	restoreOptions.Config.InitialClusterToken = coordinator.ClusterToken(restoreOptions.Config.InitialClusterToken)
+	restoreOptions.Config.InitialAdvertisePeerURLs = peerURLs
+	if restoreOptions.ClusterURLs, err = types.NewURLsMap(restoreOptions.Config.InitialCluster); err != nil {
+		return fmt.Errorf("failed creating url map for restored cluster: %w", err)
+	}
+	if restoreOptions.PeerURLs, err = types.NewURLs(peerURLs); err != nil {
+		return fmt.Errorf("failed creating peer urls for restored cluster: %w", err)
+	}
+
+	logger.Infof("Restoring the cluster as single member cluster %s with cluster token %s", restoreOptions.Config.InitialCluster, restoreOptions.Config.InitialClusterToken)
+	restored, err := e.restoreCorruptData(restoreOptions, metrics.ValueRestoreCluster)
+	if err != nil {
+		return fmt.Errorf("error while restoring the cluster: %w", err)
+	}
+	if !restored {
+		return fmt.Errorf("no snapshot restored to restore the cluster from")
+	}
+	return nil
+}
+
+// recoverClusterSeedFromDataDir turns the data directory of the seed into a single member cluster by starting an
+// embedded etcd with force-new-cluster on it, which removes all other members from the cluster.
+func (e *EtcdInitializer) recoverClusterSeedFromDataDir(podName string, logger *logrus.Entry) error {
+	dataDir := e.Config.RestoreOptions.Config.DataDir
+	notifier.Notify(notifier.Event{Type: notifier.EventRestorationStarted, Data: notifier.EventData{Kind: metrics.ValueRestoreCluster}})
+	revision, err := validator.GetLatestEtcdRevision(dataDir)
+	if err != nil {
+		return fmt.Errorf("unable to recover the cluster from data directory %s: %w", dataDir, err)
+	}
+
+	restoreOptions := e.Config.RestoreOptions.DeepCopy()
+	restoreOptions.Config.Name = podName
+	logger.Infof("Recovering the cluster as single member cluster from data directory %s at revision %d", dataDir, revision)
+	etcd, err := miscellaneous.StartEmbeddedEtcdWithForceNewCluster(logger, restoreOptions)
+	if err != nil {
+		return fmt.Errorf("unable to start embedded etcd with force-new-cluster: %w", err)
+	}
+	defer func() {
+		etcd.Server.Stop()
+		etcd.Close()
+	}()
+
+	if members := etcd.Server.Cluster().Members(); len(members) != 1 {
+		return fmt.Errorf("recovered cluster has %d members instead of 1", len(members))
+	}
+	return nil
+}
+
+// joinRestoredCluster wipes the data directory of the member and adds it as a learner to the restored cluster,
+// once the seed has restored the cluster and all members with lower ordinals have joined it.
+func (e *EtcdInitializer) joinRestoredCluster(ctx context.Context, coordinator *member.ClusterRestoreCoordinator, m member.Control, logger *logrus.Entry) error {
+	dataDir := e.Config.RestoreOptions.Config.DataDir
+	notifier.Notify(notifier.Event{Type: notifier.EventRestorationStarted, Data: notifier.EventData{Kind: metrics.ValueRestoreCluster}})
+	// the data of the original cluster must not be used again, even if the member does not get to join the restored cluster.
+	if err := e.removeDir(dataDir); err != nil {
+		return fmt.Errorf("unable to remove the data-dir %w", err)
+	}
+
+	logger.Info("Waiting for the turn to join the restored cluster")
+	if err := coordinator.WaitForTurn(ctx); err != nil {
+		return err
+	}
+
+	// the restored cluster only accepts one learner at a time, so adding the member is retried until the previous
+	// member has been promoted.
+	for {
+		err := member.AddLearnerWithRetry(ctx, m, addLearnerAttempts, dataDir)
+		if err == nil {
+			logger.Info("Joined the restored cluster as a learner")
+			return nil
+		}
+		logger.Warnf("Unable to join the restored cluster as a learner: %v", err)
+		if err := miscellaneous.SleepWithContext(ctx, member.ClusterRestorePollInterval); err != nil {
+			return fmt.Errorf("unable to join the restored cluster as a learner: %w", err)
+		}
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/gardener/etcd-backup-restore/pkg/member"
 	"github.com/gardener/etcd-backup-restore/pkg/metrics"
 	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
 	"github.com/gardener/etcd-backup-restore/pkg/snapshot/restorer"
 	"github.com/gardener/etcd-backup-restore/pkg/snapstore"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
@@ -29,6 +30,9 @@ import (
 const (
 	// addLearnerAttempts are the total number of attempts that will be made to add a learner
 	addLearnerAttempts = 6
+	// clusterRestoreTimeout is the time after which a member gives up waiting for its turn to join a restored cluster.
+	// The initialization is then retried, and the cluster restoration continues where it left off.
+	clusterRestoreTimeout = 30 * time.Minute
 )
 
 // Initialize has the following steps:
@@ -41,6 +45,16 @@ const (
 //   - Try to perform an Etcd data restoration from the latest snapshot.
 //   - No snapshots are available, start etcd as a fresh installation.
 func (e *EtcdInitializer) Initialize(mode validator.Mode, failBelowRevision int64) error {
+	start := time.Now()
+	if err := e.initialize(mode, failBelowRevision); err != nil {
+		notifier.Notify(notifier.Event{Type: notifier.EventInitializationFailed, Data: notifier.EventData{Error: err.Error(), DurationSeconds: time.Since(start).Seconds()}})
+		return err
+	}
+	notifier.Notify(notifier.Event{Type: notifier.EventInitializationSucceeded, Data: notifier.EventData{DurationSeconds: time.Since(start).Seconds()}})
+	return nil
+}
+
+func (e *EtcdInitializer) initialize(mode validator.Mode, failBelowRevision int64) error {
 	logger := e.Logger.WithField("actor", "initializer")
 	metrics.CurrentClusterSize.With(prometheus.Labels{}).Set(float64(e.Validator.OriginalClusterSize))
 	start := time.Now()
@@ -56,6 +70,14 @@ func (e *EtcdInitializer) Initialize(mode validator.Mode, failBelowRevision int6
 
 		m := member.NewMemberControl(e.Config.EtcdConnectionConfig)
 
+		// Etcd cluster restoration case
+		if restored, err := e.restoreClusterIfRequested(ctx, clientSet, m); err != nil {
+			return fmt.Errorf("failed to restore the cluster: %w", err)
+		} else if restored {
+			// return here as the member has either restored the cluster or joined the restored cluster.
+			return nil
+		}
+
 		// check heartbeat of etcd member
 		if memberHeartbeatPresent = m.WasMemberInCluster(ctx, clientSet); memberHeartbeatPresent {
 			logger.Info("member found to be already a part of the cluster")
@@ -83,6 +105,7 @@ func (e *EtcdInitializer) Initialize(mode validator.Mode, failBelowRevision int6
 	dataDirStatus, err := e.Validator.Validate(mode, failBelowRevision)
 	if dataDirStatus == validator.WrongVolumeMounted {
 		metrics.ValidationDurationSeconds.With(prometheus.Labels{metrics.LabelSucceeded: metrics.ValueSucceededFalse}).Observe(time.Since(start).Seconds())
+		notifier.Notify(notifier.Event{Type: notifier.EventWrongVolumeMounted, Data: notifier.EventData{Error: fmt.Sprintf("%v", err)}})
 		return fmt.Errorf("won't initialize ETCD because wrong ETCD volume is mounted: %v", err)
 	}
 
@@ -102,25 +125,33 @@ func (e *EtcdInitializer) Initialize(mode validator.Mode, failBelowRevision int6
 	}
 
 	metrics.ValidationDurationSeconds.With(prometheus.Labels{metrics.LabelSucceeded: metrics.ValueSucceededTrue}).Observe(time.Since(start).Seconds())
+	if dataDirStatus == validator.DataDirectoryCorrupt {
+		notifier.Notify(notifier.Event{Type: notifier.EventDataDirectoryCorrupted, Data: notifier.EventData{SnapDir: e.Config.RestoreOptions.Config.DataDir}})
+	}
 
 	if dataDirStatus != validator.DataDirectoryValid {
 		if dataDirStatus == validator.DataDirStatusInvalidInMultiNode || (e.Validator.OriginalClusterSize > 1 && dataDirStatus == validator.DataDirectoryCorrupt) || (e.Validator.OriginalClusterSize > 1 && memberHeartbeatPresent) {
 			start := time.Now()
+			notifier.Notify(notifier.Event{Type: notifier.EventRestorationStarted, Data: notifier.EventData{Kind: metrics.ValueRestoreSingleMemberInMultiNode}})
 			if err := e.restoreInMultiNode(ctx); err != nil {
 				metrics.RestorationDurationSeconds.With(prometheus.Labels{metrics.LabelRestorationKind: metrics.ValueRestoreSingleMemberInMultiNode, metrics.LabelSucceeded: metrics.ValueSucceededFalse}).Observe(time.Since(start).Seconds())
+				notifier.Notify(notifier.Event{Type: notifier.EventRestorationFailed, Data: notifier.EventData{Kind: metrics.ValueRestoreSingleMemberInMultiNode, Error: err.Error(), DurationSeconds: time.Since(start).Seconds()}})
 				return err
 			}
 			metrics.RestorationDurationSeconds.With(prometheus.Labels{metrics.LabelRestorationKind: metrics.ValueRestoreSingleMemberInMultiNode, metrics.LabelSucceeded: metrics.ValueSucceededTrue}).Observe(time.Since(start).Seconds())
+			notifier.Notify(notifier.Event{Type: notifier.EventRestorationSucceeded, Data: notifier.EventData{Kind: metrics.ValueRestoreSingleMemberInMultiNode, DurationSeconds: time.Since(start).Seconds()}})
 		} else {
 			// For case: ClusterSize=1 or when multi-node cluster(ClusterSize>1) is bootstrapped
 			start := time.Now()
-			restored, err := e.restoreCorruptData()
+			restored, err := e.restoreCorruptData(e.Config.RestoreOptions, metrics.ValueRestoreSingleNode)
 			if err != nil {
 				metrics.RestorationDurationSeconds.With(prometheus.Labels{metrics.LabelRestorationKind: metrics.ValueRestoreSingleNode, metrics.LabelSucceeded: metrics.ValueSucceededFalse}).Observe(time.Since(start).Seconds())
+				notifier.Notify(notifier.Event{Type: notifier.EventRestorationFailed, Data: notifier.EventData{Kind: metrics.ValueRestoreSingleNode, Error: err.Error(), DurationSeconds: time.Since(start).Seconds()}})
 				return fmt.Errorf("error while restoring corrupt data: %v", err)
 			}
 			if restored {
 				metrics.RestorationDurationSeconds.With(prometheus.Labels{metrics.LabelRestorationKind: metrics.ValueRestoreSingleNode, metrics.LabelSucceeded: metrics.ValueSucceededTrue}).Observe(time.Since(start).Seconds())
+				notifier.Notify(notifier.Event{Type: notifier.EventRestorationSucceeded, Data: notifier.EventData{Kind: metrics.ValueRestoreSingleNode, DurationSeconds: time.Since(start).Seconds()}})
 			}
 		}
 	}
@@ -174,12 +205,12 @@ func NewInitializer(restoreOptions *brtypes.RestoreOptions, snapstoreConfig *brt
 	}, nil
 }
 
-// restoreCorruptData attempts to restore a corrupted data directory.
+// restoreCorruptData attempts to restore a corrupted data directory with the given restore options.
 // It returns true only if restoration was successful, and false when
 // bootstrapping a new data directory or if restoration failed
-func (e *EtcdInitializer) restoreCorruptData() (bool, error) {
+func (e *EtcdInitializer) restoreCorruptData(restoreOptions *brtypes.RestoreOptions, kind string) (bool, error) {
 	logger := e.Logger
-	tempRestoreOptions := *(e.Config.RestoreOptions.DeepCopy())
+	tempRestoreOptions := *(restoreOptions.DeepCopy())
 	dataDir := tempRestoreOptions.Config.DataDir
 
 	if e.Config.SnapstoreConfig == nil || len(e.Config.SnapstoreConfig.Provider) == 0 {
@@ -208,15 +239,19 @@ func (e *EtcdInitializer) restoreCorruptData() (bool, error) {
 	tempRestoreOptions.DeltaSnapList = deltaSnapList
 	tempRestoreOptions.Config.DataDir = fmt.Sprintf("%s.%s", tempRestoreOptions.Config.DataDir, "part")
 
-	if err := e.removeDir(tempRestoreOptions.Config.DataDir); err != nil {
-		return false, fmt.Errorf("failed to delete previous temporary data directory: %v", err)
-	}
-
 	rs, err := restorer.NewRestorer(store, logrus.NewEntry(logger))
 	if err != nil {
 		return false, err
 	}
+
+	// the temporary data directory of an interrupted restoration is kept, if the restoration can be continued from its checkpoint.
+	if rs.CanResume(tempRestoreOptions) {
+		logger.Infof("Continuing the interrupted restoration into %s", tempRestoreOptions.Config.DataDir)
+	} else if err := e.removeDir(tempRestoreOptions.Config.DataDir); err != nil {
+		return false, fmt.Errorf("failed to delete previous temporary data directory: %v", err)
+	}
 	m := member.NewMemberControl(e.Config.EtcdConnectionConfig)
+	notifier.Notify(notifier.Event{Type: notifier.EventRestorationStarted, Data: restorationStartedEventData(kind, baseSnap, deltaSnapList)})
 	if err := rs.RestoreAndStopEtcd(tempRestoreOptions, m); err != nil {
 		err = fmt.Errorf("failed to restore snapshot: %v", err)
 		return false, err
@@ -229,6 +264,20 @@ func (e *EtcdInitializer) restoreCorruptData() (bool, error) {
 	return true, nil
 }
 
+// restorationStartedEventData returns the event data of a restoration of the given kind from the given snapshots.
+func restorationStartedEventData(kind string, baseSnap *brtypes.Snapshot, deltaSnapList brtypes.SnapList) notifier.EventData {
+	data := notifier.EventData{Kind: kind}
+	if baseSnap != nil {
+		data.SnapName = baseSnap.SnapName
+		data.SnapDir = baseSnap.SnapDir
+		data.LastRevision = baseSnap.LastRevision
+	}
+	if len(deltaSnapList) > 0 {
+		data.LastRevision = deltaSnapList[len(deltaSnapList)-1].LastRevision
+	}
+	return data
+}
+
 // restoreWithEmptySnapstore removes the data directory as
 // part of restoration process for empty snapstore case.
 // It returns true if data directory removal is successful,
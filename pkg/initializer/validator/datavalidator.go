@@ -407,6 +407,12 @@ waitLoop:
 	return DataDirectoryValid, nil
 }
 
+// GetLatestEtcdRevision finds out the latest revision in the backend db file of the given etcd data directory.
+// It fails if the backend db file is held by a running etcd process.
+func GetLatestEtcdRevision(dataDir string) (int64, error) {
+	return getLatestEtcdRevision(filepath.Join(dataDir, "member", "snap", "db"))
+}
+
 // getLatestEtcdRevision finds out the latest revision on the etcd db file without starting etcd server or an embedded etcd server.
 func getLatestEtcdRevision(path string) (int64, error) {
 	if _, err := os.Stat(path); err != nil {
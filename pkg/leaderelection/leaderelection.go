@@ -6,6 +6,7 @@ package leaderelection
 
 import (
 	"context"
+	errored "errors"
 	"fmt"
 	"time"
 
@@ -35,6 +36,11 @@ const (
 	NoLeaderState uint64 = 0
 )
 
+// ErrNoLeader is returned by EtcdMemberStatus if the etcd member does not know of any leader.
+var ErrNoLeader = &errors.EtcdError{
+	Message: "currently there is no etcd leader present may be due to etcd quorum loss or election is being held",
+}
+
 // LeaderElector holds the all configuration necessary to elect backup-restore Leader.
 type LeaderElector struct {
 	Config               *brtypes.Config
@@ -43,6 +49,7 @@ type LeaderElector struct {
 	Callbacks            *brtypes.LeaderCallbacks
 	LeaseCallbacks       *brtypes.MemberLeaseCallbacks
 	PromoteCallback      *brtypes.PromoteLearnerCallback
+	QuorumLossCallback   *brtypes.QuorumLossCallback
 	CheckMemberStatus    brtypes.EtcdMemberStatusCallbackFunc
 	// CurrentState defines currentState of backup-restore for LeaderElection.
 	CurrentState string
@@ -79,6 +86,9 @@ func (le *LeaderElector) Run(ctx context.Context) error {
 			return nil
 		case <-time.After(le.Config.ReelectionPeriod.Duration):
 			isLeader, isLearner, err := le.CheckMemberStatus(ctx, le.EtcdConnectionConfig, le.Config.EtcdConnectionTimeout.Duration, le.logger)
+			if le.QuorumLossCallback != nil && (err == nil || errored.Is(err, ErrNoLeader)) {
+				le.QuorumLossCallback.Observe(ctx, err != nil, le.logger)
+			}
 			if err != nil {
 				le.logger.Errorf("failed to elect the backup-restore leader: %v", err)
 
@@ -183,9 +193,7 @@ func EtcdMemberStatus(ctx context.Context, etcdConnectionConfig *brtypes.EtcdCon
 	if response.Header.MemberId == response.Leader {
 		return true, false, nil
 	} else if response.Leader == NoLeaderState {
-		return false, false, &errors.EtcdError{
-			Message: "currently there is no etcd leader present may be due to etcd quorum loss or election is being held",
-		}
+		return false, false, ErrNoLeader
 	} else if response.IsLearner {
 		return false, true, nil
 	}
@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ClusterRestorePollInterval is the interval in which members check whether it is their turn to join a restored cluster.
+	ClusterRestorePollInterval = 5 * time.Second
+
+	// clusterRestoreLeaseSuffix is the suffix of the name of the Lease which coordinates a cluster restoration.
+	clusterRestoreLeaseSuffix = "-cluster-restore"
+	// clusterRestoreIDAnnotationKey is the annotation of the coordination Lease holding the ID of the cluster restoration.
+	clusterRestoreIDAnnotationKey = "gardener.cloud/cluster-restore-id"
+	// clusterRestoreJoinedAnnotationKey is the annotation of the coordination Lease holding the comma-separated members
+	// which have been restored or have joined the restored cluster.
+	clusterRestoreJoinedAnnotationKey = "gardener.cloud/cluster-restore-joined-members"
+)
+
+// ClusterRestoreCoordinator coordinates the restoration of all members of a multi-node cluster from the backup or from
+// the data directory of one member. The seed, by default the member with ordinal 0, restores the cluster as a single
+// member cluster, and the other members join the restored cluster as learners one by one in the order of their ordinals.
+// The progress is recorded in a Lease, so that the restoration continues where it left off if a member restarts.
+type ClusterRestoreCoordinator struct {
+	client          client.Client
+	logger          *logrus.Entry
+	podName         string
+	podNamespace    string
+	statefulSetName string
+	seed            string
+	source          string
+	ordinal         int
+	restoreID       string
+}
+
+// NewClusterRestoreCoordinator returns a coordinator for the cluster restoration requested with the
+// miscellaneous.ClusterRestoreAnnotationKey annotation of the etcd StatefulSet, or nil if none is requested.
+func NewClusterRestoreCoordinator(ctx context.Context, clientSet client.Client, podName, podNamespace string, logger *logrus.Entry) (*ClusterRestoreCoordinator, error) {
+	sts, err := miscellaneous.GetStatefulSet(ctx, clientSet, podNamespace, podName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch statefulset of pod %s: %w", podName, err)
+	}
+	restoreID := sts.Annotations[miscellaneous.ClusterRestoreAnnotationKey]
+	if restoreID == "" {
+		return nil, nil
+	}
+
+	ordinal, err := strconv.Atoi(podName[strings.LastIndex(podName, "-")+1:])
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine the ordinal of pod %s: %w", podName, err)
+	}
+
+	seed := sts.Annotations[miscellaneous.ClusterRestoreSeedAnnotationKey]
+	if seed == "" {
+		seed = sts.Name + "-0"
+	}
+	source := sts.Annotations[miscellaneous.ClusterRestoreSourceAnnotationKey]
+	switch source {
+	case "":
+		source = miscellaneous.ClusterRestoreSourceSnapstore
+	case miscellaneous.ClusterRestoreSourceSnapstore, miscellaneous.ClusterRestoreSourceDataDir:
+	default:
+		return nil, fmt.Errorf("unknown cluster restore source %q", source)
+	}
+
+	return &ClusterRestoreCoordinator{
+		client:          clientSet,
+		logger:          logger.WithField("restoreID", restoreID),
+		podName:         podName,
+		podNamespace:    podNamespace,
+		statefulSetName: sts.Name,
+		seed:            seed,
+		source:          source,
+		ordinal:         ordinal,
+		restoreID:       restoreID,
+	}, nil
+}
+
+// RestoreID returns the ID of the cluster restoration.
+func (c *ClusterRestoreCoordinator) RestoreID() string {
+	return c.restoreID
+}
+
+// IsSeed returns true if the member restores the cluster, which the other members join.
+func (c *ClusterRestoreCoordinator) IsSeed() bool {
+	return c.podName == c.seed
+}
+
+// Source returns the source the seed restores the cluster from, either miscellaneous.ClusterRestoreSourceSnapstore
+// or miscellaneous.ClusterRestoreSourceDataDir.
+func (c *ClusterRestoreCoordinator) Source() string {
+	return c.source
+}
+
+// ClusterToken returns the initial cluster token of the restored cluster. It differs from the token of the
+// original cluster, so that members which still have the data of the original cluster cannot join the restored one.
+func (c *ClusterRestoreCoordinator) ClusterToken(originalToken string) string {
+	return fmt.Sprintf("%s-%s", originalToken, c.restoreID)
+}
+
+// IsPending returns true if the member has not been restored or has not joined the restored cluster yet.
+func (c *ClusterRestoreCoordinator) IsPending(ctx context.Context) (bool, error) {
+	joined, err := c.joinedMembers(ctx)
+	if err != nil {
+		return false, err
+	}
+	return !slices.Contains(joined, c.podName), nil
+}
+
+// WaitForTurn waits until the seed has restored the cluster and all other members with lower ordinals have joined it.
+func (c *ClusterRestoreCoordinator) WaitForTurn(ctx context.Context) error {
+	for {
+		joined, err := c.joinedMembers(ctx)
+		if err != nil {
+			c.logger.Warnf("Unable to fetch the progress of the cluster restoration: %v", err)
+		} else if previous := c.firstPendingPredecessor(joined); previous == "" {
+			return nil
+		} else {
+			c.logger.Infof("Waiting for member %s to join the restored cluster", previous)
+		}
+		if err := miscellaneous.SleepWithContext(ctx, ClusterRestorePollInterval); err != nil {
+			return fmt.Errorf("stopped waiting to join the restored cluster: %w", err)
+		}
+	}
+}
+
+// MarkJoined records that the member has been restored or has joined the restored cluster.
+func (c *ClusterRestoreCoordinator) MarkJoined(ctx context.Context) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		lease := &v1.Lease{}
+		err := c.client.Get(ctx, c.leaseKey(), lease)
+		if apierrors.IsNotFound(err) {
+			lease = &v1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      c.leaseKey().Name,
+					Namespace: c.podNamespace,
+					Annotations: map[string]string{
+						clusterRestoreIDAnnotationKey:     c.restoreID,
+						clusterRestoreJoinedAnnotationKey: c.podName,
+					},
+				},
+			}
+			return c.client.Create(ctx, lease)
+		}
+		if err != nil {
+			return err
+		}
+
+		// the Lease of a previous cluster restoration is reused for this one.
+		var joined []string
+		if lease.Annotations[clusterRestoreIDAnnotationKey] == c.restoreID {
+			joined = splitMembers(lease.Annotations[clusterRestoreJoinedAnnotationKey])
+		}
+		if slices.Contains(joined, c.podName) {
+			return nil
+		}
+		if lease.Annotations == nil {
+			lease.Annotations = map[string]string{}
+		}
+		lease.Annotations[clusterRestoreIDAnnotationKey] = c.restoreID
+		lease.Annotations[clusterRestoreJoinedAnnotationKey] = strings.Join(append(joined, c.podName), ",")
+		return c.client.Update(ctx, lease)
+	})
+}
+
+// joinedMembers returns the members which have been restored or have joined the restored cluster.
+func (c *ClusterRestoreCoordinator) joinedMembers(ctx context.Context) ([]string, error) {
+	lease := &v1.Lease{}
+	if err := c.client.Get(ctx, c.leaseKey(), lease); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to fetch the cluster restoration lease %s: %w", c.leaseKey().Name, err)
+	}
+	if lease.Annotations[clusterRestoreIDAnnotationKey] != c.restoreID {
+		return nil, nil
+	}
+	return splitMembers(lease.Annotations[clusterRestoreJoinedAnnotationKey]), nil
+}
+
+// firstPendingPredecessor returns the seed or the first member with a lower ordinal which has not joined yet, or an empty string.
+func (c *ClusterRestoreCoordinator) firstPendingPredecessor(joined []string) string {
+	if c.IsSeed() {
+		return ""
+	}
+	if !slices.Contains(joined, c.seed) {
+		return c.seed
+	}
+	for i := 0; i < c.ordinal; i++ {
+		if name := fmt.Sprintf("%s-%d", c.statefulSetName, i); !slices.Contains(joined, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+func (c *ClusterRestoreCoordinator) leaseKey() client.ObjectKey {
+	return client.ObjectKey{Namespace: c.podNamespace, Name: c.statefulSetName + clusterRestoreLeaseSuffix}
+}
+
+func splitMembers(members string) []string {
+	if members == "" {
+		return nil
+	}
+	return strings.Split(members, ",")
+}
@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package member_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/member"
+	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClusterRestoreCoordinator", func() {
+	const (
+		statefulSetName = "etcd-test"
+		restoreID       = "20250101"
+	)
+	var (
+		clientSet client.Client
+		sts       *appsv1.StatefulSet
+	)
+
+	newCoordinator := func(podName string) *member.ClusterRestoreCoordinator {
+		c, err := member.NewClusterRestoreCoordinator(testCtx, clientSet, podName, podNamespace, logger)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(c).ShouldNot(BeNil())
+		return c
+	}
+
+	BeforeEach(func() {
+		clientSet = miscellaneous.GetFakeKubernetesClientSet()
+		sts = &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        statefulSetName,
+				Namespace:   podNamespace,
+				Annotations: map[string]string{miscellaneous.ClusterRestoreAnnotationKey: restoreID},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		Expect(clientSet.Create(testCtx, sts)).Should(Succeed())
+	})
+
+	Context("When the cluster restore annotation is not present in statefulset", func() {
+		BeforeEach(func() {
+			sts.Annotations = nil
+		})
+
+		It("should not return a coordinator", func() {
+			c, err := member.NewClusterRestoreCoordinator(testCtx, clientSet, statefulSetName+"-0", podNamespace, logger)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(c).Should(BeNil())
+		})
+	})
+
+	Context("When the cluster restore annotation is present in statefulset", func() {
+		It("should only make the member with ordinal 0 the seed", func() {
+			Expect(newCoordinator(statefulSetName + "-0").IsSeed()).Should(BeTrue())
+			Expect(newCoordinator(statefulSetName + "-1").IsSeed()).Should(BeFalse())
+		})
+
+		It("should derive a fresh cluster token", func() {
+			Expect(newCoordinator(statefulSetName + "-0").ClusterToken("etcd-cluster")).Should(Equal("etcd-cluster-" + restoreID))
+		})
+
+		It("should let the members join in the order of their ordinals", func() {
+			seed, second, third := newCoordinator(statefulSetName+"-0"), newCoordinator(statefulSetName+"-1"), newCoordinator(statefulSetName+"-2")
+			for _, c := range []*member.ClusterRestoreCoordinator{seed, second, third} {
+				pending, err := c.IsPending(testCtx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(pending).Should(BeTrue())
+			}
+			Expect(seed.WaitForTurn(testCtx)).Should(Succeed())
+			Expect(waitForTurnBriefly(second)).ShouldNot(Succeed())
+
+			Expect(seed.MarkJoined(testCtx)).Should(Succeed())
+			pending, err := seed.IsPending(testCtx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(pending).Should(BeFalse())
+			Expect(waitForTurnBriefly(second)).Should(Succeed())
+			Expect(waitForTurnBriefly(third)).ShouldNot(Succeed())
+
+			Expect(second.MarkJoined(testCtx)).Should(Succeed())
+			Expect(second.MarkJoined(testCtx)).Should(Succeed())
+			Expect(waitForTurnBriefly(third)).Should(Succeed())
+
+			lease := &coordinationv1.Lease{}
+			Expect(clientSet.Get(testCtx, client.ObjectKey{Namespace: podNamespace, Name: statefulSetName + "-cluster-restore"}, lease)).Should(Succeed())
+			Expect(lease.Annotations).Should(HaveKeyWithValue("gardener.cloud/cluster-restore-joined-members", statefulSetName+"-0,"+statefulSetName+"-1"))
+		})
+	})
+
+	Context("When the seed and source of the cluster restoration are annotated", func() {
+		BeforeEach(func() {
+			sts.Annotations[miscellaneous.ClusterRestoreSeedAnnotationKey] = statefulSetName + "-1"
+			sts.Annotations[miscellaneous.ClusterRestoreSourceAnnotationKey] = miscellaneous.ClusterRestoreSourceDataDir
+		})
+
+		It("should let the seed join first", func() {
+			first, seed, third := newCoordinator(statefulSetName+"-0"), newCoordinator(statefulSetName+"-1"), newCoordinator(statefulSetName+"-2")
+			Expect(seed.IsSeed()).Should(BeTrue())
+			Expect(first.IsSeed()).Should(BeFalse())
+			Expect(seed.Source()).Should(Equal(miscellaneous.ClusterRestoreSourceDataDir))
+
+			Expect(seed.WaitForTurn(testCtx)).Should(Succeed())
+			Expect(waitForTurnBriefly(first)).ShouldNot(Succeed())
+
+			Expect(seed.MarkJoined(testCtx)).Should(Succeed())
+			Expect(waitForTurnBriefly(first)).Should(Succeed())
+			Expect(waitForTurnBriefly(third)).ShouldNot(Succeed())
+
+			Expect(first.MarkJoined(testCtx)).Should(Succeed())
+			Expect(waitForTurnBriefly(third)).Should(Succeed())
+		})
+	})
+
+	Context("When an unknown source of the cluster restoration is annotated", func() {
+		BeforeEach(func() {
+			sts.Annotations[miscellaneous.ClusterRestoreSourceAnnotationKey] = "tape"
+		})
+
+		It("should fail", func() {
+			_, err := member.NewClusterRestoreCoordinator(testCtx, clientSet, statefulSetName+"-0", podNamespace, logger)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("When the lease of a previous cluster restoration exists", func() {
+		JustBeforeEach(func() {
+			lease := &coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      statefulSetName + "-cluster-restore",
+					Namespace: podNamespace,
+					Annotations: map[string]string{
+						"gardener.cloud/cluster-restore-id":             "previous",
+						"gardener.cloud/cluster-restore-joined-members": statefulSetName + "-0," + statefulSetName + "-1",
+					},
+				},
+			}
+			Expect(clientSet.Create(testCtx, lease)).Should(Succeed())
+		})
+
+		It("should ignore the members which joined the previous cluster restoration", func() {
+			seed, second := newCoordinator(statefulSetName+"-0"), newCoordinator(statefulSetName+"-1")
+			pending, err := seed.IsPending(testCtx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(pending).Should(BeTrue())
+			Expect(waitForTurnBriefly(newCoordinator(statefulSetName + "-2"))).ShouldNot(Succeed())
+
+			Expect(seed.MarkJoined(testCtx)).Should(Succeed())
+			pending, err = second.IsPending(testCtx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(pending).Should(BeTrue())
+		})
+	})
+})
+
+// waitForTurnBriefly returns an error if it is not the turn of the member to join the restored cluster right away.
+func waitForTurnBriefly(c *member.ClusterRestoreCoordinator) error {
+	ctx, cancel := context.WithTimeout(testCtx, 100*time.Millisecond)
+	defer cancel()
+	return c.WaitForTurn(ctx)
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package member
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/metrics"
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrLearnerNotCaughtUp is a sentinel error returned while the learner lags too far behind the leader to be promoted.
+	ErrLearnerNotCaughtUp = errors.New("learner has not caught up with the leader")
+	// ErrLearnerCatchUpTimeout is a sentinel error returned once the learner has not caught up with the leader within the catch-up timeout.
+	ErrLearnerCatchUpTimeout = errors.New("learner has not caught up with the leader within the catch-up timeout")
+)
+
+// LearnerPromoter promotes the member from a learner to a voting member once it has caught up with the leader.
+// Promote is expected to be called periodically for as long as the member is a learner.
+type LearnerPromoter struct {
+	control        Control
+	maxApplyLag    uint64
+	catchUpTimeout time.Duration
+	// learnerSince is the time since when the member is known to be a learner, or zero if it is not.
+	learnerSince time.Time
+	// timedOut records whether the catch-up timeout has been reported for the current learner.
+	timedOut bool
+}
+
+// NewLearnerPromoter returns a LearnerPromoter which promotes the member once it lags at most maxApplyLag
+// raft entries behind the leader, and reports a failure if it does not catch up within catchUpTimeout.
+func NewLearnerPromoter(control Control, maxApplyLag uint64, catchUpTimeout time.Duration) *LearnerPromoter {
+	return &LearnerPromoter{
+		control:        control,
+		maxApplyLag:    maxApplyLag,
+		catchUpTimeout: catchUpTimeout,
+	}
+}
+
+// Promote promotes the member if it has caught up with the leader. It returns ErrLearnerNotCaughtUp while the member lags
+// too far behind, and ErrLearnerCatchUpTimeout once it has not caught up within the catch-up timeout. The timeout is only
+// reported once, as a notifier.EventLearnerCatchUpTimedOut event, but the promotion is still attempted afterwards.
+func (p *LearnerPromoter) Promote(ctx context.Context, logger *logrus.Entry) error {
+	if p.learnerSince.IsZero() {
+		p.learnerSince = time.Now()
+	}
+
+	lag, err := p.control.GetLearnerApplyLag(ctx)
+	if err != nil {
+		logger.Warnf("Unable to determine the apply lag of the learner: %v", err)
+	} else {
+		metrics.LearnerApplyLag.With(prometheus.Labels{}).Set(float64(lag))
+		if lag <= p.maxApplyLag {
+			logger.Infof("Learner lags %d raft entries behind the leader, attempting promotion", lag)
+			if err = p.control.PromoteMember(ctx); err == nil {
+				metrics.LearnerApplyLag.With(prometheus.Labels{}).Set(0)
+				p.learnerSince, p.timedOut = time.Time{}, false
+				return nil
+			}
+		} else {
+			err = fmt.Errorf("%w: lag of %d raft entries exceeds %d", ErrLearnerNotCaughtUp, lag, p.maxApplyLag)
+		}
+	}
+
+	if waited := time.Since(p.learnerSince); waited > p.catchUpTimeout {
+		err = fmt.Errorf("%w %v: %v", ErrLearnerCatchUpTimeout, p.catchUpTimeout, err)
+		if !p.timedOut {
+			p.timedOut = true
+			notifier.Notify(notifier.Event{Type: notifier.EventLearnerCatchUpTimedOut, Data: notifier.EventData{Error: err.Error(), DurationSeconds: waited.Seconds()}})
+		}
+	}
+	return err
+}
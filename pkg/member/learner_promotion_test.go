@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package member_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/member"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeLearnerControl reports the configured apply lag and records the promotions of the member.
+type fakeLearnerControl struct {
+	member.Control
+	lag        uint64
+	lagErr     error
+	promotions int
+}
+
+func (f *fakeLearnerControl) GetLearnerApplyLag(_ context.Context) (uint64, error) {
+	return f.lag, f.lagErr
+}
+
+func (f *fakeLearnerControl) PromoteMember(_ context.Context) error {
+	f.promotions++
+	return nil
+}
+
+var _ = Describe("LearnerPromoter", func() {
+	var control *fakeLearnerControl
+
+	BeforeEach(func() {
+		control = &fakeLearnerControl{}
+	})
+
+	Context("When the learner has caught up with the leader", func() {
+		It("should promote the learner", func() {
+			control.lag = 10
+			p := member.NewLearnerPromoter(control, 10, time.Minute)
+			Expect(p.Promote(testCtx, logger)).Should(Succeed())
+			Expect(control.promotions).Should(Equal(1))
+		})
+	})
+
+	Context("When the learner lags too far behind the leader", func() {
+		It("should not promote the learner", func() {
+			control.lag = 11
+			p := member.NewLearnerPromoter(control, 10, time.Minute)
+			err := p.Promote(testCtx, logger)
+			Expect(err).Should(MatchError(member.ErrLearnerNotCaughtUp))
+			Expect(control.promotions).Should(Equal(0))
+
+			control.lag = 0
+			Expect(p.Promote(testCtx, logger)).Should(Succeed())
+			Expect(control.promotions).Should(Equal(1))
+		})
+
+		It("should time out if the learner does not catch up", func() {
+			control.lag = 11
+			p := member.NewLearnerPromoter(control, 10, 10*time.Millisecond)
+			Expect(p.Promote(testCtx, logger)).Should(MatchError(member.ErrLearnerNotCaughtUp))
+			time.Sleep(20 * time.Millisecond)
+			Expect(p.Promote(testCtx, logger)).Should(MatchError(member.ErrLearnerCatchUpTimeout))
+
+			control.lag = 0
+			Expect(p.Promote(testCtx, logger)).Should(Succeed())
+			Expect(control.promotions).Should(Equal(1))
+		})
+	})
+
+	Context("When the apply lag of the learner cannot be determined", func() {
+		It("should not promote the learner and time out eventually", func() {
+			control.lagErr = fmt.Errorf("no leader")
+			p := member.NewLearnerPromoter(control, 10, 10*time.Millisecond)
+			Expect(p.Promote(testCtx, logger)).Should(MatchError(control.lagErr))
+			time.Sleep(20 * time.Millisecond)
+			Expect(p.Promote(testCtx, logger)).Should(MatchError(member.ErrLearnerCatchUpTimeout))
+			Expect(control.promotions).Should(Equal(0))
+		})
+	})
+})
@@ -16,6 +16,7 @@ import (
 	etcdClient "github.com/gardener/etcd-backup-restore/pkg/etcdutil/client"
 	"github.com/gardener/etcd-backup-restore/pkg/metrics"
 	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -73,6 +74,9 @@ type Control interface {
 
 	// GetPeerURLs returns the list of current peer URLs of the etcd cluster member.
 	GetPeerURLs(context.Context, etcdClient.ClusterCloser) ([]string, error)
+
+	// GetLearnerApplyLag returns the number of raft entries the member lags behind the leader.
+	GetLearnerApplyLag(context.Context) (uint64, error)
 }
 
 // memberControl holds the configuration for the mechanism of adding a new member to the cluster.
@@ -86,12 +90,7 @@ type memberControl struct {
 
 // NewMemberControl returns new ExponentialBackoff.
 func NewMemberControl(etcdConnConfig *brtypes.EtcdConnectionConfig) Control {
-	var configFile string
 	logger := logrus.New().WithField("actor", "member-add")
-	etcdConn := *etcdConnConfig
-
-	// We want to use the service endpoint since we're only supposed to connect to ready etcd members.
-	clientFactory := etcdutil.NewFactory(etcdConn, etcdClient.UseServiceEndpoints(true))
 	podName, err := miscellaneous.GetEnvVarOrError("POD_NAME")
 	if err != nil {
 		logger.Fatalf("Error reading POD_NAME env var : %v", err)
@@ -102,8 +101,20 @@ func NewMemberControl(etcdConnConfig *brtypes.EtcdConnectionConfig) Control {
 		logger.Fatalf("Error reading POD_NAMESPACE env var : %v", err)
 	}
 
+	return NewMemberControlForMember(etcdConnConfig, podName, podNamespace)
+}
+
+// NewMemberControlForMember returns a Control for the etcd member with the given pod name and namespace,
+// e.g. to replace another member of the cluster.
+func NewMemberControlForMember(etcdConnConfig *brtypes.EtcdConnectionConfig, podName, podNamespace string) Control {
+	logger := logrus.New().WithField("actor", "member-add")
+	etcdConn := *etcdConnConfig
+
+	// We want to use the service endpoint since we're only supposed to connect to ready etcd members.
+	clientFactory := etcdutil.NewFactory(etcdConn, etcdClient.UseServiceEndpoints(true))
+
 	//TODO: Refactor needed
-	configFile = miscellaneous.GetConfigFilePath()
+	configFile := miscellaneous.GetConfigFilePath()
 
 	return &memberControl{
 		clientFactory: clientFactory,
@@ -117,7 +128,7 @@ func NewMemberControl(etcdConnConfig *brtypes.EtcdConnectionConfig) Control {
 // AddMemberAsLearner add a member as a learner to the etcd cluster
 func (m *memberControl) AddMemberAsLearner(ctx context.Context) error {
 	//Add member as learner to cluster
-	memberPeerURLs, err := miscellaneous.GetMemberPeerURLs(m.configFile)
+	memberPeerURLs, err := miscellaneous.GetPeerURLsOfMember(m.configFile, m.podName)
 	if err != nil {
 		m.logger.Fatalf("Error fetching etcd member URL : %v", err)
 	}
@@ -149,6 +160,7 @@ func (m *memberControl) AddMemberAsLearner(ctx context.Context) error {
 	metrics.IsLearner.With(prometheus.Labels{}).Set(1)
 	metrics.AddLearnerDurationSeconds.With(prometheus.Labels{metrics.LabelSucceeded: metrics.ValueSucceededTrue}).Observe(time.Since(start).Seconds())
 	m.logger.Infof("Added member %v to cluster as a learner", strconv.FormatUint(response.Member.GetID(), 16))
+	notifier.Notify(notifier.Event{Type: notifier.EventLearnerAdded, Data: notifier.EventData{Member: m.podName, DurationSeconds: time.Since(start).Seconds()}})
 	return nil
 }
 
@@ -206,7 +218,7 @@ func (m *memberControl) IsMemberInCluster(_ context.Context) (_ bool, err error)
 func (m *memberControl) doUpdateMemberPeerAddress(ctx context.Context, cli etcdClient.ClusterCloser, id uint64) error {
 	// Already existing clusters or cluster after restoration have `http://localhost:2380` as the peer address. This needs to explicitly updated to the correct peer address.
 	m.logger.Infof("Updating member peer URL for %s", m.podName)
-	memberPeerURLs, err := miscellaneous.GetMemberPeerURLs(m.configFile)
+	memberPeerURLs, err := miscellaneous.GetPeerURLsOfMember(m.configFile, m.podName)
 	if err != nil {
 		return fmt.Errorf("could not fetch member URL : %v", err)
 	}
@@ -314,6 +326,30 @@ func (m *memberControl) IsLearnerPresent(ctx context.Context) (bool, error) {
 	return miscellaneous.CheckIfLearnerPresent(learnerCtx, cli)
 }
 
+// GetLearnerApplyLag returns the number of raft entries applied by the leader which have not been applied by the member yet.
+func (m *memberControl) GetLearnerApplyLag(ctx context.Context) (uint64, error) {
+	memberClientURLs, err := miscellaneous.GetClientURLsOfMember(m.configFile, m.podName)
+	if err != nil {
+		return 0, fmt.Errorf("could not fetch member client URL : %v", err)
+	}
+
+	cli, err := m.clientFactory.NewCluster()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build etcd cluster client : %v", err)
+	}
+	defer cli.Close()
+
+	clientMaintenance, err := m.clientFactory.NewMaintenance()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build etcd maintenance client : %v", err)
+	}
+	defer clientMaintenance.Close()
+
+	lagCtx, cancel := context.WithTimeout(ctx, EtcdTimeout)
+	defer cancel()
+	return miscellaneous.GetLearnerApplyLag(lagCtx, clientMaintenance, cli, memberClientURLs[0])
+}
+
 // IsClusterScaledUp determines whether a etcd cluster is getting scale-up or not and returns a boolean
 func (m *memberControl) IsClusterScaledUp(ctx context.Context, clientSet client.Client) (bool, error) {
 	m.logger.Info("Checking whether etcd cluster is marked for scale-up")
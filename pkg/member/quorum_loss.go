@@ -0,0 +1,334 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/metrics"
+	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// QuorumLossActionWait is recommended as long as the members which have lost the quorum are still reporting their revisions.
+	QuorumLossActionWait = "Wait"
+	// QuorumLossActionRecoverFromMember is recommended if the data directory of a member holds the latest revision.
+	// The cluster is recovered from it as a single member cluster, which the other members join.
+	QuorumLossActionRecoverFromMember = "RecoverFromMember"
+	// QuorumLossActionRestoreFromSnapstore is recommended if the snapstore holds a later revision than any member.
+	QuorumLossActionRestoreFromSnapstore = "RestoreFromSnapstore"
+
+	// quorumLossLeaseSuffix is the suffix of the name of the Lease through which the members share their revisions.
+	quorumLossLeaseSuffix = "-quorum-loss"
+	// quorumLossSinceAnnotationKey is the annotation of the Lease holding the time the quorum loss was first reported.
+	quorumLossSinceAnnotationKey = "gardener.cloud/quorum-loss-since"
+	// quorumLossRevisionsAnnotationKey is the annotation of the Lease holding the comma-separated `<member>=<revision>`
+	// pairs of the members which have lost the quorum.
+	quorumLossRevisionsAnnotationKey = "gardener.cloud/quorum-loss-revisions"
+	// quorumLossRestoreIDPrefix is the prefix of the ID of a cluster restoration requested after a quorum loss.
+	quorumLossRestoreIDPrefix = "quorum-loss-"
+)
+
+// QuorumLossRecommendation is the recovery recommended once the etcd cluster has lost its quorum.
+type QuorumLossRecommendation struct {
+	// LostSince is the time the quorum loss was first reported by any member.
+	LostSince time.Time `json:"lostSince"`
+	// Revisions are the latest revisions of the members which have reported the quorum loss.
+	Revisions map[string]int64 `json:"revisions"`
+	// Action is the recommended recovery action.
+	Action string `json:"action"`
+	// Member is the member with the latest revision, which recovers or restores the cluster.
+	Member string `json:"member"`
+	// Revision is the latest revision of Member.
+	Revision int64 `json:"revision"`
+	// SnapshotRevision is the latest revision in the snapstore, if a snapstore is configured.
+	SnapshotRevision int64 `json:"snapshotRevision,omitempty"`
+}
+
+// QuorumLossDetector detects a sustained quorum loss of a multi-node etcd cluster. Once a member has not known of a
+// leader for longer than Threshold, it shares its latest revision with the other members through a Lease, and all
+// members agree on the member with the latest revision to recover the cluster from. If enabled, the recovery is
+// requested as a cluster restoration seeded by that member.
+type QuorumLossDetector struct {
+	// Client is used to share the revisions through a Lease and to request the recovery of the cluster.
+	Client client.Client
+	// PodName is the name of the pod of the member.
+	PodName string
+	// PodNamespace is the namespace of the pod of the member.
+	PodNamespace string
+	// ClusterSize is the number of members of the cluster.
+	ClusterSize int
+	// Threshold is the time period after which the absence of a leader is reported as quorum loss.
+	// The recommendation is final once all members have reported their revisions, or at the latest after twice this period.
+	Threshold time.Duration
+	// RecoveryEnabled enables the automatic recovery of the cluster once the recommendation is final.
+	RecoveryEnabled bool
+	// LocalRevision returns the latest revision of the member.
+	LocalRevision func(context.Context) (int64, error)
+	// SnapshotRevision returns the latest revision in the snapstore. It is nil if no snapstore is configured.
+	SnapshotRevision func(context.Context) (int64, error)
+	// RestartEtcd restarts etcd, so that its initializer takes part in the recovery of the cluster. It may be nil.
+	RestartEtcd func(context.Context) error
+
+	mutex             sync.Mutex
+	lostSince         time.Time
+	recommendation    *QuorumLossRecommendation
+	notified          bool
+	recoveryRequested bool
+	revisionWithdrawn bool
+}
+
+// Observe records whether the member has lost the quorum of the cluster. It is called after every leadership status
+// check, and updates the recommendation, and possibly requests the recovery, once the quorum loss lasts longer than
+// the threshold.
+func (d *QuorumLossDetector) Observe(ctx context.Context, quorumLost bool, logger *logrus.Entry) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !quorumLost {
+		if !d.lostSince.IsZero() {
+			logger.Info("etcd cluster has regained its quorum")
+			metrics.QuorumLost.With(prometheus.Labels{}).Set(0)
+		}
+		d.lostSince, d.recommendation, d.notified, d.recoveryRequested = time.Time{}, nil, false, false
+		if !d.revisionWithdrawn {
+			if err := d.withdrawRevision(ctx); err != nil {
+				logger.Warnf("Unable to withdraw the revision reported after the quorum loss: %v", err)
+				return
+			}
+			d.revisionWithdrawn = true
+		}
+		return
+	}
+
+	if d.lostSince.IsZero() {
+		d.lostSince = time.Now()
+		logger.Warnf("etcd member does not know of any leader, reporting quorum loss after %v", d.Threshold)
+	}
+	if time.Since(d.lostSince) < d.Threshold {
+		return
+	}
+	metrics.QuorumLost.With(prometheus.Labels{}).Set(1)
+
+	recommendation, err := d.recommend(ctx)
+	if err != nil {
+		logger.Errorf("Unable to recommend a recovery from the quorum loss: %v", err)
+		return
+	}
+	if d.recommendation == nil || d.recommendation.Action != recommendation.Action || d.recommendation.Member != recommendation.Member {
+		logger.Warnf("etcd cluster has lost its quorum since %v, recommended recovery is %s with member %s at revision %d, reported revisions: %v",
+			recommendation.LostSince, recommendation.Action, recommendation.Member, recommendation.Revision, recommendation.Revisions)
+	}
+	d.recommendation = recommendation
+	if recommendation.Action == QuorumLossActionWait {
+		return
+	}
+
+	if !d.notified {
+		notifier.Notify(notifier.Event{Type: notifier.EventQuorumLost, Data: notifier.EventData{Kind: recommendation.Action, Member: recommendation.Member, LastRevision: recommendation.Revision}})
+		d.notified = true
+	}
+	if d.RecoveryEnabled && !d.recoveryRequested {
+		if err := d.requestRecovery(ctx, recommendation, logger); err != nil {
+			logger.Errorf("Unable to request the recovery from the quorum loss: %v", err)
+			return
+		}
+		d.recoveryRequested = true
+	}
+}
+
+// Recommendation returns the recovery recommended for the current quorum loss, or nil if the quorum is not lost.
+func (d *QuorumLossDetector) Recommendation() *QuorumLossRecommendation {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.recommendation
+}
+
+// recommend reports the latest revision of the member and recommends the recovery based on the revisions reported by all members.
+func (d *QuorumLossDetector) recommend(ctx context.Context) (*QuorumLossRecommendation, error) {
+	revision, err := d.LocalRevision(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine the latest revision of the member: %w", err)
+	}
+	lostSince, revisions, err := d.reportRevision(ctx, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &QuorumLossRecommendation{LostSince: lostSince, Revisions: revisions, Action: QuorumLossActionWait}
+	for name, rev := range revisions {
+		// ties are broken by the name, so that all members agree on the same member.
+		if r.Member == "" || rev > r.Revision || (rev == r.Revision && name < r.Member) {
+			r.Member, r.Revision = name, rev
+		}
+	}
+	if len(revisions) < d.ClusterSize && time.Since(lostSince) < 2*d.Threshold {
+		return r, nil
+	}
+
+	r.Action = QuorumLossActionRecoverFromMember
+	if d.SnapshotRevision != nil {
+		if r.SnapshotRevision, err = d.SnapshotRevision(ctx); err != nil {
+			return nil, fmt.Errorf("unable to determine the latest revision in the snapstore: %w", err)
+		}
+		if r.SnapshotRevision > r.Revision {
+			r.Action = QuorumLossActionRestoreFromSnapstore
+		}
+	}
+	return r, nil
+}
+
+// reportRevision records the latest revision of the member in the Lease, and returns the time the quorum loss was
+// first reported and the revisions reported by all members.
+func (d *QuorumLossDetector) reportRevision(ctx context.Context, revision int64) (time.Time, map[string]int64, error) {
+	var (
+		lostSince time.Time
+		revisions map[string]int64
+	)
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		lease := &v1.Lease{}
+		err := d.Client.Get(ctx, d.leaseKey(), lease)
+		if apierrors.IsNotFound(err) {
+			lostSince, revisions = d.lostSince, map[string]int64{d.PodName: revision}
+			lease = &v1.Lease{ObjectMeta: metav1.ObjectMeta{Name: d.leaseKey().Name, Namespace: d.PodNamespace}}
+			setQuorumLossAnnotations(lease, lostSince, revisions)
+			return d.Client.Create(ctx, lease)
+		}
+		if err != nil {
+			return err
+		}
+
+		if lostSince, revisions, err = parseQuorumLossAnnotations(lease); err != nil {
+			return err
+		}
+		if len(revisions) == 0 {
+			// the first member reporting the quorum loss determines its start.
+			lostSince = d.lostSince
+		}
+		if reported, ok := revisions[d.PodName]; ok && reported == revision {
+			return nil
+		}
+		revisions[d.PodName] = revision
+		setQuorumLossAnnotations(lease, lostSince, revisions)
+		return d.Client.Update(ctx, lease)
+	})
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("unable to report the revision in the quorum loss lease %s: %w", d.leaseKey().Name, err)
+	}
+	d.revisionWithdrawn = false
+	return lostSince, revisions, nil
+}
+
+// withdrawRevision removes the revision of the member from the Lease once the quorum has been regained.
+func (d *QuorumLossDetector) withdrawRevision(ctx context.Context) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		lease := &v1.Lease{}
+		if err := d.Client.Get(ctx, d.leaseKey(), lease); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		lostSince, revisions, err := parseQuorumLossAnnotations(lease)
+		if err != nil {
+			return err
+		}
+		if _, ok := revisions[d.PodName]; !ok {
+			return nil
+		}
+		delete(revisions, d.PodName)
+		setQuorumLossAnnotations(lease, lostSince, revisions)
+		return d.Client.Update(ctx, lease)
+	})
+}
+
+// requestRecovery requests the recovery of the cluster as a cluster restoration seeded by the recommended member with
+// the miscellaneous.ClusterRestoreAnnotationKey annotation of the etcd StatefulSet, and restarts etcd, so that the
+// initializer of the member takes part in it. All members request the same restoration, as they derive its ID from
+// the start of the quorum loss, and the first request wins.
+func (d *QuorumLossDetector) requestRecovery(ctx context.Context, r *QuorumLossRecommendation, logger *logrus.Entry) error {
+	sts, err := miscellaneous.GetStatefulSet(ctx, d.Client, d.PodNamespace, d.PodName)
+	if err != nil {
+		return fmt.Errorf("unable to fetch statefulset of pod %s: %w", d.PodName, err)
+	}
+
+	restoreID := quorumLossRestoreIDPrefix + strconv.FormatInt(r.LostSince.Unix(), 10)
+	if sts.Annotations[miscellaneous.ClusterRestoreAnnotationKey] != restoreID {
+		source := miscellaneous.ClusterRestoreSourceDataDir
+		if r.Action == QuorumLossActionRestoreFromSnapstore {
+			source = miscellaneous.ClusterRestoreSourceSnapstore
+		}
+		requested := sts.DeepCopy()
+		if requested.Annotations == nil {
+			requested.Annotations = map[string]string{}
+		}
+		requested.Annotations[miscellaneous.ClusterRestoreAnnotationKey] = restoreID
+		requested.Annotations[miscellaneous.ClusterRestoreSeedAnnotationKey] = r.Member
+		requested.Annotations[miscellaneous.ClusterRestoreSourceAnnotationKey] = source
+		if err := d.Client.Patch(ctx, requested, client.MergeFrom(sts)); err != nil {
+			return fmt.Errorf("unable to request cluster restoration %s: %w", restoreID, err)
+		}
+		logger.Infof("Requested cluster restoration %s seeded by member %s from %s", restoreID, r.Member, source)
+	}
+
+	if d.RestartEtcd == nil {
+		logger.Warnf("Restart etcd to take part in cluster restoration %s", restoreID)
+		return nil
+	}
+	logger.Infof("Restarting etcd to take part in cluster restoration %s", restoreID)
+	return d.RestartEtcd(ctx)
+}
+
+func (d *QuorumLossDetector) leaseKey() client.ObjectKey {
+	return client.ObjectKey{Namespace: d.PodNamespace, Name: d.PodName[:strings.LastIndex(d.PodName, "-")] + quorumLossLeaseSuffix}
+}
+
+func setQuorumLossAnnotations(lease *v1.Lease, lostSince time.Time, revisions map[string]int64) {
+	if lease.Annotations == nil {
+		lease.Annotations = map[string]string{}
+	}
+	pairs := make([]string, 0, len(revisions))
+	for name, revision := range revisions {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", name, revision))
+	}
+	slices.Sort(pairs)
+	lease.Annotations[quorumLossSinceAnnotationKey] = lostSince.UTC().Format(time.RFC3339Nano)
+	lease.Annotations[quorumLossRevisionsAnnotationKey] = strings.Join(pairs, ",")
+}
+
+func parseQuorumLossAnnotations(lease *v1.Lease) (time.Time, map[string]int64, error) {
+	revisions := map[string]int64{}
+	for _, pair := range splitMembers(lease.Annotations[quorumLossRevisionsAnnotationKey]) {
+		name, value, found := strings.Cut(pair, "=")
+		revision, err := strconv.ParseInt(value, 10, 64)
+		if !found || err != nil {
+			return time.Time{}, nil, fmt.Errorf("invalid revision %q in quorum loss lease %s", pair, lease.Name)
+		}
+		revisions[name] = revision
+	}
+	if len(revisions) == 0 {
+		return time.Time{}, revisions, nil
+	}
+	lostSince, err := time.Parse(time.RFC3339Nano, lease.Annotations[quorumLossSinceAnnotationKey])
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("invalid start of the quorum loss in quorum loss lease %s: %w", lease.Name, err)
+	}
+	return lostSince, revisions, nil
+}
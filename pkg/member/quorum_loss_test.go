@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package member_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/member"
+	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QuorumLossDetector", func() {
+	const (
+		statefulSetName = "etcd-test"
+		threshold       = 200 * time.Millisecond
+	)
+	var (
+		clientSet client.Client
+		detectors []*member.QuorumLossDetector
+		restarts  int
+	)
+
+	newDetector := func(podName string, revision int64) *member.QuorumLossDetector {
+		return &member.QuorumLossDetector{
+			Client:        clientSet,
+			PodName:       podName,
+			PodNamespace:  podNamespace,
+			ClusterSize:   3,
+			Threshold:     threshold,
+			LocalRevision: func(context.Context) (int64, error) { return revision, nil },
+			RestartEtcd: func(context.Context) error {
+				restarts++
+				return nil
+			},
+		}
+	}
+
+	// observeQuorumLossBeyondThreshold lets all detectors observe the quorum loss until it lasts longer than the threshold.
+	observeQuorumLossBeyondThreshold := func() {
+		for _, d := range detectors {
+			d.Observe(testCtx, true, logger)
+			Expect(d.Recommendation()).Should(BeNil())
+		}
+		time.Sleep(threshold + 50*time.Millisecond)
+	}
+
+	BeforeEach(func() {
+		clientSet = miscellaneous.GetFakeKubernetesClientSet()
+		Expect(clientSet.Create(testCtx, &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: statefulSetName, Namespace: podNamespace}})).Should(Succeed())
+		detectors = []*member.QuorumLossDetector{
+			newDetector(statefulSetName+"-0", 10),
+			newDetector(statefulSetName+"-1", 12),
+			newDetector(statefulSetName+"-2", 12),
+		}
+		restarts = 0
+	})
+
+	Context("When the quorum loss does not last longer than the threshold", func() {
+		It("should not recommend a recovery", func() {
+			detectors[0].Observe(testCtx, true, logger)
+			detectors[0].Observe(testCtx, false, logger)
+			Expect(detectors[0].Recommendation()).Should(BeNil())
+			Expect(clientSet.Get(testCtx, client.ObjectKey{Namespace: podNamespace, Name: statefulSetName + "-quorum-loss"}, &coordinationv1.Lease{})).ShouldNot(Succeed())
+		})
+	})
+
+	Context("When the quorum loss lasts longer than the threshold", func() {
+		It("should wait for all members and agree on the member with the latest revision", func() {
+			observeQuorumLossBeyondThreshold()
+			detectors[0].Observe(testCtx, true, logger)
+			Expect(detectors[0].Recommendation().Action).Should(Equal(member.QuorumLossActionWait))
+			detectors[1].Observe(testCtx, true, logger)
+			Expect(detectors[1].Recommendation().Action).Should(Equal(member.QuorumLossActionWait))
+
+			detectors[2].Observe(testCtx, true, logger)
+			detectors[0].Observe(testCtx, true, logger)
+			for _, d := range []*member.QuorumLossDetector{detectors[0], detectors[2]} {
+				recommendation := d.Recommendation()
+				Expect(recommendation.Action).Should(Equal(member.QuorumLossActionRecoverFromMember))
+				Expect(recommendation.Member).Should(Equal(statefulSetName + "-1"))
+				Expect(recommendation.Revision).Should(Equal(int64(12)))
+				Expect(recommendation.Revisions).Should(HaveLen(3))
+			}
+			Expect(restarts).Should(Equal(0))
+		})
+
+		It("should not wait for the members which do not report their revision", func() {
+			detectors = detectors[:1]
+			observeQuorumLossBeyondThreshold()
+			detectors[0].Observe(testCtx, true, logger)
+			Expect(detectors[0].Recommendation().Action).Should(Equal(member.QuorumLossActionWait))
+
+			time.Sleep(threshold)
+			detectors[0].Observe(testCtx, true, logger)
+			Expect(detectors[0].Recommendation().Action).Should(Equal(member.QuorumLossActionRecoverFromMember))
+			Expect(detectors[0].Recommendation().Member).Should(Equal(statefulSetName + "-0"))
+		})
+
+		It("should recommend restoring from the snapstore if it holds a later revision", func() {
+			for _, d := range detectors {
+				d.SnapshotRevision = func(context.Context) (int64, error) { return 15, nil }
+			}
+			observeQuorumLossBeyondThreshold()
+			for _, d := range detectors {
+				d.Observe(testCtx, true, logger)
+			}
+			recommendation := detectors[2].Recommendation()
+			Expect(recommendation.Action).Should(Equal(member.QuorumLossActionRestoreFromSnapstore))
+			Expect(recommendation.Member).Should(Equal(statefulSetName + "-1"))
+			Expect(recommendation.SnapshotRevision).Should(Equal(int64(15)))
+		})
+
+		It("should withdraw the revision once the quorum is regained", func() {
+			observeQuorumLossBeyondThreshold()
+			for _, d := range detectors {
+				d.Observe(testCtx, true, logger)
+			}
+			detectors[0].Observe(testCtx, false, logger)
+			Expect(detectors[0].Recommendation()).Should(BeNil())
+
+			lease := &coordinationv1.Lease{}
+			Expect(clientSet.Get(testCtx, client.ObjectKey{Namespace: podNamespace, Name: statefulSetName + "-quorum-loss"}, lease)).Should(Succeed())
+			Expect(lease.Annotations).Should(HaveKeyWithValue("gardener.cloud/quorum-loss-revisions", statefulSetName+"-1=12,"+statefulSetName+"-2=12"))
+		})
+	})
+
+	Context("When the recovery is enabled", func() {
+		BeforeEach(func() {
+			for _, d := range detectors {
+				d.RecoveryEnabled = true
+			}
+		})
+
+		It("should request a cluster restoration seeded by the member with the latest revision once", func() {
+			observeQuorumLossBeyondThreshold()
+			for _, d := range detectors {
+				d.Observe(testCtx, true, logger)
+			}
+			for _, d := range detectors {
+				d.Observe(testCtx, true, logger)
+			}
+			Expect(restarts).Should(Equal(3))
+
+			sts := &appsv1.StatefulSet{}
+			Expect(clientSet.Get(testCtx, client.ObjectKey{Namespace: podNamespace, Name: statefulSetName}, sts)).Should(Succeed())
+			Expect(sts.Annotations).Should(HaveKeyWithValue(miscellaneous.ClusterRestoreAnnotationKey, HavePrefix("quorum-loss-")))
+			Expect(sts.Annotations).Should(HaveKeyWithValue(miscellaneous.ClusterRestoreSeedAnnotationKey, statefulSetName+"-1"))
+			Expect(sts.Annotations).Should(HaveKeyWithValue(miscellaneous.ClusterRestoreSourceAnnotationKey, miscellaneous.ClusterRestoreSourceDataDir))
+		})
+	})
+})
@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package member
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	utilError "github.com/gardener/etcd-backup-restore/pkg/errors"
+	etcdClient "github.com/gardener/etcd-backup-restore/pkg/etcdutil/client"
+	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrQuorumAtRisk is a sentinel error returned if replacing a member would break the quorum of the etcd cluster.
+var ErrQuorumAtRisk = errors.New("replacing the member would break the quorum of the etcd cluster")
+
+// Replacement replaces a permanently failed member of the etcd cluster by a new member with the same name.
+type Replacement struct {
+	// Control controls the member which is replaced.
+	Control Control
+	// ClientFactory creates the clients used to check the health of the other members.
+	ClientFactory etcdClient.Factory
+	// Client is used to clean up the member lease. The member lease is not cleaned up if it is nil.
+	Client client.Client
+	// Name is the name of the member which is replaced.
+	Name string
+	// Namespace is the namespace of the member lease.
+	Namespace string
+	// MaxApplyLag is the number of raft entries the new member may lag behind the leader to be promoted.
+	MaxApplyLag uint64
+	// CatchUpTimeout is the time period within which the new member has to catch up with the leader.
+	CatchUpTimeout time.Duration
+	// PollInterval is the interval in which the catch-up of the new member is checked.
+	PollInterval time.Duration
+	Logger       *logrus.Entry
+}
+
+// Run replaces the member. It refuses to remove the member if the cluster is not healthy enough to keep its quorum,
+// then removes the member, cleans up its member lease, adds it back with its peer URLs as a learner, and waits
+// until the learner has caught up and has been promoted to a voting member.
+func (r *Replacement) Run(ctx context.Context) error {
+	if err := r.checkQuorumMargin(ctx); err != nil {
+		return err
+	}
+
+	r.Logger.Infof("Removing member %s from the cluster", r.Name)
+	if err := r.Control.RemoveMember(ctx); err != nil {
+		return fmt.Errorf("unable to remove member %s: %w", r.Name, err)
+	}
+
+	if err := r.cleanUpMemberLease(ctx); err != nil {
+		return err
+	}
+
+	r.Logger.Infof("Adding member %s to the cluster as a learner", r.Name)
+	if err := retry.OnError(retry.DefaultBackoff, utilError.IsErrNotNil, func() error {
+		return r.Control.AddMemberAsLearner(ctx)
+	}); err != nil {
+		return fmt.Errorf("unable to add member %s as a learner: %w", r.Name, err)
+	}
+
+	r.Logger.Infof("Waiting for member %s to catch up with the leader", r.Name)
+	promoter := NewLearnerPromoter(r.Control, r.MaxApplyLag, r.CatchUpTimeout)
+	for {
+		err := promoter.Promote(ctx, r.Logger)
+		if err == nil {
+			r.Logger.Infof("Member %s has been replaced", r.Name)
+			return nil
+		}
+		if errors.Is(err, ErrLearnerCatchUpTimeout) {
+			return fmt.Errorf("member %s has been added as a learner, but was not promoted: %w", r.Name, err)
+		}
+		r.Logger.Infof("Member %s is not promoted yet: %v", r.Name, err)
+		if err := miscellaneous.SleepWithContext(ctx, r.PollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// checkQuorumMargin checks that the member is part of the cluster, that no learner is present, and that the voting
+// members other than the replaced member are healthy enough to keep the quorum of the cluster without it.
+func (r *Replacement) checkQuorumMargin(ctx context.Context) error {
+	cli, err := r.ClientFactory.NewCluster()
+	if err != nil {
+		return fmt.Errorf("failed to build etcd cluster client : %w", err)
+	}
+	defer cli.Close()
+
+	clientMaintenance, err := r.ClientFactory.NewMaintenance()
+	if err != nil {
+		return fmt.Errorf("failed to build etcd maintenance client : %w", err)
+	}
+	defer clientMaintenance.Close()
+
+	memListCtx, cancel := context.WithTimeout(ctx, EtcdTimeout)
+	defer cancel()
+	membersInfo, err := cli.MemberList(memListCtx)
+	if err != nil {
+		return fmt.Errorf("error listing members: %w", err)
+	}
+	if findMember(membersInfo.Members, r.Name) == nil {
+		return fmt.Errorf("%w: %s", ErrMissingMember, r.Name)
+	}
+
+	votingMembers, healthyMembers := 0, 0
+	for _, member := range membersInfo.Members {
+		if member.IsLearner {
+			return fmt.Errorf("%w: learner %s is not promoted yet", ErrQuorumAtRisk, strconv.FormatUint(member.GetID(), 16))
+		}
+		votingMembers++
+		if member.GetName() == r.Name || len(member.GetClientURLs()) == 0 {
+			continue
+		}
+		statusCtx, cancel := context.WithTimeout(ctx, EtcdTimeout)
+		_, err := clientMaintenance.Status(statusCtx, member.GetClientURLs()[0])
+		cancel()
+		if err != nil {
+			r.Logger.Warnf("Member %s is not healthy: %v", member.GetName(), err)
+			continue
+		}
+		healthyMembers++
+	}
+
+	// the removal of the member has to be committed by a quorum of the current members, which must not include it.
+	quorum := votingMembers/2 + 1
+	r.Logger.Infof("%d of the other %d voting members are healthy, quorum is %d", healthyMembers, votingMembers-1, quorum)
+	if healthyMembers < quorum {
+		return fmt.Errorf("%w: only %d of the other %d voting members are healthy, but %d are required", ErrQuorumAtRisk, healthyMembers, votingMembers-1, quorum)
+	}
+	return nil
+}
+
+// cleanUpMemberLease clears the holder of the member lease renewed by the heartbeat of the replaced member,
+// so that the new member is not mistaken for the replaced one.
+func (r *Replacement) cleanUpMemberLease(ctx context.Context) error {
+	if r.Client == nil {
+		return nil
+	}
+
+	memberLease := &v1.Lease{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: r.Namespace, Name: r.Name}, memberLease); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("couldn't fetch member lease of member %s: %w", r.Name, err)
+	}
+
+	clearedMemberLease := memberLease.DeepCopy()
+	clearedMemberLease.Spec.HolderIdentity = nil
+	clearedMemberLease.Spec.RenewTime = nil
+	if err := r.Client.Patch(ctx, clearedMemberLease, client.MergeFrom(memberLease)); err != nil {
+		return fmt.Errorf("failed to clean up member lease of member %s: %w", r.Name, err)
+	}
+	r.Logger.Infof("Cleaned up member lease of member %s", r.Name)
+	return nil
+}
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package member_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/member"
+	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+	mockfactory "github.com/gardener/etcd-backup-restore/pkg/mock/etcdutil/client"
+
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/etcdserver/etcdserverpb"
+	"go.uber.org/mock/gomock"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeReplaceControl records the removal and addition of the replaced member.
+type fakeReplaceControl struct {
+	*fakeLearnerControl
+	removals  int
+	additions int
+}
+
+func (f *fakeReplaceControl) RemoveMember(_ context.Context) error {
+	f.removals++
+	return nil
+}
+
+func (f *fakeReplaceControl) AddMemberAsLearner(_ context.Context) error {
+	f.additions++
+	return nil
+}
+
+var _ = Describe("Replacement", func() {
+	const replacedMember = "etcd-test-1"
+	var (
+		ctrl        *gomock.Controller
+		factory     *mockfactory.MockFactory
+		cl          *mockfactory.MockClusterCloser
+		cm          *mockfactory.MockMaintenanceCloser
+		control     *fakeReplaceControl
+		clientSet   client.Client
+		replacement *member.Replacement
+		members     []*etcdserverpb.Member
+		unhealthy   map[string]bool
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		factory = mockfactory.NewMockFactory(ctrl)
+		cl = mockfactory.NewMockClusterCloser(ctrl)
+		cm = mockfactory.NewMockMaintenanceCloser(ctrl)
+		factory.EXPECT().NewCluster().Return(cl, nil).AnyTimes()
+		factory.EXPECT().NewMaintenance().Return(cm, nil).AnyTimes()
+		cl.EXPECT().Close().AnyTimes()
+		cm.EXPECT().Close().AnyTimes()
+
+		members = []*etcdserverpb.Member{
+			{ID: 1, Name: "etcd-test-0", ClientURLs: []string{"http://etcd-test-0:2379"}},
+			{ID: 2, Name: replacedMember, ClientURLs: []string{"http://etcd-test-1:2379"}},
+			{ID: 3, Name: "etcd-test-2", ClientURLs: []string{"http://etcd-test-2:2379"}},
+		}
+		unhealthy = map[string]bool{"http://etcd-test-1:2379": true}
+		cl.EXPECT().MemberList(gomock.Any()).DoAndReturn(func(_ context.Context) (*clientv3.MemberListResponse, error) {
+			return &clientv3.MemberListResponse{Members: members}, nil
+		}).AnyTimes()
+		cm.EXPECT().Status(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, endpoint string) (*clientv3.StatusResponse, error) {
+			if unhealthy[endpoint] {
+				return nil, fmt.Errorf("unable to connect to %s", endpoint)
+			}
+			return new(clientv3.StatusResponse), nil
+		}).AnyTimes()
+
+		clientSet = miscellaneous.GetFakeKubernetesClientSet()
+		Expect(clientSet.Create(testCtx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: replacedMember, Namespace: podNamespace},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity: ptr.To("2:1:Member"),
+				RenewTime:      &metav1.MicroTime{Time: time.Now()},
+			},
+		})).Should(Succeed())
+
+		control = &fakeReplaceControl{fakeLearnerControl: &fakeLearnerControl{}}
+		replacement = &member.Replacement{
+			Control:        control,
+			ClientFactory:  factory,
+			Client:         clientSet,
+			Name:           replacedMember,
+			Namespace:      podNamespace,
+			MaxApplyLag:    10,
+			CatchUpTimeout: time.Minute,
+			PollInterval:   time.Millisecond,
+			Logger:         logger,
+		}
+	})
+
+	Context("When the other members keep the quorum", func() {
+		It("should replace the member", func() {
+			Expect(replacement.Run(testCtx)).Should(Succeed())
+			Expect(control.removals).Should(Equal(1))
+			Expect(control.additions).Should(Equal(1))
+			Expect(control.promotions).Should(Equal(1))
+
+			lease := &coordinationv1.Lease{}
+			Expect(clientSet.Get(testCtx, client.ObjectKey{Namespace: podNamespace, Name: replacedMember}, lease)).Should(Succeed())
+			Expect(lease.Spec.HolderIdentity).Should(BeNil())
+			Expect(lease.Spec.RenewTime).Should(BeNil())
+		})
+
+		It("should fail if the new member does not catch up", func() {
+			control.lag = 11
+			replacement.CatchUpTimeout = 10 * time.Millisecond
+			Expect(replacement.Run(testCtx)).Should(MatchError(member.ErrLearnerCatchUpTimeout))
+			Expect(control.additions).Should(Equal(1))
+			Expect(control.promotions).Should(Equal(0))
+		})
+	})
+
+	Context("When removing the member would break the quorum", func() {
+		It("should refuse to replace the member", func() {
+			unhealthy["http://etcd-test-2:2379"] = true
+			Expect(replacement.Run(testCtx)).Should(MatchError(member.ErrQuorumAtRisk))
+			Expect(control.removals).Should(Equal(0))
+		})
+	})
+
+	Context("When a learner is present in the cluster", func() {
+		It("should refuse to replace the member", func() {
+			members[2].IsLearner = true
+			Expect(replacement.Run(testCtx)).Should(MatchError(member.ErrQuorumAtRisk))
+			Expect(control.removals).Should(Equal(0))
+		})
+	})
+
+	Context("When the member is not part of the cluster", func() {
+		It("should refuse to replace the member", func() {
+			replacement.Name = "etcd-test-3"
+			Expect(replacement.Run(testCtx)).Should(MatchError(member.ErrMissingMember))
+			Expect(control.removals).Should(Equal(0))
+		})
+	})
+})
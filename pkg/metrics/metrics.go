@@ -24,6 +24,8 @@ const (
 	ValueRestoreSingleMemberInMultiNode = "single_member"
 	// ValueRestoreSingleNode is value for metric of single node restoration.
 	ValueRestoreSingleNode = "single_node"
+	// ValueRestoreCluster is value for metric of coordinated restoration of all members in multi-node.
+	ValueRestoreCluster = "cluster"
 	// LabelKind is a metrics label indicates kind of snapshot associated with metric.
 	LabelKind = "kind"
 	// LabelError is a metric error to indicate error occured.
@@ -32,6 +34,14 @@ const (
 	LabelRestorationKind = "restore"
 	// LabelEndPoint is metric label for metric of etcd cluster endpoint.
 	LabelEndPoint = "endpoint"
+	// LabelStore is metric label for metric of a snapstore of a fan-out.
+	LabelStore = "store"
+	// LabelCacheResult is metric label indicating whether a snapshot was found in the snapshot cache.
+	LabelCacheResult = "result"
+	// ValueCacheHit is value for metric label result of a snapshot found in the snapshot cache.
+	ValueCacheHit = "hit"
+	// ValueCacheMiss is value for metric label result of a snapshot not found in the snapshot cache.
+	ValueCacheMiss = "miss"
 
 	namespaceEtcdBR      = "etcdbr"
 	subsystemSnapshot    = "snapshot"
@@ -46,6 +56,7 @@ var (
 			brtypes.SnapshotKindFull,
 			brtypes.SnapshotKindDelta,
 			brtypes.SnapshotKindChunk,
+			brtypes.SnapshotKindPageDiff,
 		},
 		LabelSucceeded: {
 			ValueSucceededFalse,
@@ -54,8 +65,13 @@ var (
 		LabelRestorationKind: {
 			ValueRestoreSingleMemberInMultiNode,
 			ValueRestoreSingleNode,
+			ValueRestoreCluster,
 		},
 		LabelEndPoint: {""},
+		LabelCacheResult: {
+			ValueCacheHit,
+			ValueCacheMiss,
+		},
 	}
 
 	// GCSnapshotCounter is metric to count the garbage collected snapshots.
@@ -165,6 +181,38 @@ var (
 		[]string{},
 	)
 
+	// SnapstoreFanOutPendingRepairs is metric to expose the number of snapshots which could not be saved to or deleted from a snapstore of a fan-out yet.
+	SnapstoreFanOutPendingRepairs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespaceEtcdBR,
+			Subsystem: subsystemSnapstore,
+			Name:      "fan_out_pending_repairs",
+			Help:      "Number of snapshots which could not be saved to or deleted from a snapstore of the fan-out yet.",
+		},
+		[]string{LabelStore},
+	)
+
+	// SnapstoreCacheRequestsTotal is metric to count the snapshots requested from the snapshot cache.
+	SnapstoreCacheRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespaceEtcdBR,
+			Subsystem: subsystemSnapstore,
+			Name:      "cache_requests_total",
+			Help:      "Total number of snapshots requested from the snapshot cache.",
+		},
+		[]string{LabelCacheResult},
+	)
+
+	// SnapstoreCacheSizeBytes is metric to expose the size of the snapshot cache.
+	SnapstoreCacheSizeBytes = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespaceEtcdBR,
+			Subsystem: subsystemSnapstore,
+			Name:      "cache_size_bytes",
+			Help:      "Size of the snapshots in the snapshot cache in bytes.",
+		},
+	)
+
 	//SnapshotterOperationFailure is metric to count the number of snapshotter operations that have errored out
 	SnapshotterOperationFailure = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -196,6 +244,26 @@ var (
 		[]string{},
 	)
 
+	// LearnerApplyLag is metric to expose the number of raft entries the learner lags behind the leader.
+	LearnerApplyLag = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespaceEtcdBR,
+			Name:      "learner_apply_lag",
+			Help:      "Number of raft entries applied by the leader which have not been applied by this learner yet.",
+		},
+		[]string{},
+	)
+
+	// QuorumLost is metric to expose whether the etcd member has not known of a leader for longer than the quorum loss threshold.
+	QuorumLost = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespaceEtcdBR,
+			Name:      "quorum_lost",
+			Help:      "Whether or not the etcd member has not known of a leader for longer than the quorum loss threshold.",
+		},
+		[]string{},
+	)
+
 	// IsLearnerCountTotal is metric to expose the total count when etcd member added as a learner.
 	IsLearnerCountTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -329,6 +397,15 @@ func init() {
 		GCSnapshotCounter.With(prometheus.Labels(combination))
 	}
 
+	// SnapstoreCacheRequestsTotal
+	snapstoreCacheRequestsTotalLabelValues := map[string][]string{
+		LabelCacheResult: labels[LabelCacheResult],
+	}
+	snapstoreCacheRequestsTotalCombinations := generateLabelCombinations(snapstoreCacheRequestsTotalLabelValues)
+	for _, combination := range snapstoreCacheRequestsTotalCombinations {
+		SnapstoreCacheRequestsTotal.With(prometheus.Labels(combination))
+	}
+
 	// LatestSnapshotRevision
 	latestSnapshotRevisionLabelValues := map[string][]string{
 		LabelKind: labels[LabelKind],
@@ -446,6 +523,12 @@ func init() {
 	// IsLearner
 	IsLearner.With(prometheus.Labels(map[string]string{}))
 
+	// LearnerApplyLag
+	LearnerApplyLag.With(prometheus.Labels(map[string]string{}))
+
+	// QuorumLost
+	QuorumLost.With(prometheus.Labels(map[string]string{}))
+
 	// Metrics have to be registered to be exposed:
 	prometheus.MustRegister(GCSnapshotCounter)
 
@@ -460,11 +543,16 @@ func init() {
 
 	prometheus.MustRegister(SnapstoreLatestDeltasTotal)
 	prometheus.MustRegister(SnapstoreLatestDeltasRevisionsTotal)
+	prometheus.MustRegister(SnapstoreFanOutPendingRepairs)
+	prometheus.MustRegister(SnapstoreCacheRequestsTotal)
+	prometheus.MustRegister(SnapstoreCacheSizeBytes)
 
 	prometheus.MustRegister(SnapshotterOperationFailure)
 
 	prometheus.MustRegister(CurrentClusterSize)
 	prometheus.MustRegister(IsLearner)
+	prometheus.MustRegister(LearnerApplyLag)
+	prometheus.MustRegister(QuorumLost)
 	prometheus.MustRegister(IsLearnerCountTotal)
 	prometheus.MustRegister(MemberRemoveDurationSeconds)
 	prometheus.MustRegister(AddLearnerDurationSeconds)
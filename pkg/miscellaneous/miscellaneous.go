@@ -10,11 +10,13 @@ import (
 	"crypto/x509"
 	errored "errors"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -23,6 +25,7 @@ import (
 	"github.com/gardener/etcd-backup-restore/pkg/errors"
 	etcdClient "github.com/gardener/etcd-backup-restore/pkg/etcdutil/client"
 	"github.com/gardener/etcd-backup-restore/pkg/metrics"
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
 	"github.com/gardener/etcd-backup-restore/pkg/snapstore"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
@@ -56,6 +59,20 @@ const (
 	// ScaledToMultiNodeAnnotationKey defines annotation key for scale-up to multi-node cluster.
 	ScaledToMultiNodeAnnotationKey = "gardener.cloud/scaled-to-multi-node"
 
+	// ClusterRestoreAnnotationKey defines annotation key requesting the restoration of all members of a multi-node cluster.
+	// Its value identifies the restoration.
+	ClusterRestoreAnnotationKey = "gardener.cloud/cluster-restore"
+	// ClusterRestoreSeedAnnotationKey defines annotation key for the member which restores the cluster, which the other
+	// members join. The member with ordinal 0 restores the cluster if it is not set.
+	ClusterRestoreSeedAnnotationKey = "gardener.cloud/cluster-restore-seed"
+	// ClusterRestoreSourceAnnotationKey defines annotation key for the source the seed restores the cluster from,
+	// either ClusterRestoreSourceSnapstore or ClusterRestoreSourceDataDir. The cluster is restored from the snapstore if it is not set.
+	ClusterRestoreSourceAnnotationKey = "gardener.cloud/cluster-restore-source"
+	// ClusterRestoreSourceSnapstore defines that the seed restores the cluster from the latest snapshots.
+	ClusterRestoreSourceSnapstore = "snapstore"
+	// ClusterRestoreSourceDataDir defines that the seed recovers the cluster from its data directory as a single member cluster.
+	ClusterRestoreSourceDataDir = "data-dir"
+
 	https = "https"
 
 	// etcdWrapperPort defines the port no. used by etcd-wrapper.
@@ -69,20 +86,46 @@ type advertiseURLsConfig struct {
 
 // GetLatestFullSnapshotAndDeltaSnapList returns the latest snapshot
 func GetLatestFullSnapshotAndDeltaSnapList(store brtypes.SnapStore) (*brtypes.Snapshot, brtypes.SnapList, error) {
+	fullSnapshot, deltaSnapList, _, err := getLatestFullSnapshotAndDeltaSnapLists(store)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metrics.SnapstoreLatestDeltasTotal.With(prometheus.Labels{}).Set(float64(len(deltaSnapList)))
+	if len(deltaSnapList) == 0 {
+		metrics.SnapstoreLatestDeltasRevisionsTotal.With(prometheus.Labels{}).Set(0)
+	} else {
+		revisionDiff := deltaSnapList[len(deltaSnapList)-1].LastRevision - deltaSnapList[0].StartRevision
+		metrics.SnapstoreLatestDeltasRevisionsTotal.With(prometheus.Labels{}).Set(float64(revisionDiff))
+	}
+	return fullSnapshot, deltaSnapList, nil
+}
+
+// GetLatestSupersededDeltaSnapshots returns the delta snapshots of the latest full snapshot whose revisions are covered
+// by another delta snapshot, i.e. delta snapshots which have been squashed but not yet deleted from the snapstore.
+func GetLatestSupersededDeltaSnapshots(store brtypes.SnapStore) (brtypes.SnapList, error) {
+	_, _, superseded, err := getLatestFullSnapshotAndDeltaSnapLists(store)
+	return superseded, err
+}
+
+// getLatestFullSnapshotAndDeltaSnapLists returns the latest full snapshot, its delta snapshots without the superseded
+// ones, and the superseded delta snapshots.
+func getLatestFullSnapshotAndDeltaSnapLists(store brtypes.SnapStore) (*brtypes.Snapshot, brtypes.SnapList, brtypes.SnapList, error) {
 	var (
 		fullSnapshot  *brtypes.Snapshot
 		deltaSnapList brtypes.SnapList
 	)
 	snapList, err := store.List(false)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	for index := len(snapList); index > 0; index-- {
 		if snapList[index-1].IsChunk {
 			continue
 		}
-		if snapList[index-1].Kind == brtypes.SnapshotKindFull {
+		// a page diff snapshot restores the full snapshot it was taken from, so it is the base of the later delta snapshots.
+		if snapList[index-1].Kind == brtypes.SnapshotKindFull || snapList[index-1].Kind == brtypes.SnapshotKindPageDiff {
 			fullSnapshot = snapList[index-1]
 			break
 		}
@@ -90,14 +133,39 @@ func GetLatestFullSnapshotAndDeltaSnapList(store brtypes.SnapStore) (*brtypes.Sn
 	}
 
 	sort.Sort(deltaSnapList) // ensures that the delta snapshot list is well formed
-	metrics.SnapstoreLatestDeltasTotal.With(prometheus.Labels{}).Set(float64(len(deltaSnapList)))
-	if len(deltaSnapList) == 0 {
-		metrics.SnapstoreLatestDeltasRevisionsTotal.With(prometheus.Labels{}).Set(0)
-	} else {
-		revisionDiff := deltaSnapList[len(deltaSnapList)-1].LastRevision - deltaSnapList[0].StartRevision
-		metrics.SnapstoreLatestDeltasRevisionsTotal.With(prometheus.Labels{}).Set(float64(revisionDiff))
+	deltaSnapList, superseded := splitSupersededDeltaSnapshots(deltaSnapList)
+	return fullSnapshot, deltaSnapList, superseded, nil
+}
+
+// splitSupersededDeltaSnapshots splits off the delta snapshots whose revisions are covered by another delta snapshot,
+// i.e. delta snapshots which have been squashed but not yet deleted from the snapstore.
+func splitSupersededDeltaSnapshots(deltaSnapList brtypes.SnapList) (brtypes.SnapList, brtypes.SnapList) {
+	sorted := make(brtypes.SnapList, len(deltaSnapList))
+	copy(sorted, deltaSnapList)
+	// a squashed delta snapshot ends with the same revision as the last delta snapshot it replaces, so it must sort after it.
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].LastRevision != sorted[j].LastRevision {
+			return sorted[i].LastRevision < sorted[j].LastRevision
+		}
+		return sorted[i].StartRevision > sorted[j].StartRevision
+	})
+
+	var (
+		filtered    brtypes.SnapList
+		superseded  brtypes.SnapList
+		coveredFrom int64 = math.MaxInt64
+	)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if sorted[i].StartRevision >= coveredFrom {
+			superseded = append(superseded, sorted[i])
+			continue
+		}
+		coveredFrom = sorted[i].StartRevision
+		filtered = append(filtered, sorted[i])
 	}
-	return fullSnapshot, deltaSnapList, nil
+	slices.Reverse(filtered)
+	slices.Reverse(superseded)
+	return filtered, superseded
 }
 
 type backup struct {
@@ -144,6 +212,7 @@ func getStructuredBackupList(snapList brtypes.SnapList) []backup {
 		if snapList[i].IsChunk {
 			continue
 		}
+		// page diff snapshots belong to the backup of the full snapshot they were taken against.
 		if snapList[i].Kind == brtypes.SnapshotKindFull {
 			tempBackup.FullSnapshot = snapList[i]
 			backups = append(backups, tempBackup)
@@ -157,8 +226,22 @@ func getStructuredBackupList(snapList brtypes.SnapList) []backup {
 
 // StartEmbeddedEtcd starts the embedded etcd server.
 func StartEmbeddedEtcd(logger *logrus.Entry, ro *brtypes.RestoreOptions) (*embed.Etcd, error) {
+	return startEmbeddedEtcd(logger, ro, false)
+}
+
+// StartEmbeddedEtcdWithForceNewCluster starts the embedded etcd server on the data directory of a member of a
+// multi-node cluster with the name of the member, and removes all other members from the cluster.
+func StartEmbeddedEtcdWithForceNewCluster(logger *logrus.Entry, ro *brtypes.RestoreOptions) (*embed.Etcd, error) {
+	return startEmbeddedEtcd(logger, ro, true)
+}
+
+func startEmbeddedEtcd(logger *logrus.Entry, ro *brtypes.RestoreOptions, forceNewCluster bool) (*embed.Etcd, error) {
 	cfg := embed.NewConfig()
 	cfg.Dir = filepath.Join(ro.Config.DataDir)
+	if forceNewCluster {
+		cfg.Name = ro.Config.Name
+		cfg.ForceNewCluster = true
+	}
 	DefaultListenPeerURLs := "http://localhost:0"
 	DefaultListenClientURLs := "http://localhost:0"
 	DefaultInitialAdvertisePeerURLs := "http://localhost:0"
@@ -486,6 +569,7 @@ func DoPromoteMember(ctx context.Context, member *etcdserverpb.Member, cli etcdC
 		metrics.IsLearner.With(prometheus.Labels{}).Set(0)
 		metrics.MemberPromoteDurationSeconds.With(prometheus.Labels{metrics.LabelSucceeded: metrics.ValueSucceededTrue}).Observe(time.Since(start).Seconds())
 		logger.Infof("Member %v with [ID: %v] has been promoted", member.GetName(), strconv.FormatUint(member.GetID(), 16))
+		notifier.Notify(notifier.Event{Type: notifier.EventLearnerPromoted, Data: notifier.EventData{Member: member.GetName(), DurationSeconds: time.Since(start).Seconds()}})
 		return nil
 	} else if errored.Is(err, rpctypes.Error(rpctypes.ErrGRPCMemberNotLearner)) {
 		//Member is not a learner
@@ -497,6 +581,45 @@ func DoPromoteMember(ctx context.Context, member *etcdserverpb.Member, cli etcdC
 	return err
 }
 
+// GetLearnerApplyLag returns the number of raft entries applied by the leader which have not been applied
+// by the learner with the given endpoint yet.
+func GetLearnerApplyLag(ctx context.Context, clientMaintenance etcdClient.MaintenanceCloser, client etcdClient.ClusterCloser, endpoint string) (uint64, error) {
+	learnerStatus, err := clientMaintenance.Status(ctx, endpoint)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get status of learner %s: %w", endpoint, err)
+	}
+
+	if learnerStatus.Leader == NoLeaderState {
+		return 0, &errors.EtcdError{
+			Message: "currently there is no Etcd Leader present may be due to etcd quorum loss.",
+		}
+	}
+
+	membersInfo, err := client.MemberList(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error listing members: %w", err)
+	}
+	var leaderEndpoints []string
+	for _, member := range membersInfo.Members {
+		if member.GetID() == learnerStatus.Leader {
+			leaderEndpoints = member.GetClientURLs()
+		}
+	}
+	if len(leaderEndpoints) == 0 {
+		return 0, fmt.Errorf("no client URLs found for leader %s", strconv.FormatUint(learnerStatus.Leader, 16))
+	}
+
+	leaderStatus, err := clientMaintenance.Status(ctx, leaderEndpoints[0])
+	if err != nil {
+		return 0, fmt.Errorf("failed to get status of leader %s: %w", leaderEndpoints[0], err)
+	}
+
+	if leaderStatus.RaftAppliedIndex <= learnerStatus.RaftAppliedIndex {
+		return 0, nil
+	}
+	return leaderStatus.RaftAppliedIndex - learnerStatus.RaftAppliedIndex, nil
+}
+
 // CheckIfLearnerPresent checks whether a learner(non-voting) member present or not.
 func CheckIfLearnerPresent(ctx context.Context, cli etcdClient.ClusterCloser) (bool, error) {
 	membersInfo, err := cli.MemberList(ctx)
@@ -560,7 +683,11 @@ func GetMemberPeerURLs(configFile string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return GetPeerURLsOfMember(configFile, memberName)
+}
 
+// GetPeerURLsOfMember retrieves the initial advertise peer URLs of the etcd member with the given name.
+func GetPeerURLsOfMember(configFile, memberName string) ([]string, error) {
 	advURLsConfig, err := parseAdvertiseURLsConfig(configFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse advertise URLs config: %w", err)
@@ -585,7 +712,11 @@ func GetMemberClientURLs(configFile string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return GetClientURLsOfMember(configFile, memberName)
+}
 
+// GetClientURLsOfMember retrieves the advertise client URLs of the etcd member with the given name.
+func GetClientURLsOfMember(configFile, memberName string) ([]string, error) {
 	advURLsConfig, err := parseAdvertiseURLsConfig(configFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse advertise URLs config: %w", err)
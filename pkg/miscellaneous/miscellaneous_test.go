@@ -303,6 +303,60 @@ var _ = Describe("Miscellaneous Tests", func() {
 			})
 		})
 
+		Context("Status of learner and leader are available", func() {
+			It("should return the apply lag of the learner", func() {
+				clientMaintenance, err := factory.NewMaintenance()
+				Expect(err).ShouldNot(HaveOccurred())
+
+				clientCluster, err := factory.NewCluster()
+				Expect(err).ShouldNot(HaveOccurred())
+
+				cm.EXPECT().Status(gomock.Any(), dummyClientEndpoints[1]).DoAndReturn(func(_ context.Context, _ string) (*clientv3.StatusResponse, error) {
+					response := new(clientv3.StatusResponse)
+					response.Leader = dummyID
+					response.RaftAppliedIndex = 70
+					return response, nil
+				})
+				cm.EXPECT().Status(gomock.Any(), dummyClientEndpoints[0]).DoAndReturn(func(_ context.Context, _ string) (*clientv3.StatusResponse, error) {
+					response := new(clientv3.StatusResponse)
+					response.Leader = dummyID
+					response.RaftAppliedIndex = 100
+					return response, nil
+				})
+				cl.EXPECT().MemberList(gomock.Any()).DoAndReturn(func(_ context.Context) (*clientv3.MemberListResponse, error) {
+					response := new(clientv3.MemberListResponse)
+					response.Members = []*etcdserverpb.Member{
+						{ID: dummyID, ClientURLs: []string{dummyClientEndpoints[0]}},
+						{ID: dummyID + 1, ClientURLs: []string{dummyClientEndpoints[1]}, IsLearner: true},
+					}
+					return response, nil
+				})
+
+				lag, err := GetLearnerApplyLag(testCtx, clientMaintenance, clientCluster, dummyClientEndpoints[1])
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(lag).Should(Equal(uint64(30)))
+			})
+		})
+
+		Context("Learner has no etcd leader", func() {
+			It("should return error", func() {
+				clientMaintenance, err := factory.NewMaintenance()
+				Expect(err).ShouldNot(HaveOccurred())
+
+				clientCluster, err := factory.NewCluster()
+				Expect(err).ShouldNot(HaveOccurred())
+
+				cm.EXPECT().Status(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ string) (*clientv3.StatusResponse, error) {
+					response := new(clientv3.StatusResponse)
+					response.Leader = NoLeaderState
+					return response, nil
+				})
+
+				_, err = GetLearnerApplyLag(testCtx, clientMaintenance, clientCluster, dummyClientEndpoints[1])
+				Expect(err).Should(HaveOccurred())
+			})
+		})
+
 		Context("Learner is present in a cluster", func() {
 			It("should return true", func() {
 
@@ -0,0 +1,249 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PodConditionInitialized is the pod condition reflecting the result of the last data directory initialization.
+	PodConditionInitialized corev1.PodConditionType = "backup-restore.etcd.gardener.cloud/Initialized"
+	// PodConditionBackupReady is the pod condition reflecting the result of the last snapshot.
+	PodConditionBackupReady corev1.PodConditionType = "backup-restore.etcd.gardener.cloud/BackupReady"
+
+	// eventSourceComponent is the component reported as source of the Kubernetes events.
+	eventSourceComponent = "etcd-backup-restore"
+	// maxEventMessageLength is the maximum length of the message of a Kubernetes event.
+	maxEventMessageLength = 1024
+	// kubernetesRequestTimeout is the timeout of a single request to the Kubernetes API server.
+	kubernetesRequestTimeout = 10 * time.Second
+	// kubernetesQueueSize is the number of events queued for recording before events are dropped.
+	kubernetesQueueSize = 256
+)
+
+// kubernetesEvent describes how an event is recorded as Kubernetes event.
+type kubernetesEvent struct {
+	reason    string
+	eventType string
+	// onStatefulSet records the event on the StatefulSet instead of the pod, e.g. if it concerns another member.
+	onStatefulSet bool
+}
+
+// kubernetesEvents are the events recorded as Kubernetes events. All other events are only
+// reflected in the pod conditions, if at all, to avoid flooding the API server.
+var kubernetesEvents = map[string]kubernetesEvent{
+	EventSnapshotFailed:         {reason: "SnapshotFailed", eventType: corev1.EventTypeWarning},
+	EventRestorationStarted:     {reason: "RestorationStarted", eventType: corev1.EventTypeNormal},
+	EventRestorationSucceeded:   {reason: "RestorationSucceeded", eventType: corev1.EventTypeNormal},
+	EventRestorationFailed:      {reason: "RestorationFailed", eventType: corev1.EventTypeWarning},
+	EventInitializationFailed:   {reason: "InitializationFailed", eventType: corev1.EventTypeWarning},
+	EventDataDirectoryCorrupted: {reason: "DataDirectoryCorrupted", eventType: corev1.EventTypeWarning},
+	EventWrongVolumeMounted:     {reason: "WrongVolumeMounted", eventType: corev1.EventTypeWarning},
+	EventLearnerAdded:           {reason: "LearnerAdded", eventType: corev1.EventTypeNormal},
+	EventLearnerPromoted:        {reason: "LearnerPromoted", eventType: corev1.EventTypeNormal},
+	EventLearnerCatchUpTimedOut: {reason: "LearnerCatchUpTimedOut", eventType: corev1.EventTypeWarning},
+	EventQuorumLost:             {reason: "QuorumLost", eventType: corev1.EventTypeWarning},
+	EventMemberRemoved:          {reason: "MemberRemoved", eventType: corev1.EventTypeNormal, onStatefulSet: true},
+}
+
+// KubernetesEventNotifier records events as Kubernetes events on the etcd pod and its StatefulSet,
+// and reflects the initialization and snapshot results in conditions of the etcd pod.
+type KubernetesEventNotifier struct {
+	client      client.Client
+	logger      *logrus.Entry
+	queue       chan Event
+	pod         corev1.ObjectReference
+	statefulSet *corev1.ObjectReference
+	// conditions holds the last reported status and reason of the pod conditions.
+	conditions map[corev1.PodConditionType]string
+}
+
+// NewKubernetesEventNotifier returns a new Kubernetes event notifier for the given etcd pod.
+func NewKubernetesEventNotifier(ctx context.Context, cl client.Client, podNamespace, podName string, logger *logrus.Entry) (*KubernetesEventNotifier, error) {
+	getCtx, cancel := context.WithTimeout(ctx, kubernetesRequestTimeout)
+	defer cancel()
+	pod := &corev1.Pod{}
+	if err := cl.Get(getCtx, client.ObjectKey{Namespace: podNamespace, Name: podName}, pod); err != nil {
+		return nil, fmt.Errorf("failed to get pod %s/%s: %v", podNamespace, podName, err)
+	}
+
+	k := &KubernetesEventNotifier{
+		client: cl,
+		logger: logger.WithField("actor", "kubernetes-event-notifier"),
+		queue:  make(chan Event, kubernetesQueueSize),
+		pod: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Namespace:  pod.Namespace,
+			Name:       pod.Name,
+			UID:        pod.UID,
+		},
+		conditions: map[corev1.PodConditionType]string{},
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "StatefulSet" {
+			k.statefulSet = &corev1.ObjectReference{
+				APIVersion: owner.APIVersion,
+				Kind:       owner.Kind,
+				Namespace:  pod.Namespace,
+				Name:       owner.Name,
+				UID:        owner.UID,
+			}
+			break
+		}
+	}
+	return k, nil
+}
+
+// Notify queues the event for recording. The event is dropped if the queue is full.
+func (k *KubernetesEventNotifier) Notify(event Event) {
+	select {
+	case k.queue <- event:
+	default:
+		k.logger.Warnf("Dropping Kubernetes event %s since the event queue is full", event.Type)
+	}
+}
+
+// Run records the queued events until the context is cancelled.
+func (k *KubernetesEventNotifier) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-k.queue:
+			if err := k.recordEvent(ctx, event); err != nil {
+				k.logger.Errorf("Failed to record Kubernetes event for %s: %v", event.Type, err)
+			}
+			if err := k.updateCondition(ctx, event); err != nil {
+				k.logger.Errorf("Failed to update pod condition for %s: %v", event.Type, err)
+			}
+		}
+	}
+}
+
+func (k *KubernetesEventNotifier) recordEvent(ctx context.Context, event Event) error {
+	kEvent, ok := kubernetesEvents[event.Type]
+	if !ok {
+		return nil
+	}
+	involvedObject := k.pod
+	if kEvent.onStatefulSet && k.statefulSet != nil {
+		involvedObject = *k.statefulSet
+	}
+
+	message := eventMessage(event)
+	if len(message) > maxEventMessageLength {
+		message = message[:maxEventMessageLength]
+	}
+	timestamp := metav1.NewTime(event.Time)
+	obj := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", involvedObject.Name, event.Time.UnixNano()),
+			Namespace: involvedObject.Namespace,
+		},
+		InvolvedObject:      involvedObject,
+		Reason:              kEvent.reason,
+		Message:             message,
+		Type:                kEvent.eventType,
+		Source:              corev1.EventSource{Component: eventSourceComponent},
+		FirstTimestamp:      timestamp,
+		LastTimestamp:       timestamp,
+		Count:               1,
+		ReportingController: eventSourceComponent,
+		ReportingInstance:   k.pod.Name,
+	}
+
+	createCtx, cancel := context.WithTimeout(ctx, kubernetesRequestTimeout)
+	defer cancel()
+	return k.client.Create(createCtx, obj)
+}
+
+// updateCondition patches the pod condition reflecting the event, if its status or reason changed.
+func (k *KubernetesEventNotifier) updateCondition(ctx context.Context, event Event) error {
+	var condition corev1.PodCondition
+	switch event.Type {
+	case EventInitializationSucceeded:
+		condition = corev1.PodCondition{Type: PodConditionInitialized, Status: corev1.ConditionTrue, Reason: "InitializationSucceeded"}
+	case EventInitializationFailed:
+		condition = corev1.PodCondition{Type: PodConditionInitialized, Status: corev1.ConditionFalse, Reason: "InitializationFailed"}
+	case EventSnapshotSucceeded:
+		condition = corev1.PodCondition{Type: PodConditionBackupReady, Status: corev1.ConditionTrue, Reason: "SnapshotSucceeded"}
+	case EventSnapshotFailed:
+		condition = corev1.PodCondition{Type: PodConditionBackupReady, Status: corev1.ConditionFalse, Reason: "SnapshotFailed"}
+	default:
+		return nil
+	}
+	if k.conditions[condition.Type] == condition.Reason {
+		return nil
+	}
+	condition.Message = eventMessage(event)
+	condition.LastTransitionTime = metav1.NewTime(event.Time)
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []corev1.PodCondition{condition},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: k.pod.Namespace, Name: k.pod.Name}}
+	patchCtx, cancel := context.WithTimeout(ctx, kubernetesRequestTimeout)
+	defer cancel()
+	if err := k.client.Status().Patch(patchCtx, pod, client.RawPatch(types.StrategicMergePatchType, patch)); err != nil {
+		return err
+	}
+	k.conditions[condition.Type] = condition.Reason
+	return nil
+}
+
+// eventMessage returns a human-readable description of the event.
+func eventMessage(event Event) string {
+	d := event.Data
+	switch event.Type {
+	case EventSnapshotSucceeded:
+		return fmt.Sprintf("Saved %s snapshot %s", d.Kind, d.SnapName)
+	case EventSnapshotFailed:
+		return fmt.Sprintf("Failed to save %s snapshot %s: %s", d.Kind, d.SnapName, d.Error)
+	case EventRestorationStarted:
+		if len(d.SnapName) > 0 {
+			return fmt.Sprintf("Restoring etcd data directory from full snapshot %s up to revision %d", d.SnapName, d.LastRevision)
+		}
+		return fmt.Sprintf("Restoring etcd data directory (%s)", d.Kind)
+	case EventRestorationSucceeded:
+		return fmt.Sprintf("Restored etcd data directory in %.2f seconds", d.DurationSeconds)
+	case EventRestorationFailed:
+		return fmt.Sprintf("Failed to restore etcd data directory: %s", d.Error)
+	case EventInitializationSucceeded:
+		return "Initialized etcd data directory"
+	case EventInitializationFailed:
+		return fmt.Sprintf("Failed to initialize etcd data directory: %s", d.Error)
+	case EventDataDirectoryCorrupted:
+		return fmt.Sprintf("etcd data directory %s is corrupt", d.SnapDir)
+	case EventWrongVolumeMounted:
+		return fmt.Sprintf("Wrong etcd volume is mounted: %s", d.Error)
+	case EventLearnerAdded:
+		return fmt.Sprintf("Added member %s to the etcd cluster as a learner", d.Member)
+	case EventLearnerPromoted:
+		return fmt.Sprintf("Promoted learner %s to a voting member", d.Member)
+	case EventLearnerCatchUpTimedOut:
+		return fmt.Sprintf("Learner has not caught up with the etcd leader: %s", d.Error)
+	case EventQuorumLost:
+		return fmt.Sprintf("etcd cluster has lost its quorum, recommended recovery is %s with member %s at revision %d", d.Kind, d.Member, d.LastRevision)
+	case EventMemberRemoved:
+		return fmt.Sprintf("Removed superfluous member %s from the etcd cluster", d.Member)
+	}
+	return event.Type
+}
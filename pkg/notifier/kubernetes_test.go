@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package notifier_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("KubernetesEventNotifier", func() {
+	const (
+		namespace = "default"
+		podName   = "etcd-main-0"
+		stsName   = "etcd-main"
+	)
+	var (
+		cl     client.Client
+		ctx    context.Context
+		cancel context.CancelFunc
+		logger = logrus.NewEntry(logrus.New())
+	)
+
+	BeforeEach(func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      podName,
+				UID:       "pod-uid",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "StatefulSet", Name: stsName, UID: "sts-uid"},
+				},
+			},
+		}
+		cl = fake.NewClientBuilder().WithObjects(pod).WithStatusSubresource(pod).Build()
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	startNotifier := func() *notifier.KubernetesEventNotifier {
+		k, err := notifier.NewKubernetesEventNotifier(ctx, cl, namespace, podName, logger)
+		Expect(err).ShouldNot(HaveOccurred())
+		go k.Run(ctx)
+		return k
+	}
+
+	listEvents := func() []corev1.Event {
+		events := &corev1.EventList{}
+		Expect(cl.List(ctx, events, client.InNamespace(namespace))).To(Succeed())
+		return events.Items
+	}
+
+	It("should fail if the pod does not exist", func() {
+		_, err := notifier.NewKubernetesEventNotifier(ctx, cl, namespace, "etcd-main-1", logger)
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should record warning events on the pod", func() {
+		startNotifier().Notify(notifier.Event{
+			Type: notifier.EventWrongVolumeMounted,
+			Time: time.Now(),
+			Data: notifier.EventData{Error: "volume of etcd-main-1 mounted"},
+		})
+
+		Eventually(listEvents).Should(HaveLen(1))
+		event := listEvents()[0]
+		Expect(event.Type).To(Equal(corev1.EventTypeWarning))
+		Expect(event.Reason).To(Equal("WrongVolumeMounted"))
+		Expect(event.Message).To(ContainSubstring("volume of etcd-main-1 mounted"))
+		Expect(event.InvolvedObject.Kind).To(Equal("Pod"))
+		Expect(event.InvolvedObject.Name).To(Equal(podName))
+		Expect(event.InvolvedObject.UID).To(BeEquivalentTo("pod-uid"))
+	})
+
+	It("should record member removals on the StatefulSet", func() {
+		startNotifier().Notify(notifier.Event{
+			Type: notifier.EventMemberRemoved,
+			Time: time.Now(),
+			Data: notifier.EventData{Member: "etcd-main-2"},
+		})
+
+		Eventually(listEvents).Should(HaveLen(1))
+		event := listEvents()[0]
+		Expect(event.Type).To(Equal(corev1.EventTypeNormal))
+		Expect(event.Reason).To(Equal("MemberRemoved"))
+		Expect(event.Message).To(ContainSubstring("etcd-main-2"))
+		Expect(event.InvolvedObject.Kind).To(Equal("StatefulSet"))
+		Expect(event.InvolvedObject.Name).To(Equal(stsName))
+	})
+
+	It("should reflect snapshot results in the pod conditions without recording successful snapshots as events", func() {
+		k := startNotifier()
+		getCondition := func() *corev1.PodCondition {
+			pod := &corev1.Pod{}
+			Expect(cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: podName}, pod)).To(Succeed())
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == notifier.PodConditionBackupReady {
+					return &condition
+				}
+			}
+			return nil
+		}
+
+		k.Notify(notifier.Event{Type: notifier.EventSnapshotSucceeded, Time: time.Now(), Data: notifier.EventData{Kind: brtypes.SnapshotKindDelta}})
+		Eventually(getCondition).ShouldNot(BeNil())
+		Expect(getCondition().Status).To(Equal(corev1.ConditionTrue))
+		Expect(listEvents()).To(BeEmpty())
+
+		k.Notify(notifier.Event{Type: notifier.EventSnapshotFailed, Time: time.Now(), Data: notifier.EventData{Kind: brtypes.SnapshotKindDelta, Error: "timeout"}})
+		Eventually(func() corev1.ConditionStatus { return getCondition().Status }).Should(Equal(corev1.ConditionFalse))
+		Expect(getCondition().Reason).To(Equal("SnapshotFailed"))
+		Expect(listEvents()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package notifier
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// EventSnapshotSucceeded is emitted when a full or delta snapshot has been saved to the snapstore.
+	EventSnapshotSucceeded = "snapshot.succeeded"
+	// EventSnapshotFailed is emitted when a full or delta snapshot could not be saved to the snapstore.
+	EventSnapshotFailed = "snapshot.failed"
+	// EventGarbageCollectionDeleted is emitted when the garbage collector has deleted a snapshot.
+	EventGarbageCollectionDeleted = "gc.deleted"
+	// EventGarbageCollectionFailed is emitted when the garbage collector could not delete a snapshot.
+	EventGarbageCollectionFailed = "gc.failed"
+	// EventRestorationStarted is emitted when the restoration of the etcd data directory starts.
+	EventRestorationStarted = "restoration.started"
+	// EventRestorationSucceeded is emitted when the etcd data directory has been restored.
+	EventRestorationSucceeded = "restoration.succeeded"
+	// EventRestorationFailed is emitted when the restoration of the etcd data directory has failed.
+	EventRestorationFailed = "restoration.failed"
+	// EventInitializationSucceeded is emitted when the initialization of the etcd data directory has completed.
+	EventInitializationSucceeded = "initialization.succeeded"
+	// EventInitializationFailed is emitted when the initialization of the etcd data directory has failed.
+	EventInitializationFailed = "initialization.failed"
+	// EventDefragmentationSucceeded is emitted when an etcd member has been defragmented.
+	EventDefragmentationSucceeded = "defragmentation.succeeded"
+	// EventDefragmentationFailed is emitted when the defragmentation of an etcd member has failed.
+	EventDefragmentationFailed = "defragmentation.failed"
+	// EventSecondarySyncFailed is emitted when the backups could not be copied to the secondary snapstore.
+	EventSecondarySyncFailed = "secondarysync.failed"
+	// EventDataDirectoryCorrupted is emitted when the validation finds the etcd data directory corrupted.
+	EventDataDirectoryCorrupted = "datadir.corrupted"
+	// EventWrongVolumeMounted is emitted when the validation finds the volume of another etcd member mounted.
+	EventWrongVolumeMounted = "datadir.wrongvolumemounted"
+	// EventLearnerAdded is emitted when the etcd member has been added to the cluster as a learner.
+	EventLearnerAdded = "member.learneradded"
+	// EventLearnerPromoted is emitted when a learner has been promoted to a voting member.
+	EventLearnerPromoted = "member.promoted"
+	// EventLearnerCatchUpTimedOut is emitted when a learner has not caught up with the leader within the catch-up timeout.
+	EventLearnerCatchUpTimedOut = "member.catchuptimedout"
+	// EventQuorumLost is emitted when the etcd member has not known of a leader for longer than the quorum loss threshold.
+	EventQuorumLost = "member.quorumlost"
+	// EventMemberRemoved is emitted when the member garbage collector has removed a superfluous member from the cluster.
+	EventMemberRemoved = "member.removed"
+)
+
+// Event is a backup lifecycle event.
+type Event struct {
+	Time time.Time `json:"time"`
+	Data EventData `json:"data"`
+	Type string    `json:"type"`
+}
+
+// EventData holds the details of a backup lifecycle event.
+type EventData struct {
+	Kind            string  `json:"kind,omitempty"`
+	SnapName        string  `json:"snapName,omitempty"`
+	SnapDir         string  `json:"snapDir,omitempty"`
+	Endpoint        string  `json:"endpoint,omitempty"`
+	Member          string  `json:"member,omitempty"`
+	Error           string  `json:"error,omitempty"`
+	StartRevision   int64   `json:"startRevision,omitempty"`
+	LastRevision    int64   `json:"lastRevision,omitempty"`
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+}
+
+// Notifier sends backup lifecycle events to external systems.
+type Notifier interface {
+	// Notify sends the event. It must not block the caller.
+	Notify(event Event)
+}
+
+// Multi is a notifier which sends the events to all of its notifiers.
+type Multi []Notifier
+
+// Notify sends the event to all notifiers.
+func (m Multi) Notify(event Event) {
+	for _, n := range m {
+		n.Notify(event)
+	}
+}
+
+var (
+	notifierMutex   sync.RWMutex
+	defaultNotifier Notifier
+)
+
+// SetNotifier sets the notifier used by Notify. Passing nil disables notifications.
+func SetNotifier(n Notifier) {
+	notifierMutex.Lock()
+	defer notifierMutex.Unlock()
+	defaultNotifier = n
+}
+
+// Notify sends the event with the configured notifier, if any. The event time is set if it is zero.
+func Notify(event Event) {
+	notifierMutex.RLock()
+	n := defaultNotifier
+	notifierMutex.RUnlock()
+	if n == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	n.Notify(event)
+}
@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SignatureHeader is the header carrying the HMAC-SHA256 signature of the request body, if a signing secret is configured.
+	SignatureHeader = "X-Etcdbr-Signature"
+	// cloudEventsTypePrefix is the prefix of the CloudEvents type attribute.
+	cloudEventsTypePrefix = "io.gardener.etcd-backup-restore."
+	// cloudEventsSpecVersion is the version of the CloudEvents specification the notifications adhere to.
+	cloudEventsSpecVersion = "1.0"
+	// webhookQueueSize is the number of notifications queued for delivery before notifications are dropped.
+	webhookQueueSize = 256
+)
+
+// cloudEvent is a CloudEvent in structured content mode.
+type cloudEvent struct {
+	Time            time.Time `json:"time"`
+	Data            EventData `json:"data"`
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	DataContentType string    `json:"datacontenttype"`
+}
+
+// WebhookNotifier posts events to HTTP endpoints, either as plain JSON or as CloudEvents.
+// Events are delivered in the background in the order they are notified.
+type WebhookNotifier struct {
+	config        *brtypes.NotificationConfig
+	client        *http.Client
+	logger        *logrus.Entry
+	queue         chan Event
+	source        string
+	signingSecret []byte
+}
+
+// NewWebhookNotifier returns a new webhook notifier. The source identifies the sender of the events.
+func NewWebhookNotifier(config *brtypes.NotificationConfig, source string, logger *logrus.Entry) (*WebhookNotifier, error) {
+	var signingSecret []byte
+	if len(config.SigningSecretFile) > 0 {
+		secret, err := os.ReadFile(config.SigningSecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read notification signing secret: %v", err)
+		}
+		signingSecret = bytes.TrimSpace(secret)
+	}
+	return &WebhookNotifier{
+		config:        config,
+		client:        &http.Client{Timeout: config.Timeout.Duration},
+		logger:        logger.WithField("actor", "notifier"),
+		queue:         make(chan Event, webhookQueueSize),
+		source:        source,
+		signingSecret: signingSecret,
+	}, nil
+}
+
+// Notify queues the event for delivery. The event is dropped if the queue is full.
+func (w *WebhookNotifier) Notify(event Event) {
+	select {
+	case w.queue <- event:
+	default:
+		w.logger.Warnf("Dropping notification %s since the notification queue is full", event.Type)
+	}
+}
+
+// Run delivers the queued events until the context is cancelled.
+func (w *WebhookNotifier) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-w.queue:
+			body, contentType, err := w.encode(event)
+			if err != nil {
+				w.logger.Errorf("Failed to encode notification %s: %v", event.Type, err)
+				continue
+			}
+			for _, webhookURL := range w.config.WebhookURLs {
+				if err := w.deliver(ctx, webhookURL, body, contentType); err != nil {
+					w.logger.Errorf("Failed to deliver notification %s to %s: %v", event.Type, webhookURL, err)
+				}
+			}
+		}
+	}
+}
+
+func (w *WebhookNotifier) encode(event Event) ([]byte, string, error) {
+	if w.config.Format == brtypes.NotificationFormatCloudEvents {
+		body, err := json.Marshal(cloudEvent{
+			SpecVersion:     cloudEventsSpecVersion,
+			ID:              uuid.NewString(),
+			Source:          w.source,
+			Type:            cloudEventsTypePrefix + event.Type,
+			Time:            event.Time,
+			DataContentType: "application/json",
+			Data:            event.Data,
+		})
+		return body, "application/cloudevents+json", err
+	}
+	body, err := json.Marshal(event)
+	return body, "application/json", err
+}
+
+// deliver posts the body to the webhook, retrying with exponential backoff on failure.
+func (w *WebhookNotifier) deliver(ctx context.Context, webhookURL string, body []byte, contentType string) error {
+	backoff := w.config.RetryBackoff.Duration
+	var err error
+	for attempt := uint(0); ; attempt++ {
+		if err = w.post(ctx, webhookURL, body, contentType); err == nil {
+			return nil
+		}
+		if attempt >= w.config.MaxRetries {
+			return fmt.Errorf("giving up after %d attempts: %v", attempt+1, err)
+		}
+		w.logger.Warnf("Failed to deliver notification to %s, retrying in %s: %v", webhookURL, backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (w *WebhookNotifier) post(ctx context.Context, webhookURL string, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if len(w.signingSecret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(w.signingSecret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature of the body for the given secret, in the format of the SignatureHeader.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSource returns the CloudEvents source of the etcd-backup-restore sidecar in the given pod.
+func NewSource(podNamespace, podName string) string {
+	return path.Join("/etcd-backup-restore", podNamespace, podName)
+}
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package notifier_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+	"github.com/gardener/etcd-backup-restore/pkg/wrappers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+var _ = Describe("WebhookNotifier", func() {
+	var (
+		server   *httptest.Server
+		received chan receivedRequest
+		failures atomic.Int32
+		config   *brtypes.NotificationConfig
+		ctx      context.Context
+		cancel   context.CancelFunc
+		logger   = logrus.NewEntry(logrus.New())
+		event    = notifier.Event{
+			Type: notifier.EventSnapshotSucceeded,
+			Time: time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC),
+			Data: notifier.EventData{Kind: brtypes.SnapshotKindFull, SnapName: "Full-00000000-00000010-1748858400", LastRevision: 10},
+		}
+	)
+
+	BeforeEach(func() {
+		received = make(chan receivedRequest, 10)
+		failures.Store(0)
+		server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if failures.Add(-1) >= 0 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			body, err := io.ReadAll(req.Body)
+			Expect(err).ShouldNot(HaveOccurred())
+			received <- receivedRequest{header: req.Header, body: body}
+		}))
+		config = brtypes.NewNotificationConfig()
+		config.WebhookURLs = []string{server.URL}
+		config.RetryBackoff = wrappers.Duration{Duration: 10 * time.Millisecond}
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	startNotifier := func() *notifier.WebhookNotifier {
+		w, err := notifier.NewWebhookNotifier(config, notifier.NewSource("default", "etcd-main-0"), logger)
+		Expect(err).ShouldNot(HaveOccurred())
+		go w.Run(ctx)
+		return w
+	}
+
+	It("should post the event as JSON", func() {
+		startNotifier().Notify(event)
+
+		var req receivedRequest
+		Eventually(received).Should(Receive(&req))
+		Expect(req.header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(req.header.Get(notifier.SignatureHeader)).To(BeEmpty())
+		var got notifier.Event
+		Expect(json.Unmarshal(req.body, &got)).To(Succeed())
+		Expect(got).To(Equal(event))
+	})
+
+	It("should post the event as CloudEvent", func() {
+		config.Format = brtypes.NotificationFormatCloudEvents
+		startNotifier().Notify(event)
+
+		var req receivedRequest
+		Eventually(received).Should(Receive(&req))
+		Expect(req.header.Get("Content-Type")).To(Equal("application/cloudevents+json"))
+		var got map[string]interface{}
+		Expect(json.Unmarshal(req.body, &got)).To(Succeed())
+		Expect(got).To(HaveKeyWithValue("specversion", "1.0"))
+		Expect(got).To(HaveKeyWithValue("type", "io.gardener.etcd-backup-restore.snapshot.succeeded"))
+		Expect(got).To(HaveKeyWithValue("source", "/etcd-backup-restore/default/etcd-main-0"))
+		Expect(got).To(HaveKey("id"))
+		Expect(got["data"]).To(HaveKeyWithValue("snapName", event.Data.SnapName))
+	})
+
+	It("should sign the request body", func() {
+		secretFile := filepath.Join(GinkgoT().TempDir(), "secret")
+		Expect(os.WriteFile(secretFile, []byte("s3cr3t\n"), 0600)).To(Succeed())
+		config.SigningSecretFile = secretFile
+		startNotifier().Notify(event)
+
+		var req receivedRequest
+		Eventually(received).Should(Receive(&req))
+		Expect(req.header.Get(notifier.SignatureHeader)).To(Equal(notifier.Sign([]byte("s3cr3t"), req.body)))
+	})
+
+	It("should retry failed deliveries", func() {
+		failures.Store(2)
+		startNotifier().Notify(event)
+
+		Eventually(received).Should(Receive())
+	})
+
+	It("should give up after the maximum number of retries", func() {
+		failures.Store(2)
+		config.MaxRetries = 1
+		w := startNotifier()
+		w.Notify(event)
+		Consistently(received, 200*time.Millisecond).ShouldNot(Receive())
+
+		// the next event is delivered again
+		w.Notify(event)
+		Eventually(received).Should(Receive())
+	})
+})
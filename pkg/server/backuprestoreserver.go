@@ -6,8 +6,10 @@ package server
 
 import (
 	"context"
+	errored "errors"
 	"fmt"
 	"net/http"
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -20,13 +22,17 @@ import (
 	"github.com/gardener/etcd-backup-restore/pkg/health/heartbeat"
 	"github.com/gardener/etcd-backup-restore/pkg/health/membergarbagecollector"
 	"github.com/gardener/etcd-backup-restore/pkg/initializer"
+	"github.com/gardener/etcd-backup-restore/pkg/initializer/validator"
 	"github.com/gardener/etcd-backup-restore/pkg/leaderelection"
 	"github.com/gardener/etcd-backup-restore/pkg/member"
 	"github.com/gardener/etcd-backup-restore/pkg/metrics"
 	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
 	"github.com/gardener/etcd-backup-restore/pkg/snapshot/copier"
 	"github.com/gardener/etcd-backup-restore/pkg/snapshot/snapshotter"
+	"github.com/gardener/etcd-backup-restore/pkg/snapshot/squasher"
 	"github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -42,9 +48,15 @@ type BackupRestoreServer struct {
 	logger                  *logrus.Entry
 	config                  *BackupRestoreComponentConfig
 	defragmentationSchedule cron.Schedule
+	squashSchedule          cron.Schedule
 	backoffConfig           *backoff.ExponentialBackoff
 }
 
+const (
+	// tracingShutdownTimeout is the timeout for flushing the pending spans on shutdown.
+	tracingShutdownTimeout = 5 * time.Second
+)
+
 var (
 	// runServerWithSnapshotter indicates whether to start server with or without snapshotter.
 	runServerWithSnapshotter = true
@@ -58,12 +70,19 @@ func NewBackupRestoreServer(logger *logrus.Logger, config *BackupRestoreComponen
 		// Ideally this case should not occur, since this check is done at the config validaitions.
 		return nil, err
 	}
+	var squashSchedule cron.Schedule
+	if config.SquasherConfig.Enabled() {
+		if squashSchedule, err = cron.ParseStandard(config.SquasherConfig.Schedule); err != nil {
+			return nil, err
+		}
+	}
 	exponentialBackoffConfig := backoff.NewExponentialBackOffConfig(config.ExponentialBackoffConfig.AttemptLimit, config.ExponentialBackoffConfig.Multiplier, config.ExponentialBackoffConfig.ThresholdTime.Duration)
 
 	return &BackupRestoreServer{
 		logger:                  serverLogger,
 		config:                  config,
 		defragmentationSchedule: defragmentationSchedule,
+		squashSchedule:          squashSchedule,
 		backoffConfig:           exponentialBackoffConfig,
 	}, nil
 }
@@ -111,9 +130,75 @@ func (b *BackupRestoreServer) Run(ctx context.Context) error {
 		b.logger.Warnf("No snapstore storage provider configured. Will not start backup schedule.")
 		runServerWithSnapshotter = false
 	}
+
+	if b.config.TracingConfig != nil && b.config.TracingConfig.Enabled() {
+		tp, err := tracing.NewTracerProvider(ctx, b.config.TracingConfig)
+		if err != nil {
+			return err
+		}
+		b.logger.Infof("Exporting traces to OTLP endpoint %s", b.config.TracingConfig.OTLPEndpoint)
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+			defer cancel()
+			if err := tp.Shutdown(shutdownCtx); err != nil {
+				b.logger.Errorf("Failed to flush traces: %v", err)
+			}
+		}()
+	}
+
+	if b.config.NotificationConfig != nil {
+		notifiers, err := b.startNotifiers(ctx)
+		if err != nil {
+			return err
+		}
+		if len(notifiers) > 0 {
+			notifier.SetNotifier(notifiers)
+			defer notifier.SetNotifier(nil)
+		}
+	}
 	return b.runServer(ctx, options)
 }
 
+// startNotifiers creates and starts the configured notifiers of backup lifecycle events.
+func (b *BackupRestoreServer) startNotifiers(ctx context.Context) (notifier.Multi, error) {
+	var notifiers notifier.Multi
+	podNamespace, podName := os.Getenv("POD_NAMESPACE"), os.Getenv("POD_NAME")
+	if b.config.NotificationConfig.Enabled() {
+		webhookNotifier, err := notifier.NewWebhookNotifier(b.config.NotificationConfig, notifier.NewSource(podNamespace, podName), b.logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create notifier: %v", err)
+		}
+		b.logger.Infof("Sending backup lifecycle notifications to %d webhook(s)", len(b.config.NotificationConfig.WebhookURLs))
+		go webhookNotifier.Run(ctx)
+		notifiers = append(notifiers, webhookNotifier)
+	}
+	if b.config.NotificationConfig.KubernetesEventsEnabled {
+		if eventNotifier := b.newKubernetesEventNotifier(ctx, podNamespace, podName); eventNotifier != nil {
+			b.logger.Infof("Recording Kubernetes events for pod %s/%s", podNamespace, podName)
+			go eventNotifier.Run(ctx)
+			notifiers = append(notifiers, eventNotifier)
+		}
+	}
+	return notifiers, nil
+}
+
+// newKubernetesEventNotifier creates the Kubernetes event notifier for the given etcd pod. Kubernetes events are
+// not essential to backup and restoration, so they are disabled with a warning if the notifier cannot be created,
+// e.g. because the service account lacks the permission to get the pod.
+func (b *BackupRestoreServer) newKubernetesEventNotifier(ctx context.Context, podNamespace, podName string) *notifier.KubernetesEventNotifier {
+	clientSet, err := miscellaneous.GetKubernetesClientSetOrError()
+	if err != nil {
+		b.logger.Warnf("Disabling Kubernetes events, failed to create clientset: %v", err)
+		return nil
+	}
+	eventNotifier, err := notifier.NewKubernetesEventNotifier(ctx, clientSet, podNamespace, podName, b.logger)
+	if err != nil {
+		b.logger.Warnf("Disabling Kubernetes events, failed to create Kubernetes event notifier: %v", err)
+		return nil
+	}
+	return eventNotifier
+}
+
 // startHTTPServer creates and starts the HTTP handler
 // with status 503 (Service Unavailable)
 func (b *BackupRestoreServer) startHTTPServer(initializer initializer.Initializer, storageProvider string, etcdConfig *brtypes.EtcdConnectionConfig, snapstoreConfig *brtypes.SnapstoreConfig, ssr *snapshotter.Snapshotter) *HTTPHandler {
@@ -135,6 +220,8 @@ func (b *BackupRestoreServer) startHTTPServer(initializer initializer.Initialize
 		EtcdConnectionConfig: etcdConfig,
 		StorageProvider:      storageProvider,
 		SnapstoreConfig:      snapstoreConfig,
+		events:               newEventBroadcaster(),
+		operations:           newOperationTracker(),
 	}
 	handler.SetStatus(http.StatusServiceUnavailable)
 	b.logger.Info("Registering the http request handlers...")
@@ -145,6 +232,21 @@ func (b *BackupRestoreServer) startHTTPServer(initializer initializer.Initialize
 	return handler
 }
 
+// startGRPCServer creates and starts the gRPC server backed by the given HTTP handler, if a gRPC port is configured.
+func (b *BackupRestoreServer) startGRPCServer(handler *HTTPHandler) (*GRPCServer, error) {
+	if b.config.ServerConfig.GRPCPort == 0 {
+		return nil, nil
+	}
+	grpcServer, err := NewGRPCServer(handler, b.config.ServerConfig.GRPCPort)
+	if err != nil {
+		return nil, err
+	}
+	b.logger.Info("Starting the gRPC server...")
+	go grpcServer.Start()
+
+	return grpcServer, nil
+}
+
 func waitUntilEtcdRunning(ctx context.Context, etcdConnectionConfig *brtypes.EtcdConnectionConfig, logger *logrus.Logger) error {
 	ticker := time.NewTicker(4 * time.Second)
 	defer ticker.Stop()
@@ -214,6 +316,14 @@ func (b *BackupRestoreServer) runServer(ctx context.Context, restoreOpts *brtype
 		}
 	}()
 
+	grpcServer, err := b.startGRPCServer(handler)
+	if err != nil {
+		return fmt.Errorf("failed to start gRPC server: %v", err)
+	}
+	if grpcServer != nil {
+		defer grpcServer.Stop()
+	}
+
 	metrics.CurrentClusterSize.With(prometheus.Labels{}).Set(float64(restoreOpts.OriginalClusterSize))
 
 	if err := waitUntilEtcdRunning(ctx, b.config.EtcdConnectionConfig, b.logger.Logger); err != nil {
@@ -234,6 +344,10 @@ func (b *BackupRestoreServer) runServer(ctx context.Context, restoreOpts *brtype
 				if err != nil {
 					b.logger.Fatalf("failed to create snapstore from configured storage provider: %v", err)
 				}
+				if fanOut, ok := ss.(*snapstore.FanOutSnapStore); ok {
+					b.logger.Infof("Starting periodic repair of fan-out snapstores...")
+					go fanOut.RepairPeriodically(leCtx, b.config.SnapstoreConfig.FanOut.RepairPeriod.Duration)
+				}
 
 				if b.config.SecondarySnapstoreConfig.BackupSyncEnabled {
 					b.logger.Infof("Starting periodic backup copier..")
@@ -263,6 +377,12 @@ func (b *BackupRestoreServer) runServer(ctx context.Context, restoreOpts *brtype
 				// set "http handler" with the latest snapshotter object
 				handler.SetSnapshotter(ssr)
 				go handleSsrStopRequest(leCtx, handler, ssr, ackCh, ssrStopCh, b.logger)
+
+				if b.squashSchedule != nil {
+					b.logger.Infof("Starting periodic delta snapshot squasher...")
+					sq := squasher.NewSquasher(ss, b.config.SquasherConfig, b.config.CompressionConfig, b.config.SnapshotterConfig.DeltaSnapshotFormat, b.logger)
+					go squasher.SquashPeriodically(leCtx, sq, b.squashSchedule)
+				}
 			}
 			go b.runEtcdProbeLoopWithSnapshotter(leCtx, handler, ssr, ss, ssrStopCh, ackCh)
 			go defragmentor.DefragDataPeriodically(leCtx, b.config.EtcdConnectionConfig, b.defragmentationSchedule, defragCallBack, b.logger)
@@ -305,12 +425,17 @@ func (b *BackupRestoreServer) runServer(ctx context.Context, restoreOpts *brtype
 		},
 	}
 
+	var learnerPromoter *member.LearnerPromoter
 	promoteCallback := &brtypes.PromoteLearnerCallback{
 		Promote: func(ctx context.Context, logger *logrus.Entry) {
 			if restoreOpts.OriginalClusterSize > 1 {
-				m := member.NewMemberControl(b.config.EtcdConnectionConfig)
-				if err := m.PromoteMember(ctx); err == nil {
+				if learnerPromoter == nil {
+					learnerPromoter = member.NewLearnerPromoter(member.NewMemberControl(b.config.EtcdConnectionConfig), b.config.LeaderElectionConfig.LearnerMaxApplyLag, b.config.LeaderElectionConfig.LearnerCatchUpTimeout.Duration)
+				}
+				if err := learnerPromoter.Promote(ctx, logger); err == nil {
 					logger.Info("Successfully promoted the learner to a voting member...")
+				} else if errored.Is(err, member.ErrLearnerNotCaughtUp) {
+					logger.Infof("Not promoting the learner to a voting member yet: %v", err)
 				} else {
 					logger.Errorf("unable to promote the learner to a voting member: %v", err)
 				}
@@ -326,6 +451,16 @@ func (b *BackupRestoreServer) runServer(ctx context.Context, restoreOpts *brtype
 		return err
 	}
 
+	if restoreOpts.OriginalClusterSize > 1 {
+		detector, err := b.newQuorumLossDetector(restoreOpts, handler.EnableTLS)
+		if err != nil {
+			b.logger.Warnf("Quorum loss of the etcd cluster will not be detected: %v", err)
+		} else {
+			handler.SetQuorumLossDetector(detector)
+			le.QuorumLossCallback = &brtypes.QuorumLossCallback{Observe: detector.Observe}
+		}
+	}
+
 	if runServerWithSnapshotter {
 		go handleAckState(handler, ackCh)
 	}
@@ -341,6 +476,85 @@ func (b *BackupRestoreServer) runServer(ctx context.Context, restoreOpts *brtype
 	return le.Run(ctx)
 }
 
+// newQuorumLossDetector returns the detector of a quorum loss of the multi-node etcd cluster. The latest revision of
+// the member is read from the etcd member, or from its data directory if etcd does not respond.
+func (b *BackupRestoreServer) newQuorumLossDetector(restoreOpts *brtypes.RestoreOptions, tlsEnabled bool) (*member.QuorumLossDetector, error) {
+	clientSet, err := miscellaneous.GetKubernetesClientSetOrError()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+	podName, err := miscellaneous.GetEnvVarOrError("POD_NAME")
+	if err != nil {
+		return nil, err
+	}
+	podNamespace, err := miscellaneous.GetEnvVarOrError("POD_NAMESPACE")
+	if err != nil {
+		return nil, err
+	}
+
+	detector := &member.QuorumLossDetector{
+		Client:          clientSet,
+		PodName:         podName,
+		PodNamespace:    podNamespace,
+		ClusterSize:     restoreOpts.OriginalClusterSize,
+		Threshold:       b.config.LeaderElectionConfig.QuorumLossThreshold.Duration,
+		RecoveryEnabled: b.config.LeaderElectionConfig.EnableQuorumLossRecovery,
+		LocalRevision: func(ctx context.Context) (int64, error) {
+			revision, err := b.getEtcdRevision(ctx)
+			if err != nil {
+				b.logger.Warnf("Unable to get the revision from etcd, reading it from the data directory: %v", err)
+				return validator.GetLatestEtcdRevision(restoreOpts.Config.DataDir)
+			}
+			return revision, nil
+		},
+	}
+	if runServerWithSnapshotter {
+		detector.SnapshotRevision = func(_ context.Context) (int64, error) {
+			store, err := snapstore.GetSnapstore(b.config.SnapstoreConfig)
+			if err != nil {
+				return 0, err
+			}
+			fullSnap, deltaSnaps, err := miscellaneous.GetLatestFullSnapshotAndDeltaSnapList(store)
+			if err != nil {
+				return 0, err
+			}
+			if len(deltaSnaps) > 0 {
+				return deltaSnaps[len(deltaSnaps)-1].LastRevision, nil
+			}
+			if fullSnap != nil {
+				return fullSnap.LastRevision, nil
+			}
+			return 0, nil
+		}
+	}
+	if b.config.UseEtcdWrapper {
+		detector.RestartEtcd = func(ctx context.Context) error {
+			return miscellaneous.RestartEtcdWrapper(ctx, tlsEnabled, b.config.EtcdConnectionConfig)
+		}
+	}
+	return detector, nil
+}
+
+// getEtcdRevision returns the latest revision of the etcd member, which is known even if the cluster has no leader.
+func (b *BackupRestoreServer) getEtcdRevision(ctx context.Context) (int64, error) {
+	if len(b.config.EtcdConnectionConfig.Endpoints) == 0 {
+		return 0, fmt.Errorf("etcd endpoints are not passed correctly")
+	}
+	clientMaintenance, err := etcdutil.NewFactory(*b.config.EtcdConnectionConfig).NewMaintenance()
+	if err != nil {
+		return 0, fmt.Errorf("failed to create etcd maintenance client: %w", err)
+	}
+	defer clientMaintenance.Close()
+
+	statusCtx, cancel := context.WithTimeout(ctx, b.config.LeaderElectionConfig.EtcdConnectionTimeout.Duration)
+	defer cancel()
+	response, err := clientMaintenance.Status(statusCtx, b.config.EtcdConnectionConfig.Endpoints[0])
+	if err != nil {
+		return 0, err
+	}
+	return response.Header.Revision, nil
+}
+
 func (b *BackupRestoreServer) updatePeerURLIfChanged(ctx context.Context, tlsEnabled bool, logger *logrus.Logger) error {
 	logger.Info("Checking if peerURL has changed or not.")
 
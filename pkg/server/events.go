@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"sync"
+	"time"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+)
+
+const (
+	// OperationFullSnapshot is the operation of taking an out-of-schedule full snapshot.
+	OperationFullSnapshot = "FullSnapshot"
+	// OperationDeltaSnapshot is the operation of taking an out-of-schedule delta snapshot.
+	OperationDeltaSnapshot = "DeltaSnapshot"
+	// OperationInitialization is the operation of validating and, if required, restoring the etcd data directory.
+	OperationInitialization = "Initialization"
+	// OperationDefragmentation is the operation of defragmenting the etcd cluster members.
+	OperationDefragmentation = "Defragmentation"
+
+	// PhaseStarted is the phase of an operation which has been started.
+	PhaseStarted = "Started"
+	// PhaseSucceeded is the phase of an operation which has completed successfully.
+	PhaseSucceeded = "Succeeded"
+	// PhaseFailed is the phase of an operation which has failed.
+	PhaseFailed = "Failed"
+
+	// eventSubscriberBufferSize is the number of events buffered for each subscriber before events are dropped.
+	eventSubscriberBufferSize = 64
+)
+
+// OperationEvent is an event emitted by the server for the operations triggered through its APIs.
+type OperationEvent struct {
+	Operation string            `json:"operation"`
+	Phase     string            `json:"phase"`
+	Message   string            `json:"message,omitempty"`
+	Snapshot  *brtypes.Snapshot `json:"snapshot,omitempty"`
+	Time      time.Time         `json:"time"`
+}
+
+// eventBroadcaster fans out operation events to all subscribers.
+// Publishing never blocks: events are dropped for subscribers which do not keep up.
+type eventBroadcaster struct {
+	mutex       sync.Mutex
+	subscribers map[chan OperationEvent]struct{}
+}
+
+func newEventBroadcaster() *eventBroadcaster {
+	return &eventBroadcaster{
+		subscribers: make(map[chan OperationEvent]struct{}),
+	}
+}
+
+// subscribe registers a new subscriber and returns its event channel along with a function to unsubscribe.
+func (b *eventBroadcaster) subscribe() (<-chan OperationEvent, func()) {
+	ch := make(chan OperationEvent, eventSubscriberBufferSize)
+	b.mutex.Lock()
+	b.subscribers[ch] = emptyStruct
+	b.mutex.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			delete(b.subscribers, ch)
+			b.mutex.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// publish sends the event to all subscribers.
+func (b *eventBroadcaster) publish(event OperationEvent) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
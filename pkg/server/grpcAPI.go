@@ -0,0 +1,343 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/gardener/etcd-backup-restore/pkg/api/v1alpha1"
+	"github.com/gardener/etcd-backup-restore/pkg/initializer/validator"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// forwardedMetadataKey marks calls which have been forwarded to the backup leader, so that they are never forwarded again.
+const forwardedMetadataKey = "x-etcdbr-forwarded"
+
+// GRPCServer serves the gRPC control-plane API. It shares the operations of the HTTPHandler,
+// so that both APIs behave the same way and emit the same operation events.
+type GRPCServer struct {
+	v1alpha1.UnimplementedBackupRestoreServer
+
+	handler *HTTPHandler
+	server  *grpc.Server
+	logger  *logrus.Entry
+	port    uint
+}
+
+// NewGRPCServer returns a new gRPC server listening on the given port, backed by the given HTTPHandler.
+func NewGRPCServer(handler *HTTPHandler, port uint) (*GRPCServer, error) {
+	var opts []grpc.ServerOption
+	if handler.EnableTLS {
+		creds, err := credentials.NewServerTLSFromFile(handler.ServerTLSCertFile, handler.ServerTLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS credentials for gRPC server: %v", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	s := &GRPCServer{
+		handler: handler,
+		server:  grpc.NewServer(opts...),
+		logger:  handler.Logger.WithField("actor", "grpc-server"),
+		port:    port,
+	}
+	v1alpha1.RegisterBackupRestoreServer(s.server, s)
+	return s, nil
+}
+
+// Start starts the gRPC server to listen for requests.
+func (s *GRPCServer) Start() {
+	addr := fmt.Sprintf(":%d", s.port)
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		s.logger.Fatalf("Failed to listen on %s for gRPC server: %v", addr, err)
+	}
+	s.logger.Infof("Starting gRPC server at addr: %s", addr)
+	if err := s.server.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+		s.logger.Fatalf("Failed to start gRPC server: %v", err)
+	}
+	s.logger.Info("gRPC server closed gracefully.")
+}
+
+// Stop stops the gRPC server.
+func (s *GRPCServer) Stop() {
+	s.server.Stop()
+}
+
+// TriggerFullSnapshot takes an out-of-schedule full snapshot.
+func (s *GRPCServer) TriggerFullSnapshot(ctx context.Context, req *v1alpha1.TriggerFullSnapshotRequest) (*v1alpha1.Snapshot, error) {
+	if s.handler.Snapshotter == nil {
+		if !s.canForward(ctx) {
+			return nil, status.Error(codes.FailedPrecondition, errSnapshotterNotConfigured.Error())
+		}
+		s.logger.Info("Forwarding the request to take out-of-schedule full snapshot to backup-restore leader")
+		var snap *v1alpha1.Snapshot
+		err := s.forwardToLeader(ctx, func(ctx context.Context, c v1alpha1.BackupRestoreClient) (err error) {
+			snap, err = c.TriggerFullSnapshot(ctx, req)
+			return
+		})
+		return snap, err
+	}
+	id := s.handler.startFullSnapshotOperation(idempotencyKey(ctx), req.GetFinal())
+	return s.waitForSnapshotOperation(ctx, id)
+}
+
+// TriggerDeltaSnapshot takes an out-of-schedule delta snapshot.
+func (s *GRPCServer) TriggerDeltaSnapshot(ctx context.Context, req *v1alpha1.TriggerDeltaSnapshotRequest) (*v1alpha1.Snapshot, error) {
+	if s.handler.Snapshotter == nil {
+		if !s.canForward(ctx) {
+			return nil, status.Error(codes.FailedPrecondition, errSnapshotterNotConfigured.Error())
+		}
+		s.logger.Info("Forwarding the request to take out-of-schedule delta snapshot to backup-restore leader")
+		var snap *v1alpha1.Snapshot
+		err := s.forwardToLeader(ctx, func(ctx context.Context, c v1alpha1.BackupRestoreClient) (err error) {
+			snap, err = c.TriggerDeltaSnapshot(ctx, req)
+			return
+		})
+		return snap, err
+	}
+	id := s.handler.startDeltaSnapshotOperation(idempotencyKey(ctx))
+	return s.waitForSnapshotOperation(ctx, id)
+}
+
+// waitForSnapshotOperation blocks until the snapshot operation with the given ID completes and returns its snapshot.
+// Like the blocking HTTP triggers, a cancelled call does not cancel the operation.
+func (s *GRPCServer) waitForSnapshotOperation(ctx context.Context, id string) (*v1alpha1.Snapshot, error) {
+	op, ok := s.handler.operations.wait(id, ctx.Done())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "operation %s not found", id)
+	}
+	switch op.State {
+	case OperationStateRunning:
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case OperationStateFailed:
+		return nil, status.Errorf(codes.Internal, "failed to take out-of-schedule %s: %s", op.Type, op.Error)
+	}
+	return toProtoSnapshot(op.Snapshot), nil
+}
+
+// idempotencyKey returns the idempotency key of the call, which is passed in the same-named metadata like the
+// Idempotency-Key header of the HTTP triggers.
+func idempotencyKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(idempotencyKeyHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ListSnapshots lists the snapshots present in the configured snapstore.
+func (s *GRPCServer) ListSnapshots(_ context.Context, req *v1alpha1.ListSnapshotsRequest) (*v1alpha1.ListSnapshotsResponse, error) {
+	if len(s.handler.StorageProvider) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "storage provider is not configured")
+	}
+	snapList, err := s.handler.listSnapshots(req.GetIncludeAll())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list snapshots: %v", err)
+	}
+	return &v1alpha1.ListSnapshotsResponse{Snapshots: toProtoSnapList(snapList)}, nil
+}
+
+// GetLatestSnapshots returns the latest full snapshot and the delta snapshots taken after it.
+func (s *GRPCServer) GetLatestSnapshots(_ context.Context, _ *v1alpha1.GetLatestSnapshotsRequest) (*v1alpha1.GetLatestSnapshotsResponse, error) {
+	if len(s.handler.StorageProvider) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "storage provider is not configured")
+	}
+	fullSnap, deltaSnaps, err := s.handler.getLatestSnapshots()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch latest snapshots: %v", err)
+	}
+	return &v1alpha1.GetLatestSnapshotsResponse{
+		FullSnapshot:   toProtoSnapshot(fullSnap),
+		DeltaSnapshots: toProtoSnapList(deltaSnaps),
+	}, nil
+}
+
+// GetStatus returns the status of the sidecar.
+func (s *GRPCServer) GetStatus(_ context.Context, _ *v1alpha1.GetStatusRequest) (*v1alpha1.Status, error) {
+	return &v1alpha1.Status{
+		Healthy:              s.handler.GetStatus() == http.StatusOK,
+		BackupLeader:         s.handler.Snapshotter != nil,
+		InitializationStatus: toProtoInitializationStatus(s.handler.getInitializationStatus()),
+	}, nil
+}
+
+// StartRestore starts the initialization of the etcd data directory, restoring it from the snapstore if required.
+func (s *GRPCServer) StartRestore(_ context.Context, req *v1alpha1.StartRestoreRequest) (*v1alpha1.RestoreStatus, error) {
+	mode := validator.Full
+	if req.GetMode() == v1alpha1.ValidationMode_VALIDATION_MODE_SANITY {
+		mode = validator.Sanity
+	}
+	s.logger.Infof("Received start initialization request with validation mode %s and failBelowRevision %d.", mode, req.GetFailBelowRevision())
+	s.handler.initialize(mode, req.GetFailBelowRevision())
+	return &v1alpha1.RestoreStatus{
+		Status: toProtoInitializationStatus(s.handler.getInitializationStatus()),
+	}, nil
+}
+
+// GetRestoreStatus returns the status of the initialization. Like the HTTP API, reading the result of a finished
+// initialization resets the status so that a new initialization can be started.
+func (s *GRPCServer) GetRestoreStatus(_ context.Context, _ *v1alpha1.GetRestoreStatusRequest) (*v1alpha1.RestoreStatus, error) {
+	return &v1alpha1.RestoreStatus{
+		Status: toProtoInitializationStatus(s.handler.consumeInitializationStatus()),
+	}, nil
+}
+
+// TriggerDefragmentation defragments all members of the etcd cluster.
+func (s *GRPCServer) TriggerDefragmentation(ctx context.Context, req *v1alpha1.TriggerDefragmentationRequest) (*v1alpha1.TriggerDefragmentationResponse, error) {
+	if s.handler.Snapshotter == nil && s.canForward(ctx) {
+		s.logger.Info("Forwarding the request to defragment etcd to backup-restore leader")
+		var resp *v1alpha1.TriggerDefragmentationResponse
+		err := s.forwardToLeader(ctx, func(ctx context.Context, c v1alpha1.BackupRestoreClient) (err error) {
+			resp, err = c.TriggerDefragmentation(ctx, req)
+			return
+		})
+		return resp, err
+	}
+	snap, err := s.handler.triggerDefragmentation(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to defragment etcd: %v", err)
+	}
+	return &v1alpha1.TriggerDefragmentationResponse{Snapshot: toProtoSnapshot(snap)}, nil
+}
+
+// WatchEvents streams the operation events of this sidecar until the client cancels the stream.
+func (s *GRPCServer) WatchEvents(_ *v1alpha1.WatchEventsRequest, stream grpc.ServerStreamingServer[v1alpha1.Event]) error {
+	if s.handler.events == nil {
+		return status.Error(codes.Unavailable, "operation events are not enabled")
+	}
+	events, unsubscribe := s.handler.events.subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-events:
+			if err := stream.Send(&v1alpha1.Event{
+				Operation: event.Operation,
+				Phase:     event.Phase,
+				Message:   event.Message,
+				Snapshot:  toProtoSnapshot(event.Snapshot),
+				Time:      timestamppb.New(event.Time),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// canForward returns true if the call may be forwarded to the backup leader.
+func (s *GRPCServer) canForward(ctx context.Context) bool {
+	if len(s.handler.StorageProvider) == 0 {
+		return false
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	return !ok || len(md.Get(forwardedMetadataKey)) == 0
+}
+
+// forwardToLeader invokes call against the gRPC server of the backup leader.
+func (s *GRPCServer) forwardToLeader(ctx context.Context, call func(context.Context, v1alpha1.BackupRestoreClient) error) error {
+	endPoint, err := s.handler.getBackupLeaderEndPoint(ctx, s.port)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "unable to get the backup leader endpoint: %v", err)
+	}
+	leaderURL, err := url.Parse(endPoint)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to parse backup leader endpoint: %v", err)
+	}
+
+	creds := insecure.NewCredentials()
+	if s.handler.EnableTLS {
+		caCertPool := x509.NewCertPool()
+		caCert, err := os.ReadFile(s.handler.EtcdConnectionConfig.CaFile)
+		if err != nil {
+			return status.Errorf(codes.Internal, "unable to read CA file: %v", err)
+		}
+		caCertPool.AppendCertsFromPEM(caCert)
+		creds = credentials.NewTLS(&tls.Config{ // #nosec G402 -- TLSClientConfig.MinVersion=1.2 by default.
+			RootCAs: caCertPool,
+		})
+	}
+
+	conn, err := grpc.NewClient(leaderURL.Host, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "unable to connect to backup leader: %v", err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			s.logger.Errorf("Error closing connection to backup leader: %v", err)
+		}
+	}()
+
+	return call(metadata.AppendToOutgoingContext(ctx, forwardedMetadataKey, "true"), v1alpha1.NewBackupRestoreClient(conn))
+}
+
+func toProtoSnapshot(snap *brtypes.Snapshot) *v1alpha1.Snapshot {
+	if snap == nil {
+		return nil
+	}
+	out := &v1alpha1.Snapshot{
+		Kind:              snap.Kind,
+		SnapDir:           snap.SnapDir,
+		SnapName:          snap.SnapName,
+		Prefix:            snap.Prefix,
+		CompressionSuffix: snap.CompressionSuffix,
+		StartRevision:     snap.StartRevision,
+		LastRevision:      snap.LastRevision,
+		CreatedOn:         timestamppb.New(snap.CreatedOn),
+		IsChunk:           snap.IsChunk,
+		IsFinal:           snap.IsFinal,
+	}
+	if !snap.ImmutabilityExpiryTime.IsZero() {
+		out.ImmutabilityExpiryTime = timestamppb.New(snap.ImmutabilityExpiryTime)
+	}
+	if snap.VersionID != nil {
+		out.VersionId = *snap.VersionID
+	}
+	return out
+}
+
+func toProtoSnapList(snapList brtypes.SnapList) []*v1alpha1.Snapshot {
+	out := make([]*v1alpha1.Snapshot, 0, len(snapList))
+	for _, snap := range snapList {
+		out = append(out, toProtoSnapshot(snap))
+	}
+	return out
+}
+
+func toProtoInitializationStatus(initializationStatus string) v1alpha1.InitializationStatus {
+	switch initializationStatus {
+	case initializationStatusNew:
+		return v1alpha1.InitializationStatus_INITIALIZATION_STATUS_NEW
+	case initializationStatusProgress:
+		return v1alpha1.InitializationStatus_INITIALIZATION_STATUS_PROGRESS
+	case initializationStatusSuccessful:
+		return v1alpha1.InitializationStatus_INITIALIZATION_STATUS_SUCCESSFUL
+	case initializationStatusFailed:
+		return v1alpha1.InitializationStatus_INITIALIZATION_STATUS_FAILED
+	default:
+		return v1alpha1.InitializationStatus_INITIALIZATION_STATUS_UNSPECIFIED
+	}
+}
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/api/v1alpha1"
+	"github.com/gardener/etcd-backup-restore/pkg/initializer/validator"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeInitializer struct {
+	mode validator.Mode
+	done chan struct{}
+}
+
+func (f *fakeInitializer) Initialize(mode validator.Mode, _ int64) error {
+	f.mode = mode
+	<-f.done
+	return nil
+}
+
+func newTestGRPCClient(t *testing.T, handler *HTTPHandler) v1alpha1.BackupRestoreClient {
+	s, err := NewGRPCServer(handler, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = s.server.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return v1alpha1.NewBackupRestoreClient(conn)
+}
+
+func TestGRPCInitialization(t *testing.T) {
+	init := &fakeInitializer{done: make(chan struct{})}
+	handler := &HTTPHandler{
+		Initializer:          init,
+		Logger:               logrus.NewEntry(logrus.New()),
+		initializationStatus: initializationStatusNew,
+		events:               newEventBroadcaster(),
+	}
+	client := newTestGRPCClient(t, handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchEvents(ctx, &v1alpha1.WatchEventsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// wait for the subscription to be registered before triggering the initialization
+	for {
+		handler.events.mutex.Lock()
+		n := len(handler.events.subscribers)
+		handler.events.mutex.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resp, err := client.StartRestore(ctx, &v1alpha1.StartRestoreRequest{Mode: v1alpha1.ValidationMode_VALIDATION_MODE_SANITY})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetStatus() != v1alpha1.InitializationStatus_INITIALIZATION_STATUS_PROGRESS {
+		t.Fatalf("unexpected initialization status: got %v want %v", resp.GetStatus(), v1alpha1.InitializationStatus_INITIALIZATION_STATUS_PROGRESS)
+	}
+	close(init.done)
+
+	for _, expectedPhase := range []string{PhaseStarted, PhaseSucceeded} {
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.GetOperation() != OperationInitialization || event.GetPhase() != expectedPhase {
+			t.Fatalf("unexpected event: got %s/%s want %s/%s", event.GetOperation(), event.GetPhase(), OperationInitialization, expectedPhase)
+		}
+	}
+	if init.mode != validator.Sanity {
+		t.Fatalf("unexpected validation mode: got %v want %v", init.mode, validator.Sanity)
+	}
+
+	restoreStatus, err := client.GetRestoreStatus(ctx, &v1alpha1.GetRestoreStatusRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restoreStatus.GetStatus() != v1alpha1.InitializationStatus_INITIALIZATION_STATUS_SUCCESSFUL {
+		t.Fatalf("unexpected initialization status: got %v want %v", restoreStatus.GetStatus(), v1alpha1.InitializationStatus_INITIALIZATION_STATUS_SUCCESSFUL)
+	}
+
+	st, err := client.GetStatus(ctx, &v1alpha1.GetStatusRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the handler reports itself unhealthy during initialization until the snapshotter is running
+	if st.GetHealthy() || st.GetBackupLeader() || st.GetInitializationStatus() != v1alpha1.InitializationStatus_INITIALIZATION_STATUS_NEW {
+		t.Fatalf("unexpected status: %v", st)
+	}
+}
+
+func TestGRPCSnapshotWithoutSnapshotter(t *testing.T) {
+	handler := &HTTPHandler{
+		Logger: logrus.NewEntry(logrus.New()),
+	}
+	client := newTestGRPCClient(t, handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := client.TriggerFullSnapshot(ctx, &v1alpha1.TriggerFullSnapshotRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("unexpected error: got %v want code %v", err, codes.FailedPrecondition)
+	}
+	if _, err := client.ListSnapshots(ctx, &v1alpha1.ListSnapshotsRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("unexpected error: got %v want code %v", err, codes.FailedPrecondition)
+	}
+}
@@ -21,8 +21,10 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/gardener/etcd-backup-restore/pkg/defragmentor"
 	"github.com/gardener/etcd-backup-restore/pkg/etcdutil"
 	etcdclient "github.com/gardener/etcd-backup-restore/pkg/etcdutil/client"
 	"github.com/gardener/etcd-backup-restore/pkg/initializer"
@@ -48,9 +50,18 @@ const (
 
 	// serverReadHeaderTimeout is the timeout for reading the request headers, to avoid slowloris attacks.
 	serverReadHeaderTimeout = 5 * time.Second
+
+	// idempotencyKeyHeader is the request header carrying the idempotency key of a snapshot trigger.
+	// Retried triggers with the same idempotency key return the existing operation instead of starting a new one.
+	idempotencyKeyHeader = "Idempotency-Key"
 )
 
-var emptyStruct struct{}
+var (
+	emptyStruct struct{}
+
+	// errSnapshotterNotConfigured is returned by the snapshot operations if the snapshotter is not configured.
+	errSnapshotterNotConfigured = errors.New("snapshotter is not configured")
+)
 
 // HandlerAckState denotes the state the handler would be in after sending a stop request to the snapshotter.
 type HandlerAckState int32
@@ -92,6 +103,9 @@ type HTTPHandler struct {
 	AckState                  uint32
 	EnableTLS                 bool
 	EnableProfiling           bool
+	events                    *eventBroadcaster
+	operations                *operationTracker
+	quorumLossDetector        *member.QuorumLossDetector
 }
 
 // healthCheck contains the HealthStatus of backup restore.
@@ -126,6 +140,13 @@ func (h *HTTPHandler) SetSnapshotter(ssr *snapshotter.Snapshotter) {
 	h.Snapshotter = ssr
 }
 
+// SetQuorumLossDetector sets the detector whose recommendation is served once the etcd cluster has lost its quorum.
+func (h *HTTPHandler) SetQuorumLossDetector(detector *member.QuorumLossDetector) {
+	h.HTTPHandlerMutex.Lock()
+	defer h.HTTPHandlerMutex.Unlock()
+	h.quorumLossDetector = detector
+}
+
 // RegisterHandler registers the handler for different requests
 func (h *HTTPHandler) RegisterHandler() {
 	mux := http.NewServeMux()
@@ -139,7 +160,9 @@ func (h *HTTPHandler) RegisterHandler() {
 	mux.HandleFunc("/snapshot/full", h.serveFullSnapshotTrigger)
 	mux.HandleFunc("/snapshot/delta", h.serveDeltaSnapshotTrigger)
 	mux.HandleFunc("/snapshot/latest", h.serveLatestSnapshotMetadata)
+	mux.HandleFunc("/operations/{id}", h.serveOperation)
 	mux.HandleFunc("/config", h.serveConfig)
+	mux.HandleFunc("/recovery/recommendation", h.serveRecoveryRecommendation)
 	mux.HandleFunc("/healthz", h.serveHealthz)
 	mux.Handle("/metrics", promhttp.Handler())
 
@@ -222,72 +245,108 @@ func (h *HTTPHandler) serveHealthz(rw http.ResponseWriter, _ *http.Request) {
 func (h *HTTPHandler) serveInitialize(rw http.ResponseWriter, req *http.Request) {
 	h.checkAndSetSecurityHeaders(rw)
 	h.Logger.Info("Received start initialization request.")
-	h.initializationStatusMutex.Lock()
-	defer h.initializationStatusMutex.Unlock()
-	if h.initializationStatus == initializationStatusNew {
-		h.Logger.Infof("Updating status from %s to %s", h.initializationStatus, initializationStatusProgress)
-		h.initializationStatus = initializationStatusProgress
-		go func() {
-			var mode validator.Mode
-
-			h.SetStatus(http.StatusServiceUnavailable)
-
-			failBelowRevisionStr := req.URL.Query().Get("failbelowrevision")
-			h.Logger.Infof("Validation failBelowRevision: %s", failBelowRevisionStr)
-			var failBelowRevision int64
-			if len(failBelowRevisionStr) != 0 {
-				var err error
-				failBelowRevision, err = strconv.ParseInt(failBelowRevisionStr, 10, 64)
-				if err != nil {
-					h.initializationStatusMutex.Lock()
-					defer h.initializationStatusMutex.Unlock()
-					h.Logger.Errorf("Failed initialization due wrong parameter value `failbelowrevision`: %v", err)
-					h.initializationStatus = initializationStatusFailed
-					return
-				}
-			}
-			switch modeVal := req.URL.Query().Get("mode"); modeVal {
-			case string(validator.Full):
-				mode = validator.Full
-			case string(validator.Sanity):
-				mode = validator.Sanity
-			default:
-				mode = validator.Full
-			}
-			h.Logger.Infof("Validation mode: %s", mode)
-			err := h.Initializer.Initialize(mode, failBelowRevision)
-			h.initializationStatusMutex.Lock()
-			defer h.initializationStatusMutex.Unlock()
-			if err != nil {
-				h.Logger.Errorf("Failed initialization: %v", err)
-				h.initializationStatus = initializationStatusFailed
-				return
-			}
-			h.Logger.Info("Successfully initialized data directory for etcd.")
-			h.initializationStatus = initializationStatusSuccessful
-		}()
+
+	failBelowRevisionStr := req.URL.Query().Get("failbelowrevision")
+	h.Logger.Infof("Validation failBelowRevision: %s", failBelowRevisionStr)
+	var failBelowRevision int64
+	if len(failBelowRevisionStr) != 0 {
+		var err error
+		failBelowRevision, err = strconv.ParseInt(failBelowRevisionStr, 10, 64)
+		if err != nil {
+			h.Logger.Errorf("Failed initialization due wrong parameter value `failbelowrevision`: %v", err)
+			h.failInitialization(err)
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
 	}
+
+	h.initialize(parseValidationMode(req.URL.Query().Get("mode")), failBelowRevision)
 	rw.WriteHeader(http.StatusOK)
 }
 
-// serveInitializationStatus serves the etcd initialization progress status
-func (h *HTTPHandler) serveInitializationStatus(rw http.ResponseWriter, _ *http.Request) {
-	h.checkAndSetSecurityHeaders(rw)
+// parseValidationMode returns the validation mode for the given value, defaulting to full validation.
+func parseValidationMode(modeVal string) validator.Mode {
+	switch modeVal {
+	case string(validator.Sanity):
+		return validator.Sanity
+	default:
+		return validator.Full
+	}
+}
+
+// initialize starts the initialization of the etcd data directory in the background,
+// unless an initialization is already in progress or its result has not been read yet.
+func (h *HTTPHandler) initialize(mode validator.Mode, failBelowRevision int64) {
 	h.initializationStatusMutex.Lock()
 	defer h.initializationStatusMutex.Unlock()
-	h.Logger.Infof("Responding to status request with: %s", h.initializationStatus)
+	if h.initializationStatus != initializationStatusNew {
+		return
+	}
 
-	rw.WriteHeader(http.StatusOK)
+	h.Logger.Infof("Updating status from %s to %s", h.initializationStatus, initializationStatusProgress)
+	h.initializationStatus = initializationStatusProgress
+	h.events.publish(OperationEvent{Operation: OperationInitialization, Phase: PhaseStarted})
+	go func() {
+		h.SetStatus(http.StatusServiceUnavailable)
 
-	if _, err := rw.Write([]byte(h.initializationStatus)); err != nil {
-		h.Logger.Errorf("Unable to write latest snapshot metadata response: %v", err)
+		h.Logger.Infof("Validation mode: %s", mode)
+		err := h.Initializer.Initialize(mode, failBelowRevision)
+		h.initializationStatusMutex.Lock()
+		defer h.initializationStatusMutex.Unlock()
+		if err != nil {
+			h.Logger.Errorf("Failed initialization: %v", err)
+			h.initializationStatus = initializationStatusFailed
+			h.events.publish(OperationEvent{Operation: OperationInitialization, Phase: PhaseFailed, Message: err.Error()})
+			return
+		}
+		h.Logger.Info("Successfully initialized data directory for etcd.")
+		h.initializationStatus = initializationStatusSuccessful
+		h.events.publish(OperationEvent{Operation: OperationInitialization, Phase: PhaseSucceeded})
+	}()
+}
+
+// failInitialization marks a new initialization as failed without starting it.
+func (h *HTTPHandler) failInitialization(err error) {
+	h.initializationStatusMutex.Lock()
+	defer h.initializationStatusMutex.Unlock()
+	if h.initializationStatus != initializationStatusNew {
 		return
 	}
+	h.initializationStatus = initializationStatusFailed
+	h.events.publish(OperationEvent{Operation: OperationInitialization, Phase: PhaseFailed, Message: err.Error()})
+}
 
-	if h.initializationStatus == initializationStatusSuccessful || h.initializationStatus == initializationStatusFailed {
-		h.Logger.Infof("Updating status from %s to %s", h.initializationStatus, initializationStatusNew)
+// consumeInitializationStatus returns the current initialization status. Once the result of a finished
+// initialization has been returned, the status is reset so that a new initialization can be started.
+func (h *HTTPHandler) consumeInitializationStatus() string {
+	h.initializationStatusMutex.Lock()
+	defer h.initializationStatusMutex.Unlock()
+	status := h.initializationStatus
+	if status == initializationStatusSuccessful || status == initializationStatusFailed {
+		h.Logger.Infof("Updating status from %s to %s", status, initializationStatusNew)
 		h.initializationStatus = initializationStatusNew
 	}
+	return status
+}
+
+// getInitializationStatus returns the current initialization status without resetting it.
+func (h *HTTPHandler) getInitializationStatus() string {
+	h.initializationStatusMutex.Lock()
+	defer h.initializationStatusMutex.Unlock()
+	return h.initializationStatus
+}
+
+// serveInitializationStatus serves the etcd initialization progress status
+func (h *HTTPHandler) serveInitializationStatus(rw http.ResponseWriter, _ *http.Request) {
+	h.checkAndSetSecurityHeaders(rw)
+	status := h.consumeInitializationStatus()
+	h.Logger.Infof("Responding to status request with: %s", status)
+
+	rw.WriteHeader(http.StatusOK)
+
+	if _, err := rw.Write([]byte(status)); err != nil {
+		h.Logger.Errorf("Unable to write latest snapshot metadata response: %v", err)
+	}
 }
 
 // serveFullSnapshotTrigger triggers an out-of-schedule full snapshot
@@ -314,22 +373,14 @@ func (h *HTTPHandler) serveFullSnapshotTrigger(rw http.ResponseWriter, req *http
 			return
 		}
 	}
-	s, err := h.Snapshotter.TriggerFullSnapshot(req.Context(), isFinal)
+	async, err := parseAsync(req)
 	if err != nil {
-		h.Logger.Warnf("Skipped triggering out-of-schedule full snapshot: %v", err)
-		rw.WriteHeader(http.StatusInternalServerError)
+		h.Logger.Warnf("Could not parse request parameter 'async' to bool: %v", err)
+		rw.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	out, err := json.Marshal(s)
-	if err != nil {
-		h.Logger.Warnf("Unable to marshal out-of-schedule full snapshot to json: %v", err)
-		rw.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	rw.WriteHeader(http.StatusOK)
-	if _, err = rw.Write(out); err != nil {
-		h.Logger.Errorf("Unable to write latest snapshot metadata response: %v", err)
-	}
+	id := h.startFullSnapshotOperation(req.Header.Get(idempotencyKeyHeader), isFinal)
+	h.serveSnapshotOperation(rw, req, id, async)
 }
 
 // serveDeltaSnapshotTrigger triggers an out-of-schedule delta snapshot
@@ -346,22 +397,104 @@ func (h *HTTPHandler) serveDeltaSnapshotTrigger(rw http.ResponseWriter, req *htt
 		rw.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
-	s, err := h.Snapshotter.TriggerDeltaSnapshot()
+	async, err := parseAsync(req)
 	if err != nil {
-		h.Logger.Warnf("Skipped triggering out-of-schedule delta snapshot: %v", err)
+		h.Logger.Warnf("Could not parse request parameter 'async' to bool: %v", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	id := h.startDeltaSnapshotOperation(req.Header.Get(idempotencyKeyHeader))
+	h.serveSnapshotOperation(rw, req, id, async)
+}
+
+// parseAsync returns the value of the 'async' request parameter, which defaults to false.
+func parseAsync(req *http.Request) (bool, error) {
+	asyncValue := req.URL.Query().Get("async")
+	if asyncValue == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(asyncValue)
+}
+
+// serveSnapshotOperation responds to a snapshot trigger. In async mode, the operation is returned
+// right away. Otherwise, the request is blocked until the operation completes and the snapshot is returned.
+func (h *HTTPHandler) serveSnapshotOperation(rw http.ResponseWriter, req *http.Request, id string, async bool) {
+	if async {
+		op, ok := h.operations.get(id)
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		rw.Header().Set("Location", "/operations/"+id)
+		h.writeJSON(rw, http.StatusAccepted, op)
+		return
+	}
+
+	op, ok := h.operations.wait(id, req.Context().Done())
+	if !ok {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch op.State {
+	case OperationStateRunning:
+		h.Logger.Warnf("Request for out-of-schedule %s was cancelled before the operation %s completed", op.Type, id)
+		return
+	case OperationStateFailed:
+		h.Logger.Warnf("Skipped triggering out-of-schedule %s: %s", op.Type, op.Error)
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	h.writeJSON(rw, http.StatusOK, op.Snapshot)
+}
 
-	out, err := json.Marshal(s)
+// serveOperation serves the status, progress and result of the operation with the given ID.
+func (h *HTTPHandler) serveOperation(rw http.ResponseWriter, req *http.Request) {
+	h.checkAndSetSecurityHeaders(rw)
+	if h.Snapshotter == nil && len(h.StorageProvider) > 0 {
+		h.Logger.Info("Fowarding the request of operation status to backup-restore leader")
+		h.delegateReqToLeader(rw, req)
+		return
+	}
+	op, ok := h.operations.get(req.PathValue("id"))
+	if !ok {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+	h.writeJSON(rw, http.StatusOK, op)
+}
+
+// serveRecoveryRecommendation serves the recovery recommended once the etcd cluster has lost its quorum.
+// It responds with 204 No Content as long as no quorum loss has been detected, and with 404 Not Found
+// if quorum losses are not detected, e.g. for a single-node cluster.
+func (h *HTTPHandler) serveRecoveryRecommendation(rw http.ResponseWriter, _ *http.Request) {
+	h.checkAndSetSecurityHeaders(rw)
+	h.HTTPHandlerMutex.Lock()
+	detector := h.quorumLossDetector
+	h.HTTPHandlerMutex.Unlock()
+	if detector == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+	recommendation := detector.Recommendation()
+	if recommendation == nil {
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.writeJSON(rw, http.StatusOK, recommendation)
+}
+
+// writeJSON writes the given value as JSON response with the given status code.
+func (h *HTTPHandler) writeJSON(rw http.ResponseWriter, statusCode int, v any) {
+	out, err := json.Marshal(v)
 	if err != nil {
-		h.Logger.Warnf("Unable to marshal out-of-schedule delta snapshot to json: %v", err)
+		h.Logger.Warnf("Unable to marshal response to json: %v", err)
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	rw.WriteHeader(http.StatusOK)
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(statusCode)
 	if _, err = rw.Write(out); err != nil {
-		h.Logger.Errorf("Unable to write latest snapshot metadata response: %v", err)
+		h.Logger.Errorf("Unable to write response: %v", err)
 	}
 }
 
@@ -378,14 +511,7 @@ func (h *HTTPHandler) serveLatestSnapshotMetadata(rw http.ResponseWriter, req *h
 		return
 	}
 
-	store, err := snapstore.GetSnapstore(h.SnapstoreConfig)
-	if err != nil {
-		h.Logger.Warnf("Unable to create snapstore from configured storage provider: %v", err)
-		rw.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	fullSnap, deltaSnaps, err := miscellaneous.GetLatestFullSnapshotAndDeltaSnapList(store)
+	fullSnap, deltaSnaps, err := h.getLatestSnapshots()
 	if err != nil {
 		h.Logger.Warnf("Unable to fetch latest snapshots from snapstore: %v", err)
 		rw.WriteHeader(http.StatusInternalServerError)
@@ -409,6 +535,95 @@ func (h *HTTPHandler) serveLatestSnapshotMetadata(rw http.ResponseWriter, req *h
 	}
 }
 
+// startFullSnapshotOperation starts an operation taking an out-of-schedule full snapshot, unless one has already been
+// started with the given idempotency key, and returns its ID. The operation is not bound to the triggering request.
+func (h *HTTPHandler) startFullSnapshotOperation(idempotencyKey string, isFinal bool) string {
+	uploadedBytes := &atomic.Int64{}
+	id, _ := h.operations.start(OperationFullSnapshot, idempotencyKey, uploadedBytes.Load, func() (*brtypes.Snapshot, error) {
+		return h.triggerFullSnapshot(context.Background(), isFinal, uploadedBytes)
+	})
+	return id
+}
+
+// startDeltaSnapshotOperation starts an operation taking an out-of-schedule delta snapshot, unless one has already been
+// started with the given idempotency key, and returns its ID.
+func (h *HTTPHandler) startDeltaSnapshotOperation(idempotencyKey string) string {
+	uploadedBytes := &atomic.Int64{}
+	id, _ := h.operations.start(OperationDeltaSnapshot, idempotencyKey, uploadedBytes.Load, func() (*brtypes.Snapshot, error) {
+		return h.triggerDeltaSnapshot(uploadedBytes)
+	})
+	return id
+}
+
+// triggerFullSnapshot takes an out-of-schedule full snapshot using the configured Snapshotter,
+// counting the bytes uploaded into uploadedBytes.
+func (h *HTTPHandler) triggerFullSnapshot(ctx context.Context, isFinal bool, uploadedBytes *atomic.Int64) (*brtypes.Snapshot, error) {
+	ssr := h.Snapshotter
+	if ssr == nil {
+		return nil, errSnapshotterNotConfigured
+	}
+	h.events.publish(OperationEvent{Operation: OperationFullSnapshot, Phase: PhaseStarted})
+	s, err := ssr.TriggerFullSnapshotWithProgress(ctx, isFinal, uploadedBytes)
+	if err != nil {
+		h.events.publish(OperationEvent{Operation: OperationFullSnapshot, Phase: PhaseFailed, Message: err.Error()})
+		return nil, err
+	}
+	h.events.publish(OperationEvent{Operation: OperationFullSnapshot, Phase: PhaseSucceeded, Snapshot: s})
+	return s, nil
+}
+
+// triggerDeltaSnapshot takes an out-of-schedule delta snapshot using the configured Snapshotter,
+// counting the bytes uploaded into uploadedBytes.
+func (h *HTTPHandler) triggerDeltaSnapshot(uploadedBytes *atomic.Int64) (*brtypes.Snapshot, error) {
+	ssr := h.Snapshotter
+	if ssr == nil {
+		return nil, errSnapshotterNotConfigured
+	}
+	h.events.publish(OperationEvent{Operation: OperationDeltaSnapshot, Phase: PhaseStarted})
+	s, err := ssr.TriggerDeltaSnapshotWithProgress(uploadedBytes)
+	if err != nil {
+		h.events.publish(OperationEvent{Operation: OperationDeltaSnapshot, Phase: PhaseFailed, Message: err.Error()})
+		return nil, err
+	}
+	h.events.publish(OperationEvent{Operation: OperationDeltaSnapshot, Phase: PhaseSucceeded, Snapshot: s})
+	return s, nil
+}
+
+// getLatestSnapshots returns the latest full snapshot and the delta snapshots taken after it from the configured snapstore.
+func (h *HTTPHandler) getLatestSnapshots() (*brtypes.Snapshot, brtypes.SnapList, error) {
+	store, err := snapstore.GetSnapstore(h.SnapstoreConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create snapstore from configured storage provider: %v", err)
+	}
+	return miscellaneous.GetLatestFullSnapshotAndDeltaSnapList(store)
+}
+
+// listSnapshots returns all snapshots present in the configured snapstore.
+func (h *HTTPHandler) listSnapshots(includeAll bool) (brtypes.SnapList, error) {
+	store, err := snapstore.GetSnapstore(h.SnapstoreConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create snapstore from configured storage provider: %v", err)
+	}
+	return store.List(includeAll)
+}
+
+// triggerDefragmentation defragments all members of the etcd cluster. If the snapshotter is configured,
+// a full snapshot is taken after the defragmentation and returned.
+func (h *HTTPHandler) triggerDefragmentation(ctx context.Context) (*brtypes.Snapshot, error) {
+	var callback defragmentor.CallbackFunc
+	if ssr := h.Snapshotter; ssr != nil {
+		callback = ssr.TriggerFullSnapshot
+	}
+	h.events.publish(OperationEvent{Operation: OperationDefragmentation, Phase: PhaseStarted})
+	s, err := defragmentor.Defragment(ctx, h.EtcdConnectionConfig, callback, h.Logger)
+	if err != nil {
+		h.events.publish(OperationEvent{Operation: OperationDefragmentation, Phase: PhaseFailed, Message: err.Error()})
+		return nil, err
+	}
+	h.events.publish(OperationEvent{Operation: OperationDefragmentation, Phase: PhaseSucceeded, Snapshot: s})
+	return s, nil
+}
+
 func (h *HTTPHandler) serveConfig(rw http.ResponseWriter, req *http.Request) {
 	inputFileName := miscellaneous.EtcdConfigFilePath
 	dir, err := os.UserHomeDir()
@@ -591,14 +806,51 @@ func (h *HTTPHandler) delegateReqToLeader(rw http.ResponseWriter, req *http.Requ
 	// Get the BackupLeader URL
 	// Get the ReverseProxy object
 	// Delegate the http request to BackupLeader using the reverse proxy.
+	backupLeaderEndPoint, err := h.getBackupLeaderEndPoint(req.Context(), h.Port)
+	if err != nil {
+		h.Logger.Warnf("Unable to get the backup leader endpoint: %v", err)
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
 
-	factory := etcdutil.NewFactory(*h.EtcdConnectionConfig)
-	clientMaintenance, err := factory.NewMaintenance()
+	backupLeaderURL, err := url.Parse(backupLeaderEndPoint)
 	if err != nil {
-		h.Logger.Warnf("failed to create etcd maintenance client:  %v", err)
+		h.Logger.Warnf("Unable to parse backup leader endpoint: %v", err)
 		rw.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
+
+	h.Logger.Infof("backup-restore leader with url [%v] is healthy", backupLeaderURL)
+
+	// create the reverse Proxy
+	revProxyHandler := httputil.NewSingleHostReverseProxy(backupLeaderURL)
+
+	if h.EnableTLS {
+		caCertPool := x509.NewCertPool()
+
+		caCert, err := os.ReadFile(h.EtcdConnectionConfig.CaFile)
+		if err != nil {
+			return
+		}
+		caCertPool.AppendCertsFromPEM(caCert)
+		revProxyHandler.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{ // #nosec G402 -- TLSClientConfig.MinVersion=1.2 by default.
+				RootCAs: caCertPool,
+			},
+		}
+	}
+
+	revProxyHandler.ServeHTTP(rw, req)
+}
+
+// getBackupLeaderEndPoint returns the endpoint on the given port of the backup-restore sidecar of the etcd leader,
+// provided that the backup-restore leader is healthy.
+func (h *HTTPHandler) getBackupLeaderEndPoint(ctx context.Context, port uint) (string, error) {
+	factory := etcdutil.NewFactory(*h.EtcdConnectionConfig)
+	clientMaintenance, err := factory.NewMaintenance()
+	if err != nil {
+		return "", fmt.Errorf("failed to create etcd maintenance client: %v", err)
+	}
 	defer func() {
 		if err := clientMaintenance.Close(); err != nil {
 			h.Logger.Errorf("Error closing etcd maintenance client: %v", err)
@@ -607,9 +859,7 @@ func (h *HTTPHandler) delegateReqToLeader(rw http.ResponseWriter, req *http.Requ
 
 	cl, err := factory.NewCluster()
 	if err != nil {
-		h.Logger.Warnf("failed to create etcd cluster client:  %v", err)
-		rw.WriteHeader(http.StatusMethodNotAllowed)
-		return
+		return "", fmt.Errorf("failed to create etcd cluster client: %v", err)
 	}
 	defer func() {
 		if err := cl.Close(); err != nil {
@@ -617,71 +867,34 @@ func (h *HTTPHandler) delegateReqToLeader(rw http.ResponseWriter, req *http.Requ
 		}
 	}()
 
-	ctx, cancel := context.WithTimeout(req.Context(), h.EtcdConnectionConfig.ConnectionTimeout.Duration)
+	ctx, cancel := context.WithTimeout(ctx, h.EtcdConnectionConfig.ConnectionTimeout.Duration)
 	defer cancel()
 
 	if len(h.EtcdConnectionConfig.Endpoints) == 0 {
-		h.Logger.Warnf("etcd endpoints are not passed correctly")
-		rw.WriteHeader(http.StatusMethodNotAllowed)
-		return
+		return "", fmt.Errorf("etcd endpoints are not passed correctly")
 	}
 
 	_, etcdLeaderEndPoint, err := miscellaneous.GetLeader(ctx, clientMaintenance, cl, h.EtcdConnectionConfig.Endpoints[0])
 	if err != nil {
-		h.Logger.Warnf("Unable to get the etcd leader endpoint: %v", err)
-		rw.WriteHeader(http.StatusMethodNotAllowed)
-		return
+		return "", fmt.Errorf("unable to get the etcd leader endpoint: %v", err)
 	}
 
-	backupLeaderEndPoint, err := miscellaneous.GetBackupLeaderEndPoint(etcdLeaderEndPoint, h.Port)
+	backupLeaderHTTPEndPoint, err := miscellaneous.GetBackupLeaderEndPoint(etcdLeaderEndPoint, h.Port)
 	if err != nil {
-		h.Logger.Warnf("Unable to get the backup leader endpoint: %v", err)
-		rw.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
-	backupLeaderURL, err := url.Parse(backupLeaderEndPoint)
-	if err != nil {
-		h.Logger.Warnf("Unable to parse backup leader endpoint: %v", err)
-		rw.WriteHeader(http.StatusMethodNotAllowed)
-		return
+		return "", err
 	}
 
-	isHealthy, err := IsBackupRestoreHealthy(backupLeaderEndPoint+"/healthz", h.EnableTLS, h.EtcdConnectionConfig.CaFile)
+	isHealthy, err := IsBackupRestoreHealthy(backupLeaderHTTPEndPoint+"/healthz", h.EnableTLS, h.EtcdConnectionConfig.CaFile)
 	if err != nil {
-		h.Logger.Warnf("Unable to check backup leader health: %v", err)
-		rw.WriteHeader(http.StatusMethodNotAllowed)
-		return
+		return "", fmt.Errorf("unable to check backup leader health: %v", err)
 	}
 
 	// when backup-leader is not in a healthy state.
 	if !isHealthy {
-		h.Logger.Warnf("backup leader is not healthy: %v", err)
-		rw.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
-	h.Logger.Infof("backup-restore leader with url [%v] is healthy", backupLeaderURL)
-
-	// create the reverse Proxy
-	revProxyHandler := httputil.NewSingleHostReverseProxy(backupLeaderURL)
-
-	if h.EnableTLS {
-		caCertPool := x509.NewCertPool()
-
-		caCert, err := os.ReadFile(h.EtcdConnectionConfig.CaFile)
-		if err != nil {
-			return
-		}
-		caCertPool.AppendCertsFromPEM(caCert)
-		revProxyHandler.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{ // #nosec G402 -- TLSClientConfig.MinVersion=1.2 by default.
-				RootCAs: caCertPool,
-			},
-		}
+		return "", fmt.Errorf("backup leader is not healthy")
 	}
 
-	revProxyHandler.ServeHTTP(rw, req)
+	return miscellaneous.GetBackupLeaderEndPoint(etcdLeaderEndPoint, port)
 }
 
 // IsBackupRestoreHealthy checks whether the backup-restore of given backup-restore URL is healthy or not.
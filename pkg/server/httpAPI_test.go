@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+
+	"github.com/gardener/etcd-backup-restore/pkg/member"
 )
 
 func TestHealthCheckHandler(t *testing.T) {
@@ -54,3 +57,19 @@ func healthCheckTest(handlerFunc http.HandlerFunc, expectedStatus int, expectedH
 	}
 	return nil
 }
+
+func TestRecoveryRecommendationHandler(t *testing.T) {
+	handler := HTTPHandler{HTTPHandlerMutex: &sync.Mutex{}}
+	rr := httptest.NewRecorder()
+	handler.serveRecoveryRecommendation(rr, httptest.NewRequest(http.MethodGet, "/recovery/recommendation", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code without quorum loss detector: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+
+	handler.SetQuorumLossDetector(&member.QuorumLossDetector{})
+	rr = httptest.NewRecorder()
+	handler.serveRecoveryRecommendation(rr, httptest.NewRequest(http.MethodGet, "/recovery/recommendation", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("handler returned wrong status code without quorum loss: got %v want %v", rr.Code, http.StatusNoContent)
+	}
+}
@@ -31,6 +31,9 @@ func NewBackupRestoreComponentConfig() *BackupRestoreComponentConfig {
 		HealthConfig:             brtypes.NewHealthConfig(),
 		LeaderElectionConfig:     brtypes.NewLeaderElectionConfig(),
 		ExponentialBackoffConfig: brtypes.NewExponentialBackOffConfig(),
+		NotificationConfig:       brtypes.NewNotificationConfig(),
+		TracingConfig:            brtypes.NewTracingConfig(),
+		SquasherConfig:           brtypes.NewSquasherConfig(),
 		UseEtcdWrapper:           usageOfEtcdWrapperEnabled,
 	}
 }
@@ -47,6 +50,9 @@ func (c *BackupRestoreComponentConfig) AddFlags(fs *flag.FlagSet) {
 	c.LeaderElectionConfig.AddFlags(fs)
 	c.ExponentialBackoffConfig.AddFlags(fs)
 	c.SecondarySnapstoreConfig.AddFlags(fs)
+	c.NotificationConfig.AddFlags(fs)
+	c.TracingConfig.AddFlags(fs)
+	c.SquasherConfig.AddFlags(fs)
 	// Miscellaneous
 	fs.StringVar(&c.DefragmentationSchedule, "defragmentation-schedule", c.DefragmentationSchedule, "schedule to defragment etcd data directory")
 	fs.BoolVar(&c.UseEtcdWrapper, "use-etcd-wrapper", c.UseEtcdWrapper, "to enable backup-restore to use etcd-wrapper related functionality. Note: enable this flag only if etcd-wrapper is deployed.")
@@ -88,6 +94,15 @@ func (c *BackupRestoreComponentConfig) Validate() error {
 	if err := c.SecondarySnapstoreConfig.Validate(); err != nil {
 		return err
 	}
+	if err := c.NotificationConfig.Validate(); err != nil {
+		return err
+	}
+	if err := c.TracingConfig.Validate(); err != nil {
+		return err
+	}
+	if err := c.SquasherConfig.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -102,6 +117,7 @@ type HTTPServerConfig struct {
 	TLSCertFile     string `json:"server-cert,omitempty"`
 	TLSKeyFile      string `json:"server-key,omitempty"`
 	Port            uint   `json:"port,omitempty"`
+	GRPCPort        uint   `json:"grpcPort,omitempty"`
 	EnableProfiling bool   `json:"enableProfiling,omitempty"`
 }
 
@@ -116,6 +132,7 @@ func NewHTTPServerConfig() *HTTPServerConfig {
 // AddFlags adds the flags to flagset.
 func (c *HTTPServerConfig) AddFlags(fs *flag.FlagSet) {
 	fs.UintVarP(&c.Port, "server-port", "p", c.Port, "port on which server should listen")
+	fs.UintVar(&c.GRPCPort, "grpc-server-port", c.GRPCPort, "port on which the gRPC server should listen, 0 disables the gRPC server")
 	fs.BoolVar(&c.EnableProfiling, "enable-profiling", c.EnableProfiling, "enable profiling")
 	fs.StringVar(&c.TLSCertFile, "server-cert", "", "TLS certificate file for backup-restore server")
 	fs.StringVar(&c.TLSKeyFile, "server-key", "", "TLS key file for backup-restore server")
@@ -123,6 +140,9 @@ func (c *HTTPServerConfig) AddFlags(fs *flag.FlagSet) {
 
 // Validate validates the config.E
 func (c *HTTPServerConfig) Validate() error {
+	if c.GRPCPort != 0 && c.GRPCPort == c.Port {
+		return fmt.Errorf("gRPC server port must be different from the HTTP server port %d", c.Port)
+	}
 	enableTLS := c.TLSCertFile != "" && c.TLSKeyFile != ""
 	if enableTLS {
 		// Check for existence of server cert and key files before proceeding
@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"sync"
+	"time"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// OperationStateRunning is the state of an operation which has not completed yet.
+	OperationStateRunning = "Running"
+	// OperationStateSucceeded is the state of an operation which has completed successfully.
+	OperationStateSucceeded = "Succeeded"
+	// OperationStateFailed is the state of an operation which has failed.
+	OperationStateFailed = "Failed"
+
+	// operationRetentionPeriod is the duration for which completed operations are kept.
+	operationRetentionPeriod = time.Hour
+)
+
+// Operation is an operation, such as an out-of-schedule snapshot, tracked by the server.
+type Operation struct {
+	StartedAt      time.Time         `json:"startedAt"`
+	CompletedAt    *time.Time        `json:"completedAt,omitempty"`
+	Snapshot       *brtypes.Snapshot `json:"snapshot,omitempty"`
+	ID             string            `json:"id"`
+	Type           string            `json:"type"`
+	State          string            `json:"state"`
+	IdempotencyKey string            `json:"idempotencyKey,omitempty"`
+	Error          string            `json:"error,omitempty"`
+	BytesUploaded  int64             `json:"bytesUploaded"`
+}
+
+// trackedOperation holds an operation along with the state required to track its progress.
+type trackedOperation struct {
+	op       Operation
+	progress func() int64
+	done     chan struct{}
+}
+
+// operationTracker keeps track of operations and their idempotency keys.
+// Operations are kept in memory and are lost when the process restarts.
+type operationTracker struct {
+	mutex           sync.Mutex
+	operations      map[string]*trackedOperation
+	idempotencyKeys map[string]string
+}
+
+func newOperationTracker() *operationTracker {
+	return &operationTracker{
+		operations:      make(map[string]*trackedOperation),
+		idempotencyKeys: make(map[string]string),
+	}
+}
+
+// start runs fn in the background as a new operation of the given type and returns the operation ID.
+// If an operation of the same type has already been started with the given idempotency key, the ID of the
+// existing operation is returned instead and fn is not run. The progress function, if set, is used to report
+// the number of bytes uploaded while the operation is running.
+func (t *operationTracker) start(opType, idempotencyKey string, progress func() int64, fn func() (*brtypes.Snapshot, error)) (string, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.prune()
+
+	key := opType + "/" + idempotencyKey
+	if len(idempotencyKey) > 0 {
+		if id, ok := t.idempotencyKeys[key]; ok {
+			return id, false
+		}
+	}
+
+	tracked := &trackedOperation{
+		op: Operation{
+			ID:             uuid.NewString(),
+			Type:           opType,
+			State:          OperationStateRunning,
+			IdempotencyKey: idempotencyKey,
+			StartedAt:      time.Now(),
+		},
+		progress: progress,
+		done:     make(chan struct{}),
+	}
+	t.operations[tracked.op.ID] = tracked
+	if len(idempotencyKey) > 0 {
+		t.idempotencyKeys[key] = tracked.op.ID
+	}
+
+	go func() {
+		snap, err := fn()
+		t.complete(tracked, snap, err)
+	}()
+	return tracked.op.ID, true
+}
+
+func (t *operationTracker) complete(tracked *trackedOperation, snap *brtypes.Snapshot, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	tracked.op.CompletedAt = &now
+	tracked.op.Snapshot = snap
+	if tracked.progress != nil {
+		tracked.op.BytesUploaded = tracked.progress()
+		tracked.progress = nil
+	}
+	if err != nil {
+		tracked.op.State = OperationStateFailed
+		tracked.op.Error = err.Error()
+	} else {
+		tracked.op.State = OperationStateSucceeded
+	}
+	close(tracked.done)
+}
+
+// get returns a copy of the operation with the given ID.
+func (t *operationTracker) get(id string) (Operation, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	tracked, ok := t.operations[id]
+	if !ok {
+		return Operation{}, false
+	}
+	op := tracked.op
+	if tracked.progress != nil {
+		op.BytesUploaded = tracked.progress()
+	}
+	return op, true
+}
+
+// wait blocks until the operation with the given ID has completed or the stop channel is closed,
+// and returns the operation.
+func (t *operationTracker) wait(id string, stopCh <-chan struct{}) (Operation, bool) {
+	t.mutex.Lock()
+	tracked, ok := t.operations[id]
+	t.mutex.Unlock()
+	if !ok {
+		return Operation{}, false
+	}
+
+	select {
+	case <-tracked.done:
+	case <-stopCh:
+	}
+	return t.get(id)
+}
+
+// prune removes the operations which have completed more than operationRetentionPeriod ago.
+// It must be called with the mutex held.
+func (t *operationTracker) prune() {
+	for id, tracked := range t.operations {
+		if tracked.op.CompletedAt == nil || time.Since(*tracked.op.CompletedAt) < operationRetentionPeriod {
+			continue
+		}
+		delete(t.operations, id)
+		if len(tracked.op.IdempotencyKey) > 0 {
+			delete(t.idempotencyKeys, tracked.op.Type+"/"+tracked.op.IdempotencyKey)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestOperationTrackerIdempotency(t *testing.T) {
+	tracker := newOperationTracker()
+	release := make(chan struct{})
+	calls := 0
+	fn := func() (*brtypes.Snapshot, error) {
+		calls++
+		<-release
+		return &brtypes.Snapshot{SnapName: "snap"}, nil
+	}
+	progress := func() int64 { return 42 }
+
+	id, started := tracker.start(OperationFullSnapshot, "key", progress, fn)
+	if !started {
+		t.Fatal("expected a new operation to be started")
+	}
+	retryID, started := tracker.start(OperationFullSnapshot, "key", progress, fn)
+	if started || retryID != id {
+		t.Fatalf("expected retried trigger to return existing operation %s, got %s (started: %v)", id, retryID, started)
+	}
+	if otherID, started := tracker.start(OperationDeltaSnapshot, "key", nil, func() (*brtypes.Snapshot, error) { return nil, nil }); !started || otherID == id {
+		t.Fatal("expected idempotency keys to be scoped by operation type")
+	}
+
+	op, ok := tracker.get(id)
+	if !ok {
+		t.Fatalf("operation %s not found", id)
+	}
+	if op.State != OperationStateRunning || op.BytesUploaded != 42 {
+		t.Fatalf("unexpected running operation: %+v", op)
+	}
+
+	close(release)
+	op, ok = tracker.wait(id, nil)
+	if !ok {
+		t.Fatalf("operation %s not found", id)
+	}
+	if op.State != OperationStateSucceeded || op.Snapshot == nil || op.Snapshot.SnapName != "snap" || op.CompletedAt == nil || op.BytesUploaded != 42 {
+		t.Fatalf("unexpected completed operation: %+v", op)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the operation to run once, ran %d times", calls)
+	}
+}
+
+func TestServeOperation(t *testing.T) {
+	handler := &HTTPHandler{
+		Logger:     logrus.NewEntry(logrus.New()),
+		operations: newOperationTracker(),
+	}
+	id, _ := handler.operations.start(OperationDeltaSnapshot, "", nil, func() (*brtypes.Snapshot, error) { return nil, nil })
+	handler.operations.wait(id, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/operations/{id}", handler.serveOperation)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/operations/"+id, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var op Operation
+	if err := json.Unmarshal(rr.Body.Bytes(), &op); err != nil {
+		t.Fatal(err)
+	}
+	if op.ID != id || op.Type != OperationDeltaSnapshot || op.State != OperationStateSucceeded {
+		t.Fatalf("handler returned unexpected operation: %+v", op)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/operations/unknown", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
@@ -28,6 +28,9 @@ type BackupRestoreComponentConfig struct {
 	HealthConfig             *brtypes.HealthConfig             `json:"healthConfig,omitempty"`
 	LeaderElectionConfig     *brtypes.Config                   `json:"leaderElectionConfig,omitempty"`
 	ExponentialBackoffConfig *brtypes.ExponentialBackoffConfig `json:"exponentialBackoffConfig,omitempty"`
+	NotificationConfig       *brtypes.NotificationConfig       `json:"notificationConfig,omitempty"`
+	TracingConfig            *brtypes.TracingConfig            `json:"tracingConfig,omitempty"`
+	SquasherConfig           *brtypes.SquasherConfig           `json:"squasherConfig,omitempty"`
 	DefragmentationSchedule  string                            `json:"defragmentationSchedule"`
 	UseEtcdWrapper           bool                              `json:"useEtcdWrapper,omitempty"`
 }
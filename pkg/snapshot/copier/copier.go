@@ -6,13 +6,16 @@ package copier
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
 	"github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
 	"github.com/sirupsen/logrus"
@@ -215,7 +218,16 @@ func (c *Copier) getSnapshots() (brtypes.SnapList, error) {
 	return miscellaneous.GetFilteredBackups(c.sourceSnapStore, c.maxBackups, nil)
 }
 
-func (c *Copier) copySnapshot(snapshot *brtypes.Snapshot) error {
+func (c *Copier) copySnapshot(snapshot *brtypes.Snapshot) (err error) {
+	_, span := tracing.Start(context.TODO(), "copier.copySnapshot", tracing.SnapshotAttributes(snapshot)...)
+	defer func() { tracing.End(span, err) }()
+
+	if mf, ok := c.sourceSnapStore.(brtypes.MetadataFetcher); ok && snapshot.Kind != brtypes.SnapshotKindDelta {
+		if snapshot.Metadata, err = mf.FetchMetadata(*snapshot); err != nil && !errors.Is(err, brtypes.ErrMetadataFetchUnsupported) {
+			return fmt.Errorf("could not fetch metadata of snapshot %s from source store: %v", snapshot.SnapName, err)
+		}
+	}
+
 	rc, err := c.sourceSnapStore.Fetch(*snapshot)
 	if err != nil {
 		return fmt.Errorf("could not fetch snapshot %s from source store: %v", snapshot.SnapName, err)
@@ -277,6 +289,7 @@ func (c *Copier) syncBackups(ctx context.Context, interval time.Duration) {
 	// start an initial sync first
 	if err := c.CopyBackups(ctx); err != nil {
 		c.logger.Errorf("could not perform the initial copy of backups: %v", err)
+		notifier.Notify(notifier.Event{Type: notifier.EventSecondarySyncFailed, Data: notifier.EventData{Error: err.Error()}})
 	}
 	for {
 		select {
@@ -286,6 +299,7 @@ func (c *Copier) syncBackups(ctx context.Context, interval time.Duration) {
 		case <-ticker.C:
 			if err := c.CopyBackups(ctx); err != nil {
 				c.logger.Errorf("could not copy backups: %v", err)
+				notifier.Notify(notifier.Event{Type: notifier.EventSecondarySyncFailed, Data: notifier.EventData{Error: err.Error()}})
 			}
 		case <-c.stopCh:
 			c.logger.Info("Backup copier is shutting down")
@@ -0,0 +1,319 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package delta encodes and decodes the events of delta snapshots.
+//
+// Delta snapshots are stored in one of two formats, both followed by the SHA256 hash of all preceding bytes:
+//   - the JSON format, a JSON array of events, which is the default.
+//   - the binary format, a header of the magic bytes "EBRD" and the format version, followed by one record
+//     per event. A record is the uvarint length of its payload, followed by the payload: the time of the
+//     event as varint seconds since the Unix epoch and uvarint nanoseconds, followed by the protobuf encoded
+//     etcd event.
+//
+// The binary format can be decoded as a stream, one event at a time. The format is detected from the first
+// bytes of a delta snapshot, so delta snapshots of both formats can be restored from the same backup.
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+)
+
+const (
+	// binaryFormatVersion is the version of the binary format written by the encoder.
+	binaryFormatVersion byte = 1
+	// readBufferSize is the size of the chunks read from the underlying reader of a decoder.
+	readBufferSize = 32 * 1024
+	// maxEventLength is the maximum length of an encoded event, to guard against allocating memory for
+	// corrupted lengths before the hash of the delta snapshot has been verified.
+	maxEventLength = 256 * 1024 * 1024
+)
+
+var (
+	// binaryFormatMagic are the first bytes of a delta snapshot in the binary format.
+	binaryFormatMagic = []byte("EBRD")
+
+	// ErrHashMismatch is returned if the hash of a delta snapshot does not match its contents.
+	ErrHashMismatch = errors.New("delta snapshot hash mismatch")
+	// ErrMissingHash is returned if a delta snapshot is too short to contain a hash.
+	ErrMissingHash = errors.New("delta snapshot is missing hash")
+)
+
+// Encoder encodes the events of a delta snapshot incrementally into an in-memory buffer.
+type Encoder struct {
+	format string
+	buf    []byte
+	events int
+}
+
+// NewEncoder returns an encoder for the given format. An empty format selects the default format.
+func NewEncoder(format string) (*Encoder, error) {
+	if len(format) == 0 {
+		format = brtypes.DefaultDeltaSnapshotFormat
+	}
+	if format != brtypes.DeltaSnapshotFormatJSON && format != brtypes.DeltaSnapshotFormatBinary {
+		return nil, fmt.Errorf("unsupported delta snapshot format %s", format)
+	}
+	e := &Encoder{format: format}
+	e.Reset()
+	return e, nil
+}
+
+// Reset discards the encoded events.
+func (e *Encoder) Reset() {
+	e.events = 0
+	e.buf = e.buf[:0]
+	if e.format == brtypes.DeltaSnapshotFormatBinary {
+		e.buf = append(e.buf, binaryFormatMagic...)
+		e.buf = append(e.buf, binaryFormatVersion)
+	}
+}
+
+// Encode appends the event.
+func (e *Encoder) Encode(event *brtypes.Event) error {
+	if e.format == brtypes.DeltaSnapshotFormatJSON {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event to json: %v", err)
+		}
+		if e.events == 0 {
+			e.buf = append(e.buf, '[')
+		} else {
+			e.buf = append(e.buf, ',')
+		}
+		e.buf = append(e.buf, data...)
+		e.events++
+		return nil
+	}
+
+	data, err := (*mvccpb.Event)(event.EtcdEvent).Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal event to protobuf: %v", err)
+	}
+	var timeBuf [2 * binary.MaxVarintLen64]byte
+	timeLen := binary.PutVarint(timeBuf[:], event.Time.Unix())
+	timeLen += binary.PutUvarint(timeBuf[timeLen:], uint64(event.Time.Nanosecond())) // #nosec G115 -- nanoseconds are never negative.
+	e.buf = binary.AppendUvarint(e.buf, uint64(timeLen+len(data)))                   // #nosec G115 -- lengths are never negative.
+	e.buf = append(e.buf, timeBuf[:timeLen]...)
+	e.buf = append(e.buf, data...)
+	e.events++
+	return nil
+}
+
+// Len returns the number of encoded events.
+func (e *Encoder) Len() int {
+	return e.events
+}
+
+// Size returns the number of bytes of the encoded events.
+func (e *Encoder) Size() int {
+	return len(e.buf)
+}
+
+// Finish completes the delta snapshot by appending its hash, and returns it. The encoder must be reset
+// before encoding further events.
+func (e *Encoder) Finish() []byte {
+	if e.format == brtypes.DeltaSnapshotFormatJSON {
+		if e.events == 0 {
+			e.buf = append(e.buf, '[')
+		}
+		e.buf = append(e.buf, ']')
+	}
+	sum := sha256.Sum256(e.buf)
+	e.buf = append(e.buf, sum[:]...)
+	return e.buf
+}
+
+// Encode encodes the events as a complete delta snapshot in the given format.
+func Encode(format string, events []brtypes.Event) ([]byte, error) {
+	e, err := NewEncoder(format)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		if err := e.Encode(&events[i]); err != nil {
+			return nil, err
+		}
+	}
+	return e.Finish(), nil
+}
+
+// Decoder decodes the events of a delta snapshot in either format.
+type Decoder struct {
+	format string
+	r      *bufio.Reader
+	// events holds the remaining events of a delta snapshot in the JSON format, which is decoded at once.
+	events []brtypes.Event
+}
+
+// NewDecoder returns a decoder reading a delta snapshot from r. The hash of the delta snapshot is verified
+// before the decoder reports the end of the events, so the events must only be relied on once Next
+// returned io.EOF.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	d := &Decoder{r: bufio.NewReaderSize(newHashVerifyingReader(r), readBufferSize)}
+
+	header, err := d.r.Peek(len(binaryFormatMagic) + 1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if !bytes.HasPrefix(header, binaryFormatMagic) {
+		d.format = brtypes.DeltaSnapshotFormatJSON
+		data, err := io.ReadAll(d.r)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &d.events); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal events: %v", err)
+		}
+		return d, nil
+	}
+
+	if len(header) <= len(binaryFormatMagic) {
+		return nil, fmt.Errorf("delta snapshot header is truncated")
+	}
+	if version := header[len(binaryFormatMagic)]; version != binaryFormatVersion {
+		return nil, fmt.Errorf("unsupported binary delta snapshot format version %d", version)
+	}
+	if _, err := d.r.Discard(len(header)); err != nil {
+		return nil, err
+	}
+	d.format = brtypes.DeltaSnapshotFormatBinary
+	return d, nil
+}
+
+// Format returns the format of the delta snapshot.
+func (d *Decoder) Format() string {
+	return d.format
+}
+
+// Next returns the next event. It returns io.EOF after the last event, once the hash has been verified.
+func (d *Decoder) Next() (*brtypes.Event, error) {
+	if d.format == brtypes.DeltaSnapshotFormatJSON {
+		if len(d.events) == 0 {
+			return nil, io.EOF
+		}
+		event := &d.events[0]
+		d.events = d.events[1:]
+		return event, nil
+	}
+
+	length, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read event length: %w", err)
+	}
+	if length > maxEventLength {
+		return nil, fmt.Errorf("event length %d exceeds the maximum length", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(d.r, payload); err != nil {
+		return nil, fmt.Errorf("failed to read event: %w", noEOF(err))
+	}
+
+	sec, n := binary.Varint(payload)
+	if n <= 0 {
+		return nil, fmt.Errorf("failed to decode event time")
+	}
+	nsec, m := binary.Uvarint(payload[n:])
+	if m <= 0 || nsec >= uint64(time.Second) {
+		return nil, fmt.Errorf("failed to decode event time")
+	}
+	etcdEvent := &mvccpb.Event{}
+	if err := etcdEvent.Unmarshal(payload[n+m:]); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %v", err)
+	}
+	return &brtypes.Event{EtcdEvent: (*clientv3.Event)(etcdEvent), Time: time.Unix(sec, int64(nsec))}, nil
+}
+
+// ReadAll decodes all events of the delta snapshot read from r and verifies its hash.
+func ReadAll(r io.Reader) ([]brtypes.Event, error) {
+	d, err := NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+	if d.format == brtypes.DeltaSnapshotFormatJSON {
+		return d.events, nil
+	}
+
+	var events []brtypes.Event
+	for {
+		event, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+}
+
+// noEOF converts an unexpected end of the events into io.ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// hashVerifyingReader reads a delta snapshot and holds back its trailing hash. It returns io.EOF
+// only after the hash of the bytes read matched the trailing hash.
+type hashVerifyingReader struct {
+	r       io.Reader
+	hash    hash.Hash
+	buf     []byte
+	pending []byte
+	eof     bool
+}
+
+func newHashVerifyingReader(r io.Reader) *hashVerifyingReader {
+	return &hashVerifyingReader{
+		r:    r,
+		hash: sha256.New(),
+		buf:  make([]byte, readBufferSize),
+	}
+}
+
+func (h *hashVerifyingReader) Read(p []byte) (int, error) {
+	for len(h.pending) <= sha256.Size && !h.eof {
+		n, err := h.r.Read(h.buf)
+		h.pending = append(h.pending, h.buf[:n]...)
+		if errors.Is(err, io.EOF) {
+			h.eof = true
+		} else if err != nil {
+			return 0, err
+		}
+	}
+
+	available := len(h.pending) - sha256.Size
+	if available <= 0 {
+		if available < 0 {
+			return 0, ErrMissingHash
+		}
+		if !bytes.Equal(h.hash.Sum(nil), h.pending) {
+			return 0, ErrHashMismatch
+		}
+		return 0, io.EOF
+	}
+
+	n := copy(p, h.pending[:available])
+	h.hash.Write(p[:n])
+	h.pending = h.pending[n:]
+	return n, nil
+}
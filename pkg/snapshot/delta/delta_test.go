@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package delta_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/snapshot/delta"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+)
+
+var _ = Describe("Delta snapshot format", func() {
+	var events []brtypes.Event
+
+	BeforeEach(func() {
+		events = nil
+		for rev := int64(2); rev <= 20; rev++ {
+			eventType := mvccpb.PUT
+			if rev%5 == 0 {
+				eventType = mvccpb.DELETE
+			}
+			events = append(events, brtypes.Event{
+				EtcdEvent: &clientv3.Event{
+					Type: eventType,
+					Kv: &mvccpb.KeyValue{
+						Key:            []byte(fmt.Sprintf("key-%d", rev%4)),
+						Value:          []byte(fmt.Sprintf("value-%d", rev)),
+						CreateRevision: 2,
+						ModRevision:    rev,
+						Version:        rev - 1,
+					},
+				},
+				Time: time.Unix(1700000000+rev, rev*1000),
+			})
+		}
+	})
+
+	for _, format := range []string{brtypes.DeltaSnapshotFormatBinary, brtypes.DeltaSnapshotFormatJSON} {
+		Context(fmt.Sprintf("with %s format", format), func() {
+			It("should round trip the events", func() {
+				data, err := delta.Encode(format, events)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				d, err := delta.NewDecoder(bytes.NewReader(data))
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(d.Format()).To(Equal(format))
+
+				decoded, err := delta.ReadAll(bytes.NewReader(data))
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(decoded).To(HaveLen(len(events)))
+				for i := range events {
+					Expect(decoded[i].EtcdEvent).To(Equal(events[i].EtcdEvent))
+					Expect(decoded[i].Time.Equal(events[i].Time)).To(BeTrue())
+				}
+			})
+
+			It("should encode incrementally", func() {
+				e, err := delta.NewEncoder(format)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(e.Encode(&events[0])).To(Succeed())
+				e.Reset()
+				Expect(e.Len()).To(Equal(0))
+				for i := range events {
+					Expect(e.Encode(&events[i])).To(Succeed())
+				}
+				Expect(e.Len()).To(Equal(len(events)))
+
+				expected, err := delta.Encode(format, events)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(e.Size()).To(BeNumerically("~", len(expected)-sha256.Size, 1))
+				Expect(e.Finish()).To(Equal(expected))
+			})
+
+			It("should decode a delta snapshot without events", func() {
+				data, err := delta.Encode(format, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				decoded, err := delta.ReadAll(bytes.NewReader(data))
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(decoded).To(BeEmpty())
+			})
+
+			It("should fail on a hash mismatch", func() {
+				data, err := delta.Encode(format, events)
+				Expect(err).ShouldNot(HaveOccurred())
+				data[len(data)-1] ^= 0xff
+
+				_, err = delta.ReadAll(bytes.NewReader(data))
+				Expect(errors.Is(err, delta.ErrHashMismatch)).To(BeTrue())
+			})
+		})
+	}
+
+	It("should decode delta snapshots in the legacy JSON format", func() {
+		data, err := json.Marshal(events)
+		Expect(err).ShouldNot(HaveOccurred())
+		hash := sha256.Sum256(data)
+		data = append(data, hash[:]...)
+
+		decoded, err := delta.ReadAll(bytes.NewReader(data))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(decoded).To(HaveLen(len(events)))
+		for i := range events {
+			Expect(decoded[i].EtcdEvent).To(Equal(events[i].EtcdEvent))
+			Expect(decoded[i].Time.Equal(events[i].Time)).To(BeTrue())
+		}
+	})
+
+	It("should decode the binary format one event at a time", func() {
+		data, err := delta.Encode(brtypes.DeltaSnapshotFormatBinary, events)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		d, err := delta.NewDecoder(bytes.NewReader(data))
+		Expect(err).ShouldNot(HaveOccurred())
+		for i := range events {
+			event, err := d.Next()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(event.EtcdEvent).To(Equal(events[i].EtcdEvent))
+		}
+		_, err = d.Next()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("should fail if the hash is missing", func() {
+		_, err := delta.ReadAll(bytes.NewReader([]byte("[]")))
+		Expect(errors.Is(err, delta.ErrMissingHash)).To(BeTrue())
+	})
+
+	It("should fail on a truncated binary delta snapshot", func() {
+		data, err := delta.Encode(brtypes.DeltaSnapshotFormatBinary, events)
+		Expect(err).ShouldNot(HaveOccurred())
+		// drop the last bytes of the last event, and keep a valid hash of the remaining bytes.
+		data = data[:len(data)-sha256.Size-3]
+		hash := sha256.Sum256(data)
+		data = append(data, hash[:]...)
+
+		_, err = delta.ReadAll(bytes.NewReader(data))
+		Expect(errors.Is(err, io.ErrUnexpectedEOF)).To(BeTrue())
+	})
+
+	It("should fail on an unsupported binary format version", func() {
+		data, err := delta.Encode(brtypes.DeltaSnapshotFormatBinary, events)
+		Expect(err).ShouldNot(HaveOccurred())
+		data[4] = 2
+
+		_, err = delta.NewDecoder(bytes.NewReader(data))
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should fail on an unsupported format", func() {
+		_, err := delta.NewEncoder("xml")
+		Expect(err).Should(HaveOccurred())
+	})
+})
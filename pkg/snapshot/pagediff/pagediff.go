@@ -0,0 +1,260 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pagediff computes and applies page diff snapshots, the pages of the bolt database of a full snapshot
+// which differ from those of a base full snapshot.
+//
+// A page diff snapshot is a header of the magic bytes "EBRP" and the format version, followed by the uvarint
+// page size, the uvarint size of the full snapshot and the uvarint length of the name of the base full snapshot
+// followed by the name. The header is followed by one record per changed page: the uvarint index of the page,
+// followed by the page. The last page of a full snapshot may be shorter than the page size.
+//
+// Applying the records of a page diff snapshot to the base full snapshot, truncated or extended to the size of
+// the full snapshot, restores the full snapshot byte by byte, including the hash etcd appends to it.
+package pagediff
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// formatVersion is the version of the format written by Write.
+	formatVersion byte = 1
+	// defaultPageSize is the page size used if the page size cannot be read from the bolt database.
+	defaultPageSize = 4096
+	// maxPageSize guards against allocating memory for corrupted page sizes.
+	maxPageSize = 1 << 20
+	// maxBaseNameLength guards against allocating memory for corrupted base snapshot names.
+	maxBaseNameLength = 4096
+
+	// boltMagic is the magic number in the meta page of a bolt database.
+	boltMagic = 0xED0CDAED
+	// boltMetaOffset is the offset of the meta data in the first page of a bolt database, after the page header.
+	boltMetaOffset = 16
+)
+
+var (
+	// formatMagic are the first bytes of a page diff snapshot.
+	formatMagic = []byte("EBRP")
+
+	// ErrPageSizeChanged is returned if the page size of a full snapshot differs from that of the base full snapshot.
+	ErrPageSizeChanged = errors.New("page size differs from the page size of the base full snapshot")
+)
+
+// pageHash is the truncated SHA256 hash of a page.
+type pageHash [16]byte
+
+func hashPage(page []byte) pageHash {
+	sum := sha256.Sum256(page)
+	var h pageHash
+	copy(h[:], sum[:])
+	return h
+}
+
+// Hashes holds the hashes of the pages of a full snapshot, against which page diff snapshots can be computed.
+type Hashes struct {
+	// BaseName is the name of the full snapshot the hashes belong to.
+	BaseName string
+	pageSize int
+	pages    []pageHash
+}
+
+// PageSize returns the page size of the full snapshot.
+func (h *Hashes) PageSize() int {
+	return h.pageSize
+}
+
+// Pages returns the number of pages of the full snapshot.
+func (h *Hashes) Pages() int {
+	return len(h.pages)
+}
+
+// readPageSize returns the page size of the bolt database the reader starts with, without consuming it.
+func readPageSize(br *bufio.Reader) int {
+	header, err := br.Peek(boltMetaOffset + 12)
+	if err != nil {
+		return defaultPageSize
+	}
+	if binary.LittleEndian.Uint32(header[boltMetaOffset:]) != boltMagic {
+		return defaultPageSize
+	}
+	pageSize := int(binary.LittleEndian.Uint32(header[boltMetaOffset+8:]))
+	if pageSize <= 0 || pageSize > maxPageSize {
+		return defaultPageSize
+	}
+	return pageSize
+}
+
+// readPages calls fn for each page read from the reader, together with its index.
+func readPages(br *bufio.Reader, pageSize int, fn func(index uint64, page []byte) error) (int64, error) {
+	var (
+		page  = make([]byte, pageSize)
+		size  int64
+		index uint64
+	)
+	for {
+		n, err := io.ReadFull(br, page)
+		if n > 0 {
+			size += int64(n)
+			if err := fn(index, page[:n]); err != nil {
+				return size, err
+			}
+			index++
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return size, nil
+		}
+		if err != nil {
+			return size, err
+		}
+	}
+}
+
+// ComputeHashes computes the hashes of the pages of the full snapshot read from the reader.
+func ComputeHashes(r io.Reader, baseName string) (*Hashes, error) {
+	br := bufio.NewReader(r)
+	h := &Hashes{
+		BaseName: baseName,
+		pageSize: readPageSize(br),
+	}
+	if _, err := readPages(br, h.pageSize, func(_ uint64, page []byte) error {
+		h.pages = append(h.pages, hashPage(page))
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read full snapshot: %w", err)
+	}
+	return h, nil
+}
+
+// Write writes the page diff snapshot of the full snapshot of the given size read from the reader against the
+// base full snapshot with the given hashes. It returns the number of changed pages.
+func Write(w io.Writer, r io.Reader, size int64, base *Hashes) (int, error) {
+	br := bufio.NewReader(r)
+	if pageSize := readPageSize(br); pageSize != base.pageSize {
+		return 0, ErrPageSizeChanged
+	}
+
+	bw := bufio.NewWriter(w)
+	header := append([]byte{}, formatMagic...)
+	header = append(header, formatVersion)
+	header = binary.AppendUvarint(header, uint64(base.pageSize))
+	header = binary.AppendUvarint(header, uint64(size))
+	header = binary.AppendUvarint(header, uint64(len(base.BaseName)))
+	header = append(header, base.BaseName...)
+	if _, err := bw.Write(header); err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	record := make([]byte, 0, binary.MaxVarintLen64)
+	read, err := readPages(br, base.pageSize, func(index uint64, page []byte) error {
+		if index < uint64(len(base.pages)) && hashPage(page) == base.pages[index] {
+			return nil
+		}
+		changed++
+		if _, err := bw.Write(binary.AppendUvarint(record[:0], index)); err != nil {
+			return err
+		}
+		_, err := bw.Write(page)
+		return err
+	})
+	if err != nil {
+		return changed, err
+	}
+	if read != size {
+		return changed, fmt.Errorf("read %d bytes of full snapshot, expected %d", read, size)
+	}
+	return changed, bw.Flush()
+}
+
+// Reader reads a page diff snapshot.
+type Reader struct {
+	br       *bufio.Reader
+	pageSize int
+	size     int64
+	baseName string
+}
+
+// NewReader reads the header of the page diff snapshot from the reader.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(formatMagic)+1)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("failed to read page diff snapshot header: %w", err)
+	}
+	if !bytes.Equal(magic[:len(formatMagic)], formatMagic) {
+		return nil, fmt.Errorf("invalid page diff snapshot header")
+	}
+	if magic[len(formatMagic)] != formatVersion {
+		return nil, fmt.Errorf("unsupported page diff snapshot format version %d", magic[len(formatMagic)])
+	}
+	pageSize, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page size: %w", err)
+	}
+	if pageSize == 0 || pageSize > maxPageSize {
+		return nil, fmt.Errorf("invalid page size %d", pageSize)
+	}
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read full snapshot size: %w", err)
+	}
+	nameLength, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base full snapshot name: %w", err)
+	}
+	if nameLength > maxBaseNameLength {
+		return nil, fmt.Errorf("invalid base full snapshot name length %d", nameLength)
+	}
+	name := make([]byte, nameLength)
+	if _, err := io.ReadFull(br, name); err != nil {
+		return nil, fmt.Errorf("failed to read base full snapshot name: %w", err)
+	}
+	return &Reader{
+		br:       br,
+		pageSize: int(pageSize),
+		size:     int64(size),
+		baseName: string(name),
+	}, nil
+}
+
+// BaseName returns the name of the base full snapshot the page diff snapshot has to be applied to.
+func (r *Reader) BaseName() string {
+	return r.baseName
+}
+
+// Apply applies the changed pages to the file holding the base full snapshot, which turns it into the full snapshot.
+func (r *Reader) Apply(f *os.File) error {
+	if err := f.Truncate(r.size); err != nil {
+		return fmt.Errorf("failed to resize base full snapshot: %w", err)
+	}
+	page := make([]byte, r.pageSize)
+	for {
+		index, err := binary.ReadUvarint(r.br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read page index: %w", err)
+		}
+		offset := int64(index) * int64(r.pageSize)
+		if index > uint64(r.size)/uint64(r.pageSize) || offset >= r.size {
+			return fmt.Errorf("page %d beyond the full snapshot size %d", index, r.size)
+		}
+		length := min(int64(r.pageSize), r.size-offset)
+		if _, err := io.ReadFull(r.br, page[:length]); err != nil {
+			return fmt.Errorf("failed to read page %d: %w", index, err)
+		}
+		if _, err := f.WriteAt(page[:length], offset); err != nil {
+			return fmt.Errorf("failed to write page %d: %w", index, err)
+		}
+	}
+}
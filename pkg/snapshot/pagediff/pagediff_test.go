@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pagediff_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	. "github.com/gardener/etcd-backup-restore/pkg/snapshot/pagediff"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PageDiff", func() {
+	const pageSize = 4096
+
+	// newDB returns random data of the given number of pages, starting with a bolt meta page of the given page size.
+	newDB := func(pages int, dbPageSize uint32) []byte {
+		db := make([]byte, pages*pageSize)
+		rand.New(rand.NewSource(1)).Read(db)
+		binary.LittleEndian.PutUint32(db[16:], 0xED0CDAED)
+		binary.LittleEndian.PutUint32(db[24:], dbPageSize)
+		return db
+	}
+
+	// restore applies the page diff snapshot to a file holding the base full snapshot and returns the result.
+	restore := func(base []byte, diff []byte) []byte {
+		f, err := os.Create(filepath.Join(GinkgoT().TempDir(), "db"))
+		Expect(err).ShouldNot(HaveOccurred())
+		defer f.Close()
+		_, err = f.Write(base)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		r, err := NewReader(bytes.NewReader(diff))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(r.BaseName()).Should(Equal("Full-00000000-00000001-1"))
+		Expect(r.Apply(f)).Should(Succeed())
+		restored, err := os.ReadFile(f.Name())
+		Expect(err).ShouldNot(HaveOccurred())
+		return restored
+	}
+
+	write := func(base []byte, db []byte) ([]byte, int) {
+		hashes, err := ComputeHashes(bytes.NewReader(base), "Full-00000000-00000001-1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(hashes.PageSize()).Should(Equal(pageSize))
+		var diff bytes.Buffer
+		changed, err := Write(&diff, bytes.NewReader(db), int64(len(db)), hashes)
+		Expect(err).ShouldNot(HaveOccurred())
+		return diff.Bytes(), changed
+	}
+
+	It("should only contain the changed pages", func() {
+		base := newDB(64, pageSize)
+		db := append([]byte{}, base...)
+		copy(db[10*pageSize+100:], "changed")
+		copy(db[40*pageSize:], "changed")
+
+		diff, changed := write(base, db)
+		Expect(changed).Should(Equal(2))
+		Expect(len(diff)).Should(BeNumerically("<", 3*pageSize))
+		Expect(restore(base, diff)).Should(Equal(db))
+	})
+
+	It("should restore a full snapshot which grew", func() {
+		base := newDB(16, pageSize)
+		db := append(append([]byte{}, base...), newDB(4, pageSize)[:3*pageSize+32]...)
+
+		diff, changed := write(base, db)
+		Expect(changed).Should(Equal(4))
+		Expect(restore(base, diff)).Should(Equal(db))
+	})
+
+	It("should restore a full snapshot which shrank", func() {
+		base := newDB(16, pageSize)
+		db := append([]byte{}, base[:8*pageSize+32]...)
+
+		diff, changed := write(base, db)
+		Expect(changed).Should(Equal(1))
+		Expect(restore(base, diff)).Should(Equal(db))
+	})
+
+	It("should fail if the page size changed", func() {
+		hashes, err := ComputeHashes(bytes.NewReader(newDB(4, pageSize)), "Full-00000000-00000001-1")
+		Expect(err).ShouldNot(HaveOccurred())
+		db := newDB(4, 2*pageSize)
+		_, err = Write(&bytes.Buffer{}, bytes.NewReader(db), int64(len(db)), hashes)
+		Expect(err).Should(MatchError(ErrPageSizeChanged))
+	})
+
+	It("should reject data which is no page diff snapshot", func() {
+		_, err := NewReader(bytes.NewReader(newDB(1, pageSize)))
+		Expect(err).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package restorer
+
+import (
+	"sync"
+)
+
+// fetchBudget throttles the fetchers of delta snapshots, so that the total size of the fetched delta snapshots
+// which are not yet applied stays within the budget. The temporary snapshots directory is commonly memory backed,
+// and the fetched delta snapshots occupy the page cache in any case.
+//
+// The next delta snapshot to apply is always fetched, even if the budget is exhausted, so the restoration makes
+// progress if a single delta snapshot exceeds the budget. Since the fetchers receive the delta snapshots in order,
+// the next delta snapshot to apply is never blocked behind later ones.
+type fetchBudget struct {
+	mutex     sync.Mutex
+	cond      *sync.Cond
+	limit     int64
+	used      int64
+	nextIndex int
+	stopped   bool
+}
+
+// newFetchBudget returns a budget of the given size in bytes, which releases all waiting fetchers once stopCh is closed.
+// A limit of 0 disables the budget.
+func newFetchBudget(limit int64, stopCh <-chan bool) *fetchBudget {
+	b := &fetchBudget{limit: limit}
+	b.cond = sync.NewCond(&b.mutex)
+	go func() {
+		<-stopCh
+		b.mutex.Lock()
+		b.stopped = true
+		b.mutex.Unlock()
+		b.cond.Broadcast()
+	}()
+	return b
+}
+
+// acquire waits until the delta snapshot with the given index may be fetched. It returns false if the budget was stopped.
+func (b *fetchBudget) acquire(index int) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for !b.stopped && b.limit > 0 && b.used >= b.limit && index != b.nextIndex {
+		b.cond.Wait()
+	}
+	return !b.stopped
+}
+
+// add accounts a fetched delta snapshot of the given size.
+func (b *fetchBudget) add(size int64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.used += size
+}
+
+// release returns the size of the applied delta snapshot with the given index to the budget.
+func (b *fetchBudget) release(index int, size int64) {
+	b.mutex.Lock()
+	b.used -= size
+	b.nextIndex = index + 1
+	b.mutex.Unlock()
+	b.cond.Broadcast()
+}
@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package restorer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/sirupsen/logrus"
+)
+
+// checkpointFileName is the name of the file in the temporary snapshots directory which records the progress of a restoration.
+const checkpointFileName = "restoration-checkpoint.json"
+
+// errStaleCheckpoint is returned if the partially restored data directory does not match the checkpoint.
+var errStaleCheckpoint = errors.New("partially restored data directory is behind the restoration checkpoint")
+
+// checkpoint records the progress of a restoration, so that an interrupted restoration continues with the
+// delta snapshots after the last applied one, instead of starting over from the base snapshot.
+type checkpoint struct {
+	// BaseSnapshot is the path of the base snapshot the data directory was restored from.
+	BaseSnapshot string `json:"baseSnapshot"`
+	// DataDir is the data directory which is restored.
+	DataDir string `json:"dataDir"`
+	// LastAppliedSnapshot is the path of the last applied snapshot.
+	LastAppliedSnapshot string `json:"lastAppliedSnapshot"`
+	// LastRevision is the revision of the data directory after the last applied snapshot.
+	LastRevision int64 `json:"lastRevision"`
+}
+
+// checkpointer persists the checkpoint of a restoration after every applied snapshot.
+type checkpointer struct {
+	logger   *logrus.Entry
+	filePath string
+	current  checkpoint
+	// resumedRevision is the revision the restoration was resumed from, or 0 if it was not resumed.
+	resumedRevision int64
+	// resumable is unset if the revisions of the partially restored data directory cannot be verified against the
+	// checkpoint, i.e. if the embedded etcd applies the delta snapshots with batched revisions.
+	resumable bool
+}
+
+func newCheckpointer(ro brtypes.RestoreOptions, logger *logrus.Entry) *checkpointer {
+	return &checkpointer{
+		logger:    logger,
+		filePath:  checkpointFilePath(ro.Config.TempSnapshotsDir),
+		resumable: !ro.Config.BatchDeltaRevisions || ro.Config.DeltaApplyEngine == brtypes.DeltaApplyEngineOffline,
+		current: checkpoint{
+			BaseSnapshot: snapshotPath(ro.BaseSnapshot),
+			DataDir:      ro.Config.DataDir,
+		},
+	}
+}
+
+// applied records that the given snapshot has been applied. Failures are only logged, since they
+// only cost the progress of the restoration if it is interrupted.
+func (c *checkpointer) applied(snap *brtypes.Snapshot) {
+	c.current.LastAppliedSnapshot = snapshotPath(snap)
+	c.current.LastRevision = snap.LastRevision
+
+	data, err := json.Marshal(c.current)
+	if err != nil {
+		c.logger.Warnf("Unable to marshal the restoration checkpoint: %v", err)
+		return
+	}
+	tempFilePath := c.filePath + ".tmp"
+	if err := os.WriteFile(tempFilePath, data, 0600); err != nil {
+		c.logger.Warnf("Unable to write the restoration checkpoint %s: %v", tempFilePath, err)
+		return
+	}
+	if err := os.Rename(tempFilePath, c.filePath); err != nil {
+		c.logger.Warnf("Unable to write the restoration checkpoint %s: %v", c.filePath, err)
+	}
+}
+
+// resume loads the checkpoint of a previous restoration and returns true if it can be continued,
+// i.e. the checkpoint is for the same base snapshot and data directory, and the data directory exists.
+func (c *checkpointer) resume() bool {
+	data, err := os.ReadFile(c.filePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			c.logger.Warnf("Unable to read the restoration checkpoint %s: %v", c.filePath, err)
+		}
+		return false
+	}
+
+	var previous checkpoint
+	if err := json.Unmarshal(data, &previous); err != nil {
+		c.logger.Warnf("Ignoring the invalid restoration checkpoint %s: %v", c.filePath, err)
+		return false
+	}
+	if !c.resumable {
+		c.logger.Infof("Ignoring the restoration checkpoint %s, since the delta snapshots are applied with batched revisions", c.filePath)
+		return false
+	}
+	if previous.BaseSnapshot != c.current.BaseSnapshot || previous.DataDir != c.current.DataDir {
+		c.logger.Infof("Ignoring the restoration checkpoint %s of base snapshot %s and data directory %s", c.filePath, previous.BaseSnapshot, previous.DataDir)
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(previous.DataDir, "member", "snap", "db")); err != nil {
+		c.logger.Infof("Ignoring the restoration checkpoint %s, since the partially restored database is not accessible: %v", c.filePath, err)
+		return false
+	}
+
+	c.current = previous
+	c.resumedRevision = previous.LastRevision
+	return true
+}
+
+// verify checks that the partially restored data directory contains at least the revisions recorded in the checkpoint.
+func (c *checkpointer) verify(revision int64) error {
+	if revision < c.resumedRevision {
+		return fmt.Errorf("%w: revision %d is lower than revision %d of snapshot %s", errStaleCheckpoint, revision, c.resumedRevision, c.current.LastAppliedSnapshot)
+	}
+	return nil
+}
+
+// remove removes the checkpoint.
+func (c *checkpointer) remove() {
+	if err := os.Remove(c.filePath); err != nil && !os.IsNotExist(err) {
+		c.logger.Warnf("Unable to remove the restoration checkpoint %s: %v", c.filePath, err)
+	}
+}
+
+// CanResume returns true if a previous restoration with the given options was interrupted and can be continued
+// from its checkpoint. In that case, the partially restored data directory has to be kept.
+func (r *Restorer) CanResume(ro brtypes.RestoreOptions) bool {
+	return newCheckpointer(ro, r.logger).resume()
+}
+
+// remainingDeltaSnapshots returns the delta snapshots with revisions after the given revision.
+// The first of them may overlap with the given revision, just like with a base snapshot.
+func remainingDeltaSnapshots(snapList brtypes.SnapList, revision int64) brtypes.SnapList {
+	for i, snap := range snapList {
+		if snap.LastRevision > revision {
+			return snapList[i:]
+		}
+	}
+	return nil
+}
+
+// removeTempDir removes the temporary snapshots directory. If keepCheckpoint is set, the checkpoint is kept,
+// so that a failed restoration can be continued.
+func (r *Restorer) removeTempDir(dir string, keepCheckpoint bool) {
+	if !keepCheckpoint {
+		if err := os.RemoveAll(dir); err != nil {
+			r.logger.Errorf("failed to remove restoration temp directory %s: %v", dir, err)
+		}
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		r.logger.Errorf("failed to read restoration temp directory %s: %v", dir, err)
+		return
+	}
+	for _, entry := range entries {
+		if entry.Name() == checkpointFileName {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			r.logger.Errorf("failed to remove %s from restoration temp directory %s: %v", entry.Name(), dir, err)
+		}
+	}
+}
+
+func checkpointFilePath(tempDir string) string {
+	return filepath.Join(tempDir, checkpointFileName)
+}
+
+func snapshotPath(snap *brtypes.Snapshot) string {
+	if snap == nil {
+		return ""
+	}
+	return path.Join(snap.SnapDir, snap.SnapName)
+}
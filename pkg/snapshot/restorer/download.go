@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package restorer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+)
+
+// downloadPart is a byte range of a snapshot which is downloaded separately.
+type downloadPart struct {
+	offset int64
+	length int64
+}
+
+// fetchSnapshotParallel downloads the snapshot into the given file in parts of partSize bytes, with up to maxParallelDownloads
+// parts being downloaded at the same time. Each part is written at its offset, so the parts are reassembled in the file
+// regardless of the order in which they are downloaded. It returns false if the snapshot is not downloaded in parts,
+// i.e. if the store does not support fetching byte ranges or the snapshot fits into a single part.
+func (r *Restorer) fetchSnapshotParallel(snap *brtypes.Snapshot, file *os.File, maxParallelDownloads uint, partSize int64) (bool, error) {
+	rangeFetcher, ok := r.store.(brtypes.RangeFetcher)
+	if !ok || maxParallelDownloads <= 1 || partSize <= 0 {
+		return false, nil
+	}
+	size, err := rangeFetcher.Size(*snap)
+	if errors.Is(err, brtypes.ErrRangeFetchUnsupported) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get the size of snapshot %s: %w", snap.SnapName, err)
+	}
+	if size <= partSize {
+		return false, nil
+	}
+
+	var (
+		numParts   = (size + partSize - 1) / partSize
+		numWorkers = int(min(int64(maxParallelDownloads), numParts))
+		partCh     = make(chan downloadPart, numParts)
+		errCh      = make(chan error, numParts)
+		stopCh     = make(chan struct{})
+		stopOnce   sync.Once
+		wg         sync.WaitGroup
+	)
+	r.logger.Infof("Downloading snapshot %s of %d bytes in %d parts with %d parallel downloads", snap.SnapName, size, numParts, numWorkers)
+
+	for offset := int64(0); offset < size; offset += partSize {
+		partCh <- downloadPart{offset: offset, length: min(partSize, size-offset)}
+	}
+	close(partCh)
+
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for part := range partCh {
+				select {
+				case <-stopCh:
+					return
+				default:
+				}
+				if err := downloadSnapshotPart(rangeFetcher, snap, file, part); err != nil {
+					errCh <- err
+					// the remaining parts are not downloaded once a part failed.
+					stopOnce.Do(func() { close(stopCh) })
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	var errs []error
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return true, ErrorArrayToError(errs)
+	}
+	return true, nil
+}
+
+// downloadSnapshotPart downloads the given part of the snapshot and writes it at its offset into the file.
+func downloadSnapshotPart(rangeFetcher brtypes.RangeFetcher, snap *brtypes.Snapshot, file *os.File, part downloadPart) error {
+	rc, err := rangeFetcher.FetchRange(*snap, part.offset, part.length)
+	if err != nil {
+		return fmt.Errorf("failed to fetch bytes [%d, %d) of snapshot %s: %w", part.offset, part.offset+part.length, snap.SnapName, err)
+	}
+	defer rc.Close()
+
+	n, err := io.Copy(io.NewOffsetWriter(file, part.offset), io.LimitReader(rc, part.length))
+	if err != nil {
+		return fmt.Errorf("failed to download bytes [%d, %d) of snapshot %s: %w", part.offset, part.offset+part.length, snap.SnapName, err)
+	}
+	if n != part.length {
+		return fmt.Errorf("failed to download bytes [%d, %d) of snapshot %s: received %d bytes", part.offset, part.offset+part.length, snap.SnapName, n)
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package restorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/snapshot/delta"
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"go.etcd.io/etcd/lease"
+	"go.etcd.io/etcd/mvcc"
+	"go.etcd.io/etcd/mvcc/backend"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+	"go.etcd.io/etcd/pkg/traceutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// offlineBatchLimit and offlineBatchInterval keep the backend from committing its bolt transaction on its own.
+	// The offline store commits explicitly once the events of a delta snapshot have been applied completely.
+	offlineBatchLimit    = math.MaxInt
+	offlineBatchInterval = time.Duration(math.MaxInt64)
+)
+
+// offlineStore applies the events of delta snapshots directly to the mvcc store in the bolt database
+// of the restored data directory, without starting an embedded etcd.
+type offlineStore struct {
+	be     backend.Backend
+	lessor lease.Lessor
+	kv     mvcc.KV
+	// uncommitted is set while the store holds writes which have not been committed yet.
+	uncommitted bool
+}
+
+// newOfflineStore opens the bolt database of the given etcd data directory.
+func newOfflineStore(dataDir string, zapLogger *zap.Logger) (*offlineStore, error) {
+	dbPath := filepath.Join(dataDir, "member", "snap", "db")
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("failed to find the restored database %s: %v", dbPath, err)
+	}
+
+	bcfg := backend.DefaultBackendConfig()
+	bcfg.Path = dbPath
+	bcfg.BatchLimit = offlineBatchLimit
+	bcfg.BatchInterval = offlineBatchInterval
+	bcfg.Logger = zapLogger
+	be := backend.New(bcfg)
+
+	// The lessor is only required to detach the leases of overwritten or deleted keys. It is never
+	// promoted, so it does not revoke expired leases. The events are applied without leases, same as
+	// through the embedded etcd. No consistent index getter is passed, so the consistent index stays
+	// the one written by the restoration of the base snapshot, matching the WAL.
+	lessor := lease.NewLessor(zapLogger, be, nil, lease.LessorConfig{})
+	return &offlineStore{
+		be:     be,
+		lessor: lessor,
+		kv:     mvcc.NewStore(zapLogger, be, lessor, nil, mvcc.StoreConfig{}),
+	}, nil
+}
+
+// applyEventsAndVerify applies the events of one delta snapshot while decoding them, one write transaction
+// per revision, and verifies that the resulting revision matches the last revision of the snapshot.
+// Events of revisions which have already been applied are skipped, since the first delta snapshot
+// may overlap with the base snapshot.
+func (s *offlineStore) applyEventsAndVerify(d *delta.Decoder, snap *brtypes.Snapshot) error {
+	var (
+		appliedRev = s.kv.Rev()
+		lastRev    int64
+		txn        mvcc.TxnWrite
+	)
+	defer func() {
+		if txn != nil {
+			txn.End()
+		}
+	}()
+
+	for {
+		e, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read events of delta snapshot %s : %v", snap.SnapName, err)
+		}
+		ev := e.EtcdEvent
+		if ev.Kv.ModRevision <= appliedRev {
+			continue
+		}
+		if txn != nil && ev.Kv.ModRevision > lastRev {
+			txn.End()
+			txn = nil
+		}
+		if txn == nil {
+			txn = s.kv.Write(traceutil.TODO())
+			s.uncommitted = true
+		}
+		lastRev = ev.Kv.ModRevision
+
+		switch ev.Type {
+		case mvccpb.PUT:
+			txn.Put(ev.Kv.Key, ev.Kv.Value, lease.NoLease)
+		case mvccpb.DELETE:
+			txn.DeleteRange(ev.Kv.Key, nil)
+		default:
+			return fmt.Errorf("failed to apply events for delta snapshot %s : unexpected event type", snap.SnapName)
+		}
+	}
+	if txn != nil {
+		txn.End()
+		txn = nil
+	}
+
+	if rev := s.kv.Rev(); rev != snap.LastRevision {
+		return fmt.Errorf("snapshot revision verification failed for delta snapshot %s : mismatched event revision while applying delta snapshot, expected %d but applied %d ", snap.SnapName, snap.LastRevision, rev)
+	}
+	return nil
+}
+
+// commit commits the applied events to the bolt database.
+func (s *offlineStore) commit() {
+	s.kv.Commit()
+	s.uncommitted = false
+}
+
+// compact compacts the mvcc store up to its current revision and waits for the compaction to finish.
+func (s *offlineStore) compact() error {
+	ch, err := s.kv.Compact(traceutil.TODO(), s.kv.Rev())
+	if err != nil {
+		if errors.Is(err, mvcc.ErrCompacted) {
+			return nil
+		}
+		return err
+	}
+	<-ch
+	return nil
+}
+
+// close closes the store. If defrag is set, the database is compacted and defragmented first.
+// Closing the backend commits the pending writes, so a store with uncommitted writes has to be discarded afterwards.
+func (s *offlineStore) close(defrag bool) error {
+	var errs []error
+	if defrag {
+		if err := s.compact(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to compact the restored database: %v", err))
+		} else if err := s.be.Defrag(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to defragment the restored database: %v", err))
+		}
+	}
+	if err := s.kv.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	s.lessor.Stop()
+	if err := s.be.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	return ErrorArrayToError(errs)
+}
+
+// applyDeltaSnapshotsOffline fetches the events from delta snapshots in parallel and applies them
+// sequentially, directly to the bolt database of the restored data directory.
+func (r *Restorer) applyDeltaSnapshotsOffline(ctx context.Context, ro brtypes.RestoreOptions, cp *checkpointer) (err error) {
+	store, err := newOfflineStore(ro.Config.DataDir, r.zapLogger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// the events of a delta snapshot are only committed once they have been applied completely. If applying them
+		// failed halfway, the database cannot be rolled back to the last checkpoint and is discarded instead.
+		discard := err != nil && store.uncommitted
+		// the database is only compacted and defragmented if all delta snapshots have been applied.
+		if closeErr := store.close(err == nil); closeErr != nil {
+			r.logger.Errorf("failed to close the restored database: %v", closeErr)
+			if err == nil {
+				err = closeErr
+			}
+		}
+		if discard {
+			r.logger.Warnf("Discarding the partially restored data directory %s, since a delta snapshot was applied only partially", ro.Config.DataDir)
+			cp.remove()
+			if removeErr := os.RemoveAll(ro.Config.DataDir); removeErr != nil {
+				r.logger.Errorf("failed to remove the partially restored data directory %s: %v", ro.Config.DataDir, removeErr)
+			}
+		}
+	}()
+
+	if err := cp.verify(store.kv.Rev()); err != nil {
+		return err
+	}
+
+	var (
+		snapList        = ro.DeltaSnapList
+		numSnaps        = len(snapList)
+		numFetchers     = int(math.Min(float64(ro.Config.MaxFetchers), float64(numSnaps)))
+		snapLocationsCh = make(chan string, numSnaps)
+		errCh           = make(chan error, numFetchers+1)
+		fetcherInfoCh   = make(chan brtypes.FetcherInfo, numSnaps)
+		applierInfoCh   = make(chan brtypes.ApplierInfo, numSnaps)
+		wg              sync.WaitGroup
+		stopCh          = make(chan bool)
+		budget          = newFetchBudget(ro.Config.FetchedSnapshotsBudget, stopCh)
+	)
+
+	go r.applySnapsOffline(ctx, store, snapList, applierInfoCh, errCh, stopCh, &wg, budget, cp)
+
+	for f := 0; f < numFetchers; f++ {
+		go r.fetchSnaps(ctx, f, fetcherInfoCh, applierInfoCh, snapLocationsCh, errCh, stopCh, &wg, ro.Config.TempSnapshotsDir, budget)
+	}
+
+	for i, snap := range snapList {
+		fetcherInfoCh <- brtypes.FetcherInfo{
+			Snapshot:  *snap,
+			SnapIndex: i,
+		}
+	}
+	close(fetcherInfoCh)
+
+	err = <-errCh
+
+	if cleanupErr := r.cleanup(snapLocationsCh, stopCh, &wg); cleanupErr != nil {
+		r.logger.Errorf("Cleanup of temporary snapshots failed: %v", cleanupErr)
+	}
+
+	if err != nil {
+		r.logger.Errorf("Restoration failed.")
+		return err
+	}
+
+	r.logger.Infof("Restoration complete.")
+	return nil
+}
+
+// applySnapsOffline applies delta snapshot events to the offline store sequentially, in the right order of snapshots, regardless of the order in which they were fetched.
+func (r *Restorer) applySnapsOffline(ctx context.Context, store *offlineStore, snapList brtypes.SnapList, applierInfoCh <-chan brtypes.ApplierInfo, errCh chan<- error, stopCh <-chan bool, wg *sync.WaitGroup, budget *fetchBudget, cp *checkpointer) {
+	defer wg.Done()
+	wg.Add(1)
+
+	pathList := make([]string, len(snapList))
+	sizeList := make([]int64, len(snapList))
+	nextSnapIndexToApply := 0
+	for {
+		select {
+		case _, more := <-stopCh:
+			if !more {
+				return
+			}
+		case applierInfo := <-applierInfoCh:
+			if applierInfo.SnapIndex == -1 {
+				return
+			}
+
+			fetchedSnapIndex := applierInfo.SnapIndex
+			pathList[fetchedSnapIndex] = applierInfo.SnapFilePath
+			sizeList[fetchedSnapIndex] = applierInfo.SnapFileSize
+
+			if fetchedSnapIndex < nextSnapIndexToApply {
+				errCh <- fmt.Errorf("snap index mismatch for delta snapshot %d; expected snap index to be atleast %d", fetchedSnapIndex, nextSnapIndexToApply)
+				return
+			}
+			if fetchedSnapIndex != nextSnapIndexToApply {
+				continue
+			}
+			for currSnapIndex := fetchedSnapIndex; currSnapIndex < len(snapList); currSnapIndex++ {
+				if pathList[currSnapIndex] == "" {
+					break
+				}
+
+				filePath := pathList[currSnapIndex]
+				snap := snapList[currSnapIndex]
+
+				_, span := tracing.Start(ctx, "restorer.applySnapsOffline", tracing.SnapshotAttributes(snap)...)
+				r.logger.Infof("Applying delta snapshot %s offline [%d/%d]", path.Join(snap.SnapDir, snap.SnapName), currSnapIndex+1, len(snapList))
+				if err := r.applyDeltaSnapshotFileOffline(store, filePath, snap); err != nil {
+					tracing.End(span, err)
+					errCh <- err
+					return
+				}
+				tracing.End(span, nil)
+				// the applied revisions are committed before they are checkpointed, so they survive an interruption.
+				store.commit()
+				cp.applied(snap)
+
+				r.logger.Infof("Removing temporary delta snapshot events file %s for snapshot %s", filePath, snap.SnapName)
+				if err := os.Remove(filePath); err != nil {
+					r.logger.Warnf("Unable to remove file: %s; err: %v", filePath, err)
+				}
+				budget.release(currSnapIndex, sizeList[currSnapIndex])
+
+				nextSnapIndexToApply++
+				if nextSnapIndexToApply == len(snapList) {
+					errCh <- nil // restore finished
+					return
+				}
+
+				// superseded revisions are compacted periodically to keep the database small, same as for the embedded etcd.
+				if nextSnapIndexToApply%periodicallyMakeEtcdLeanDeltaSnapshotInterval == 0 {
+					if err := store.compact(); err != nil {
+						r.logger.Warnf("Unable to compact the restored database: %v", err)
+					}
+				}
+			}
+		}
+	}
+}
+
+// applyDeltaSnapshotFileOffline verifies the delta snapshot persisted to the given file and streams its events to the offline store.
+func (r *Restorer) applyDeltaSnapshotFileOffline(store *offlineStore, filePath string, snap *brtypes.Snapshot) error {
+	if err := r.verifyDeltaSnapshotFile(filePath, snap); err != nil {
+		return err
+	}
+
+	r.logger.Infof("Reading snapshot contents %s from raw snapshot file %s", snap.SnapName, filePath)
+	file, err := os.Open(filePath) // #nosec G304 -- this is a trusted snapshot file.
+	if err != nil {
+		return fmt.Errorf("failed to open file %s for delta snapshot %s : %v", filePath, snap.SnapName, err)
+	}
+
+	d, err := r.openDeltaSnapshot(file, snap)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return store.applyEventsAndVerify(d.Decoder, snap)
+}
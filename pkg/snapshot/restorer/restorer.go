@@ -5,17 +5,14 @@
 package restorer
 
 import (
-	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"os"
 	"path"
 	"path/filepath"
-	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -25,6 +22,9 @@ import (
 	"github.com/gardener/etcd-backup-restore/pkg/etcdutil/client"
 	"github.com/gardener/etcd-backup-restore/pkg/member"
 	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+	"github.com/gardener/etcd-backup-restore/pkg/snapshot/delta"
+	"github.com/gardener/etcd-backup-restore/pkg/snapshot/pagediff"
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
 	"github.com/sirupsen/logrus"
@@ -33,6 +33,7 @@ import (
 	"go.etcd.io/etcd/embed"
 	"go.etcd.io/etcd/mvcc/mvccpb"
 	"go.uber.org/zap"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
 )
 
 const (
@@ -76,20 +77,51 @@ func (r *Restorer) RestoreAndStopEtcd(ro brtypes.RestoreOptions, m member.Contro
 }
 
 // Restore restores the etcd data directory as per specified restore options but returns the ETCD server that it statrted.
+// The progress of the restoration is checkpointed in the temporary snapshots directory, so that an interrupted
+// restoration continues with the delta snapshot after the last applied one.
 func (r *Restorer) Restore(ro brtypes.RestoreOptions, m member.Control) (*embed.Etcd, error) {
+	e, err := r.restore(ro, m)
+	if !errors.Is(err, errStaleCheckpoint) {
+		return e, err
+	}
+
+	r.logger.Warnf("Unable to resume the restoration: %v", err)
+	r.logger.Info("Restoring from the base snapshot again...")
+	if e != nil {
+		e.Server.Stop()
+		e.Close()
+	}
+	newCheckpointer(ro, r.logger).remove()
+	if err := os.RemoveAll(ro.Config.DataDir); err != nil {
+		return nil, fmt.Errorf("failed to remove the partially restored data directory %s: %v", ro.Config.DataDir, err)
+	}
+	return r.restore(ro, m)
+}
+
+func (r *Restorer) restore(ro brtypes.RestoreOptions, m member.Control) (e *embed.Etcd, err error) {
+	ctx, span := tracing.Start(context.TODO(), "restorer.Restore", tracing.SnapshotAttributes(ro.BaseSnapshot)...)
+	defer func() { tracing.End(span, err) }()
+
 	r.logger.Infof("Creating temporary directory %s for persisting full and delta snapshots locally.", ro.Config.TempSnapshotsDir)
-	err := os.MkdirAll(ro.Config.TempSnapshotsDir, 0700)
+	err = os.MkdirAll(ro.Config.TempSnapshotsDir, 0700)
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
-		if err := os.RemoveAll(ro.Config.TempSnapshotsDir); err != nil {
-			r.logger.Errorf("failed to remove restoration temp directory %s: %v", ro.Config.TempSnapshotsDir, err)
-		}
+		// the checkpoint is kept on failures, so that the next attempt continues from it.
+		r.removeTempDir(ro.Config.TempSnapshotsDir, err != nil)
 	}()
 
-	if err := r.restoreFromBaseSnapshot(ro); err != nil {
-		return nil, fmt.Errorf("failed to restore from the base snapshot: %v", err)
+	cp := newCheckpointer(ro, r.logger)
+	if cp.resume() {
+		r.logger.Infof("Resuming the restoration of %s from revision %d of snapshot %s", ro.Config.DataDir, cp.resumedRevision, cp.current.LastAppliedSnapshot)
+		ro.DeltaSnapList = remainingDeltaSnapshots(ro.DeltaSnapList, cp.resumedRevision)
+	} else {
+		cp.remove()
+		if err := r.restoreFromBaseSnapshot(ctx, ro); err != nil {
+			return nil, fmt.Errorf("failed to restore from the base snapshot: %v", err)
+		}
+		cp.applied(ro.BaseSnapshot)
 	}
 
 	if len(ro.DeltaSnapList) == 0 {
@@ -98,8 +130,17 @@ func (r *Restorer) Restore(ro brtypes.RestoreOptions, m member.Control) (*embed.
 	}
 
 	r.logger.Infof("Attempting to apply %d delta snapshots for restoration.", len(ro.DeltaSnapList))
+	if ro.Config.DeltaApplyEngine == brtypes.DeltaApplyEngineOffline {
+		r.logger.Infof("Applying delta snapshots offline...")
+		if err := r.applyDeltaSnapshotsOffline(ctx, ro, cp); err != nil {
+			return nil, err
+		}
+	}
+
+	// The embedded etcd is started for the offline engine as well, since callers expect a running
+	// etcd on the restored data directory, e.g. to update the member peer URL or to take a snapshot.
 	r.logger.Infof("Starting an embedded etcd server...")
-	e, err := miscellaneous.StartEmbeddedEtcd(r.logger, &ro)
+	e, err = miscellaneous.StartEmbeddedEtcd(r.logger, &ro)
 	if err != nil {
 		return e, err
 	}
@@ -112,9 +153,11 @@ func (r *Restorer) Restore(ro brtypes.RestoreOptions, m member.Control) (*embed.
 		InsecureTransport:  true,
 	})
 
-	r.logger.Infof("Applying delta snapshots...")
-	if err := r.applyDeltaSnapshots(clientFactory, embeddedEtcdEndpoints, ro); err != nil {
-		return e, err
+	if ro.Config.DeltaApplyEngine != brtypes.DeltaApplyEngineOffline {
+		r.logger.Infof("Applying delta snapshots...")
+		if err := r.applyDeltaSnapshots(ctx, clientFactory, embeddedEtcdEndpoints, ro, cp); err != nil {
+			return e, err
+		}
 	}
 
 	if m != nil {
@@ -136,37 +179,23 @@ func (r *Restorer) Restore(ro brtypes.RestoreOptions, m member.Control) (*embed.
 }
 
 // restoreFromBaseSnapshot restores the etcd data directory from the base snapshot.
-func (r *Restorer) restoreFromBaseSnapshot(ro brtypes.RestoreOptions) error {
+func (r *Restorer) restoreFromBaseSnapshot(ctx context.Context, ro brtypes.RestoreOptions) (err error) {
 	baseSnapshotPath := path.Join(ro.BaseSnapshot.SnapDir, ro.BaseSnapshot.SnapName)
 	if baseSnapshotPath == "" {
 		r.logger.Warnf("Base snapshot path not provided. Will do nothing.")
 		return nil
 	}
+	_, span := tracing.Start(ctx, "restorer.restoreFromBaseSnapshot", tracing.SnapshotAttributes(ro.BaseSnapshot)...)
+	defer func() { tracing.End(span, err) }()
 
 	r.logger.Infof("Restoring from base snapshot: %s", baseSnapshotPath)
+	r.warnIfTakenOfNewerEtcd(*ro.BaseSnapshot, ro.Config.TargetEtcdVersion)
 	startTime := time.Now()
 
-	rc, err := r.store.Fetch(*ro.BaseSnapshot)
-	if err != nil {
-		return fmt.Errorf("failed to fetch the base snapshot from the object store with error: %w", err)
-	}
-	defer func() {
-		if err := rc.Close(); err != nil {
-			r.logger.Errorf("failed to close the base snapshot reader: %v", err)
-		}
-	}()
-
-	// Decompress the snapshot if necessary
 	isCompressed, compressionPolicy, err := compressor.IsSnapshotCompressed(ro.BaseSnapshot.CompressionSuffix)
 	if err != nil {
 		return fmt.Errorf("failed to determine snapshot compression policy: %w", err)
 	}
-	if isCompressed {
-		rc, err = compressor.DecompressSnapshot(rc, compressionPolicy)
-		if err != nil {
-			return fmt.Errorf("unable to decompress the snapshot: %w", err)
-		}
-	}
 
 	// Copy the database snapshot to a temporary file on disk which the restore API will use
 	db, err := os.CreateTemp(ro.Config.TempSnapshotsDir, "snapshot-*.db")
@@ -175,13 +204,21 @@ func (r *Restorer) restoreFromBaseSnapshot(ro brtypes.RestoreOptions) error {
 	}
 	// Clean up the temporary resources required for restoration before exiting to ensure disk is not exhausted
 	defer func() {
+		if err := db.Close(); err != nil {
+			r.logger.Warnf("Failed to close the temporary file of the database, err: %v", err)
+		}
 		if err := os.Remove(db.Name()); err != nil {
 			r.logger.Warnf("Failed to clean up temporary resources allocated for restoration of the database, err: %v", err)
 		}
 	}()
 
-	if _, err := io.Copy(db, rc); err != nil {
-		return fmt.Errorf("failed to copy snapshot data into the temporary file on disk needed for restoration with error: %w", err)
+	if ro.BaseSnapshot.Kind == brtypes.SnapshotKindPageDiff {
+		err = r.fetchPageDiffSnapshot(ro, db, isCompressed, compressionPolicy)
+	} else {
+		err = r.fetchBaseSnapshot(ro, ro.BaseSnapshot, db, isCompressed, compressionPolicy)
+	}
+	if err != nil {
+		return err
 	}
 
 	elapsedTime := time.Since(startTime).Seconds()
@@ -211,8 +248,167 @@ func (r *Restorer) restoreFromBaseSnapshot(ro brtypes.RestoreOptions) error {
 	return nil
 }
 
+// warnIfTakenOfNewerEtcd warns if the base snapshot was taken of a newer version of etcd than the target version of
+// etcd it is restored for, since the data of a newer version of etcd might not be restored correctly. The check is
+// skipped if the target version is unknown.
+func (r *Restorer) warnIfTakenOfNewerEtcd(snap brtypes.Snapshot, targetVersion string) {
+	if len(targetVersion) == 0 {
+		return
+	}
+	target, err := utilversion.ParseGeneric(targetVersion)
+	if err != nil {
+		r.logger.Warnf("Failed to parse the target etcd version %s: %v", targetVersion, err)
+		return
+	}
+
+	metadata := snap.Metadata
+	if metadata == nil {
+		mf, ok := r.store.(brtypes.MetadataFetcher)
+		if !ok {
+			return
+		}
+		if metadata, err = mf.FetchMetadata(snap); err != nil {
+			if errors.Is(err, brtypes.ErrMetadataFetchUnsupported) {
+				return
+			}
+			r.logger.Warnf("Failed to fetch the metadata of the base snapshot %s: %v", snap.SnapName, err)
+			return
+		}
+	}
+	snapVersion, ok := metadata[brtypes.EtcdVersionMetadataKey]
+	if !ok {
+		return
+	}
+	taken, err := utilversion.ParseGeneric(snapVersion)
+	if err != nil {
+		r.logger.Warnf("Failed to parse the etcd version %s of the base snapshot %s: %v", snapVersion, snap.SnapName, err)
+		return
+	}
+	if target.LessThan(taken) {
+		r.logger.Warnf("Base snapshot %s was taken of etcd %s, which is newer than etcd %s it is restored for", snap.SnapName, snapVersion, targetVersion)
+	}
+}
+
+// fetchPageDiffSnapshot fetches the full snapshot the page diff base snapshot was taken against into the given file,
+// and applies the changed pages of the page diff snapshot to it.
+func (r *Restorer) fetchPageDiffSnapshot(ro brtypes.RestoreOptions, db *os.File, isCompressed bool, compressionPolicy string) error {
+	diff, err := os.CreateTemp(ro.Config.TempSnapshotsDir, "snapshot-*.pagediff")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary file for page diff snapshot: %w", err)
+	}
+	defer func() {
+		if err := diff.Close(); err != nil {
+			r.logger.Warnf("Failed to close the temporary file of the page diff snapshot, err: %v", err)
+		}
+		if err := os.Remove(diff.Name()); err != nil {
+			r.logger.Warnf("Failed to clean up the temporary file of the page diff snapshot, err: %v", err)
+		}
+	}()
+
+	if err := r.fetchBaseSnapshot(ro, ro.BaseSnapshot, diff, isCompressed, compressionPolicy); err != nil {
+		return err
+	}
+	if _, err := diff.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read the page diff snapshot: %w", err)
+	}
+	reader, err := pagediff.NewReader(diff)
+	if err != nil {
+		return fmt.Errorf("failed to read the page diff snapshot: %w", err)
+	}
+
+	snapList, err := r.store.List(false)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots to find the full snapshot of the page diff snapshot: %w", err)
+	}
+	var base *brtypes.Snapshot
+	for _, snap := range snapList {
+		if snap.Kind == brtypes.SnapshotKindFull && !snap.IsChunk && snap.SnapName == reader.BaseName() {
+			base = snap
+			break
+		}
+	}
+	if base == nil {
+		return fmt.Errorf("full snapshot %s of the page diff snapshot not found", reader.BaseName())
+	}
+
+	r.logger.Infof("Fetching full snapshot %s of the page diff snapshot", base.SnapName)
+	isBaseCompressed, baseCompressionPolicy, err := compressor.IsSnapshotCompressed(base.CompressionSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to determine snapshot compression policy: %w", err)
+	}
+	if err := r.fetchBaseSnapshot(ro, base, db, isBaseCompressed, baseCompressionPolicy); err != nil {
+		return err
+	}
+	if err := reader.Apply(db); err != nil {
+		return fmt.Errorf("failed to apply the page diff snapshot to its full snapshot: %w", err)
+	}
+	return nil
+}
+
+// fetchBaseSnapshot fetches the base snapshot into the given file and decompresses it if necessary. Large base snapshots
+// are downloaded in parallel parts if the store supports fetching byte ranges. The integrity of the reassembled database
+// is verified by its hash when it is restored, unless the hash check is skipped.
+func (r *Restorer) fetchBaseSnapshot(ro brtypes.RestoreOptions, baseSnapshot *brtypes.Snapshot, db *os.File, isCompressed bool, compressionPolicy string) error {
+	download := db
+	if isCompressed {
+		// compressed snapshots can only be decompressed as a stream, so their parts are reassembled in a separate file first.
+		f, err := os.CreateTemp(ro.Config.TempSnapshotsDir, "snapshot-*.download")
+		if err != nil {
+			return fmt.Errorf("failed to create a temporary file for snapshot download: %w", err)
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				r.logger.Warnf("Failed to close the temporary file of the snapshot download, err: %v", err)
+			}
+			if err := os.Remove(f.Name()); err != nil {
+				r.logger.Warnf("Failed to clean up the temporary file of the snapshot download, err: %v", err)
+			}
+		}()
+		download = f
+	}
+
+	downloaded, err := r.fetchSnapshotParallel(baseSnapshot, download, ro.Config.MaxParallelDownloads, ro.Config.DownloadPartSize)
+	if err != nil {
+		return fmt.Errorf("failed to download the base snapshot from the object store with error: %w", err)
+	}
+
+	var rc io.ReadCloser
+	if downloaded {
+		if !isCompressed {
+			return nil
+		}
+		if _, err := download.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to read the downloaded base snapshot: %w", err)
+		}
+		rc = io.NopCloser(download)
+	} else {
+		rc, err = r.store.Fetch(*baseSnapshot)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the base snapshot from the object store with error: %w", err)
+		}
+		defer func() {
+			if err := rc.Close(); err != nil {
+				r.logger.Errorf("failed to close the base snapshot reader: %v", err)
+			}
+		}()
+	}
+
+	// Decompress the snapshot if necessary
+	if isCompressed {
+		rc, err = compressor.DecompressSnapshot(rc, compressionPolicy)
+		if err != nil {
+			return fmt.Errorf("unable to decompress the snapshot: %w", err)
+		}
+	}
+
+	if _, err := io.Copy(db, rc); err != nil {
+		return fmt.Errorf("failed to copy snapshot data into the temporary file on disk needed for restoration with error: %w", err)
+	}
+	return nil
+}
+
 // applyDeltaSnapshots fetches the events from delta snapshots in parallel and applies them to the embedded etcd sequentially.
-func (r *Restorer) applyDeltaSnapshots(clientFactory client.Factory, endPoints []string, ro brtypes.RestoreOptions) error {
+func (r *Restorer) applyDeltaSnapshots(ctx context.Context, clientFactory client.Factory, endPoints []string, ro brtypes.RestoreOptions, cp *checkpointer) error {
 
 	clientKV, err := clientFactory.NewKV()
 	if err != nil {
@@ -236,19 +432,22 @@ func (r *Restorer) applyDeltaSnapshots(clientFactory client.Factory, endPoints [
 
 	snapList := ro.DeltaSnapList
 	numMaxFetchers := ro.Config.MaxFetchers
+	limits := newTxnLimits(ro.Config)
 
 	firstDeltaSnap := snapList[0]
 
-	if err := r.applyFirstDeltaSnapshot(clientKV, firstDeltaSnap); err != nil {
+	if err := verifyCheckpoint(clientKV, cp); err != nil {
 		return err
 	}
 
-	embeddedEtcdQuotaBytes := float64(ro.Config.EmbeddedEtcdQuotaBytes)
-
-	if err := verifySnapshotRevision(clientKV, snapList[0]); err != nil {
+	if err := r.applyFirstDeltaSnapshot(clientKV, firstDeltaSnap, ro.Config.TempSnapshotsDir, limits); err != nil {
 		return err
 	}
 
+	embeddedEtcdQuotaBytes := float64(ro.Config.EmbeddedEtcdQuotaBytes)
+
+	cp.applied(firstDeltaSnap)
+
 	// no more delta snapshots available
 	if len(snapList) == 1 {
 		return nil
@@ -267,12 +466,13 @@ func (r *Restorer) applyDeltaSnapshots(clientFactory client.Factory, endPoints [
 		stopHandleAlarmCh   = make(chan bool)
 		dbSizeAlarmCh       = make(chan string)
 		dbSizeAlarmDisarmCh = make(chan bool)
+		budget              = newFetchBudget(ro.Config.FetchedSnapshotsBudget, stopCh)
 	)
 
-	go r.applySnaps(clientKV, clientMaintenance, remainingSnaps, dbSizeAlarmCh, dbSizeAlarmDisarmCh, applierInfoCh, errCh, stopCh, &wg, endPoints, embeddedEtcdQuotaBytes)
+	go r.applySnaps(ctx, clientKV, clientMaintenance, remainingSnaps, dbSizeAlarmCh, dbSizeAlarmDisarmCh, applierInfoCh, errCh, stopCh, &wg, endPoints, embeddedEtcdQuotaBytes, limits, budget, cp)
 
 	for f := 0; f < numFetchers; f++ {
-		go r.fetchSnaps(f, fetcherInfoCh, applierInfoCh, snapLocationsCh, errCh, stopCh, &wg, ro.Config.TempSnapshotsDir)
+		go r.fetchSnaps(ctx, f, fetcherInfoCh, applierInfoCh, snapLocationsCh, errCh, stopCh, &wg, ro.Config.TempSnapshotsDir, budget)
 	}
 
 	go r.HandleAlarm(stopHandleAlarmCh, dbSizeAlarmCh, dbSizeAlarmDisarmCh, clientMaintenance)
@@ -333,12 +533,16 @@ func (r *Restorer) cleanup(snapLocationsCh chan string, stopCh chan bool, wg *sy
 	return nil
 }
 
-// fetchSnaps fetches delta snapshots as events and persists them onto disk.
-func (r *Restorer) fetchSnaps(fetcherIndex int, fetcherInfoCh <-chan brtypes.FetcherInfo, applierInfoCh chan<- brtypes.ApplierInfo, snapLocationsCh chan<- string, errCh chan<- error, stopCh chan bool, wg *sync.WaitGroup, tempDir string) {
+// fetchSnaps fetches delta snapshots as events and persists them onto disk, as far as the budget allows.
+// A fetcher stops after its first error, since the restoration fails anyway.
+func (r *Restorer) fetchSnaps(ctx context.Context, fetcherIndex int, fetcherInfoCh <-chan brtypes.FetcherInfo, applierInfoCh chan<- brtypes.ApplierInfo, snapLocationsCh chan<- string, errCh chan<- error, stopCh chan bool, wg *sync.WaitGroup, tempDir string, budget *fetchBudget) {
 	defer wg.Done()
 	wg.Add(1)
 
 	for fetcherInfo := range fetcherInfoCh {
+		if !budget.acquire(fetcherInfo.SnapIndex) {
+			return
+		}
 		select {
 		case _, more := <-stopCh:
 			if !more {
@@ -346,24 +550,33 @@ func (r *Restorer) fetchSnaps(fetcherIndex int, fetcherInfoCh <-chan brtypes.Fet
 			}
 		default:
 			r.logger.Infof("Fetcher #%d fetching delta snapshot %s", fetcherIndex+1, path.Join(fetcherInfo.Snapshot.SnapDir, fetcherInfo.Snapshot.SnapName))
+			_, span := tracing.Start(ctx, "restorer.fetchSnaps", tracing.SnapshotAttributes(&fetcherInfo.Snapshot)...)
 
 			rc, err := r.store.Fetch(fetcherInfo.Snapshot)
 			if err != nil {
+				span.RecordError(err)
 				errCh <- fmt.Errorf("failed to fetch delta snapshot %s from store : %v", fetcherInfo.Snapshot.SnapName, err)
 				applierInfoCh <- brtypes.ApplierInfo{SnapIndex: -1} // cannot use close(ch) as concurrent fetchSnaps routines might try to send on channel, causing a panic
+				tracing.End(span, err)
+				return
 			}
 
 			snapTempFilePath := filepath.Join(tempDir, fetcherInfo.Snapshot.SnapName)
-			if err = persistRawDeltaSnapshot(rc, snapTempFilePath); err != nil {
+			snapLocationsCh <- snapTempFilePath // used for cleanup later
+
+			size, err := persistRawDeltaSnapshot(rc, snapTempFilePath)
+			tracing.End(span, err)
+			if err != nil {
 				errCh <- fmt.Errorf("failed to persist delta snapshot %s to temp file path %s : %v", fetcherInfo.Snapshot.SnapName, snapTempFilePath, err)
 				applierInfoCh <- brtypes.ApplierInfo{SnapIndex: -1}
+				return
 			}
-
-			snapLocationsCh <- snapTempFilePath // used for cleanup later
+			budget.add(size)
 
 			applierInfo := brtypes.ApplierInfo{
 				SnapFilePath: snapTempFilePath,
 				SnapIndex:    fetcherInfo.SnapIndex,
+				SnapFileSize: size,
 			}
 			applierInfoCh <- applierInfo
 		}
@@ -371,7 +584,7 @@ func (r *Restorer) fetchSnaps(fetcherIndex int, fetcherInfoCh <-chan brtypes.Fet
 }
 
 // applySnaps applies delta snapshot events to the embedded etcd sequentially, in the right order of snapshots, regardless of the order in which they were fetched.
-func (r *Restorer) applySnaps(clientKV client.KVCloser, clientMaintenance client.MaintenanceCloser, remainingSnaps brtypes.SnapList, dbSizeAlarmCh chan string, dbSizeAlarmDisarmCh <-chan bool, applierInfoCh <-chan brtypes.ApplierInfo, errCh chan<- error, stopCh <-chan bool, wg *sync.WaitGroup, endPoints []string, embeddedEtcdQuotaBytes float64) {
+func (r *Restorer) applySnaps(ctx context.Context, clientKV client.KVCloser, clientMaintenance client.MaintenanceCloser, remainingSnaps brtypes.SnapList, dbSizeAlarmCh chan string, dbSizeAlarmDisarmCh <-chan bool, applierInfoCh <-chan brtypes.ApplierInfo, errCh chan<- error, stopCh <-chan bool, wg *sync.WaitGroup, endPoints []string, embeddedEtcdQuotaBytes float64, limits txnLimits, budget *fetchBudget, cp *checkpointer) {
 	defer wg.Done()
 	wg.Add(1)
 
@@ -386,6 +599,7 @@ func (r *Restorer) applySnaps(clientKV client.KVCloser, clientMaintenance client
 	prevAttemptToMakeEtcdLeanFailed := false
 
 	pathList := make([]string, len(remainingSnaps))
+	sizeList := make([]int64, len(remainingSnaps))
 	nextSnapIndexToApply := 0
 	for {
 		select {
@@ -400,6 +614,7 @@ func (r *Restorer) applySnaps(clientKV client.KVCloser, clientMaintenance client
 
 			fetchedSnapIndex := applierInfo.SnapIndex
 			pathList[fetchedSnapIndex] = applierInfo.SnapFilePath
+			sizeList[fetchedSnapIndex] = applierInfo.SnapFileSize
 
 			if fetchedSnapIndex < nextSnapIndexToApply {
 				errCh <- fmt.Errorf("snap index mismatch for delta snapshot %d; expected snap index to be atleast %d", fetchedSnapIndex, nextSnapIndexToApply)
@@ -414,29 +629,21 @@ func (r *Restorer) applySnaps(clientKV client.KVCloser, clientMaintenance client
 					filePath := pathList[currSnapIndex]
 					snapName := remainingSnaps[currSnapIndex].SnapName
 
-					r.logger.Infof("Reading snapshot contents %s from raw snapshot file %s", snapName, filePath)
-					eventsData, err := r.readSnapshotContentsFromFile(filePath, remainingSnaps[currSnapIndex])
-					if err != nil {
-						errCh <- fmt.Errorf("failed to read events data from delta snapshot file %s : %v", filePath, err)
-						return
-					}
-
-					var events []brtypes.Event
-					if err = json.Unmarshal(eventsData, &events); err != nil {
-						errCh <- fmt.Errorf("failed to unmarshal events from events data for delta snapshot %s : %v", snapName, err)
-						return
-					}
-
+					_, span := tracing.Start(ctx, "restorer.applySnaps", tracing.SnapshotAttributes(remainingSnaps[currSnapIndex])...)
 					r.logger.Infof("Applying delta snapshot %s [%d/%d]", path.Join(remainingSnaps[currSnapIndex].SnapDir, remainingSnaps[currSnapIndex].SnapName), currSnapIndex+2, len(remainingSnaps)+1)
-					if err := applyEventsAndVerify(clientKV, events, remainingSnaps[currSnapIndex]); err != nil {
+					if err := r.applyDeltaSnapshotFile(clientKV, filePath, remainingSnaps[currSnapIndex], 0, limits); err != nil {
+						tracing.End(span, err)
 						errCh <- err
 						return
 					}
+					tracing.End(span, nil)
+					cp.applied(remainingSnaps[currSnapIndex])
 
 					r.logger.Infof("Removing temporary delta snapshot events file %s for snapshot %s", filePath, snapName)
-					if err = os.Remove(filePath); err != nil {
+					if err := os.Remove(filePath); err != nil {
 						r.logger.Warnf("Unable to remove file: %s; err: %v", filePath, err)
 					}
+					budget.release(currSnapIndex, sizeList[currSnapIndex])
 
 					nextSnapIndexToApply++
 					if nextSnapIndexToApply == len(remainingSnaps) {
@@ -448,7 +655,11 @@ func (r *Restorer) applySnaps(clientKV client.KVCloser, clientMaintenance client
 
 					if numberOfDeltaSnapApplied%periodicallyMakeEtcdLeanDeltaSnapshotInterval == 0 || prevAttemptToMakeEtcdLeanFailed {
 						r.logger.Info("making an embedded etcd lean and check for db size alarm")
-						if err := r.MakeEtcdLeanAndCheckAlarm(int64(remainingSnaps[currSnapIndex].LastRevision), endPoints, embeddedEtcdQuotaBytes, dbSizeAlarmCh, dbSizeAlarmDisarmCh, clientKV, clientMaintenance); err != nil {
+						compactRevision, err := compactionRevision(clientKV, remainingSnaps[currSnapIndex], limits)
+						if err == nil {
+							err = r.MakeEtcdLeanAndCheckAlarm(compactRevision, endPoints, embeddedEtcdQuotaBytes, dbSizeAlarmCh, dbSizeAlarmDisarmCh, clientKV, clientMaintenance)
+						}
+						if err != nil {
 							r.logger.Errorf("unable to make embedded etcd lean: %v", err)
 							r.logger.Warn("etcd mvcc: database space might exceeds its quota limit")
 							r.logger.Info("backup-restore will try again in next attempt...")
@@ -466,49 +677,58 @@ func (r *Restorer) applySnaps(clientKV client.KVCloser, clientMaintenance client
 	}
 }
 
-// applyEventsAndVerify applies events from one snapshot to the embedded etcd and verifies the correctness of the sequence of snapshot applied.
-func applyEventsAndVerify(clientKV client.KVCloser, events []brtypes.Event, snap *brtypes.Snapshot) error {
-	if err := applyEventsToEtcd(clientKV, events); err != nil {
-		return fmt.Errorf("failed to apply events to etcd for delta snapshot %s : %v", snap.SnapName, err)
+// applyDeltaSnapshotFile verifies the delta snapshot persisted to the given file, streams its events after the applied
+// revision to the embedded etcd and verifies the correctness of the sequence of snapshot applied.
+func (r *Restorer) applyDeltaSnapshotFile(clientKV client.KVCloser, filePath string, snap *brtypes.Snapshot, appliedRevision int64, limits txnLimits) error {
+	if err := r.verifyDeltaSnapshotFile(filePath, snap); err != nil {
+		return err
 	}
 
-	if err := verifySnapshotRevision(clientKV, snap); err != nil {
-		return fmt.Errorf("snapshot revision verification failed for delta snapshot %s : %v", snap.SnapName, err)
+	r.logger.Infof("Reading snapshot contents %s from raw snapshot file %s", snap.SnapName, filePath)
+	file, err := os.Open(filePath) // #nosec G304 -- this is a trusted snapshot file.
+	if err != nil {
+		return fmt.Errorf("failed to open file %s for delta snapshot %s : %v", filePath, snap.SnapName, err)
 	}
-	return nil
-}
 
-// applyFirstDeltaSnapshot applies the events from first delta snapshot to etcd.
-func (r *Restorer) applyFirstDeltaSnapshot(clientKV client.KVCloser, snap *brtypes.Snapshot) error {
-	r.logger.Infof("Applying first delta snapshot %s", path.Join(snap.SnapDir, snap.SnapName))
-
-	rc, err := r.store.Fetch(*snap)
+	d, err := r.openDeltaSnapshot(file, snap)
 	if err != nil {
-		return fmt.Errorf("failed to fetch delta snapshot %s from store : %v", snap.SnapName, err)
+		return err
 	}
+	defer d.Close()
 
-	eventsData, err := r.readSnapshotContentsFromReadCloser(rc, snap)
+	lastRevision, err := applyEventsToEtcd(clientKV, d.Decoder, appliedRevision, limits)
 	if err != nil {
-		return fmt.Errorf("failed to read events data from delta snapshot %s : %v", snap.SnapName, err)
+		return fmt.Errorf("failed to apply events to etcd for delta snapshot %s : %v", snap.SnapName, err)
+	}
+
+	if err := verifyAppliedRevision(clientKV, snap, lastRevision, limits); err != nil {
+		return fmt.Errorf("snapshot revision verification failed for delta snapshot %s : %v", snap.SnapName, err)
 	}
+	return nil
+}
 
-	var events []brtypes.Event
-	if err = json.Unmarshal(eventsData, &events); err != nil {
-		return fmt.Errorf("failed to unmarshal events data from delta snapshot %s : %v", snap.SnapName, err)
+// verifyCheckpoint verifies that the embedded etcd contains the revisions recorded in the checkpoint of a resumed restoration.
+func verifyCheckpoint(clientKV client.KVCloser, cp *checkpointer) error {
+	revision, err := latestRevision(clientKV)
+	if err != nil {
+		return err
 	}
+	return cp.verify(revision)
+}
+
+// applyFirstDeltaSnapshot fetches the first delta snapshot to the temporary directory and applies its events to etcd.
+func (r *Restorer) applyFirstDeltaSnapshot(clientKV client.KVCloser, snap *brtypes.Snapshot, tempDir string, limits txnLimits) error {
+	r.logger.Infof("Applying first delta snapshot %s", path.Join(snap.SnapDir, snap.SnapName))
 
 	// Note: Since revision in full snapshot file name might be lower than actual revision stored in snapshot.
 	// This is because of issue referred below. So, as per workaround used in our logic of taking delta snapshot,
 	// the latest revision from full snapshot may overlap with first few revision on first delta snapshot
 	// Hence, we have to additionally take care of that.
 	// Refer: https://github.com/coreos/etcd/issues/9037
-	ctx, cancel := context.WithTimeout(context.TODO(), etcdConnectionTimeout)
-	defer cancel()
-	resp, err := clientKV.Get(ctx, "", clientv3.WithLastRev()...)
+	lastRevision, err := latestRevision(clientKV)
 	if err != nil {
-		return fmt.Errorf("failed to get etcd latest revision: %v", err)
+		return err
 	}
-	lastRevision := resp.Header.Revision
 
 	if lastRevision == snap.LastRevision {
 		// there is no need to apply this fist delta snapshot
@@ -519,53 +739,158 @@ func (r *Restorer) applyFirstDeltaSnapshot(clientKV client.KVCloser, snap *brtyp
 		return nil
 	}
 
-	var newRevisionIndex int
-	for index, event := range events {
-		if event.EtcdEvent.Kv.ModRevision > lastRevision {
-			newRevisionIndex = index
-			break
+	rc, err := r.store.Fetch(*snap)
+	if err != nil {
+		return fmt.Errorf("failed to fetch delta snapshot %s from store : %v", snap.SnapName, err)
+	}
+
+	snapTempFilePath := filepath.Join(tempDir, snap.SnapName)
+	defer func() {
+		if err := os.Remove(snapTempFilePath); err != nil && !os.IsNotExist(err) {
+			r.logger.Warnf("Unable to remove file: %s; err: %v", snapTempFilePath, err)
 		}
+	}()
+	if _, err := persistRawDeltaSnapshot(rc, snapTempFilePath); err != nil {
+		return fmt.Errorf("failed to persist delta snapshot %s to temp file path %s : %v", snap.SnapName, snapTempFilePath, err)
 	}
 
-	r.logger.Infof("Applying first delta snapshot %s", path.Join(snap.SnapDir, snap.SnapName))
+	// the events of revisions which are already part of the full snapshot are skipped.
+	return r.applyDeltaSnapshotFile(clientKV, snapTempFilePath, snap, lastRevision, limits)
+}
+
+// verifyDeltaSnapshotFile reads all events of the delta snapshot persisted to the given file and verifies its hash
+// and the types of its events, so that its events are only applied once the delta snapshot is known to be intact.
+func (r *Restorer) verifyDeltaSnapshotFile(filePath string, snap *brtypes.Snapshot) error {
+	r.logger.Infof("Verifying snapshot contents %s from raw snapshot file %s", snap.SnapName, filePath)
+	file, err := os.Open(filePath) // #nosec G304 -- this is a trusted snapshot file.
+	if err != nil {
+		return fmt.Errorf("failed to open file %s for delta snapshot %s : %v", filePath, snap.SnapName, err)
+	}
+
+	d, err := r.openDeltaSnapshot(file, snap)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
 
-	return applyEventsToEtcd(clientKV, events[newRevisionIndex:])
+	for {
+		e, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to verify delta snapshot %s : %v", snap.SnapName, err)
+		}
+		if t := e.EtcdEvent.Type; t != mvccpb.PUT && t != mvccpb.DELETE {
+			return fmt.Errorf("failed to verify delta snapshot %s : unexpected event type %v", snap.SnapName, t)
+		}
+	}
 }
 
-func persistRawDeltaSnapshot(rc io.ReadCloser, tempFilePath string) error {
+// persistRawDeltaSnapshot persists the raw delta snapshot to the given file and returns its size.
+func persistRawDeltaSnapshot(rc io.ReadCloser, tempFilePath string) (int64, error) {
 	tempFile, err := os.Create(tempFilePath) // #nosec G304 -- this is a trusted filepath for persisting delta snapshots for restoration.
 	if err != nil {
 		err = fmt.Errorf("failed to create temp file %s to store raw delta snapshot", tempFilePath)
-		return err
+		return 0, err
 	}
 	defer func() {
 		_ = tempFile.Close()
 	}()
 
-	_, err = tempFile.ReadFrom(rc)
+	size, err := tempFile.ReadFrom(rc)
 	if err != nil {
-		return err
+		return size, err
+	}
+
+	return size, rc.Close()
+}
+
+// txnLimits are the limits of the embedded etcd for the transactions applying the events of delta snapshots.
+type txnLimits struct {
+	maxTxnOps       int
+	maxRequestBytes int
+	// batchRevisions applies the events in transactions bounded by the limits instead of one transaction per revision.
+	batchRevisions bool
+}
+
+func newTxnLimits(config *brtypes.RestorationConfig) txnLimits {
+	return txnLimits{
+		maxTxnOps:       int(config.MaxTxnOps),       // #nosec G115 -- the limits are far below MaxInt.
+		maxRequestBytes: int(config.MaxRequestBytes), // #nosec G115 -- the limits are far below MaxInt.
+		batchRevisions:  config.BatchDeltaRevisions,
 	}
+}
 
-	return rc.Close()
+// exceeded returns an error naming the limit to raise, if a transaction with the given number of operations
+// and bytes of keys and values exceeds the limits.
+func (l txnLimits) exceeded(ops, opsBytes int) error {
+	if l.maxTxnOps > 0 && ops > l.maxTxnOps {
+		return fmt.Errorf("more than %d operations, increase the max-txn-ops", l.maxTxnOps)
+	}
+	if l.maxRequestBytes > 0 && opsBytes > l.maxRequestBytes {
+		return fmt.Errorf("more than %d bytes, increase the max-request-bytes", l.maxRequestBytes)
+	}
+	return nil
 }
 
-// applyEventsToEtcd performs operations in events sequentially.
-func applyEventsToEtcd(clientKV client.KVCloser, events []brtypes.Event) error {
+// applyEventsToEtcd performs operations in events sequentially while decoding them, and returns the revision of the
+// last event. Events of revisions up to the applied revision are skipped. By default, the events of one revision are
+// applied in one transaction, so that the restored etcd keeps the revisions of the backed up etcd. If the revisions are
+// batched, the events are applied in transactions bounded by the limits instead, coalescing small revisions and
+// splitting large ones. Only the events of one transaction are held in memory.
+func applyEventsToEtcd(clientKV client.KVCloser, d *delta.Decoder, appliedRevision int64, limits txnLimits) (int64, error) {
 	var (
-		lastRev int64
-		ops     = []clientv3.Op{}
-		ctx     = context.TODO()
+		lastRev  int64
+		ops      = []clientv3.Op{}
+		opsBytes int
+		keys     = map[string]struct{}{}
+		ctx      = context.TODO()
 	)
 
-	for _, e := range events {
+	commit := func() error {
+		if len(ops) == 0 {
+			return nil
+		}
+		if _, err := clientKV.Txn(ctx).Then(ops...).Commit(); err != nil {
+			return err
+		}
+		ops, opsBytes = ops[:0], 0
+		clear(keys)
+		return nil
+	}
+
+	for {
+		e, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return lastRev, err
+		}
+
 		ev := e.EtcdEvent
 		nextRev := ev.Kv.ModRevision
-		if lastRev != 0 && nextRev > lastRev {
-			if _, err := clientKV.Txn(ctx).Then(ops...).Commit(); err != nil {
-				return err
+		if nextRev <= appliedRevision {
+			continue
+		}
+
+		evBytes := len(ev.Kv.Key) + len(ev.Kv.Value)
+		if limits.batchRevisions {
+			if err := limits.exceeded(1, evBytes); err != nil {
+				return lastRev, fmt.Errorf("event of revision %d has %v", nextRev, err)
+			}
+			// a transaction must not write a key twice, so a key written again starts the next transaction.
+			_, written := keys[string(ev.Kv.Key)]
+			if written || limits.exceeded(len(ops)+1, opsBytes+evBytes) != nil {
+				if err := commit(); err != nil {
+					return lastRev, err
+				}
+			}
+		} else if lastRev != 0 && nextRev > lastRev {
+			if err := commit(); err != nil {
+				return lastRev, err
 			}
-			ops = []clientv3.Op{}
 		}
 		lastRev = nextRev
 		switch ev.Type {
@@ -575,11 +900,51 @@ func applyEventsToEtcd(clientKV client.KVCloser, events []brtypes.Event) error {
 		case mvccpb.DELETE:
 			ops = append(ops, clientv3.OpDelete(string(ev.Kv.Key)))
 		default:
-			return fmt.Errorf("unexpected event type")
+			return lastRev, fmt.Errorf("unexpected event type")
+		}
+		keys[string(ev.Kv.Key)] = struct{}{}
+		opsBytes += evBytes
+
+		// Without batching, the events of one revision have to be applied in a single transaction, so a revision
+		// exceeding the limits of the embedded etcd cannot be applied at all.
+		if !limits.batchRevisions {
+			if err := limits.exceeded(len(ops), opsBytes); err != nil {
+				return lastRev, fmt.Errorf("revision %d has %v, or set batch-delta-revisions", lastRev, err)
+			}
 		}
 	}
-	_, err := clientKV.Txn(ctx).Then(ops...).Commit()
-	return err
+	return lastRev, commit()
+}
+
+// verifyAppliedRevision verifies the revision of the embedded etcd after applying the given delta snapshot. If the
+// revisions are batched, the embedded etcd does not keep the revisions, so the revision of the last event is verified instead.
+func verifyAppliedRevision(clientKV client.KVCloser, snap *brtypes.Snapshot, lastEventRevision int64, limits txnLimits) error {
+	if !limits.batchRevisions {
+		return verifySnapshotRevision(clientKV, snap)
+	}
+	if snap.LastRevision != lastEventRevision {
+		return fmt.Errorf("mismatched event revision while applying delta snapshot, expected %d but applied %d ", snap.LastRevision, lastEventRevision)
+	}
+	return nil
+}
+
+// latestRevision returns the latest revision of the embedded etcd.
+func latestRevision(clientKV client.KVCloser) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.TODO(), etcdConnectionTimeout)
+	defer cancel()
+	resp, err := clientKV.Get(ctx, "", clientv3.WithLastRev()...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get etcd latest revision: %v", err)
+	}
+	return resp.Header.Revision, nil
+}
+
+// compactionRevision returns the revision the embedded etcd is compacted to after applying the given delta snapshot.
+func compactionRevision(clientKV client.KVCloser, snap *brtypes.Snapshot, limits txnLimits) (int64, error) {
+	if !limits.batchRevisions {
+		return snap.LastRevision, nil
+	}
+	return latestRevision(clientKV)
 }
 
 func verifySnapshotRevision(clientKV client.KVCloser, snap *brtypes.Snapshot) error {
@@ -617,56 +982,42 @@ func getNormalizedSnapshotReadCloser(rc io.ReadCloser, snap *brtypes.Snapshot) (
 	return rc, isCompressed, compressionPolicy, nil
 }
 
-func (r *Restorer) readSnapshotContentsFromReadCloser(rc io.ReadCloser, snap *brtypes.Snapshot) ([]byte, error) {
-	startTime := time.Now()
+// deltaSnapshotReader decodes the events of a delta snapshot as a stream.
+type deltaSnapshotReader struct {
+	*delta.Decoder
+	closers []io.Closer
+}
 
-	rc, wasCompressed, compressionPolicy, err := getNormalizedSnapshotReadCloser(rc, snap)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decompress delta snapshot %s : %v", snap.SnapName, err)
+// Close closes the decompressor and the underlying reader of the delta snapshot.
+func (d *deltaSnapshotReader) Close() error {
+	var errs []error
+	for i := len(d.closers) - 1; i >= 0; i-- {
+		if err := d.closers[i].Close(); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return ErrorArrayToError(errs)
+}
 
-	buf := new(bytes.Buffer)
-	bufSize, err := buf.ReadFrom(rc)
+// openDeltaSnapshot returns a reader decoding the events of the delta snapshot read from rc, decompressing it if required.
+func (r *Restorer) openDeltaSnapshot(rc io.ReadCloser, snap *brtypes.Snapshot) (*deltaSnapshotReader, error) {
+	d := &deltaSnapshotReader{closers: []io.Closer{rc}}
+
+	normalizedRC, wasCompressed, compressionPolicy, err := getNormalizedSnapshotReadCloser(rc, snap)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse contents from delta snapshot %s : %v", snap.SnapName, err)
+		_ = d.Close()
+		return nil, fmt.Errorf("failed to decompress delta snapshot %s : %v", snap.SnapName, err)
 	}
-
-	totalTime := time.Since(startTime).Seconds()
 	if wasCompressed {
-		r.logger.Infof("successfully decompressed data of delta snapshot in %v seconds [CompressionPolicy:%v]", totalTime, compressionPolicy)
-	} else {
-		r.logger.Infof("successfully read the data of delta snapshot in %v seconds", totalTime)
-	}
-
-	if bufSize <= sha256.Size {
-		return nil, fmt.Errorf("delta snapshot is missing hash")
-	}
-
-	sha := buf.Bytes()
-	data := sha[:bufSize-sha256.Size]
-	snapHash := sha[bufSize-sha256.Size:]
-
-	// check for match
-	h := sha256.New()
-	if _, err := h.Write(data); err != nil {
-		return nil, fmt.Errorf("unable to check integrity of snapshot %s: %v", snap.SnapName, err)
-	}
-
-	computedSha := h.Sum(nil)
-	if !reflect.DeepEqual(snapHash, computedSha) {
-		return nil, fmt.Errorf("expected sha256 %v, got %v", snapHash, computedSha)
+		r.logger.Infof("decompressing data of delta snapshot %s [CompressionPolicy:%v]", snap.SnapName, compressionPolicy)
+		d.closers = append(d.closers, normalizedRC)
 	}
 
-	return data, nil
-}
-
-func (r *Restorer) readSnapshotContentsFromFile(filePath string, snap *brtypes.Snapshot) ([]byte, error) {
-	file, err := os.Open(filePath) // #nosec G304 -- this is a trusted snapshot file.
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file %s for delta snapshot %s : %v", filePath, snap.SnapName, err)
+	if d.Decoder, err = delta.NewDecoder(normalizedRC); err != nil {
+		_ = d.Close()
+		return nil, fmt.Errorf("failed to read events from delta snapshot %s : %v", snap.SnapName, err)
 	}
-
-	return r.readSnapshotContentsFromReadCloser(file, snap)
+	return d, nil
 }
 
 // ErrorArrayToError takes an array of errors and returns a single concatenated error
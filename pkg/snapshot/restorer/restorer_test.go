@@ -210,6 +210,173 @@ var _ = Describe("Running Restorer", func() {
 				Expect(err).ShouldNot(HaveOccurred())
 			})
 		})
+
+		Context("with a fetched snapshots budget smaller than a single delta snapshot", func() {
+			It("should restore etcd data directory", func() {
+				restoreOpts.Config.MaxFetchers = 4
+				restoreOpts.Config.FetchedSnapshotsBudget = 1
+
+				err = restorer.RestoreAndStopEtcd(restoreOpts, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				err = utils.CheckDataConsistency(testCtx, restoreOpts.Config.DataDir, keyTo, logger)
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with the base snapshot downloaded in parallel parts", func() {
+			It("should restore etcd data directory", func() {
+				restoreOpts.Config.MaxParallelDownloads = 4
+				restoreOpts.Config.DownloadPartSize = 4 * 1024
+
+				err = restorer.RestoreAndStopEtcd(restoreOpts, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				err = utils.CheckDataConsistency(testCtx, restoreOpts.Config.DataDir, keyTo, logger)
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with the base snapshot downloaded in parallel parts from a fan-out of deduplicating snapstores", func() {
+			It("should restore etcd data directory", func() {
+				dir := GinkgoT().TempDir()
+				names := []string{"dedup-0", "dedup-1"}
+				stores := make([]brtypes.SnapStore, len(names))
+				for i, name := range names {
+					localStore, err := snapstore.NewLocalSnapStore(path.Join(dir, name, "v2"))
+					Expect(err).ShouldNot(HaveOccurred())
+					stores[i] = snapstore.NewDedupSnapStore(localStore, path.Join(dir, name), 1)
+				}
+				fanOut, err := snapstore.NewFanOutSnapStore(stores, names, brtypes.FanOutPolicyAll)
+				Expect(err).ShouldNot(HaveOccurred())
+				for _, snap := range append(brtypes.SnapList{baseSnapshot}, deltaSnapList...) {
+					rc, err := store.Fetch(*snap)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(fanOut.Save(*snap, rc)).Should(Succeed())
+				}
+
+				restoreOpts.BaseSnapshot, restoreOpts.DeltaSnapList, err = miscellaneous.GetLatestFullSnapshotAndDeltaSnapList(fanOut)
+				Expect(err).ShouldNot(HaveOccurred())
+				restorer, err = NewRestorer(fanOut, logger)
+				Expect(err).ShouldNot(HaveOccurred())
+				restoreOpts.Config.MaxParallelDownloads = 4
+				restoreOpts.Config.DownloadPartSize = 4 * 1024
+
+				err = restorer.RestoreAndStopEtcd(restoreOpts, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				err = utils.CheckDataConsistency(testCtx, restoreOpts.Config.DataDir, keyTo, logger)
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with a maximum request size lower than the events of a revision", func() {
+			It("should fail to restore", func() {
+				restoreOpts.Config.MaxRequestBytes = 1
+
+				err = restorer.RestoreAndStopEtcd(restoreOpts, nil)
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("max-request-bytes"))
+			})
+		})
+
+		Context("with batched delta revisions", func() {
+			It("should restore etcd data directory", func() {
+				restoreOpts.Config.BatchDeltaRevisions = true
+				restoreOpts.Config.MaxTxnOps = 2
+
+				err = restorer.RestoreAndStopEtcd(restoreOpts, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				err = utils.CheckDataConsistency(testCtx, restoreOpts.Config.DataDir, keyTo, logger)
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with an interrupted restoration", func() {
+			for _, engine := range []string{brtypes.DeltaApplyEngineEmbeddedEtcd, brtypes.DeltaApplyEngineOffline} {
+				It(fmt.Sprintf("should continue from the last applied delta snapshot with the %s engine", engine), func() {
+					Expect(deltaSnapList.Len()).Should(BeNumerically(">", 2))
+					restoreOpts.Config.DeltaApplyEngine = engine
+
+					// the restoration is interrupted by a delta snapshot which can't be fetched.
+					applied := deltaSnapList.Len() / 2
+					missingSnap := *deltaSnapList[applied]
+					missingSnap.SnapName = "missing-" + missingSnap.SnapName
+					restoreOpts.DeltaSnapList = append(append(brtypes.SnapList{}, deltaSnapList[:applied]...), &missingSnap)
+					err = restorer.RestoreAndStopEtcd(restoreOpts, nil)
+					Expect(err).Should(HaveOccurred())
+					Expect(filepath.Join(tempDir, "restoration-checkpoint.json")).To(BeAnExistingFile())
+
+					restoreOpts.DeltaSnapList = deltaSnapList
+					Expect(restorer.CanResume(restoreOpts)).To(BeTrue())
+					err = restorer.RestoreAndStopEtcd(restoreOpts, nil)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(tempDir).NotTo(BeAnExistingFile())
+
+					err = utils.CheckDataConsistency(testCtx, restoreOpts.Config.DataDir, keyTo, logger)
+					Expect(err).ShouldNot(HaveOccurred())
+				})
+			}
+
+			It("should restore from the base snapshot if the checkpoint is for a different base snapshot", func() {
+				Expect(os.MkdirAll(tempDir, 0700)).To(Succeed())
+				checkpoint := fmt.Sprintf(`{"baseSnapshot":"missing","dataDir":%q,"lastRevision":1}`, etcdDir)
+				Expect(os.WriteFile(filepath.Join(tempDir, "restoration-checkpoint.json"), []byte(checkpoint), 0600)).To(Succeed())
+				Expect(restorer.CanResume(restoreOpts)).To(BeFalse())
+
+				err = restorer.RestoreAndStopEtcd(restoreOpts, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				err = utils.CheckDataConsistency(testCtx, restoreOpts.Config.DataDir, keyTo, logger)
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with offline delta apply engine", func() {
+			var offlineEtcdDir = filepath.Join(outputDir, "offline.etcd")
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(offlineEtcdDir)).To(Succeed())
+			})
+
+			It("should restore an etcd data directory equivalent to the one restored with the embedded etcd", func() {
+				Expect(deltaSnapList.Len()).Should(BeNumerically(">", 1))
+
+				restoreOpts.Config.DeltaApplyEngine = brtypes.DeltaApplyEngineEmbeddedEtcd
+				err = restorer.RestoreAndStopEtcd(restoreOpts, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+				embeddedRevision, embeddedKVs := readAllKVs(etcdDir)
+
+				restoreOpts.Config.DeltaApplyEngine = brtypes.DeltaApplyEngineOffline
+				restoreOpts.Config.DataDir = offlineEtcdDir
+				err = restorer.RestoreAndStopEtcd(restoreOpts, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+				offlineRevision, offlineKVs := readAllKVs(offlineEtcdDir)
+
+				Expect(offlineRevision).To(Equal(deltaSnapList[deltaSnapList.Len()-1].LastRevision))
+				Expect(offlineRevision).To(Equal(embeddedRevision))
+				Expect(offlineKVs).To(Equal(embeddedKVs))
+
+				err = utils.CheckDataConsistency(testCtx, offlineEtcdDir, keyTo, logger)
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+
+			It("should discard the data directory if a delta snapshot was applied only partially", func() {
+				Expect(deltaSnapList.Len()).Should(BeNumerically(">", 1))
+				restoreOpts.Config.DeltaApplyEngine = brtypes.DeltaApplyEngineOffline
+
+				// the revision of the database does not match the last delta snapshot once its events have been applied.
+				lastSnap := *deltaSnapList[deltaSnapList.Len()-1]
+				lastSnap.LastRevision++
+				restoreOpts.DeltaSnapList = append(append(brtypes.SnapList{}, deltaSnapList[:deltaSnapList.Len()-1]...), &lastSnap)
+				err = restorer.RestoreAndStopEtcd(restoreOpts, nil)
+				Expect(err).Should(HaveOccurred())
+				Expect(restoreOpts.Config.DataDir).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(tempDir, "restoration-checkpoint.json")).NotTo(BeAnExistingFile())
+				Expect(restorer.CanResume(restoreOpts)).To(BeFalse())
+			})
+		})
 	})
 
 	Describe("NEGATIVE: Negative Compression Scenarios", func() {
@@ -618,6 +785,17 @@ var _ = Describe("Running Restorer", func() {
 				Expect(err).ShouldNot(HaveOccurred())
 				err = utils.CheckDataConsistency(testCtx, restoreOpts.Config.DataDir, keyTo, logger)
 				Expect(err).ShouldNot(HaveOccurred())
+
+				// the compressed base snapshot is downloaded in parallel parts, and decompressed afterwards.
+				err = os.RemoveAll(etcdDir)
+				Expect(err).ShouldNot(HaveOccurred())
+				restoreOpts.Config.MaxParallelDownloads = 4
+				restoreOpts.Config.DownloadPartSize = 512
+
+				err = restorer.RestoreAndStopEtcd(restoreOpts, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+				err = utils.CheckDataConsistency(testCtx, restoreOpts.Config.DataDir, keyTo, logger)
+				Expect(err).ShouldNot(HaveOccurred())
 			})
 		})
 	})
@@ -1114,6 +1292,31 @@ func corruptEtcdDir() error {
 	return os.RemoveAll(etcdDir)
 }
 
+// readAllKVs starts an embedded etcd on the given data directory and returns its revision and all its key-values.
+func readAllKVs(dir string) (int64, []string) {
+	e, err := utils.StartEmbeddedEtcd(testCtx, dir, logger, utils.DefaultEtcdName, utils.EmbeddedEtcdPortNo)
+	Expect(err).ShouldNot(HaveOccurred())
+	defer func() {
+		e.Server.Stop()
+		e.Close()
+	}()
+
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{e.Clients[0].Addr().String()},
+		DialTimeout: 10 * time.Second,
+	})
+	Expect(err).ShouldNot(HaveOccurred())
+	defer cli.Close()
+
+	resp, err := cli.Get(testCtx, "", clientv3.WithPrefix())
+	Expect(err).ShouldNot(HaveOccurred())
+	kvs := make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		kvs = append(kvs, fmt.Sprintf("%s=%s create_revision=%d mod_revision=%d version=%d", kv.Key, kv.Value, kv.CreateRevision, kv.ModRevision, kv.Version))
+	}
+	return resp.Header.Revision, kvs
+}
+
 // takeValidSnaps saves valid snaps in the v1 prefix dir of snapstore so that restorer could restore from them
 // TODO: Consider removing when backward compatibility no longer needed
 func takeValidSnaps(logger *logrus.Entry, container string, resp *utils.EtcdDataPopulationResponse, deltaSnapshotPeriod time.Duration, endpoints []string, mode int, backupVersion int) error {
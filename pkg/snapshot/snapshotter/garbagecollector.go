@@ -8,9 +8,11 @@ import (
 	"errors"
 	"math"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/gardener/etcd-backup-restore/pkg/metrics"
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
 	"github.com/gardener/etcd-backup-restore/pkg/snapstore"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
@@ -161,10 +163,13 @@ func (ssr *Snapshotter) RunGarbageCollector(stopCh <-chan struct{}) {
 							ssr.logger.Warnf("GC: Failed to delete snapshot %s: %v", path.Join(nextSnap.SnapDir, nextSnap.SnapName), err)
 							metrics.SnapshotterOperationFailure.With(prometheus.Labels{metrics.LabelError: err.Error()}).Inc()
 							metrics.GCSnapshotCounter.With(prometheus.Labels{metrics.LabelKind: brtypes.SnapshotKindFull, metrics.LabelSucceeded: metrics.ValueSucceededFalse}).Inc()
+							notifyGarbageCollection(nextSnap, err)
 							continue
 						}
 						metrics.GCSnapshotCounter.With(prometheus.Labels{metrics.LabelKind: brtypes.SnapshotKindFull, metrics.LabelSucceeded: metrics.ValueSucceededTrue}).Inc()
+						notifyGarbageCollection(nextSnap, nil)
 						total++
+						total += ssr.garbageCollectPageDiffSnapshots(snapStream)
 					}
 				}
 
@@ -195,10 +200,13 @@ func (ssr *Snapshotter) RunGarbageCollector(stopCh <-chan struct{}) {
 							ssr.logger.Warnf("GC: Failed to delete snapshot %s: %v", snapPath, err)
 							metrics.SnapshotterOperationFailure.With(prometheus.Labels{metrics.LabelError: err.Error()}).Inc()
 							metrics.GCSnapshotCounter.With(prometheus.Labels{metrics.LabelKind: brtypes.SnapshotKindFull, metrics.LabelSucceeded: metrics.ValueSucceededFalse}).Inc()
+							notifyGarbageCollection(snap, err)
 							continue
 						}
 						metrics.GCSnapshotCounter.With(prometheus.Labels{metrics.LabelKind: brtypes.SnapshotKindFull, metrics.LabelSucceeded: metrics.ValueSucceededTrue}).Inc()
+						notifyGarbageCollection(snap, nil)
 						total++
+						total += ssr.garbageCollectPageDiffSnapshots(snapStream)
 					}
 				}
 			}
@@ -262,7 +270,7 @@ func (ssr *Snapshotter) GarbageCollectChunks(snapList brtypes.SnapList) (int, br
 }
 
 /*
-GarbageCollectDeltaSnapshots traverses the list of snapshots and removes delta snapshots that are older than the retention period specified in the Snapshotter's configuration.
+GarbageCollectDeltaSnapshots traverses the list of snapshots and removes delta and page diff snapshots that are older than the retention period specified in the Snapshotter's configuration.
 
 Parameters:
 
@@ -270,18 +278,37 @@ Parameters:
 
 Returns:
 
-	int - Total number of delta snapshots deleted.
+	int - Total number of delta and page diff snapshots deleted.
 	error - Error information, if any error occurred during the garbage collection. Returns 'nil' if operation is successful.
 */
 func (ssr *Snapshotter) GarbageCollectDeltaSnapshots(snapStream brtypes.SnapList) (int, error) {
-	totalDeleted := 0
 	cutoffTime := time.Now().UTC().Add(-ssr.config.DeltaSnapshotRetentionPeriod.Duration)
+	// page diff snapshots are retained like delta snapshots, as they are based on the full snapshot of the snapStream.
+	return ssr.garbageCollectIncrementalSnapshots(snapStream, func(snap *brtypes.Snapshot) bool {
+		return (snap.Kind == brtypes.SnapshotKindDelta || snap.Kind == brtypes.SnapshotKindPageDiff) && snap.CreatedOn.Before(cutoffTime)
+	})
+}
+
+// garbageCollectPageDiffSnapshots removes all page diff snapshots of the snapStream, whose full snapshot has been deleted.
+func (ssr *Snapshotter) garbageCollectPageDiffSnapshots(snapStream brtypes.SnapList) int {
+	totalDeleted, err := ssr.garbageCollectIncrementalSnapshots(snapStream, func(snap *brtypes.Snapshot) bool {
+		return snap.Kind == brtypes.SnapshotKindPageDiff
+	})
+	if err != nil {
+		ssr.logger.Warnf("GC: Failed to delete page diff snapshots of deleted full snapshot: %v", err)
+	}
+	return totalDeleted
+}
+
+// garbageCollectIncrementalSnapshots removes the snapshots of the snapStream selected by the given function.
+func (ssr *Snapshotter) garbageCollectIncrementalSnapshots(snapStream brtypes.SnapList, selected func(snap *brtypes.Snapshot) bool) (int, error) {
+	totalDeleted := 0
 	var finalError error
 	for i, errorCount := len(snapStream)-1, 0; i >= 0; i-- {
-		if (*snapStream[i]).Kind == brtypes.SnapshotKindDelta && snapStream[i].CreatedOn.Before(cutoffTime) {
+		if !snapStream[i].IsChunk && selected(snapStream[i]) {
 
 			snapPath := path.Join(snapStream[i].SnapDir, snapStream[i].SnapName)
-			ssr.logger.Infof("GC: Deleting old delta snapshot: %s", snapPath)
+			ssr.logger.Infof("GC: Deleting old %s snapshot: %s", strings.ToLower(snapStream[i].Kind), snapPath)
 			if !snapStream[i].IsDeletable() {
 				ssr.logger.Infof("GC: Skipping the snapshot: %s, since its immutability period hasn't expired yet", snapPath)
 				continue
@@ -294,13 +321,15 @@ func (ssr *Snapshotter) GarbageCollectDeltaSnapshots(snapStream brtypes.SnapList
 				errorCount++
 				ssr.logger.Warnf("GC: Failed to delete snapshot %s: %v", snapPath, err)
 				metrics.SnapshotterOperationFailure.With(prometheus.Labels{metrics.LabelError: err.Error()}).Inc()
-				metrics.GCSnapshotCounter.With(prometheus.Labels{metrics.LabelKind: brtypes.SnapshotKindDelta, metrics.LabelSucceeded: metrics.ValueSucceededFalse}).Inc()
+				metrics.GCSnapshotCounter.With(prometheus.Labels{metrics.LabelKind: snapStream[i].Kind, metrics.LabelSucceeded: metrics.ValueSucceededFalse}).Inc()
+				notifyGarbageCollection(snapStream[i], err)
 				finalError = errors.Join(finalError, err)
 				if errorCount == DeltaSnapshotGCErrorThreshold {
 					return totalDeleted, finalError
 				}
 			} else {
-				metrics.GCSnapshotCounter.With(prometheus.Labels{metrics.LabelKind: brtypes.SnapshotKindDelta, metrics.LabelSucceeded: metrics.ValueSucceededTrue}).Inc()
+				metrics.GCSnapshotCounter.With(prometheus.Labels{metrics.LabelKind: snapStream[i].Kind, metrics.LabelSucceeded: metrics.ValueSucceededTrue}).Inc()
+				notifyGarbageCollection(snapStream[i], nil)
 				totalDeleted++
 			}
 		}
@@ -308,3 +337,13 @@ func (ssr *Snapshotter) GarbageCollectDeltaSnapshots(snapStream brtypes.SnapList
 
 	return totalDeleted, finalError
 }
+
+// notifyGarbageCollection notifies about the deletion of the snapshot by the garbage collector.
+// Chunks are not notified, since they are deleted along with the snapshot they belong to.
+func notifyGarbageCollection(snap *brtypes.Snapshot, err error) {
+	if err != nil {
+		notifier.Notify(notifier.Event{Type: notifier.EventGarbageCollectionFailed, Data: notifier.EventData{Kind: snap.Kind, SnapName: snap.SnapName, SnapDir: snap.SnapDir, Error: err.Error()}})
+		return
+	}
+	notifier.Notify(notifier.Event{Type: notifier.EventGarbageCollectionDeleted, Data: notifier.EventData{Kind: snap.Kind, SnapName: snap.SnapName, SnapDir: snap.SnapDir, StartRevision: snap.StartRevision, LastRevision: snap.LastRevision}})
+}
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapshotter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gardener/etcd-backup-restore/pkg/etcdutil"
+	etcdclient "github.com/gardener/etcd-backup-restore/pkg/etcdutil/client"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+)
+
+// etcdMetadataKeys are the keys of the metadata of the etcd cluster, which is stored with full snapshots.
+var etcdMetadataKeys = []string{brtypes.EtcdVersionMetadataKey, brtypes.EtcdClusterIDMetadataKey}
+
+// metadataSnapStore wraps a snapstore and stores the metadata of the etcd cluster with full and page diff snapshots.
+type metadataSnapStore struct {
+	passthroughSnapStore
+	metadata map[string]string
+}
+
+// Save saves the snapshot with the metadata of the etcd cluster, if it is a full or page diff snapshot.
+func (m *metadataSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) error {
+	if snap.Kind == brtypes.SnapshotKindFull || snap.Kind == brtypes.SnapshotKindPageDiff {
+		snap.Metadata = m.metadata
+	}
+	return m.SnapStore.Save(snap, rc)
+}
+
+// getEtcdMetadata returns the version of the etcd server and the ID of the etcd cluster.
+func (ssr *Snapshotter) getEtcdMetadata(ctx context.Context, clientMaintenance etcdclient.MaintenanceCloser) (map[string]string, error) {
+	if len(ssr.etcdConnectionConfig.Endpoints) == 0 {
+		return nil, fmt.Errorf("etcd endpoints are not passed correctly")
+	}
+	ctx, cancel := context.WithTimeout(ctx, ssr.etcdConnectionConfig.ConnectionTimeout.Duration)
+	defer cancel()
+	status, err := clientMaintenance.Status(ctx, ssr.etcdConnectionConfig.Endpoints[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to get etcd status: %w", err)
+	}
+	return map[string]string{
+		brtypes.EtcdVersionMetadataKey:   status.Version,
+		brtypes.EtcdClusterIDMetadataKey: strconv.FormatUint(status.Header.GetClusterId(), 16),
+	}, nil
+}
+
+// hasEtcdMetadataChanged checks whether the version of the etcd server or the ID of the etcd cluster differ from the
+// ones stored with the previous full snapshot, e.g. because etcd was upgraded or the etcd cluster was recreated.
+// Snapshots without the metadata, and snapstores which do not store it, are never considered to be changed.
+func (ssr *Snapshotter) hasEtcdMetadataChanged() bool {
+	if ssr.PrevFullSnapshot.Metadata == nil {
+		mf, ok := ssr.store.(brtypes.MetadataFetcher)
+		if !ok {
+			return false
+		}
+		metadata, err := mf.FetchMetadata(*ssr.PrevFullSnapshot)
+		if errors.Is(err, brtypes.ErrMetadataFetchUnsupported) {
+			return false
+		}
+		if err != nil {
+			ssr.logger.Warnf("Failed to fetch the metadata of the previous full snapshot %s: %v", ssr.PrevFullSnapshot.SnapName, err)
+			return false
+		}
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		ssr.PrevFullSnapshot.Metadata = metadata
+	}
+	if len(ssr.PrevFullSnapshot.Metadata) == 0 {
+		return false
+	}
+
+	clientMaintenance, err := etcdutil.NewFactory(*ssr.etcdConnectionConfig).NewMaintenance()
+	if err != nil {
+		ssr.logger.Warnf("Failed to create etcd maintenance client: %v", err)
+		return false
+	}
+	defer clientMaintenance.Close()
+	metadata, err := ssr.getEtcdMetadata(context.TODO(), clientMaintenance)
+	if err != nil {
+		ssr.logger.Warnf("Failed to get the metadata of the etcd cluster: %v", err)
+		return false
+	}
+
+	for _, key := range etcdMetadataKeys {
+		prevValue, ok := ssr.PrevFullSnapshot.Metadata[key]
+		if !ok || prevValue == metadata[key] {
+			continue
+		}
+		ssr.logger.Infof("Metadata %s of the previous full snapshot %s is %q, but etcd reports %q", key, ssr.PrevFullSnapshot.SnapName, prevValue, metadata[key])
+		return true
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapshotter
+
+import (
+	"io"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+)
+
+// passthroughSnapStore is embedded by the snapstores wrapping the snapstore of the snapshotter. It passes the ranged
+// and metadata fetches through to the wrapped snapstore, since they are not part of brtypes.SnapStore and would be lost
+// by the wrapping otherwise.
+type passthroughSnapStore struct {
+	brtypes.SnapStore
+}
+
+// Size returns the size of the snapshot, if the underlying snapstore can fetch byte ranges of snapshots.
+func (p *passthroughSnapStore) Size(snap brtypes.Snapshot) (int64, error) {
+	rf, ok := p.SnapStore.(brtypes.RangeFetcher)
+	if !ok {
+		return -1, brtypes.ErrRangeFetchUnsupported
+	}
+	return rf.Size(snap)
+}
+
+// FetchRange fetches a byte range of the snapshot, if the underlying snapstore can fetch byte ranges of snapshots.
+func (p *passthroughSnapStore) FetchRange(snap brtypes.Snapshot, offset, length int64) (io.ReadCloser, error) {
+	rf, ok := p.SnapStore.(brtypes.RangeFetcher)
+	if !ok {
+		return nil, brtypes.ErrRangeFetchUnsupported
+	}
+	return rf.FetchRange(snap, offset, length)
+}
+
+// FetchMetadata fetches the metadata of the snapshot, if the underlying snapstore stores it.
+func (p *passthroughSnapStore) FetchMetadata(snap brtypes.Snapshot) (map[string]string, error) {
+	mf, ok := p.SnapStore.(brtypes.MetadataFetcher)
+	if !ok {
+		return nil, brtypes.ErrMetadataFetchUnsupported
+	}
+	return mf.FetchMetadata(snap)
+}
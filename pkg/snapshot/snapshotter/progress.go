@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapshotter
+
+import (
+	"io"
+	"sync/atomic"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+)
+
+// progressSnapStore wraps a snapstore and counts the bytes of the snapshot being saved by an operation.
+type progressSnapStore struct {
+	passthroughSnapStore
+	uploadedBytes *atomic.Int64
+}
+
+// newProgressSnapStore returns the snapstore which counts the bytes uploaded into the given counter,
+// or the snapstore itself if the counter is nil.
+func newProgressSnapStore(store brtypes.SnapStore, uploadedBytes *atomic.Int64) brtypes.SnapStore {
+	if uploadedBytes == nil {
+		return store
+	}
+	return &progressSnapStore{passthroughSnapStore: passthroughSnapStore{SnapStore: store}, uploadedBytes: uploadedBytes}
+}
+
+// Save resets the byte counter and saves the snapshot, counting the bytes uploaded by the underlying snapstore.
+func (p *progressSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) error {
+	p.uploadedBytes.Store(0)
+	return p.SnapStore.Save(snap, &countingReadCloser{ReadCloser: rc, count: p.uploadedBytes})
+}
+
+// countingReadCloser counts the bytes read from the snapshot as uploaded, unless the snapstore stages the snapshot
+// before uploading it and counts the bytes it uploads instead.
+type countingReadCloser struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.count.Add(int64(n))
+	return n, err
+}
+
+// StagingReader returns the underlying reader, whose bytes are not counted as uploaded.
+func (c *countingReadCloser) StagingReader() io.ReadCloser {
+	return c.ReadCloser
+}
+
+// AddUploadedBytes counts the given number of bytes as uploaded.
+func (c *countingReadCloser) AddUploadedBytes(n int64) {
+	c.count.Add(n)
+}
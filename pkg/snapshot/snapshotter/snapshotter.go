@@ -7,23 +7,27 @@ package snapshotter
 import (
 	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/json"
 	"fmt"
 	"io"
 	"path"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gardener/etcd-backup-restore/pkg/compressor"
 	"github.com/gardener/etcd-backup-restore/pkg/errors"
 	"github.com/gardener/etcd-backup-restore/pkg/etcdutil"
+	etcdclient "github.com/gardener/etcd-backup-restore/pkg/etcdutil/client"
 	"github.com/gardener/etcd-backup-restore/pkg/health/heartbeat"
 	"github.com/gardener/etcd-backup-restore/pkg/metrics"
 	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+	"github.com/gardener/etcd-backup-restore/pkg/notifier"
+	"github.com/gardener/etcd-backup-restore/pkg/snapshot/delta"
+	"github.com/gardener/etcd-backup-restore/pkg/snapshot/pagediff"
 	"github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 	"github.com/gardener/etcd-backup-restore/pkg/wrappers"
 
@@ -47,17 +51,18 @@ var (
 	emptyStruct struct{}
 )
 
-// event is wrapper over etcd event to keep track of time of event
-type event struct {
-	EtcdEvent *clientv3.Event `json:"etcdEvent"`
-	Time      time.Time       `json:"time"`
-}
-
 type result struct {
 	Snapshot *brtypes.Snapshot `json:"snapshot"`
 	Err      error             `json:"error"`
 }
 
+// snapshotRequest is a request to take an out-of-schedule snapshot. The bytes uploaded are counted into
+// uploadedBytes, if set.
+type snapshotRequest struct {
+	uploadedBytes *atomic.Int64
+	isFinal       bool
+}
+
 // NewSnapshotterConfig returns the snapshotter config.
 func NewSnapshotterConfig() *brtypes.SnapshotterConfig {
 	return &brtypes.SnapshotterConfig{
@@ -67,6 +72,7 @@ func NewSnapshotterConfig() *brtypes.SnapshotterConfig {
 		GarbageCollectionPeriod:  wrappers.Duration{Duration: brtypes.DefaultGarbageCollectionPeriod},
 		GarbageCollectionPolicy:  brtypes.GarbageCollectionPolicyExponential,
 		MaxBackups:               brtypes.DefaultMaxBackups,
+		DeltaSnapshotFormat:      brtypes.DefaultDeltaSnapshotFormat,
 	}
 }
 
@@ -82,8 +88,8 @@ type Snapshotter struct {
 	PrevSnapshot                 *brtypes.Snapshot
 	PrevFullSnapshot             *brtypes.Snapshot
 	etcdConnectionConfig         *brtypes.EtcdConnectionConfig
-	fullSnapshotReqCh            chan bool
-	deltaSnapshotReqCh           chan struct{}
+	fullSnapshotReqCh            chan snapshotRequest
+	deltaSnapshotReqCh           chan snapshotRequest
 	fullSnapshotAckCh            chan result
 	deltaSnapshotAckCh           chan result
 	logger                       *logrus.Entry
@@ -95,11 +101,15 @@ type Snapshotter struct {
 	cancelWatch                  context.CancelFunc
 	SsrStateMutex                *sync.Mutex
 	config                       *brtypes.SnapshotterConfig
-	events                       []byte
+	events                       *delta.Encoder
 	PrevDeltaSnapshots           brtypes.SnapList
 	lastEventRevision            int64
 	SsrState                     brtypes.SnapshotterState
 	PrevFullSnapshotSucceeded    bool
+	// pageDiffBase is the full snapshot page diff snapshots are taken against, together with its page hashes.
+	pageDiffBase      *brtypes.Snapshot
+	pageDiffHashes    *pagediff.Hashes
+	pageDiffSnapshots uint
 }
 
 // NewSnapshotter returns the snapshotter object.
@@ -130,6 +140,11 @@ func NewSnapshotter(logger *logrus.Entry, config *brtypes.SnapshotterConfig, sto
 
 	metrics.LatestSnapshotRevision.With(prometheus.Labels{metrics.LabelKind: prevSnapshot.Kind}).Set(float64(prevSnapshot.LastRevision))
 
+	events, err := delta.NewEncoder(config.DeltaSnapshotFormat)
+	if err != nil {
+		return nil, err
+	}
+
 	//Attempt to create clientset only if `enable-snapshot-lease-renewal` flag of healthConfig is set
 	var clientSet client.Client
 	if healthConfig.SnapshotLeaseRenewalEnabled {
@@ -143,6 +158,7 @@ func NewSnapshotter(logger *logrus.Entry, config *brtypes.SnapshotterConfig, sto
 		logger:                    logger.WithField("actor", "snapshotter"),
 		store:                     store,
 		config:                    config,
+		events:                    events,
 		etcdConnectionConfig:      etcdConnectionConfig,
 		compressionConfig:         compressionConfig,
 		HealthConfig:              healthConfig,
@@ -152,8 +168,8 @@ func NewSnapshotter(logger *logrus.Entry, config *brtypes.SnapshotterConfig, sto
 		PrevDeltaSnapshots:        deltaSnapList,
 		SsrState:                  brtypes.SnapshotterInactive,
 		SsrStateMutex:             &sync.Mutex{},
-		fullSnapshotReqCh:         make(chan bool),
-		deltaSnapshotReqCh:        make(chan struct{}),
+		fullSnapshotReqCh:         make(chan snapshotRequest),
+		deltaSnapshotReqCh:        make(chan snapshotRequest),
 		fullSnapshotAckCh:         make(chan result),
 		deltaSnapshotAckCh:        make(chan result),
 		cancelWatch:               func() {},
@@ -203,7 +219,13 @@ func (ssr *Snapshotter) Run(stopCh <-chan struct{}, startWithFullSnapshot bool)
 
 // TriggerFullSnapshot sends the events to take full snapshot. This is to
 // trigger full snapshot externally out of regular schedule.
-func (ssr *Snapshotter) TriggerFullSnapshot(_ context.Context, isFinal bool) (*brtypes.Snapshot, error) {
+func (ssr *Snapshotter) TriggerFullSnapshot(ctx context.Context, isFinal bool) (*brtypes.Snapshot, error) {
+	return ssr.TriggerFullSnapshotWithProgress(ctx, isFinal, nil)
+}
+
+// TriggerFullSnapshotWithProgress triggers an out-of-schedule full snapshot like TriggerFullSnapshot, and counts
+// the bytes of the snapshot uploaded to the snapstore into uploadedBytes.
+func (ssr *Snapshotter) TriggerFullSnapshotWithProgress(_ context.Context, isFinal bool, uploadedBytes *atomic.Int64) (*brtypes.Snapshot, error) {
 	ssr.SsrStateMutex.Lock()
 	defer ssr.SsrStateMutex.Unlock()
 
@@ -211,7 +233,7 @@ func (ssr *Snapshotter) TriggerFullSnapshot(_ context.Context, isFinal bool) (*b
 		return nil, fmt.Errorf("snapshotter is not active")
 	}
 	ssr.logger.Info("Triggering out of schedule full snapshot...")
-	ssr.fullSnapshotReqCh <- isFinal
+	ssr.fullSnapshotReqCh <- snapshotRequest{isFinal: isFinal, uploadedBytes: uploadedBytes}
 	res := <-ssr.fullSnapshotAckCh
 	return res.Snapshot, res.Err
 }
@@ -219,6 +241,12 @@ func (ssr *Snapshotter) TriggerFullSnapshot(_ context.Context, isFinal bool) (*b
 // TriggerDeltaSnapshot sends the events to take delta snapshot. This is to
 // trigger delta snapshot externally out of regular schedule.
 func (ssr *Snapshotter) TriggerDeltaSnapshot() (*brtypes.Snapshot, error) {
+	return ssr.TriggerDeltaSnapshotWithProgress(nil)
+}
+
+// TriggerDeltaSnapshotWithProgress triggers an out-of-schedule delta snapshot like TriggerDeltaSnapshot, and counts
+// the bytes of the snapshot uploaded to the snapstore into uploadedBytes.
+func (ssr *Snapshotter) TriggerDeltaSnapshotWithProgress(uploadedBytes *atomic.Int64) (*brtypes.Snapshot, error) {
 	ssr.SsrStateMutex.Lock()
 	defer ssr.SsrStateMutex.Unlock()
 
@@ -229,7 +257,7 @@ func (ssr *Snapshotter) TriggerDeltaSnapshot() (*brtypes.Snapshot, error) {
 		return nil, fmt.Errorf("found delta snapshot interval %s less than %v. Delta snapshotting is disabled. ", ssr.config.DeltaSnapshotPeriod.Duration, time.Duration(brtypes.DeltaSnapshotIntervalThreshold))
 	}
 	ssr.logger.Info("Triggering out of schedule delta snapshot...")
-	ssr.deltaSnapshotReqCh <- emptyStruct
+	ssr.deltaSnapshotReqCh <- snapshotRequest{uploadedBytes: uploadedBytes}
 	res := <-ssr.deltaSnapshotAckCh
 	return res.Snapshot, res.Err
 }
@@ -288,8 +316,14 @@ func (ssr *Snapshotter) closeEtcdClient() {
 // TakeFullSnapshotAndResetTimer takes a full snapshot and resets the full snapshot
 // timer as per the schedule.
 func (ssr *Snapshotter) TakeFullSnapshotAndResetTimer(isFinal bool) (*brtypes.Snapshot, error) {
+	return ssr.takeFullSnapshotAndResetTimer(isFinal, false, nil)
+}
+
+// takeFullSnapshotAndResetTimer takes a full snapshot, or a page diff snapshot if allowed and configured,
+// and resets the full snapshot timer as per the schedule. The bytes uploaded are counted into uploadedBytes, if set.
+func (ssr *Snapshotter) takeFullSnapshotAndResetTimer(isFinal, allowPageDiff bool, uploadedBytes *atomic.Int64) (*brtypes.Snapshot, error) {
 	ssr.logger.Infof("Taking scheduled full snapshot for time: %s", time.Now().Local())
-	s, err := ssr.takeFullSnapshot(isFinal)
+	s, err := ssr.takeFullSnapshot(isFinal, allowPageDiff, uploadedBytes)
 	if err != nil {
 		// As per design principle, in business critical service if backup is not working,
 		// it's better to fail the process. So, we are quiting here.
@@ -303,7 +337,9 @@ func (ssr *Snapshotter) TakeFullSnapshotAndResetTimer(isFinal bool) (*brtypes.Sn
 // takeFullSnapshot will store full snapshot of etcd to brtypes.
 // It basically will connect to etcd. Then ask for snapshot. And finally
 // store it to underlying snapstore on the fly.
-func (ssr *Snapshotter) takeFullSnapshot(isFinal bool) (*brtypes.Snapshot, error) {
+// A page diff snapshot against the previous full snapshot is taken instead, if allowed and configured.
+// The bytes uploaded are counted into uploadedBytes, if set.
+func (ssr *Snapshotter) takeFullSnapshot(isFinal, allowPageDiff bool, uploadedBytes *atomic.Int64) (*brtypes.Snapshot, error) {
 	defer ssr.cleanupInMemoryEvents()
 	// close previous watch and client.
 	ssr.closeEtcdClient()
@@ -363,10 +399,16 @@ func (ssr *Snapshotter) takeFullSnapshot(isFinal bool) (*brtypes.Snapshot, error
 		}
 		defer clientMaintenance.Close()
 
-		s, err := etcdutil.TakeAndSaveFullSnapshot(ctx, clientMaintenance, ssr.store, ssr.snapstoreConfig.TempDir, lastRevision, ssr.compressionConfig, compressionSuffix, isFinal, ssr.logger)
+		metadata, err := ssr.getEtcdMetadata(context.TODO(), clientMaintenance)
+		if err != nil {
+			ssr.logger.Warnf("Saving full snapshot without the metadata of the etcd cluster: %v", err)
+		}
+
+		s, err := ssr.takeAndSaveFullSnapshot(ctx, clientMaintenance, metadata, lastRevision, compressionSuffix, isFinal, allowPageDiff, uploadedBytes)
 		if err != nil {
 			return nil, err
 		}
+		s.Metadata = metadata
 
 		ssr.PrevSnapshot = s
 		ssr.PrevFullSnapshot = s
@@ -406,13 +448,39 @@ func (ssr *Snapshotter) takeFullSnapshot(isFinal bool) (*brtypes.Snapshot, error
 	return ssr.PrevSnapshot, nil
 }
 
+// takeAndSaveFullSnapshot takes and saves a page diff snapshot against the previous full snapshot if allowed and
+// the configured number of page diff snapshots has not been reached yet, and a full snapshot otherwise.
+// The snapshot is saved with the given metadata of the etcd cluster, and the bytes uploaded are counted into uploadedBytes, if set.
+func (ssr *Snapshotter) takeAndSaveFullSnapshot(ctx context.Context, clientMaintenance etcdclient.MaintenanceCloser, metadata map[string]string, lastRevision int64, compressionSuffix string, isFinal, allowPageDiff bool, uploadedBytes *atomic.Int64) (*brtypes.Snapshot, error) {
+	store := newProgressSnapStore(&metadataSnapStore{passthroughSnapStore: passthroughSnapStore{SnapStore: ssr.store}, metadata: metadata}, uploadedBytes)
+	if ssr.config.MaxPageDiffSnapshots == 0 {
+		return etcdutil.TakeAndSaveFullSnapshot(ctx, clientMaintenance, store, ssr.snapstoreConfig.TempDir, lastRevision, ssr.compressionConfig, compressionSuffix, isFinal, ssr.logger)
+	}
+
+	if allowPageDiff && !isFinal && ssr.pageDiffBase != nil && ssr.pageDiffSnapshots < ssr.config.MaxPageDiffSnapshots {
+		s, err := etcdutil.TakeAndSavePageDiffSnapshot(ctx, clientMaintenance, store, ssr.snapstoreConfig.TempDir, lastRevision, ssr.compressionConfig, compressionSuffix, ssr.pageDiffBase, ssr.pageDiffHashes, ssr.logger)
+		if err == nil {
+			ssr.pageDiffSnapshots++
+			return s, nil
+		}
+		ssr.logger.Warnf("Taking page diff snapshot against full snapshot %s failed, taking full snapshot instead: %v", ssr.pageDiffBase.SnapName, err)
+	}
+
+	s, hashes, err := etcdutil.TakeAndSaveFullSnapshotWithPageHashes(ctx, clientMaintenance, store, ssr.snapstoreConfig.TempDir, lastRevision, ssr.compressionConfig, compressionSuffix, isFinal, ssr.logger)
+	if err != nil {
+		return nil, err
+	}
+	ssr.pageDiffBase, ssr.pageDiffHashes, ssr.pageDiffSnapshots = s, hashes, 0
+	return s, nil
+}
+
 func (ssr *Snapshotter) cleanupInMemoryEvents() {
-	ssr.events = []byte{}
+	ssr.events.Reset()
 	ssr.lastEventRevision = -1
 }
 
-func (ssr *Snapshotter) takeDeltaSnapshotAndResetTimer() (*brtypes.Snapshot, error) {
-	s, err := ssr.TakeDeltaSnapshot()
+func (ssr *Snapshotter) takeDeltaSnapshotAndResetTimer(uploadedBytes *atomic.Int64) (*brtypes.Snapshot, error) {
+	s, err := ssr.takeDeltaSnapshot(uploadedBytes)
 	if err != nil {
 		// As per design principle, in business critical service if backup is not working,
 		// it's better to fail the process. So, we are quiting here.
@@ -434,15 +502,20 @@ func (ssr *Snapshotter) takeDeltaSnapshotAndResetTimer() (*brtypes.Snapshot, err
 // TakeDeltaSnapshot takes a delta snapshot that contains
 // the etcd events collected up till now
 func (ssr *Snapshotter) TakeDeltaSnapshot() (*brtypes.Snapshot, error) {
+	return ssr.takeDeltaSnapshot(nil)
+}
+
+// takeDeltaSnapshot takes a delta snapshot that contains the etcd events collected up till now,
+// and counts the bytes uploaded into uploadedBytes, if set.
+func (ssr *Snapshotter) takeDeltaSnapshot(uploadedBytes *atomic.Int64) (*brtypes.Snapshot, error) {
 	defer ssr.cleanupInMemoryEvents()
 	ssr.logger.Infof("Taking delta snapshot for time: %s", time.Now().Local())
 
-	if len(ssr.events) == 0 {
+	if ssr.events.Len() == 0 {
 		ssr.logger.Infof("No events received to save snapshot. Skipping delta snapshot.")
 		metrics.SnapshotRequired.With(prometheus.Labels{metrics.LabelKind: brtypes.SnapshotKindDelta}).Set(0)
 		return nil, nil
 	}
-	ssr.events = append(ssr.events, byte(']'))
 
 	// Update the snapstore object before taking a delta snapshot if the credentials have changed
 	// Refer: https://github.com/gardener/etcd-backup-restore/issues/449
@@ -466,36 +539,41 @@ func (ssr *Snapshotter) TakeDeltaSnapshot() (*brtypes.Snapshot, error) {
 		return nil, fmt.Errorf("failed to get compressionSuffix: %v", err)
 	}
 	snap := snapstore.NewSnapshot(brtypes.SnapshotKindDelta, ssr.PrevSnapshot.LastRevision+1, ssr.lastEventRevision, compressionSuffix, false)
+	ctx, span := tracing.Start(context.TODO(), "snapshotter.TakeDeltaSnapshot", tracing.SnapshotAttributes(snap)...)
 
-	// compute hash
-	hash := sha256.New()
-	if _, err := hash.Write(ssr.events); err != nil {
-		return nil, fmt.Errorf("failed to compute hash of events: %v", err)
-	}
-	ssr.events = hash.Sum(ssr.events)
+	// complete the delta snapshot with the hash of its events
+	data := ssr.events.Finish()
 
 	startTime := time.Now()
-	rc := io.NopCloser(bytes.NewReader(ssr.events))
+	rc := io.NopCloser(bytes.NewReader(data))
 
 	// if compression is enabled
 	//    then compress the snapshot.
 	if ssr.compressionConfig.Enabled {
 		ssr.logger.Info("start the Compression of delta snapshot")
-		rc, err = compressor.CompressSnapshot(rc, ssr.compressionConfig.CompressionPolicy)
+		_, compressionSpan := tracing.Start(ctx, "compressor.CompressSnapshot", tracing.AttributeCompressionPolicy.String(ssr.compressionConfig.CompressionPolicy))
+		rc, err = compressor.CompressSnapshot(tracing.NewSpanReadCloser(rc, compressionSpan), ssr.compressionConfig.CompressionPolicy)
 		if err != nil {
+			tracing.End(compressionSpan, err)
+			tracing.End(span, err)
 			return nil, fmt.Errorf("unable to compress delta snapshot: %v", err)
 		}
 	}
 	defer rc.Close()
 
-	if err := ssr.store.Save(*snap, rc); err != nil {
+	store := newProgressSnapStore(ssr.store, uploadedBytes)
+	if err := store.Save(*snap, rc); err != nil {
 		timeTaken := time.Since(startTime).Seconds()
 		metrics.SnapshotDurationSeconds.With(prometheus.Labels{metrics.LabelKind: brtypes.SnapshotKindDelta, metrics.LabelSucceeded: metrics.ValueSucceededFalse}).Observe(timeTaken)
+		notifier.Notify(notifier.Event{Type: notifier.EventSnapshotFailed, Data: notifier.EventData{Kind: snap.Kind, SnapName: snap.SnapName, StartRevision: snap.StartRevision, LastRevision: snap.LastRevision, Error: err.Error(), DurationSeconds: timeTaken}})
 		ssr.logger.Errorf("Error saving delta snapshots. %v", err)
+		tracing.End(span, err)
 		return nil, err
 	}
+	tracing.End(span, nil)
 	timeTaken := time.Since(startTime).Seconds()
 	metrics.SnapshotDurationSeconds.With(prometheus.Labels{metrics.LabelKind: brtypes.SnapshotKindDelta, metrics.LabelSucceeded: metrics.ValueSucceededTrue}).Observe(timeTaken)
+	notifier.Notify(notifier.Event{Type: notifier.EventSnapshotSucceeded, Data: notifier.EventData{Kind: snap.Kind, SnapName: snap.SnapName, SnapDir: snap.SnapDir, StartRevision: snap.StartRevision, LastRevision: snap.LastRevision, DurationSeconds: timeTaken}})
 	logrus.Infof("Total time to save delta snapshot: %f seconds.", timeTaken)
 	ssr.PrevSnapshot = snap
 	ssr.PrevDeltaSnapshots = append(ssr.PrevDeltaSnapshots, snap)
@@ -594,46 +672,31 @@ func (ssr *Snapshotter) handleDeltaWatchEvents(wr clientv3.WatchResponse) error
 	}
 	// aggregate events
 	for _, ev := range wr.Events {
-		timedEvent := newEvent(ev)
-		jsonByte, err := json.Marshal(timedEvent)
-		if err != nil {
-			return fmt.Errorf("failed to marshal events to json: %v", err)
-		}
-		if len(ssr.events) == 0 {
-			ssr.events = append(ssr.events, byte('['))
-		} else {
-			ssr.events = append(ssr.events, byte(','))
+		if err := ssr.events.Encode(&brtypes.Event{EtcdEvent: ev, Time: time.Now()}); err != nil {
+			return err
 		}
-		ssr.events = append(ssr.events, jsonByte...)
 		ssr.lastEventRevision = ev.Kv.ModRevision
 		metrics.SnapshotRequired.With(prometheus.Labels{metrics.LabelKind: brtypes.SnapshotKindFull}).Set(1)
 		metrics.SnapshotRequired.With(prometheus.Labels{metrics.LabelKind: brtypes.SnapshotKindDelta}).Set(1)
 	}
 	ssr.logger.Debugf("Added events till revision: %d", ssr.lastEventRevision)
 	// #nosec G115 -- validated for size to be lesser than MaxInt.
-	if len(ssr.events) >= int(ssr.config.DeltaSnapshotMemoryLimit) {
-		ssr.logger.Infof("Delta events memory crossed the memory limit: %d Bytes", len(ssr.events))
-		_, err := ssr.takeDeltaSnapshotAndResetTimer()
+	if ssr.events.Size() >= int(ssr.config.DeltaSnapshotMemoryLimit) {
+		ssr.logger.Infof("Delta events memory crossed the memory limit: %d Bytes", ssr.events.Size())
+		_, err := ssr.takeDeltaSnapshotAndResetTimer(nil)
 		return err
 	}
 	return nil
 }
 
-func newEvent(e *clientv3.Event) *event {
-	return &event{
-		EtcdEvent: e,
-		Time:      time.Now(),
-	}
-}
-
 func (ssr *Snapshotter) snapshotEventHandler(stopCh <-chan struct{}) error {
 	leaseUpdateCtx, leaseUpdateCancel := context.WithCancel(context.TODO())
 	defer leaseUpdateCancel()
 	ssr.logger.Info("Starting the Snapshot EventHandler.")
 	for {
 		select {
-		case isFinal := <-ssr.fullSnapshotReqCh:
-			s, err := ssr.TakeFullSnapshotAndResetTimer(isFinal)
+		case req := <-ssr.fullSnapshotReqCh:
+			s, err := ssr.takeFullSnapshotAndResetTimer(req.isFinal, false, req.uploadedBytes)
 			res := result{
 				Snapshot: s,
 				Err:      err,
@@ -649,8 +712,8 @@ func (ssr *Snapshotter) snapshotEventHandler(stopCh <-chan struct{}) error {
 				ssr.FullSnapshotLeaseUpdateTimer.Reset(time.Nanosecond)
 			}
 
-		case <-ssr.deltaSnapshotReqCh:
-			s, err := ssr.takeDeltaSnapshotAndResetTimer()
+		case req := <-ssr.deltaSnapshotReqCh:
+			s, err := ssr.takeDeltaSnapshotAndResetTimer(req.uploadedBytes)
 			res := result{
 				Snapshot: s,
 				Err:      err,
@@ -668,7 +731,7 @@ func (ssr *Snapshotter) snapshotEventHandler(stopCh <-chan struct{}) error {
 			}
 
 		case <-ssr.fullSnapshotTimer.C:
-			if _, err := ssr.TakeFullSnapshotAndResetTimer(false); err != nil {
+			if _, err := ssr.takeFullSnapshotAndResetTimer(false, true, nil); err != nil {
 				ssr.PrevFullSnapshotSucceeded = false
 				return err
 			}
@@ -680,7 +743,7 @@ func (ssr *Snapshotter) snapshotEventHandler(stopCh <-chan struct{}) error {
 
 		case <-ssr.deltaSnapshotTimer.C:
 			if ssr.config.DeltaSnapshotPeriod.Duration >= time.Second {
-				if _, err := ssr.takeDeltaSnapshotAndResetTimer(); err != nil {
+				if _, err := ssr.takeDeltaSnapshotAndResetTimer(nil); err != nil {
 					return err
 				}
 				if ssr.HealthConfig.SnapshotLeaseRenewalEnabled {
@@ -764,6 +827,11 @@ func (ssr *Snapshotter) IsFullSnapshotRequiredAtStartup(timeWindow float64) bool
 		return true
 	}
 
+	if ssr.hasEtcdMetadataChanged() {
+		ssr.logger.Info("etcd was upgraded or the etcd cluster was recreated since the previous full snapshot, taking a full snapshot at startup")
+		return true
+	}
+
 	if !ssr.WasScheduledFullSnapshotMissed(timeWindow) {
 		return false
 	}
@@ -21,6 +21,7 @@ import (
 	"github.com/gardener/etcd-backup-restore/pkg/wrappers"
 	"github.com/gardener/etcd-backup-restore/test/utils"
 
+	"go.etcd.io/etcd/version"
 	v1 "k8s.io/api/coordination/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -913,6 +914,70 @@ var _ = Describe("Snapshotter", func() {
 					Expect(isFullSnapCanBeMissed).Should(BeTrue())
 				})
 			})
+
+			Context("Previous full snapshot was taken exactly at scheduled snapshot time of another etcd version or cluster", func() {
+				var etcdMetadata map[string]string
+
+				BeforeEach(func() {
+					snapstoreConfig = &brtypes.SnapstoreConfig{Provider: brtypes.SnapstoreProviderFilesystem, Container: GinkgoT().TempDir(), Prefix: snapsInV2}
+					store, err = snapstore.GetSnapstore(snapstoreConfig)
+					Expect(err).ShouldNot(HaveOccurred())
+					// the full snapshot is scheduled daily, last an hour ago, so that the schedule does not depend on the time of day.
+					scheduled := time.Now().Add(-time.Hour)
+					scheduled = time.Date(scheduled.Year(), scheduled.Month(), scheduled.Day(), scheduled.Hour(), scheduled.Minute(), 0, 0, time.Local)
+					snapshotterConfig := &brtypes.SnapshotterConfig{
+						FullSnapshotSchedule: fmt.Sprintf("%d %d * * *", scheduled.Minute(), scheduled.Hour()),
+					}
+					ssr, err = NewSnapshotter(logger, snapshotterConfig, store, etcdConnectionConfig, compressionConfig, healthConfig, snapstoreConfig)
+					Expect(err).ShouldNot(HaveOccurred())
+					_, err = ssr.TakeFullSnapshotAndResetTimer(false)
+					Expect(err).ShouldNot(HaveOccurred())
+
+					etcdMetadata = map[string]string{
+						brtypes.EtcdVersionMetadataKey:   version.Version,
+						brtypes.EtcdClusterIDMetadataKey: etcd.Server.Cluster().ID().String(),
+					}
+
+					// Previous full snapshot was taken exactly at the last scheduled time
+					ssr, err = NewSnapshotter(logger, snapshotterConfig, store, etcdConnectionConfig, compressionConfig, healthConfig, snapstoreConfig)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(ssr.PrevFullSnapshot).ShouldNot(BeNil())
+					ssr.PrevFullSnapshot.CreatedOn = scheduled
+					ssr.PrevFullSnapshotSucceeded = true
+				})
+
+				It("should save the full snapshot with the version of etcd and the ID of the etcd cluster", func() {
+					metadata, err := store.(brtypes.MetadataFetcher).FetchMetadata(*ssr.PrevFullSnapshot)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(metadata).Should(Equal(etcdMetadata))
+				})
+
+				It("should return false if etcd is unchanged", func() {
+					Expect(ssr.IsFullSnapshotRequiredAtStartup(fullSnapshotTimeWindow)).Should(BeFalse())
+					Expect(ssr.PrevFullSnapshot.Metadata).Should(Equal(etcdMetadata))
+				})
+
+				It("should return true if etcd was upgraded", func() {
+					ssr.PrevFullSnapshot.Metadata = map[string]string{
+						brtypes.EtcdVersionMetadataKey:   "3.3.27",
+						brtypes.EtcdClusterIDMetadataKey: etcdMetadata[brtypes.EtcdClusterIDMetadataKey],
+					}
+					Expect(ssr.IsFullSnapshotRequiredAtStartup(fullSnapshotTimeWindow)).Should(BeTrue())
+				})
+
+				It("should return true if the etcd cluster was recreated", func() {
+					ssr.PrevFullSnapshot.Metadata = map[string]string{
+						brtypes.EtcdVersionMetadataKey:   etcdMetadata[brtypes.EtcdVersionMetadataKey],
+						brtypes.EtcdClusterIDMetadataKey: "cdf818194e3a8c32",
+					}
+					Expect(ssr.IsFullSnapshotRequiredAtStartup(fullSnapshotTimeWindow)).Should(BeTrue())
+				})
+
+				It("should return false if the previous full snapshot was saved without metadata", func() {
+					ssr.PrevFullSnapshot.Metadata = map[string]string{}
+					Expect(ssr.IsFullSnapshotRequiredAtStartup(fullSnapshotTimeWindow)).Should(BeFalse())
+				})
+			})
 		})
 
 		Describe("Scenarios to get maximum time window for full snapshot", func() {
@@ -0,0 +1,319 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package squasher merges consecutive delta snapshots into a single delta snapshot, to shorten
+// the chain of delta snapshots which has to be applied during restoration.
+package squasher
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/compressor"
+	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+	"github.com/gardener/etcd-backup-restore/pkg/snapshot/delta"
+	"github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// Squasher squashes the delta snapshots of the latest full snapshot.
+type Squasher struct {
+	logger            *logrus.Entry
+	store             brtypes.SnapStore
+	config            *brtypes.SquasherConfig
+	compressionConfig *compressor.CompressionConfig
+	// deltaSnapshotFormat is the format of the squashed delta snapshots.
+	deltaSnapshotFormat string
+}
+
+// NewSquasher returns a new squasher, which saves the squashed delta snapshots in the given delta snapshot format.
+func NewSquasher(store brtypes.SnapStore, config *brtypes.SquasherConfig, compressionConfig *compressor.CompressionConfig, deltaSnapshotFormat string, logger *logrus.Entry) *Squasher {
+	return &Squasher{
+		logger:              logger.WithField("actor", "squasher"),
+		store:               store,
+		config:              config,
+		compressionConfig:   compressionConfig,
+		deltaSnapshotFormat: deltaSnapshotFormat,
+	}
+}
+
+// Squash merges runs of at least MinDeltaSnapshots consecutive delta snapshots of the latest full snapshot
+// into single delta snapshots and returns the squashed delta snapshots.
+//
+// A squashed delta snapshot covers exactly the revisions from the start revision of the first to the last
+// revision of the last delta snapshot it replaces, and contains every revision in between. Only duplicate writes
+// of a key within the same revision are dropped, e.g. from overlapping delta snapshots: the restorer applies one
+// transaction per revision and verifies the resulting revision, and the versions of the keys depend on every write.
+//
+// The squashed delta snapshot is saved before the delta snapshots it replaces are deleted. Listing the latest
+// delta snapshots ignores delta snapshots covered by another one, so a restoration always sees a consistent chain.
+// Replaced delta snapshots left over by an earlier squash, e.g. because it was interrupted, are deleted first.
+func (s *Squasher) Squash(ctx context.Context) (brtypes.SnapList, error) {
+	s.deleteSupersededDeltaSnapshots()
+
+	_, deltaSnapList, err := miscellaneous.GetLatestFullSnapshotAndDeltaSnapList(s.store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the latest delta snapshots: %v", err)
+	}
+
+	var squashed brtypes.SnapList
+	for _, run := range s.consecutiveRuns(deltaSnapList) {
+		snaps, err := s.squashRun(ctx, run)
+		squashed = append(squashed, snaps...)
+		if err != nil {
+			return squashed, err
+		}
+	}
+	if len(squashed) == 0 {
+		s.logger.Infof("Found no run of at least %d consecutive delta snapshots to squash", s.config.MinDeltaSnapshots)
+	}
+	return squashed, nil
+}
+
+// deleteSupersededDeltaSnapshots deletes the delta snapshots of the latest full snapshot which are covered by a
+// squashed delta snapshot, but have not been deleted when they were squashed.
+func (s *Squasher) deleteSupersededDeltaSnapshots() {
+	superseded, err := miscellaneous.GetLatestSupersededDeltaSnapshots(s.store)
+	if err != nil {
+		s.logger.Warnf("Failed to list the superseded delta snapshots: %v", err)
+		return
+	}
+	for _, snap := range superseded {
+		s.logger.Infof("Deleting superseded delta snapshot %s", path.Join(snap.SnapDir, snap.SnapName))
+		if err := s.store.Delete(*snap); err != nil {
+			s.logger.Warnf("Failed to delete superseded delta snapshot %s: %v", path.Join(snap.SnapDir, snap.SnapName), err)
+		}
+	}
+}
+
+// consecutiveRuns splits the delta snapshots into runs of consecutive revisions of at most MaxDeltaSnapshots
+// delta snapshots, and returns the runs of at least MinDeltaSnapshots delta snapshots. Final delta snapshots
+// are never squashed, since they mark the end of the backup.
+func (s *Squasher) consecutiveRuns(deltaSnapList brtypes.SnapList) []brtypes.SnapList {
+	var (
+		runs []brtypes.SnapList
+		run  brtypes.SnapList
+	)
+	closeRun := func() {
+		if uint(len(run)) >= s.config.MinDeltaSnapshots {
+			runs = append(runs, run)
+		}
+		run = nil
+	}
+
+	for _, snap := range deltaSnapList {
+		if snap.IsFinal {
+			closeRun()
+			continue
+		}
+		if len(run) > 0 && (snap.StartRevision > run[len(run)-1].LastRevision+1 || uint(len(run)) == s.config.MaxDeltaSnapshots) {
+			closeRun()
+		}
+		run = append(run, snap)
+	}
+	closeRun()
+	return runs
+}
+
+// squashRun squashes a run of consecutive delta snapshots. The run is split further if the events
+// of the squashed delta snapshot would exceed MaxSnapshotSize.
+func (s *Squasher) squashRun(ctx context.Context, run brtypes.SnapList) (brtypes.SnapList, error) {
+	var (
+		squashed brtypes.SnapList
+		pending  brtypes.SnapList
+		events   []brtypes.Event
+		size     int
+	)
+	flush := func() error {
+		defer func() {
+			pending, events, size = nil, nil, 0
+		}()
+		if len(pending) < 2 {
+			return nil
+		}
+		snap, err := s.saveSquashedSnapshot(pending, events)
+		if err != nil {
+			return err
+		}
+		squashed = append(squashed, snap)
+		return nil
+	}
+
+	for _, snap := range run {
+		select {
+		case <-ctx.Done():
+			return squashed, ctx.Err()
+		default:
+		}
+
+		snapEvents, snapSize, err := s.readDeltaSnapshot(snap)
+		if err != nil {
+			return squashed, err
+		}
+		if len(pending) > 0 && size+snapSize > int(s.config.MaxSnapshotSize) {
+			if err := flush(); err != nil {
+				return squashed, err
+			}
+		}
+		pending = append(pending, snap)
+		events = mergeEvents(events, snapEvents)
+		size += snapSize
+	}
+	if err := flush(); err != nil {
+		return squashed, err
+	}
+	return squashed, nil
+}
+
+// mergeEvents appends the events of the next delta snapshot to the merged events. Events of revisions
+// which are already merged are only kept if they write a key which has not been written in that revision.
+func mergeEvents(merged, events []brtypes.Event) []brtypes.Event {
+	var lastRevision int64
+	if len(merged) > 0 {
+		lastRevision = merged[len(merged)-1].EtcdEvent.Kv.ModRevision
+	}
+
+	written := map[string]struct{}{}
+	for i := len(merged) - 1; i >= 0 && merged[i].EtcdEvent.Kv.ModRevision == lastRevision; i-- {
+		written[string(merged[i].EtcdEvent.Kv.Key)] = struct{}{}
+	}
+
+	for _, event := range events {
+		revision := event.EtcdEvent.Kv.ModRevision
+		if revision < lastRevision {
+			continue
+		}
+		if revision == lastRevision {
+			if _, ok := written[string(event.EtcdEvent.Kv.Key)]; ok {
+				continue
+			}
+			written[string(event.EtcdEvent.Kv.Key)] = struct{}{}
+		}
+		merged = append(merged, event)
+	}
+	return merged
+}
+
+// readDeltaSnapshot fetches the delta snapshot, verifies its hash and returns its events and their size.
+func (s *Squasher) readDeltaSnapshot(snap *brtypes.Snapshot) ([]brtypes.Event, int, error) {
+	rc, err := s.store.Fetch(*snap)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch delta snapshot %s: %v", snap.SnapName, err)
+	}
+	defer rc.Close()
+
+	isCompressed, compressionPolicy, err := compressor.IsSnapshotCompressed(snap.CompressionSuffix)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to determine compression policy of delta snapshot %s: %v", snap.SnapName, err)
+	}
+	if isCompressed {
+		if rc, err = compressor.DecompressSnapshot(rc, compressionPolicy); err != nil {
+			return nil, 0, fmt.Errorf("failed to decompress delta snapshot %s: %v", snap.SnapName, err)
+		}
+		defer rc.Close()
+	}
+
+	cr := &countingReader{r: rc}
+	events, err := delta.ReadAll(cr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read events of delta snapshot %s: %v", snap.SnapName, err)
+	}
+	return events, cr.n - sha256.Size, nil
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+// saveSquashedSnapshot saves the merged events as a delta snapshot replacing the given delta snapshots,
+// and deletes the replaced delta snapshots afterwards.
+func (s *Squasher) saveSquashedSnapshot(snaps brtypes.SnapList, events []brtypes.Event) (*brtypes.Snapshot, error) {
+	first, last := snaps[0], snaps[len(snaps)-1]
+	if len(events) == 0 || events[len(events)-1].EtcdEvent.Kv.ModRevision != last.LastRevision {
+		return nil, fmt.Errorf("events of delta snapshots %s to %s do not end with revision %d", first.SnapName, last.SnapName, last.LastRevision)
+	}
+
+	compressionSuffix, err := compressor.GetCompressionSuffix(s.compressionConfig.Enabled, s.compressionConfig.CompressionPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compressionSuffix: %v", err)
+	}
+	// The squashed delta snapshot keeps the creation time of the last delta snapshot it replaces,
+	// so that the garbage collection treats it the same way.
+	snap := snapstore.NewSnapshot(brtypes.SnapshotKindDelta, first.StartRevision, last.LastRevision, compressionSuffix, false)
+	snap.CreatedOn = last.CreatedOn
+	snap.SnapDir = last.SnapDir
+	snap.Prefix = last.Prefix
+	snap.GenerateSnapshotName()
+
+	data, err := delta.Encode(s.deltaSnapshotFormat, events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode events: %v", err)
+	}
+
+	rc := io.NopCloser(bytes.NewReader(data))
+	if s.compressionConfig.Enabled {
+		if rc, err = compressor.CompressSnapshot(rc, s.compressionConfig.CompressionPolicy); err != nil {
+			return nil, fmt.Errorf("unable to compress squashed delta snapshot: %v", err)
+		}
+	}
+	defer rc.Close()
+
+	s.logger.Infof("Squashing %d delta snapshots from revision %d to %d into %s", len(snaps), first.StartRevision, last.LastRevision, snap.SnapName)
+	if err := s.store.Save(*snap, rc); err != nil {
+		return nil, fmt.Errorf("failed to save squashed delta snapshot %s: %v", snap.SnapName, err)
+	}
+
+	for _, replaced := range snaps {
+		if err := s.store.Delete(*replaced); err != nil {
+			// the replaced delta snapshot is ignored during restoration, since it is covered by the squashed one.
+			s.logger.Warnf("Failed to delete squashed delta snapshot %s: %v", path.Join(replaced.SnapDir, replaced.SnapName), err)
+		}
+	}
+	s.logger.Infof("Successfully squashed %d delta snapshots into %s", len(snaps), snap.SnapName)
+	return snap, nil
+}
+
+// squasherJob implements the cron.Job for squashing delta snapshots.
+type squasherJob struct {
+	ctx      context.Context
+	squasher *Squasher
+}
+
+func (j *squasherJob) Run() {
+	startTime := time.Now()
+	snaps, err := j.squasher.Squash(j.ctx)
+	if err != nil {
+		j.squasher.logger.Errorf("Failed to squash delta snapshots: %v", err)
+		return
+	}
+	j.squasher.logger.Infof("Squashed delta snapshots into %d delta snapshots in %.2f seconds", len(snaps), time.Since(startTime).Seconds())
+}
+
+// SquashPeriodically squashes the delta snapshots as per the given schedule until the context is cancelled.
+func SquashPeriodically(ctx context.Context, squasher *Squasher, schedule cron.Schedule) {
+	jobRunner := cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	jobRunner.Schedule(schedule, &squasherJob{ctx: ctx, squasher: squasher})
+	jobRunner.Start()
+
+	<-ctx.Done()
+	squasher.logger.Info("Closing squasher.")
+	jobRunnerCtx := jobRunner.Stop()
+	<-jobRunnerCtx.Done()
+}
@@ -0,0 +1,213 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package squasher_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/compressor"
+	"github.com/gardener/etcd-backup-restore/pkg/miscellaneous"
+	"github.com/gardener/etcd-backup-restore/pkg/snapshot/delta"
+	"github.com/gardener/etcd-backup-restore/pkg/snapshot/squasher"
+	"github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+)
+
+var _ = Describe("Squasher", func() {
+	var (
+		store             brtypes.SnapStore
+		config            *brtypes.SquasherConfig
+		compressionConfig *compressor.CompressionConfig
+		logger            = logrus.NewEntry(logrus.New())
+		createdOn         time.Time
+	)
+
+	// putEvents returns one put event per revision in [start, last].
+	putEvents := func(start, last int64) []brtypes.Event {
+		var events []brtypes.Event
+		for rev := start; rev <= last; rev++ {
+			events = append(events, brtypes.Event{EtcdEvent: &clientv3.Event{
+				Type: mvccpb.PUT,
+				Kv:   &mvccpb.KeyValue{Key: []byte(fmt.Sprintf("key-%d", rev%3)), Value: []byte(fmt.Sprintf("value-%d", rev)), ModRevision: rev},
+			}, Time: time.Unix(rev, 0)})
+		}
+		return events
+	}
+
+	// saveDeltaSnapshot saves the events in the JSON format, while the squasher saves them in the binary format.
+	saveDeltaSnapshot := func(start, last int64, events []brtypes.Event, final bool) *brtypes.Snapshot {
+		data, err := delta.Encode(brtypes.DeltaSnapshotFormatJSON, events)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		createdOn = createdOn.Add(time.Second)
+		snap := snapstore.NewSnapshot(brtypes.SnapshotKindDelta, start, last, "", final)
+		snap.CreatedOn = createdOn
+		snap.GenerateSnapshotName()
+		Expect(store.Save(*snap, io.NopCloser(bytes.NewReader(data)))).To(Succeed())
+		return snap
+	}
+
+	readEvents := func(snap *brtypes.Snapshot) []brtypes.Event {
+		rc, err := store.Fetch(*snap)
+		Expect(err).ShouldNot(HaveOccurred())
+		defer rc.Close()
+		events, err := delta.ReadAll(rc)
+		Expect(err).ShouldNot(HaveOccurred())
+		return events
+	}
+
+	revisions := func(snaps brtypes.SnapList) [][2]int64 {
+		var revs [][2]int64
+		for _, snap := range snaps {
+			revs = append(revs, [2]int64{snap.StartRevision, snap.LastRevision})
+		}
+		return revs
+	}
+
+	BeforeEach(func() {
+		var err error
+		store, err = snapstore.GetSnapstore(&brtypes.SnapstoreConfig{Container: GinkgoT().TempDir(), Provider: brtypes.SnapstoreProviderLocal})
+		Expect(err).ShouldNot(HaveOccurred())
+		config = brtypes.NewSquasherConfig()
+		config.MinDeltaSnapshots = 2
+		compressionConfig = compressor.NewCompressorConfig()
+		createdOn = time.Now().Add(-time.Hour).Truncate(time.Second)
+
+		full := snapstore.NewSnapshot(brtypes.SnapshotKindFull, 0, 1, "", false)
+		full.CreatedOn = createdOn
+		full.GenerateSnapshotName()
+		Expect(store.Save(*full, io.NopCloser(bytes.NewReader([]byte("full"))))).To(Succeed())
+	})
+
+	It("should squash consecutive delta snapshots into one covering the same revisions", func() {
+		var events []brtypes.Event
+		for start := int64(2); start < 12; start += 2 {
+			saveDeltaSnapshot(start, start+1, putEvents(start, start+1), false)
+			events = append(events, putEvents(start, start+1)...)
+		}
+
+		squashed, err := squasher.NewSquasher(store, config, compressionConfig, brtypes.DeltaSnapshotFormatBinary, logger).Squash(context.TODO())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(revisions(squashed)).To(Equal([][2]int64{{2, 11}}))
+
+		_, deltaSnapList, err := miscellaneous.GetLatestFullSnapshotAndDeltaSnapList(store)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(revisions(deltaSnapList)).To(Equal([][2]int64{{2, 11}}))
+		Expect(readEvents(deltaSnapList[0])).To(Equal(events))
+
+		snapList, err := store.List(false)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(snapList).To(HaveLen(2))
+	})
+
+	It("should not squash across revision gaps, final delta snapshots or the maximum number of delta snapshots", func() {
+		config.MinDeltaSnapshots = 3
+		config.MaxDeltaSnapshots = 4
+		saveDeltaSnapshot(2, 3, putEvents(2, 3), false)
+		saveDeltaSnapshot(4, 5, putEvents(4, 5), false)
+		// gap, the run before is too short
+		saveDeltaSnapshot(7, 8, putEvents(7, 8), false)
+		saveDeltaSnapshot(9, 9, putEvents(9, 9), false)
+		saveDeltaSnapshot(10, 10, putEvents(10, 10), false)
+		saveDeltaSnapshot(11, 11, putEvents(11, 11), false)
+		saveDeltaSnapshot(12, 12, putEvents(12, 12), false)
+		saveDeltaSnapshot(13, 13, putEvents(13, 13), false)
+		saveDeltaSnapshot(14, 14, putEvents(14, 14), false)
+		saveDeltaSnapshot(15, 15, putEvents(15, 15), true)
+
+		squashed, err := squasher.NewSquasher(store, config, compressionConfig, brtypes.DeltaSnapshotFormatBinary, logger).Squash(context.TODO())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(revisions(squashed)).To(Equal([][2]int64{{7, 11}, {12, 14}}))
+
+		_, deltaSnapList, err := miscellaneous.GetLatestFullSnapshotAndDeltaSnapList(store)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(revisions(deltaSnapList)).To(Equal([][2]int64{{2, 3}, {4, 5}, {7, 11}, {12, 14}, {15, 15}}))
+	})
+
+	It("should split runs whose events exceed the maximum snapshot size", func() {
+		for start := int64(2); start < 10; start += 2 {
+			saveDeltaSnapshot(start, start+1, putEvents(start, start+1), false)
+		}
+		data, err := delta.Encode(brtypes.DeltaSnapshotFormatJSON, putEvents(2, 3))
+		Expect(err).ShouldNot(HaveOccurred())
+		config.MaxSnapshotSize = uint(2 * (len(data) - sha256.Size))
+
+		squashed, err := squasher.NewSquasher(store, config, compressionConfig, brtypes.DeltaSnapshotFormatBinary, logger).Squash(context.TODO())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(revisions(squashed)).To(Equal([][2]int64{{2, 5}, {6, 9}}))
+	})
+
+	It("should drop duplicate writes of overlapping delta snapshots", func() {
+		saveDeltaSnapshot(2, 4, putEvents(2, 4), false)
+		saveDeltaSnapshot(4, 6, putEvents(4, 6), false)
+
+		squashed, err := squasher.NewSquasher(store, config, compressionConfig, brtypes.DeltaSnapshotFormatBinary, logger).Squash(context.TODO())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(squashed).To(HaveLen(1))
+		Expect(readEvents(squashed[0])).To(Equal(putEvents(2, 6)))
+	})
+
+	It("should compress the squashed delta snapshot if compression is enabled", func() {
+		compressionConfig.Enabled = true
+		saveDeltaSnapshot(2, 3, putEvents(2, 3), false)
+		saveDeltaSnapshot(4, 5, putEvents(4, 5), false)
+
+		squashed, err := squasher.NewSquasher(store, config, compressionConfig, brtypes.DeltaSnapshotFormatBinary, logger).Squash(context.TODO())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(squashed).To(HaveLen(1))
+		Expect(squashed[0].CompressionSuffix).NotTo(BeEmpty())
+
+		// squashing again reads the compressed delta snapshot
+		saveDeltaSnapshot(6, 7, putEvents(6, 7), false)
+		squashed, err = squasher.NewSquasher(store, config, compressionConfig, brtypes.DeltaSnapshotFormatBinary, logger).Squash(context.TODO())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(revisions(squashed)).To(Equal([][2]int64{{2, 7}}))
+	})
+
+	It("should ignore delta snapshots covered by a squashed delta snapshot when listing the latest delta snapshots", func() {
+		saveDeltaSnapshot(2, 3, putEvents(2, 3), false)
+		saveDeltaSnapshot(4, 5, putEvents(4, 5), false)
+		createdOn = createdOn.Add(-time.Second)
+		// squashed delta snapshot whose replaced delta snapshots have not been deleted yet
+		saveDeltaSnapshot(2, 5, putEvents(2, 5), false)
+		saveDeltaSnapshot(6, 7, putEvents(6, 7), false)
+
+		_, deltaSnapList, err := miscellaneous.GetLatestFullSnapshotAndDeltaSnapList(store)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(revisions(deltaSnapList)).To(Equal([][2]int64{{2, 5}, {6, 7}}))
+	})
+
+	It("should delete delta snapshots left over by an earlier squash", func() {
+		config.MinDeltaSnapshots = 3
+		saveDeltaSnapshot(2, 3, putEvents(2, 3), false)
+		saveDeltaSnapshot(4, 5, putEvents(4, 5), false)
+		createdOn = createdOn.Add(-time.Second)
+		// squashed delta snapshot whose replaced delta snapshots have not been deleted yet
+		saveDeltaSnapshot(2, 5, putEvents(2, 5), false)
+
+		superseded, err := miscellaneous.GetLatestSupersededDeltaSnapshots(store)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(revisions(superseded)).To(Equal([][2]int64{{2, 3}, {4, 5}}))
+
+		squashed, err := squasher.NewSquasher(store, config, compressionConfig, brtypes.DeltaSnapshotFormatBinary, logger).Squash(context.TODO())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(squashed).To(BeEmpty())
+
+		snapList, err := store.List(false)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(revisions(snapList)).To(ConsistOf([2]int64{0, 1}, [2]int64{2, 5}))
+	})
+})
@@ -20,12 +20,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
@@ -39,12 +41,16 @@ const (
 	absCredentialJSONFile  = "AZURE_APPLICATION_CREDENTIALS_JSON" // #nosec G101 -- This is not a hardcoded password, but only a path to the credentials.
 	// AzuriteEndpoint is the environment variable which indicates the endpoint at which the Azurite emulator is hosted
 	AzuriteEndpoint = "AZURE_STORAGE_API_ENDPOINT"
+	// absFederatedTokenFile is the environment variable set by Azure Workload Identity for the federated token.
+	absFederatedTokenFile = "AZURE_FEDERATED_TOKEN_FILE" // #nosec G101 -- This is not a hardcoded password, but only a path to the token.
 )
 
 // AzureBlockBlobClientI defines the methods that are invoked from the Azure Block Blob API.
 type AzureBlockBlobClientI interface {
 	// DownloadStream reads a range of bytes from a blob. The response also includes the blob's properties and metadata.
 	DownloadStream(context.Context, *blob.DownloadStreamOptions) (blob.DownloadStreamResponse, error)
+	// GetProperties returns the blob's properties.
+	GetProperties(context.Context, *blob.GetPropertiesOptions) (blob.GetPropertiesResponse, error)
 	// Delete marks the specified blob or snapshot for deletion. The blob is later deleted during the internal garbage collection of Azure Blob Storage.
 	// Note that deleting a blob also deletes all its snapshots.
 	Delete(context.Context, *blob.DeleteOptions) (blob.DeleteResponse, error)
@@ -96,6 +102,11 @@ type absCredentials struct {
 	StorageAccount  string  `json:"storageAccount"`
 	StorageKey      string  `json:"storageKey"`
 	EmulatorEnabled bool    `json:"emulatorEnabled,omitempty"`
+	// ClientID, TenantID and TokenPath configure Azure Workload Identity, they default to the environment variables
+	// AZURE_CLIENT_ID, AZURE_TENANT_ID and AZURE_FEDERATED_TOKEN_FILE.
+	ClientID  string `json:"clientID,omitempty"`
+	TenantID  string `json:"tenantID,omitempty"`
+	TokenPath string `json:"tokenPath,omitempty"`
 }
 
 // NewABSSnapStore creates a new ABSSnapStore using a shared configuration and a specified bucket
@@ -105,11 +116,6 @@ func NewABSSnapStore(config *brtypes.SnapstoreConfig) (*ABSSnapStore, error) {
 		return nil, fmt.Errorf("failed to get credentials: %w", err)
 	}
 
-	sharedKeyCredential, err := container.NewSharedKeyCredential(absCreds.StorageAccount, absCreds.StorageKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create sharedKeyCredential: %w", err)
-	}
-
 	emulatorEnabled := config.IsEmulatorEnabled || absCreds.EmulatorEnabled
 	domain := brtypes.AzureBlobStorageGlobalDomain
 	if absCreds.Domain != nil {
@@ -127,15 +133,9 @@ func NewABSSnapStore(config *brtypes.SnapstoreConfig) (*ABSSnapStore, error) {
 	}
 	containerURL := fmt.Sprintf("%s/%s", blobServiceURL, config.Container)
 
-	client, err := container.NewClientWithSharedKeyCredential(containerURL, sharedKeyCredential, &container.ClientOptions{
-		ClientOptions: azcore.ClientOptions{
-			Retry: policy.RetryOptions{
-				TryTimeout: downloadTimeout,
-			},
-		},
-	})
+	client, err := newABSContainerClient(containerURL, absCreds)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create client with shared key credential with error: %w", err)
+		return nil, err
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), providerConnectionTimeout)
@@ -151,6 +151,45 @@ func NewABSSnapStore(config *brtypes.SnapstoreConfig) (*ABSSnapStore, error) {
 	return NewABSSnapStoreFromClient(config.Container, config.Prefix, config.TempDir, config.MaxParallelChunkUploads, config.MinChunkSize, &AzureContainerClient{client}), nil
 }
 
+// newABSContainerClient creates a container client which authenticates with the storage key, or with Azure Workload
+// Identity if no storage key is set. The workload identity credential re-reads the federated token whenever it
+// requests a new access token, so that rotated tokens are used without recreating the client.
+func newABSContainerClient(containerURL string, absCreds *absCredentials) (*container.Client, error) {
+	clientOptions := &container.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Retry: policy.RetryOptions{
+				TryTimeout: downloadTimeout,
+			},
+		},
+	}
+
+	if absCreds.StorageKey != "" {
+		sharedKeyCredential, err := container.NewSharedKeyCredential(absCreds.StorageAccount, absCreds.StorageKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sharedKeyCredential: %w", err)
+		}
+		client, err := container.NewClientWithSharedKeyCredential(containerURL, sharedKeyCredential, clientOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client with shared key credential with error: %w", err)
+		}
+		return client, nil
+	}
+
+	workloadIdentityCredential, err := azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+		ClientID:      absCreds.ClientID,
+		TenantID:      absCreds.TenantID,
+		TokenFilePath: absCreds.TokenPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workload identity credential: %w", err)
+	}
+	client, err := container.NewClient(containerURL, workloadIdentityCredential, clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client with workload identity credential with error: %w", err)
+	}
+	return client, nil
+}
+
 // ConstructBlobServiceURL constructs the Blob Service URL based on the activation status of the Azurite Emulator.
 // The `domain` must either be the default Azure global blob storage domain, or a specific domain for Azurite (without HTTP scheme).
 func ConstructBlobServiceURL(storageAccount, domain string, emulatorEnabled bool) (string, error) {
@@ -254,6 +293,20 @@ func readABSCredentialFiles(dirname string) (*absCredentials, error) {
 				return nil, err
 			}
 			absConfig.EmulatorEnabled = emulatorEnabled
+		} else if file.Name() == "clientID" {
+			data, err := os.ReadFile(path.Join(dirname, file.Name())) // #nosec G304 -- this is a trusted file, obtained via mounted secret.
+			if err != nil {
+				return nil, err
+			}
+			absConfig.ClientID = string(data)
+		} else if file.Name() == "tenantID" {
+			data, err := os.ReadFile(path.Join(dirname, file.Name())) // #nosec G304 -- this is a trusted file, obtained via mounted secret.
+			if err != nil {
+				return nil, err
+			}
+			absConfig.TenantID = string(data)
+		} else if file.Name() == "token" {
+			absConfig.TokenPath = path.Join(dirname, file.Name())
 		}
 	}
 
@@ -289,6 +342,51 @@ func (a *ABSSnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
 	return streamResp.Body, nil
 }
 
+// Size returns the size of the snapshot file in the store.
+func (a *ABSSnapStore) Size(snap brtypes.Snapshot) (int64, error) {
+	blobName := path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)
+
+	props, err := a.client.NewBlockBlobClient(blobName).GetProperties(context.Background(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the properties of the blob %s with error: %w", blobName, err)
+	}
+	if props.ContentLength == nil {
+		return 0, fmt.Errorf("failed to get the size of the blob %s", blobName)
+	}
+	return *props.ContentLength, nil
+}
+
+// FetchMetadata returns the user-defined metadata stored with the snapshot.
+func (a *ABSSnapStore) FetchMetadata(snap brtypes.Snapshot) (map[string]string, error) {
+	blobName := path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)
+
+	props, err := a.client.NewBlockBlobClient(blobName).GetProperties(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the properties of the blob %s with error: %w", blobName, err)
+	}
+	metadata := make(map[string]string, len(props.Metadata))
+	for key, value := range props.Metadata {
+		if value != nil {
+			metadata[key] = *value
+		}
+	}
+	return lowercaseMetadataKeys(metadata), nil
+}
+
+// FetchRange should open reader for a byte range of the snapshot file from store.
+func (a *ABSSnapStore) FetchRange(snap brtypes.Snapshot, offset, length int64) (io.ReadCloser, error) {
+	blobName := path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)
+
+	streamResp, err := a.client.NewBlockBlobClient(blobName).DownloadStream(context.Background(), &blob.DownloadStreamOptions{
+		Range: blob.HTTPRange{Offset: offset, Count: length},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the range [%d, %d) of the blob %s with error: %w", offset, offset+length, blobName, err)
+	}
+
+	return streamResp.Body, nil
+}
+
 // List will return sorted list with all snapshot files on store.
 func (a *ABSSnapStore) List(includeAll bool) (brtypes.SnapList, error) {
 	prefixTokens := strings.Split(a.prefix, "/")
@@ -346,6 +444,9 @@ func (a *ABSSnapStore) List(includeAll bool) (brtypes.SnapList, error) {
 
 // Save will write the snapshot to store
 func (a *ABSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error) {
+	saveCtx, span := startSaveSpan(brtypes.SnapstoreProviderABS, &snap)
+	defer func() { tracing.End(span, err) }()
+
 	tempFile, size, err := writeSnapshotToTempFile(a.tempDir, rc)
 	if err != nil {
 		return err
@@ -379,7 +480,7 @@ func (a *ABSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 
 	for i := uint(0); i < a.maxParallelChunkUploads; i++ {
 		wg.Add(1)
-		go a.blockUploader(&wg, cancelCh, &snap, tempFile, chunkUploadCh, resCh)
+		go a.blockUploader(saveCtx, &wg, cancelCh, &snap, tempFile, chunkUploadCh, resCh)
 	}
 	logrus.Infof("Uploading snapshot of size: %d, chunkSize: %d, noOfChunks: %d", size, chunkSize, noOfChunks)
 	for offset, index := int64(0), 1; offset < size; offset += int64(chunkSize) {
@@ -393,7 +494,7 @@ func (a *ABSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 	}
 	logrus.Infof("Triggered chunk upload for all chunks, total: %d", noOfChunks)
 
-	snapshotErr := collectChunkUploadError(chunkUploadCh, resCh, cancelCh, noOfChunks)
+	snapshotErr := collectChunkUploadError(chunkUploadCh, resCh, cancelCh, noOfChunks, uploadedChunkReporter(rc, size))
 	wg.Wait()
 	if snapshotErr != nil {
 		return fmt.Errorf("failed uploading chunk, id: %d, offset: %d, error: %w", snapshotErr.chunk.id, snapshotErr.chunk.offset, snapshotErr.err)
@@ -410,7 +511,14 @@ func (a *ABSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 	blobClient := a.client.NewBlockBlobClient(blobName)
 	ctx, cancel := context.WithTimeout(context.TODO(), chunkUploadTimeout)
 	defer cancel()
-	if _, err := blobClient.CommitBlockList(ctx, blockList, nil); err != nil {
+	var commitBlockListOptions *blockblob.CommitBlockListOptions
+	if len(snap.Metadata) > 0 {
+		commitBlockListOptions = &blockblob.CommitBlockListOptions{Metadata: make(map[string]*string, len(snap.Metadata))}
+		for key, value := range snap.Metadata {
+			commitBlockListOptions.Metadata[key] = ptr.To(value)
+		}
+	}
+	if _, err := blobClient.CommitBlockList(ctx, blockList, commitBlockListOptions); err != nil {
 		return fmt.Errorf("failed uploading blocklist for snapshot with error: %w", err)
 	}
 	logrus.Info("Blocklist uploaded successfully.")
@@ -444,7 +552,7 @@ func (a *ABSSnapStore) uploadBlock(snap *brtypes.Snapshot, file *os.File, offset
 	return nil
 }
 
-func (a *ABSSnapStore) blockUploader(wg *sync.WaitGroup, stopCh <-chan struct{}, snap *brtypes.Snapshot, file *os.File, chunkUploadCh chan chunk, errCh chan<- chunkUploadResult) {
+func (a *ABSSnapStore) blockUploader(ctx context.Context, wg *sync.WaitGroup, stopCh <-chan struct{}, snap *brtypes.Snapshot, file *os.File, chunkUploadCh chan chunk, errCh chan<- chunkUploadResult) {
 	defer wg.Done()
 	for {
 		select {
@@ -455,7 +563,9 @@ func (a *ABSSnapStore) blockUploader(wg *sync.WaitGroup, stopCh <-chan struct{},
 				return
 			}
 			logrus.Infof("Uploading chunk with offset : %d, attempt: %d", uploadChunk.offset, uploadChunk.attempt)
+			_, span := startChunkUploadSpan(ctx, brtypes.SnapstoreProviderABS, snap, uploadChunk)
 			err := a.uploadBlock(snap, file, uploadChunk.offset, uploadChunk.size)
+			tracing.End(span, err)
 			errCh <- chunkUploadResult{
 				err:   err,
 				chunk: &uploadChunk,
@@ -493,15 +603,32 @@ func GetABSCredentialsLastModifiedTime() (time.Time, error) {
 
 	if dir, isSet := os.LookupEnv(absCredentialDirectory); isSet {
 		// credential files which are essential for creating the snapstore
-		credentialFiles := []string{"storageKey", "storageAccount"}
-		for i := range credentialFiles {
-			credentialFiles[i] = filepath.Join(dir, credentialFiles[i])
+		// either the storage key or workload identity credential files should be set, unless the federated token is
+		// provided by Azure Workload Identity
+		var (
+			staticCredentialsFiles           = []string{filepath.Join(dir, "storageKey"), filepath.Join(dir, "storageAccount")}
+			workloadIdentityCredentialsFiles = []string{filepath.Join(dir, "storageAccount"), filepath.Join(dir, "clientID"), filepath.Join(dir, "tenantID")}
+		)
+
+		absTimeStamp, err1 := getLatestCredentialsModifiedTime(staticCredentialsFiles)
+		if err1 == nil {
+			return absTimeStamp, nil
 		}
-		absTimeStamp, err := getLatestCredentialsModifiedTime(credentialFiles)
-		if err != nil {
-			return time.Time{}, fmt.Errorf("failed to get ABS credential timestamp from the directory %v with error: %w", dir, err)
+
+		absTimeStamp, err2 := getWorkloadIdentityCredentialsModifiedTime(workloadIdentityCredentialsFiles, filepath.Join(dir, "token"))
+		if err2 == nil {
+			return absTimeStamp, nil
 		}
-		return absTimeStamp, nil
+
+		if _, isSet := os.LookupEnv(absFederatedTokenFile); isSet {
+			absTimeStamp, err3 := getLatestCredentialsModifiedTime([]string{filepath.Join(dir, "storageAccount")})
+			if err3 == nil {
+				return absTimeStamp, nil
+			}
+			err2 = errors.Join(err2, err3)
+		}
+
+		return time.Time{}, fmt.Errorf("failed to get ABS credential timestamp from the directory %v with error: %w", dir, errors.Join(err1, err2))
 	}
 
 	if filename, isSet := os.LookupEnv(absCredentialJSONFile); isSet {
@@ -520,5 +647,8 @@ func isABSConfigEmpty(config *absCredentials) error {
 	if len(config.StorageKey) != 0 && len(config.StorageAccount) != 0 {
 		return nil
 	}
-	return fmt.Errorf("azure object storage credentials: storageKey or storageAccount is missing")
+	if len(config.StorageAccount) != 0 && (len(config.TokenPath) != 0 || len(os.Getenv(absFederatedTokenFile)) != 0) {
+		return nil
+	}
+	return fmt.Errorf("azure object storage credentials: storageAccount, or storageKey or workload identity token is missing")
 }
@@ -134,23 +134,38 @@ type fakeBlockBlobClient struct {
 	checkExistenceFn func() bool
 	commitFn         func(*[]byte)
 	getContentFn     func() *[]byte
+	metadata         map[string]*string
 	name             string
 	mutex            sync.Mutex
 }
 
 // DownloadStream returns the only field that is accessed from the response, which is the io.ReadCloser to the data
-func (c *fakeBlockBlobClient) DownloadStream(_ context.Context, _ *blob.DownloadStreamOptions) (blob.DownloadStreamResponse, error) {
+func (c *fakeBlockBlobClient) DownloadStream(_ context.Context, o *blob.DownloadStreamOptions) (blob.DownloadStreamResponse, error) {
 	if ok := c.checkExistenceFn(); !ok {
 		return blob.DownloadStreamResponse{}, fmt.Errorf("the blob does not exist")
 	}
 
+	content := *c.getContentFn()
+	if o != nil && o.Range.Count > 0 {
+		content = rangeOf(content, fmt.Sprintf("bytes=%d-%d", o.Range.Offset, o.Range.Offset+o.Range.Count-1))
+	}
 	return blob.DownloadStreamResponse{
 		DownloadResponse: blob.DownloadResponse{
-			Body: io.NopCloser(bytes.NewReader(*c.getContentFn())),
+			Body: io.NopCloser(bytes.NewReader(content)),
 		},
 	}, nil
 }
 
+// GetProperties returns the size of the blob from the objectMap
+func (c *fakeBlockBlobClient) GetProperties(_ context.Context, _ *blob.GetPropertiesOptions) (blob.GetPropertiesResponse, error) {
+	if ok := c.checkExistenceFn(); !ok {
+		return blob.GetPropertiesResponse{}, fmt.Errorf("the blob does not exist")
+	}
+
+	size := int64(len(*c.getContentFn()))
+	return blob.GetPropertiesResponse{ContentLength: &size, Metadata: c.metadata}, nil
+}
+
 // Delete deletes the blobs from the objectMap
 func (c *fakeBlockBlobClient) Delete(_ context.Context, _ *blob.DeleteOptions) (blob.DeleteResponse, error) {
 	if ok := c.checkExistenceFn(); !ok {
@@ -163,7 +178,7 @@ func (c *fakeBlockBlobClient) Delete(_ context.Context, _ *blob.DeleteOptions) (
 }
 
 // CommitBlockList "commits" the blocks in the "staging" area
-func (c *fakeBlockBlobClient) CommitBlockList(_ context.Context, _ []string, _ *blockblob.CommitBlockListOptions) (blockblob.CommitBlockListResponse, error) {
+func (c *fakeBlockBlobClient) CommitBlockList(_ context.Context, _ []string, o *blockblob.CommitBlockListOptions) (blockblob.CommitBlockListResponse, error) {
 	keys := []string{}
 	for key := range c.staging {
 		keys = append(keys, key)
@@ -177,6 +192,10 @@ func (c *fakeBlockBlobClient) CommitBlockList(_ context.Context, _ []string, _ *
 
 	c.commitFn(&contents)
 	c.staging = make(map[string][]byte)
+	c.metadata = nil
+	if o != nil {
+		c.metadata = o.Metadata
+	}
 
 	return blockblob.CommitBlockListResponse{}, nil
 }
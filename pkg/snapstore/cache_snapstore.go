@@ -0,0 +1,407 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/metrics"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// cacheHeaderSize is the size of the header of a cached snapshot: the hex encoded SHA-256 of the snapshot
+	// followed by a newline.
+	cacheHeaderSize = 2*sha256.Size + 1
+	// cacheTempPattern names the files snapshots are written to while they are saved.
+	cacheTempPattern = "save-*.tmp"
+)
+
+// errCacheSizeExceeded is recorded by the cacheTee once the snapshot exceeds the size of the cache.
+var errCacheSizeExceeded = errors.New("snapshot exceeds the cache size")
+
+var (
+	cacheLocksMutex sync.Mutex
+	// cacheLocks serialises the eviction per cache directory, as several snapstores may share the same cache directory.
+	cacheLocks = map[string]*sync.Mutex{}
+)
+
+func getCacheLock(dir string) *sync.Mutex {
+	cacheLocksMutex.Lock()
+	defer cacheLocksMutex.Unlock()
+	lock, ok := cacheLocks[dir]
+	if !ok {
+		lock = &sync.Mutex{}
+		cacheLocks[dir] = lock
+	}
+	return lock
+}
+
+// CacheSnapStore is a snapstore which keeps a copy of the snapshots saved to the wrapped snapstore in a local directory,
+// from which they are fetched instead of the wrapped snapstore. The size of the directory is bounded by evicting the
+// least recently used snapshots. Cached snapshots are validated by their hash before they are used.
+type CacheSnapStore struct {
+	store   brtypes.SnapStore
+	dir     string
+	maxSize int64
+	lock    *sync.Mutex
+}
+
+// NewCacheSnapStore returns a snapstore caching the snapshots saved to the given snapstore in the given directory,
+// which holds at most maxSize bytes.
+func NewCacheSnapStore(store brtypes.SnapStore, dir string, maxSize int64) (*CacheSnapStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot cache directory %s: %w", dir, err)
+	}
+	c := &CacheSnapStore{
+		store:   store,
+		dir:     dir,
+		maxSize: maxSize,
+		lock:    getCacheLock(dir),
+	}
+	// the cache may exceed its size if it was configured smaller before.
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.evict(); err != nil {
+		logrus.Warnf("Failed to evict snapshots from snapshot cache %s: %v", dir, err)
+	}
+	return c, nil
+}
+
+// cacheDir returns the directory of the snapshot cache for the storage location of the snapstore config, so that
+// snapstores of different storage locations can share the same configured cache directory.
+func cacheDir(config *brtypes.SnapstoreConfig) string {
+	sum := sha256.Sum256([]byte(dedupLocation(config)))
+	return filepath.Join(config.SnapshotCacheDir, hex.EncodeToString(sum[:8]))
+}
+
+// isCached returns whether the snapshot is cached. Chunks are only parts of snapshots, so they are not cached.
+func isCached(snap brtypes.Snapshot) bool {
+	return !snap.IsChunk
+}
+
+// entryPath returns the path of the cached snapshot.
+func (c *CacheSnapStore) entryPath(snap brtypes.Snapshot) string {
+	sum := sha256.Sum256([]byte(path.Join(snap.SnapDir, snap.SnapName)))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// cacheTee writes the data read from the snapshot to the cache file. Failures to write to the cache file are recorded,
+// but do not fail reading the snapshot. Once the snapshot exceeds the size of the cache, the cache file is removed.
+type cacheTee struct {
+	rc      io.ReadCloser
+	f       *os.File
+	hash    hash.Hash
+	size    int64
+	maxSize int64
+	eof     bool
+	err     error
+}
+
+func (t *cacheTee) Read(p []byte) (int, error) {
+	n, err := t.rc.Read(p)
+	if n > 0 && t.err == nil {
+		if t.size+int64(n)+cacheHeaderSize > t.maxSize {
+			t.discard(errCacheSizeExceeded)
+		} else {
+			t.hash.Write(p[:n])
+			if _, werr := t.f.Write(p[:n]); werr != nil {
+				t.discard(werr)
+			}
+			t.size += int64(n)
+		}
+	}
+	if err == io.EOF {
+		t.eof = true
+	}
+	return n, err
+}
+
+func (t *cacheTee) Close() error {
+	return t.rc.Close()
+}
+
+// discard stops writing to the cache file and removes it, so that it does not occupy space until the snapshot is saved.
+func (t *cacheTee) discard(err error) {
+	t.err = err
+	_ = t.f.Close()
+	if rerr := os.Remove(t.f.Name()); rerr != nil && !os.IsNotExist(rerr) {
+		logrus.Warnf("Failed to remove temporary file of snapshot cache: %v", rerr)
+	}
+}
+
+// progressCacheTee is the cacheTee of a snapshot reader whose upload progress is tracked.
+type progressCacheTee struct {
+	*cacheTee
+	pr brtypes.UploadProgressReader
+}
+
+// StagingReader makes the cacheTee read from the staging reader of the snapshot and returns it.
+func (t *progressCacheTee) StagingReader() io.ReadCloser {
+	t.rc = t.pr.StagingReader()
+	return t.cacheTee
+}
+
+func (t *progressCacheTee) AddUploadedBytes(n int64) {
+	t.pr.AddUploadedBytes(n)
+}
+
+// Save saves the snapshot to the wrapped snapstore and, once it has been saved, to the cache.
+func (c *CacheSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) error {
+	if !isCached(snap) {
+		return c.store.Save(snap, rc)
+	}
+	f, err := os.CreateTemp(c.dir, cacheTempPattern)
+	if err != nil {
+		logrus.Warnf("Failed to create file to cache snapshot %s: %v", snap.SnapName, err)
+		return c.store.Save(snap, rc)
+	}
+	defer func() {
+		// the file has been renamed if the snapshot was cached.
+		if err := os.Remove(f.Name()); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("Failed to remove temporary file of snapshot cache: %v", err)
+		}
+	}()
+	defer f.Close()
+
+	// the header is written once the hash is known.
+	if _, err := f.Write(bytes.Repeat([]byte{' '}, cacheHeaderSize)); err != nil {
+		logrus.Warnf("Failed to write file to cache snapshot %s: %v", snap.SnapName, err)
+		return c.store.Save(snap, rc)
+	}
+	tee := &cacheTee{rc: rc, f: f, hash: sha256.New(), maxSize: c.maxSize}
+	var teeReader io.ReadCloser = tee
+	if pr, ok := rc.(brtypes.UploadProgressReader); ok {
+		teeReader = &progressCacheTee{cacheTee: tee, pr: pr}
+	}
+	if err := c.store.Save(snap, teeReader); err != nil {
+		return err
+	}
+	if errors.Is(tee.err, errCacheSizeExceeded) {
+		logrus.Infof("Not caching snapshot %s, since it exceeds the cache size of %d bytes", snap.SnapName, c.maxSize)
+		return nil
+	}
+	if !tee.eof || tee.err != nil {
+		logrus.Warnf("Not caching snapshot %s, since it could not be written to the cache completely: %v", snap.SnapName, tee.err)
+		return nil
+	}
+	if err := c.commit(snap, f, tee.hash.Sum(nil)); err != nil {
+		logrus.Warnf("Failed to cache snapshot %s: %v", snap.SnapName, err)
+	}
+	return nil
+}
+
+// commit writes the hash of the snapshot to the header of the cache file and moves it to the path of the snapshot.
+func (c *CacheSnapStore) commit(snap brtypes.Snapshot, f *os.File, sum []byte) error {
+	if _, err := f.WriteAt([]byte(hex.EncodeToString(sum)+"\n"), 0); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := os.Rename(f.Name(), c.entryPath(snap)); err != nil {
+		return err
+	}
+	return c.evict()
+}
+
+// evict removes the least recently used snapshots until the cache does not exceed its size anymore.
+// It has to be called with the lock held.
+func (c *CacheSnapStore) evict() error {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	var (
+		entries []os.FileInfo
+		size    int64
+	)
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || strings.HasSuffix(dirEntry.Name(), path.Ext(cacheTempPattern)) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			// the entry has been removed in the meantime.
+			continue
+		}
+		entries = append(entries, info)
+		size += info.Size()
+	}
+	// the modification time of an entry is updated whenever it is fetched.
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().Before(entries[j].ModTime())
+	})
+	for _, entry := range entries {
+		if size <= c.maxSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		size -= entry.Size()
+	}
+	metrics.SnapstoreCacheSizeBytes.Set(float64(size))
+	return nil
+}
+
+// openEntry opens the cached snapshot and validates it by its hash. It returns the file positioned after the header,
+// or nil if the snapshot is not cached or invalid. Invalid snapshots are removed from the cache.
+func (c *CacheSnapStore) openEntry(snap brtypes.Snapshot) *os.File {
+	if !isCached(snap) {
+		return nil
+	}
+	entryPath := c.entryPath(snap)
+	f, err := os.Open(entryPath) // #nosec G304 -- this is a trusted file written by etcdbr.
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.Warnf("Failed to open cached snapshot %s: %v", snap.SnapName, err)
+		}
+		return nil
+	}
+	if err := validateCacheEntry(f); err != nil {
+		f.Close()
+		logrus.Warnf("Removing invalid cached snapshot %s: %v", snap.SnapName, err)
+		if err := os.Remove(entryPath); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("Failed to remove invalid cached snapshot %s: %v", snap.SnapName, err)
+		}
+		return nil
+	}
+	now := time.Now()
+	if err := os.Chtimes(entryPath, now, now); err != nil {
+		logrus.Warnf("Failed to mark cached snapshot %s as used: %v", snap.SnapName, err)
+	}
+	return f
+}
+
+// validateCacheEntry compares the hash of the cached snapshot with the hash in its header,
+// and positions the file after the header.
+func validateCacheEntry(f *os.File) error {
+	header := make([]byte, cacheHeaderSize)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	expected, err := hex.DecodeString(strings.TrimSuffix(string(header), "\n"))
+	if err != nil {
+		return fmt.Errorf("invalid header: %w", err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if actual := h.Sum(nil); !bytes.Equal(actual, expected) {
+		return fmt.Errorf("expected SHA256 %x, got %x", expected, actual)
+	}
+	_, err = f.Seek(cacheHeaderSize, io.SeekStart)
+	return err
+}
+
+// Fetch opens the cached snapshot, or fetches it from the wrapped snapstore if it is not cached.
+func (c *CacheSnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
+	if f := c.openEntry(snap); f != nil {
+		metrics.SnapstoreCacheRequestsTotal.With(prometheus.Labels{metrics.LabelCacheResult: metrics.ValueCacheHit}).Inc()
+		logrus.Infof("Fetching snapshot %s from the snapshot cache", snap.SnapName)
+		return f, nil
+	}
+	if isCached(snap) {
+		metrics.SnapstoreCacheRequestsTotal.With(prometheus.Labels{metrics.LabelCacheResult: metrics.ValueCacheMiss}).Inc()
+	}
+	return c.store.Fetch(snap)
+}
+
+// Size returns the size of the cached snapshot, or the size of the snapshot in the wrapped snapstore if it is not cached.
+func (c *CacheSnapStore) Size(snap brtypes.Snapshot) (int64, error) {
+	if f := c.openEntry(snap); f != nil {
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return -1, err
+		}
+		metrics.SnapstoreCacheRequestsTotal.With(prometheus.Labels{metrics.LabelCacheResult: metrics.ValueCacheHit}).Inc()
+		return info.Size() - cacheHeaderSize, nil
+	}
+	rf, ok := c.store.(brtypes.RangeFetcher)
+	if !ok {
+		return -1, brtypes.ErrRangeFetchUnsupported
+	}
+	if isCached(snap) {
+		metrics.SnapstoreCacheRequestsTotal.With(prometheus.Labels{metrics.LabelCacheResult: metrics.ValueCacheMiss}).Inc()
+	}
+	return rf.Size(snap)
+}
+
+// FetchRange opens a reader for a byte range of the cached snapshot, or of the snapshot in the wrapped snapstore if it
+// is not cached. The cached snapshot has been validated when its size was requested.
+func (c *CacheSnapStore) FetchRange(snap brtypes.Snapshot, offset, length int64) (io.ReadCloser, error) {
+	if isCached(snap) {
+		f, err := os.Open(c.entryPath(snap))
+		if err == nil {
+			return &cacheRangeReader{Reader: io.NewSectionReader(f, cacheHeaderSize+offset, length), f: f}, nil
+		}
+	}
+	rf, ok := c.store.(brtypes.RangeFetcher)
+	if !ok {
+		return nil, brtypes.ErrRangeFetchUnsupported
+	}
+	return rf.FetchRange(snap, offset, length)
+}
+
+// FetchMetadata returns the metadata of the snapshot in the wrapped snapstore, since it is not cached.
+func (c *CacheSnapStore) FetchMetadata(snap brtypes.Snapshot) (map[string]string, error) {
+	mf, ok := c.store.(brtypes.MetadataFetcher)
+	if !ok {
+		return nil, brtypes.ErrMetadataFetchUnsupported
+	}
+	return mf.FetchMetadata(snap)
+}
+
+// cacheRangeReader reads a byte range of a cached snapshot.
+type cacheRangeReader struct {
+	io.Reader
+	f *os.File
+}
+
+func (r *cacheRangeReader) Close() error {
+	return r.f.Close()
+}
+
+// List returns the snapshots of the wrapped snapstore.
+func (c *CacheSnapStore) List(includeAll bool) (brtypes.SnapList, error) {
+	return c.store.List(includeAll)
+}
+
+// Delete deletes the snapshot from the wrapped snapstore and from the cache.
+func (c *CacheSnapStore) Delete(snap brtypes.Snapshot) error {
+	if err := c.store.Delete(snap); err != nil {
+		return err
+	}
+	if !isCached(snap) {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := os.Remove(c.entryPath(snap)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logrus.Warnf("Failed to remove deleted snapshot %s from the snapshot cache: %v", snap.SnapName, err)
+	}
+	return nil
+}
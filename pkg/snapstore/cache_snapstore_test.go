@@ -0,0 +1,218 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	. "github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CacheSnapStore", func() {
+	const dataSize = 1024 * 1024
+	var (
+		localStore *LocalSnapStore
+		cacheDir   string
+		cache      *CacheSnapStore
+	)
+
+	newSnapshot := func(kind string, lastRevision int64) brtypes.Snapshot {
+		snap := brtypes.Snapshot{
+			Kind:         kind,
+			LastRevision: lastRevision,
+			CreatedOn:    time.Unix(lastRevision, 0).UTC(),
+		}
+		if kind == brtypes.SnapshotKindDelta {
+			snap.StartRevision = lastRevision
+		}
+		snap.GenerateSnapshotName()
+		return snap
+	}
+
+	newData := func(fill byte) []byte {
+		return bytes.Repeat([]byte{fill}, dataSize)
+	}
+
+	save := func(snap brtypes.Snapshot, data []byte) {
+		Expect(cache.Save(snap, io.NopCloser(bytes.NewReader(data)))).Should(Succeed())
+	}
+
+	fetch := func(snap brtypes.Snapshot) []byte {
+		rc, err := cache.Fetch(snap)
+		Expect(err).ShouldNot(HaveOccurred())
+		defer rc.Close()
+		fetched, err := io.ReadAll(rc)
+		Expect(err).ShouldNot(HaveOccurred())
+		return fetched
+	}
+
+	cachedFiles := func() []string {
+		entries, err := os.ReadDir(cacheDir)
+		Expect(err).ShouldNot(HaveOccurred())
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	// listed returns the snapshot as listed by the local snapstore, which sets its prefix.
+	listed := func(snap brtypes.Snapshot) brtypes.Snapshot {
+		snapList, err := localStore.List(true)
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, s := range snapList {
+			if s.SnapName == snap.SnapName {
+				return *s
+			}
+		}
+		Fail("snapshot " + snap.SnapName + " not listed")
+		return brtypes.Snapshot{}
+	}
+
+	// removeFromStore removes the snapshot from the wrapped snapstore only, so that it can only be fetched from the cache.
+	removeFromStore := func(snap brtypes.Snapshot) {
+		Expect(localStore.Delete(listed(snap))).Should(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		localStore, err = NewLocalSnapStore(path.Join(GinkgoT().TempDir(), "v2"))
+		Expect(err).ShouldNot(HaveOccurred())
+		cacheDir = GinkgoT().TempDir()
+		cache, err = NewCacheSnapStore(localStore, cacheDir, 3*dataSize)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should fetch saved snapshots from the cache", func() {
+		full, delta := newSnapshot(brtypes.SnapshotKindFull, 1), newSnapshot(brtypes.SnapshotKindDelta, 2)
+		save(full, newData('f'))
+		save(delta, newData('d'))
+		Expect(cachedFiles()).Should(HaveLen(2))
+
+		removeFromStore(full)
+		removeFromStore(delta)
+		Expect(fetch(full)).Should(Equal(newData('f')))
+		Expect(fetch(delta)).Should(Equal(newData('d')))
+	})
+
+	It("should fetch snapshots which are not cached from the wrapped snapstore", func() {
+		snap := newSnapshot(brtypes.SnapshotKindFull, 1)
+		Expect(localStore.Save(snap, io.NopCloser(bytes.NewReader(newData('f'))))).Should(Succeed())
+		Expect(fetch(listed(snap))).Should(Equal(newData('f')))
+	})
+
+	It("should evict the least recently used snapshots", func() {
+		first, second, third := newSnapshot(brtypes.SnapshotKindFull, 1), newSnapshot(brtypes.SnapshotKindDelta, 2), newSnapshot(brtypes.SnapshotKindDelta, 3)
+		save(first, newData('1'))
+		save(second, newData('2'))
+		// make the first snapshot the most recently used one.
+		past := time.Now().Add(-time.Hour)
+		for _, name := range cachedFiles() {
+			Expect(os.Chtimes(filepath.Join(cacheDir, name), past, past)).Should(Succeed())
+		}
+		Expect(fetch(first)).Should(Equal(newData('1')))
+
+		save(third, newData('3'))
+		Expect(cachedFiles()).Should(HaveLen(2))
+		removeFromStore(first)
+		removeFromStore(third)
+		Expect(fetch(first)).Should(Equal(newData('1')))
+		Expect(fetch(third)).Should(Equal(newData('3')))
+	})
+
+	It("should not cache snapshots larger than the cache", func() {
+		snap := newSnapshot(brtypes.SnapshotKindFull, 1)
+		save(snap, bytes.Repeat([]byte{'f'}, 4*dataSize))
+		Expect(cachedFiles()).Should(BeEmpty())
+		Expect(fetch(listed(snap))).Should(HaveLen(4 * dataSize))
+	})
+
+	It("should drop the cache file as soon as a snapshot exceeds the cache", func() {
+		snap := newSnapshot(brtypes.SnapshotKindFull, 1)
+		var filesBeforeEOF []string
+		data := io.MultiReader(bytes.NewReader(bytes.Repeat([]byte{'f'}, 4*dataSize)), &eofReader{onEOF: func() {
+			filesBeforeEOF = cachedFiles()
+		}})
+		Expect(cache.Save(snap, io.NopCloser(data))).Should(Succeed())
+		Expect(filesBeforeEOF).Should(BeEmpty())
+		Expect(cachedFiles()).Should(BeEmpty())
+	})
+
+	It("should fetch corrupted cached snapshots from the wrapped snapstore and remove them from the cache", func() {
+		snap := newSnapshot(brtypes.SnapshotKindFull, 1)
+		save(snap, newData('f'))
+		Expect(cachedFiles()).Should(HaveLen(1))
+		cached := filepath.Join(cacheDir, cachedFiles()[0])
+		f, err := os.OpenFile(cached, os.O_WRONLY, 0600)
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = f.WriteAt([]byte("corrupted"), 1000)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(f.Close()).Should(Succeed())
+
+		Expect(fetch(listed(snap))).Should(Equal(newData('f')))
+		Expect(cachedFiles()).Should(BeEmpty())
+	})
+
+	It("should remove deleted snapshots from the cache", func() {
+		snap := newSnapshot(brtypes.SnapshotKindFull, 1)
+		save(snap, newData('f'))
+		snapList, err := cache.List(false)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(snapList).Should(HaveLen(1))
+		Expect(cache.Delete(*snapList[0])).Should(Succeed())
+		Expect(cachedFiles()).Should(BeEmpty())
+	})
+
+	It("should not cache snapshots which failed to be saved", func() {
+		snap := newSnapshot(brtypes.SnapshotKindFull, 1)
+		failing := io.NopCloser(io.MultiReader(strings.NewReader("partial"), &failingReader{}))
+		Expect(cache.Save(snap, failing)).ShouldNot(Succeed())
+		Expect(cachedFiles()).Should(BeEmpty())
+	})
+
+	It("should serve byte ranges of cached snapshots", func() {
+		snap := newSnapshot(brtypes.SnapshotKindFull, 1)
+		data := append(newData('a'), newData('b')...)
+		cache, err := NewCacheSnapStore(localStore, cacheDir, int64(3*len(data)))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cache.Save(snap, io.NopCloser(bytes.NewReader(data)))).Should(Succeed())
+		removeFromStore(snap)
+
+		size, err := cache.Size(snap)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(size).Should(Equal(int64(len(data))))
+		rc, err := cache.FetchRange(snap, dataSize-1, 2)
+		Expect(err).ShouldNot(HaveOccurred())
+		defer rc.Close()
+		Expect(io.ReadAll(rc)).Should(Equal([]byte("ab")))
+	})
+})
+
+// eofReader calls onEOF when it is read, before it returns io.EOF.
+type eofReader struct {
+	onEOF func()
+}
+
+func (r *eofReader) Read([]byte) (int, error) {
+	r.onEOF()
+	return 0, io.EOF
+}
+
+// failingReader fails every read.
+type failingReader struct{}
+
+func (*failingReader) Read([]byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
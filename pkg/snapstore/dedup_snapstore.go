@@ -0,0 +1,511 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gardener/etcd-backup-restore/pkg/compressor"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// dedupManifestMagic starts every manifest, so that manifests can be told apart from full snapshots
+	// which were saved before deduplication was enabled.
+	dedupManifestMagic = "etcdbr-dedup-manifest-v1\n"
+	// dedupChunkParent names the chunk objects like the chunks of a full snapshot of revision 0 taken at the epoch,
+	// so that every snapstore saves and lists them like any other snapshot chunk.
+	dedupChunkParent = "Full-00000000-00000000-0"
+
+	// dedupMinChunkSize, dedupMaxChunkSize and dedupChunkMask define the content-defined chunking,
+	// which cuts chunks of 1 MiB on average.
+	dedupMinChunkSize = 256 * 1024
+	dedupMaxChunkSize = 4 * 1024 * 1024
+	dedupChunkMask    = 1<<20 - 1
+)
+
+var (
+	// dedupGear holds the random values of the gear hash used to find the chunk boundaries.
+	dedupGear [256]uint64
+
+	dedupLocksMutex sync.Mutex
+	// dedupLocks serialises the removal of unreferenced chunks with the saves per snapstore, so that no chunk
+	// is removed while a save relies on it. It is shared by all snapstores created for the same storage location.
+	dedupLocks = map[string]*sync.RWMutex{}
+)
+
+func init() {
+	// splitmix64 with a fixed seed, so that the chunk boundaries never change between versions.
+	seed := uint64(0x9e3779b97f4a7c15)
+	for i := range dedupGear {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		dedupGear[i] = z ^ (z >> 31)
+	}
+}
+
+func getDedupLock(location string) *sync.RWMutex {
+	dedupLocksMutex.Lock()
+	defer dedupLocksMutex.Unlock()
+	lock, ok := dedupLocks[location]
+	if !ok {
+		lock = &sync.RWMutex{}
+		dedupLocks[location] = lock
+	}
+	return lock
+}
+
+// dedupManifest lists the chunks a deduplicated full snapshot consists of.
+type dedupManifest struct {
+	// Size is the size of the uncompressed full snapshot.
+	Size int64 `json:"size"`
+	// CompressionSuffix is the compression suffix of the full snapshot, which is also used for its chunks.
+	CompressionSuffix string `json:"compressionSuffix,omitempty"`
+	// Chunks holds the chunks in the order of the full snapshot.
+	Chunks []dedupManifestChunk `json:"chunks"`
+}
+
+// dedupManifestChunk is a chunk of a deduplicated full snapshot.
+type dedupManifestChunk struct {
+	// Name is the name of the chunk object below the chunk directory: the SHA-256 of the uncompressed chunk
+	// followed by the compression suffix.
+	Name string `json:"name"`
+	// Size is the size of the uncompressed chunk.
+	Size int64 `json:"size"`
+}
+
+// DedupSnapStore is a snapstore which splits full snapshots into content-defined chunks, which are stored only once
+// no matter how many full snapshots contain them. Each full snapshot is stored as a manifest listing its chunks, which
+// Fetch reassembles into the full snapshot. All other snapshots are passed to the wrapped snapstore unchanged.
+type DedupSnapStore struct {
+	store    brtypes.SnapStore
+	lock     *sync.RWMutex
+	parallel uint
+
+	manifestsMutex sync.Mutex
+	// manifests caches the manifests of the full snapshots by path, nil marks a full snapshot which is not deduplicated.
+	manifests map[string]*dedupManifest
+}
+
+// NewDedupSnapStore returns a snapstore deduplicating the full snapshots saved to the given snapstore. The location
+// identifies the storage location of the snapstore, the chunks are uploaded with the given parallelism.
+func NewDedupSnapStore(store brtypes.SnapStore, location string, parallel uint) *DedupSnapStore {
+	if parallel == 0 {
+		parallel = 1
+	}
+	return &DedupSnapStore{
+		store:     store,
+		lock:      getDedupLock(location),
+		parallel:  parallel,
+		manifests: map[string]*dedupManifest{},
+	}
+}
+
+func dedupLocation(config *brtypes.SnapstoreConfig) string {
+	return path.Join(fanOutStoreName(config), config.Prefix)
+}
+
+// isDeduplicated returns whether the snapshot is stored as a manifest if deduplication is enabled.
+func isDeduplicated(snap brtypes.Snapshot) bool {
+	return snap.Kind == brtypes.SnapshotKindFull && !snap.IsChunk && !isDedupChunk(snap)
+}
+
+// isDedupChunk returns whether the snapshot is a chunk of a deduplicated full snapshot, or a part of one.
+func isDedupChunk(snap brtypes.Snapshot) bool {
+	return strings.HasPrefix(snap.SnapName, dedupChunkParent+"/")
+}
+
+func dedupSnapshotKey(snap brtypes.Snapshot) string {
+	return path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)
+}
+
+// chunkSnapshot returns the snapshot of the chunk object of the given full snapshot.
+func chunkSnapshot(snap brtypes.Snapshot, name string) brtypes.Snapshot {
+	return brtypes.Snapshot{
+		Kind:     brtypes.SnapshotKindFull,
+		SnapName: path.Join(dedupChunkParent, name),
+		Prefix:   snap.Prefix,
+		IsChunk:  true,
+	}
+}
+
+// Save stores a full snapshot as its chunks, which are not stored yet, and a manifest listing them.
+func (d *DedupSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) error {
+	if !isDeduplicated(snap) {
+		return d.store.Save(snap, rc)
+	}
+	defer rc.Close()
+
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	stored, err := d.listChunks()
+	if err != nil {
+		return fmt.Errorf("failed to list chunks: %w", err)
+	}
+	isCompressed, compressionPolicy, err := compressor.IsSnapshotCompressed(snap.CompressionSuffix)
+	if err != nil {
+		return err
+	}
+	var data io.Reader = rc
+	if isCompressed {
+		decompressed, err := compressor.DecompressSnapshot(rc, compressionPolicy)
+		if err != nil {
+			return fmt.Errorf("failed to decompress full snapshot %s: %w", snap.SnapName, err)
+		}
+		defer decompressed.Close()
+		data = decompressed
+	}
+
+	var (
+		manifest = &dedupManifest{CompressionSuffix: snap.CompressionSuffix}
+		uploads  = make(chan []byte)
+		errCh    = make(chan error, d.parallel)
+		wg       sync.WaitGroup
+		uploaded int64
+	)
+	for range d.parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range uploads {
+				if err := d.saveChunk(snap, chunk, isCompressed, compressionPolicy); err != nil {
+					select {
+					case errCh <- err:
+					default:
+					}
+				}
+			}
+		}()
+	}
+
+	chunker := newDedupChunker(data)
+	var chunkErr error
+	for chunkErr == nil {
+		var chunk []byte
+		chunk, chunkErr = chunker.next()
+		if len(chunk) == 0 || (chunkErr != nil && chunkErr != io.EOF) {
+			continue
+		}
+		sum := sha256.Sum256(chunk)
+		name := hex.EncodeToString(sum[:]) + snap.CompressionSuffix
+		manifest.Chunks = append(manifest.Chunks, dedupManifestChunk{Name: name, Size: int64(len(chunk))})
+		manifest.Size += int64(len(chunk))
+		if _, ok := stored[name]; ok {
+			continue
+		}
+		stored[name] = struct{}{}
+		uploaded += int64(len(chunk))
+		uploads <- chunk
+	}
+	close(uploads)
+	wg.Wait()
+	close(errCh)
+	if chunkErr != io.EOF {
+		return fmt.Errorf("failed to read full snapshot %s: %w", snap.SnapName, chunkErr)
+	}
+	if err := <-errCh; err != nil {
+		return fmt.Errorf("failed to save chunks of full snapshot %s: %w", snap.SnapName, err)
+	}
+
+	data, err = encodeDedupManifest(manifest)
+	if err != nil {
+		return err
+	}
+	if err := d.store.Save(snap, io.NopCloser(data)); err != nil {
+		return err
+	}
+	logrus.Infof("Saved full snapshot %s of %d bytes as %d chunks, of which %d bytes were not stored yet", snap.SnapName, manifest.Size, len(manifest.Chunks), uploaded)
+	return nil
+}
+
+// saveChunk saves the chunk, compressed with the compression policy of the full snapshot.
+func (d *DedupSnapStore) saveChunk(snap brtypes.Snapshot, chunk []byte, isCompressed bool, compressionPolicy string) error {
+	sum := sha256.Sum256(chunk)
+	var rc io.ReadCloser = io.NopCloser(bytes.NewReader(chunk))
+	if isCompressed {
+		var err error
+		if rc, err = compressor.CompressSnapshot(rc, compressionPolicy); err != nil {
+			return err
+		}
+	}
+	return d.store.Save(chunkSnapshot(snap, hex.EncodeToString(sum[:])+snap.CompressionSuffix), rc)
+}
+
+func encodeDedupManifest(manifest *dedupManifest) (io.Reader, error) {
+	buf := bytes.NewBufferString(dedupManifestMagic)
+	if err := json.NewEncoder(buf).Encode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return buf, nil
+}
+
+// listChunks returns the names of the chunks stored in the wrapped snapstore.
+func (d *DedupSnapStore) listChunks() (map[string]struct{}, error) {
+	snapList, err := d.store.List(true)
+	if err != nil {
+		return nil, err
+	}
+	chunks := map[string]struct{}{}
+	for _, snap := range snapList {
+		if isDedupChunk(*snap) {
+			chunks[strings.TrimPrefix(snap.SnapName, dedupChunkParent+"/")] = struct{}{}
+		}
+	}
+	return chunks, nil
+}
+
+// FetchMetadata returns the metadata of the snapshot, which is stored with the manifest of deduplicated full snapshots.
+func (d *DedupSnapStore) FetchMetadata(snap brtypes.Snapshot) (map[string]string, error) {
+	mf, ok := d.store.(brtypes.MetadataFetcher)
+	if !ok {
+		return nil, brtypes.ErrMetadataFetchUnsupported
+	}
+	return mf.FetchMetadata(snap)
+}
+
+// Fetch opens a reader for the snapshot. Deduplicated full snapshots are reassembled from their chunks.
+func (d *DedupSnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
+	rc, err := d.store.Fetch(snap)
+	if err != nil || !isDeduplicated(snap) {
+		return rc, err
+	}
+	br := bufio.NewReader(rc)
+	manifest, err := readDedupManifest(br)
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("failed to read manifest of full snapshot %s: %w", snap.SnapName, err)
+	}
+	if manifest == nil {
+		// the full snapshot was saved before deduplication was enabled
+		return struct {
+			io.Reader
+			io.Closer
+		}{br, rc}, nil
+	}
+	rc.Close()
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(d.writeChunks(snap, manifest, pw))
+	}()
+	isCompressed, compressionPolicy, err := compressor.IsSnapshotCompressed(manifest.CompressionSuffix)
+	if err != nil || !isCompressed {
+		return pr, err
+	}
+	return compressor.CompressSnapshot(pr, compressionPolicy)
+}
+
+// readDedupManifest reads the manifest from the reader. It returns nil if the reader does not start with a manifest.
+func readDedupManifest(br *bufio.Reader) (*dedupManifest, error) {
+	magic, err := br.Peek(len(dedupManifestMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if string(magic) != dedupManifestMagic {
+		return nil, nil
+	}
+	if _, err := br.Discard(len(dedupManifestMagic)); err != nil {
+		return nil, err
+	}
+	manifest := &dedupManifest{}
+	if err := json.NewDecoder(br).Decode(manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// writeChunks writes the uncompressed chunks of the full snapshot in order, verifying the checksum of each of them.
+func (d *DedupSnapStore) writeChunks(snap brtypes.Snapshot, manifest *dedupManifest, w io.Writer) error {
+	isCompressed, compressionPolicy, err := compressor.IsSnapshotCompressed(manifest.CompressionSuffix)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range manifest.Chunks {
+		rc, err := d.store.Fetch(chunkSnapshot(snap, chunk.Name))
+		if err != nil {
+			return fmt.Errorf("failed to fetch chunk %s of full snapshot %s: %w", chunk.Name, snap.SnapName, err)
+		}
+		data := rc
+		if isCompressed {
+			if data, err = compressor.DecompressSnapshot(rc, compressionPolicy); err != nil {
+				rc.Close()
+				return fmt.Errorf("failed to decompress chunk %s of full snapshot %s: %w", chunk.Name, snap.SnapName, err)
+			}
+		}
+		sum := sha256.New()
+		n, err := io.Copy(io.MultiWriter(w, sum), data)
+		data.Close()
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to copy chunk %s of full snapshot %s: %w", chunk.Name, snap.SnapName, err)
+		}
+		if n != chunk.Size || hex.EncodeToString(sum.Sum(nil))+manifest.CompressionSuffix != chunk.Name {
+			return fmt.Errorf("chunk %s of full snapshot %s is corrupted", chunk.Name, snap.SnapName)
+		}
+	}
+	return nil
+}
+
+// List returns the snapshots of the wrapped snapstore without the chunks of the deduplicated full snapshots.
+func (d *DedupSnapStore) List(includeAll bool) (brtypes.SnapList, error) {
+	snapList, err := d.store.List(includeAll)
+	if err != nil {
+		return nil, err
+	}
+	filteredSnapList := brtypes.SnapList{}
+	for _, snap := range snapList {
+		if !isDedupChunk(*snap) {
+			filteredSnapList = append(filteredSnapList, snap)
+		}
+	}
+	return filteredSnapList, nil
+}
+
+// Delete deletes the snapshot. After deleting a deduplicated full snapshot, the chunks which are not referenced by
+// any full snapshot anymore are deleted as well.
+func (d *DedupSnapStore) Delete(snap brtypes.Snapshot) error {
+	if !isDeduplicated(snap) {
+		return d.store.Delete(snap)
+	}
+	if err := d.store.Delete(snap); err != nil {
+		return err
+	}
+	d.manifestsMutex.Lock()
+	delete(d.manifests, dedupSnapshotKey(snap))
+	d.manifestsMutex.Unlock()
+	if err := d.GarbageCollectChunks(); err != nil {
+		logrus.Warnf("Failed to garbage collect chunks after deleting full snapshot %s: %v", snap.SnapName, err)
+	}
+	return nil
+}
+
+// GarbageCollectChunks deletes the chunks which are not referenced by any full snapshot. These are the chunks of
+// deleted full snapshots, as well as leftovers of saves which failed.
+func (d *DedupSnapStore) GarbageCollectChunks() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	snapList, err := d.store.List(true)
+	if err != nil {
+		return err
+	}
+	var (
+		chunks     []*brtypes.Snapshot
+		referenced = map[string]struct{}{}
+		listed     = map[string]struct{}{}
+	)
+	for _, snap := range snapList {
+		switch {
+		case isDedupChunk(*snap):
+			chunks = append(chunks, snap)
+		case isDeduplicated(*snap):
+			manifest, err := d.getManifest(*snap)
+			if err != nil {
+				// the chunks of a full snapshot whose manifest cannot be read must be kept
+				return fmt.Errorf("failed to read manifest of full snapshot %s: %w", snap.SnapName, err)
+			}
+			listed[dedupSnapshotKey(*snap)] = struct{}{}
+			if manifest == nil {
+				continue
+			}
+			for _, chunk := range manifest.Chunks {
+				referenced[path.Join(dedupChunkParent, chunk.Name)] = struct{}{}
+			}
+		}
+	}
+	d.manifestsMutex.Lock()
+	for key := range d.manifests {
+		if _, ok := listed[key]; !ok {
+			delete(d.manifests, key)
+		}
+	}
+	d.manifestsMutex.Unlock()
+
+	var errs []error
+	deleted := 0
+	for _, chunk := range chunks {
+		if _, ok := referenced[chunk.SnapName]; ok || !chunk.IsDeletable() {
+			continue
+		}
+		if err := d.store.Delete(*chunk); err != nil {
+			if !errors.Is(err, brtypes.ErrSnapshotDeleteFailDueToImmutability) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		deleted++
+	}
+	if deleted > 0 {
+		logrus.Infof("Deleted %d chunks which are not referenced by any full snapshot", deleted)
+	}
+	return errors.Join(errs...)
+}
+
+// getManifest returns the manifest of the full snapshot, or nil if the full snapshot is not deduplicated.
+func (d *DedupSnapStore) getManifest(snap brtypes.Snapshot) (*dedupManifest, error) {
+	key := dedupSnapshotKey(snap)
+	d.manifestsMutex.Lock()
+	manifest, ok := d.manifests[key]
+	d.manifestsMutex.Unlock()
+	if ok {
+		return manifest, nil
+	}
+	rc, err := d.store.Fetch(snap)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	if manifest, err = readDedupManifest(bufio.NewReader(rc)); err != nil {
+		return nil, err
+	}
+	d.manifestsMutex.Lock()
+	d.manifests[key] = manifest
+	d.manifestsMutex.Unlock()
+	return manifest, nil
+}
+
+// dedupChunker splits a stream into content-defined chunks using a gear hash, so that an insertion or removal
+// only changes the chunks around it.
+type dedupChunker struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+func newDedupChunker(r io.Reader) *dedupChunker {
+	return &dedupChunker{r: bufio.NewReaderSize(r, 1<<20)}
+}
+
+// next returns the next chunk. It returns io.EOF together with the last chunk, which may be empty.
+func (c *dedupChunker) next() ([]byte, error) {
+	c.buf = make([]byte, 0, dedupMinChunkSize)
+	var fp uint64
+	for len(c.buf) < dedupMaxChunkSize {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return c.buf, err
+		}
+		c.buf = append(c.buf, b)
+		fp = (fp << 1) + dedupGear[b]
+		if len(c.buf) >= dedupMinChunkSize && fp&dedupChunkMask == 0 {
+			break
+		}
+	}
+	return c.buf, nil
+}
@@ -0,0 +1,204 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore_test
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/compressor"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	. "github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DedupSnapStore", func() {
+	const dataSize = 8 * 1024 * 1024
+	var (
+		localStore *LocalSnapStore
+		dedup      *DedupSnapStore
+		data       []byte
+	)
+
+	newFullSnapshot := func(lastRevision int64, compressionSuffix string) brtypes.Snapshot {
+		snap := brtypes.Snapshot{
+			Kind:              brtypes.SnapshotKindFull,
+			LastRevision:      lastRevision,
+			CreatedOn:         time.Unix(lastRevision, 0).UTC(),
+			CompressionSuffix: compressionSuffix,
+		}
+		snap.GenerateSnapshotName()
+		return snap
+	}
+
+	// listed returns the snapshot as listed by the local snapstore, which sets its prefix.
+	listed := func(snap brtypes.Snapshot) brtypes.Snapshot {
+		snapList, err := dedup.List(true)
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, s := range snapList {
+			if s.SnapName == snap.SnapName {
+				return *s
+			}
+		}
+		Fail("snapshot " + snap.SnapName + " not listed")
+		return brtypes.Snapshot{}
+	}
+
+	chunks := func() []string {
+		snapList, err := localStore.List(true)
+		Expect(err).ShouldNot(HaveOccurred())
+		var names []string
+		for _, snap := range snapList {
+			if strings.HasPrefix(snap.SnapName, "Full-00000000-00000000-0/") {
+				names = append(names, snap.SnapName)
+			}
+		}
+		return names
+	}
+
+	fetch := func(snap brtypes.Snapshot) []byte {
+		rc, err := dedup.Fetch(listed(snap))
+		Expect(err).ShouldNot(HaveOccurred())
+		defer rc.Close()
+		fetched, err := io.ReadAll(rc)
+		Expect(err).ShouldNot(HaveOccurred())
+		return fetched
+	}
+
+	BeforeEach(func() {
+		var err error
+		localStore, err = NewLocalSnapStore(path.Join(GinkgoT().TempDir(), "v2"))
+		Expect(err).ShouldNot(HaveOccurred())
+		dedup = NewDedupSnapStore(localStore, GinkgoT().TempDir(), 2)
+		data = make([]byte, dataSize)
+		rand.New(rand.NewSource(1)).Read(data)
+	})
+
+	It("should reassemble a full snapshot from its chunks", func() {
+		snap := newFullSnapshot(1, "")
+		Expect(dedup.Save(snap, io.NopCloser(bytes.NewReader(data)))).Should(Succeed())
+		Expect(len(chunks())).Should(BeNumerically(">", 1))
+		Expect(fetch(snap)).Should(Equal(data))
+	})
+
+	It("should reassemble a compressed full snapshot from its compressed chunks", func() {
+		snap := newFullSnapshot(1, compressor.GzipCompressionExtension)
+		compressed, err := compressor.CompressSnapshot(io.NopCloser(bytes.NewReader(data)), compressor.GzipCompressionPolicy)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(dedup.Save(snap, compressed)).Should(Succeed())
+		for _, chunk := range chunks() {
+			Expect(chunk).Should(HaveSuffix(compressor.GzipCompressionExtension))
+		}
+
+		decompressed, err := compressor.DecompressSnapshot(io.NopCloser(bytes.NewReader(fetch(snap))), compressor.GzipCompressionPolicy)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(io.ReadAll(decompressed)).Should(Equal(data))
+	})
+
+	It("should store unchanged chunks of a later full snapshot only once", func() {
+		first := newFullSnapshot(1, "")
+		Expect(dedup.Save(first, io.NopCloser(bytes.NewReader(data)))).Should(Succeed())
+		firstChunks := len(chunks())
+
+		changed := append([]byte{}, data...)
+		copy(changed[dataSize/2:], "changed in the middle")
+		second := newFullSnapshot(2, "")
+		Expect(dedup.Save(second, io.NopCloser(bytes.NewReader(changed)))).Should(Succeed())
+		Expect(len(chunks())).Should(BeNumerically("<=", firstChunks+2))
+
+		Expect(fetch(first)).Should(Equal(data))
+		Expect(fetch(second)).Should(Equal(changed))
+	})
+
+	It("should not list the chunks", func() {
+		snap := newFullSnapshot(1, "")
+		Expect(dedup.Save(snap, io.NopCloser(bytes.NewReader(data)))).Should(Succeed())
+		snapList, err := dedup.List(false)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(snapList).Should(HaveLen(1))
+		Expect(snapList[0].SnapName).Should(Equal(snap.SnapName))
+	})
+
+	It("should delete only the chunks which are not referenced anymore", func() {
+		first := newFullSnapshot(1, "")
+		Expect(dedup.Save(first, io.NopCloser(bytes.NewReader(data)))).Should(Succeed())
+		firstChunks := chunks()
+
+		changed := append([]byte{}, data...)
+		copy(changed, "changed at the start")
+		second := newFullSnapshot(2, "")
+		Expect(dedup.Save(second, io.NopCloser(bytes.NewReader(changed)))).Should(Succeed())
+		var secondChunks []string
+		for _, chunk := range chunks() {
+			if !slices.Contains(firstChunks, chunk) {
+				secondChunks = append(secondChunks, chunk)
+			}
+		}
+		Expect(secondChunks).ShouldNot(BeEmpty())
+
+		Expect(dedup.Delete(listed(first))).Should(Succeed())
+		Expect(chunks()).Should(HaveLen(len(firstChunks)))
+		Expect(chunks()).Should(ContainElements(secondChunks))
+		Expect(fetch(second)).Should(Equal(changed))
+
+		Expect(dedup.Delete(listed(second))).Should(Succeed())
+		Expect(chunks()).Should(BeEmpty())
+	})
+
+	It("should delete the leftovers of failed saves", func() {
+		snap := newFullSnapshot(1, "")
+		Expect(dedup.Save(snap, io.NopCloser(bytes.NewReader(data)))).Should(Succeed())
+		Expect(localStore.Delete(listed(snap))).Should(Succeed())
+		Expect(chunks()).ShouldNot(BeEmpty())
+
+		Expect(dedup.GarbageCollectChunks()).Should(Succeed())
+		Expect(chunks()).Should(BeEmpty())
+	})
+
+	It("should fetch full snapshots which were saved before deduplication was enabled", func() {
+		snap := newFullSnapshot(1, "")
+		Expect(localStore.Save(snap, io.NopCloser(bytes.NewReader(data)))).Should(Succeed())
+		Expect(fetch(snap)).Should(Equal(data))
+		Expect(dedup.GarbageCollectChunks()).Should(Succeed())
+		Expect(fetch(snap)).Should(Equal(data))
+	})
+
+	It("should store delta snapshots unchanged", func() {
+		snap := brtypes.Snapshot{
+			Kind:          brtypes.SnapshotKindDelta,
+			StartRevision: 2,
+			LastRevision:  3,
+			CreatedOn:     time.Unix(3, 0).UTC(),
+		}
+		snap.GenerateSnapshotName()
+		Expect(dedup.Save(snap, io.NopCloser(strings.NewReader("delta")))).Should(Succeed())
+		Expect(chunks()).Should(BeEmpty())
+
+		rc, err := localStore.Fetch(listed(snap))
+		Expect(err).ShouldNot(HaveOccurred())
+		defer rc.Close()
+		Expect(io.ReadAll(rc)).Should(Equal([]byte("delta")))
+	})
+
+	It("should fail to fetch a full snapshot with a corrupted chunk", func() {
+		snap := newFullSnapshot(1, "")
+		Expect(dedup.Save(snap, io.NopCloser(bytes.NewReader(data)))).Should(Succeed())
+		chunk := chunks()[0]
+		Expect(localStore.Save(brtypes.Snapshot{SnapName: chunk, IsChunk: true}, io.NopCloser(strings.NewReader("corrupted")))).Should(Succeed())
+
+		rc, err := dedup.Fetch(listed(snap))
+		Expect(err).ShouldNot(HaveOccurred())
+		defer rc.Close()
+		_, err = io.ReadAll(rc)
+		Expect(err).Should(MatchError(ContainSubstring("corrupted")))
+	})
+})
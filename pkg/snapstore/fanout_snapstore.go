@@ -0,0 +1,697 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/metrics"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/sirupsen/logrus"
+)
+
+// errFanOutSaveReturned is used to unblock the writes to a snapstore which returned from Save without reading the whole snapshot.
+var errFanOutSaveReturned = errors.New("snapstore returned from save")
+
+var (
+	fanOutRepairsMutex sync.Mutex
+	// fanOutRepairs holds the pending repairs per fan-out config, so that they survive the recreation of the snapstores.
+	fanOutRepairs = map[*brtypes.FanOutConfig]*fanOutRepairQueue{}
+)
+
+// fanOutRepair is an operation which failed on a snapstore of a fan-out and has to be repeated.
+type fanOutRepair struct {
+	snap   brtypes.Snapshot
+	delete bool
+}
+
+// fanOutRepairQueue holds the pending repairs of each snapstore of a fan-out, keyed by the snapshot.
+type fanOutRepairQueue struct {
+	mutex   sync.Mutex
+	names   []string
+	pending []map[string]fanOutRepair
+	// inFlight counts the saves and deletes in progress per snapshot, whose snapshots may be missing in some snapstores for now.
+	inFlight map[string]int
+}
+
+func newFanOutRepairQueue(names []string) *fanOutRepairQueue {
+	q := &fanOutRepairQueue{
+		names:    names,
+		pending:  make([]map[string]fanOutRepair, len(names)),
+		inFlight: map[string]int{},
+	}
+	for i := range q.pending {
+		q.pending[i] = map[string]fanOutRepair{}
+	}
+	return q
+}
+
+// getFanOutRepairQueue returns the repair queue shared by all snapstores created for the given fan-out config.
+func getFanOutRepairQueue(config *brtypes.FanOutConfig, names []string) *fanOutRepairQueue {
+	fanOutRepairsMutex.Lock()
+	defer fanOutRepairsMutex.Unlock()
+	q, ok := fanOutRepairs[config]
+	if !ok || len(q.names) != len(names) {
+		q = newFanOutRepairQueue(names)
+		fanOutRepairs[config] = q
+	}
+	return q
+}
+
+// add records the repair for the snapstore. If overwrite is false, a repair recorded meanwhile is kept.
+func (q *fanOutRepairQueue) add(store int, key string, repair fanOutRepair, overwrite bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if _, ok := q.pending[store][key]; ok && !overwrite {
+		return
+	}
+	q.pending[store][key] = repair
+	q.updateMetrics(store)
+}
+
+// remove drops the repair of the snapshot for the snapstore.
+func (q *fanOutRepairQueue) remove(store int, key string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	delete(q.pending[store], key)
+	q.updateMetrics(store)
+}
+
+// take returns all pending repairs and clears the queue.
+func (q *fanOutRepairQueue) take() []map[string]fanOutRepair {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	pending := q.pending
+	q.pending = make([]map[string]fanOutRepair, len(pending))
+	for i := range q.pending {
+		q.pending[i] = map[string]fanOutRepair{}
+	}
+	return pending
+}
+
+// pendingDelete returns whether a delete of the snapshot is pending for any snapstore.
+func (q *fanOutRepairQueue) pendingDelete(key string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for _, repairs := range q.pending {
+		if repair, ok := repairs[key]; ok && repair.delete {
+			return true
+		}
+	}
+	return false
+}
+
+// start marks a save or delete of the snapshot as in progress until finish is called.
+func (q *fanOutRepairQueue) start(key string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.inFlight[key]++
+}
+
+// finish marks a save or delete of the snapshot started by start as done.
+func (q *fanOutRepairQueue) finish(key string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.inFlight[key]--; q.inFlight[key] <= 0 {
+		delete(q.inFlight, key)
+	}
+}
+
+// busy returns whether a save or delete of the snapshot is in progress.
+func (q *fanOutRepairQueue) busy(key string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.inFlight[key] > 0
+}
+
+// len returns the number of pending repairs of the snapstore.
+func (q *fanOutRepairQueue) len(store int) int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.pending[store])
+}
+
+func (q *fanOutRepairQueue) updateMetrics(store int) {
+	metrics.SnapstoreFanOutPendingRepairs.With(map[string]string{metrics.LabelStore: q.names[store]}).Set(float64(len(q.pending[store])))
+}
+
+// FanOutSnapStore is a snapstore which writes every snapshot to several snapstores at once.
+// Snapshots which could not be saved to or deleted from some of the snapstores are repaired later by Repair.
+type FanOutSnapStore struct {
+	stores  []brtypes.SnapStore
+	policy  string
+	repairs *fanOutRepairQueue
+
+	snapsMutex sync.Mutex
+	// snaps holds the snapshots found by the latest List, keyed by the snapshot and the index of the snapstore
+	// holding it, since the prefix and version of a snapshot differ between the snapstores.
+	snaps map[string]map[int]brtypes.Snapshot
+	// listed holds whether each snapstore could be listed by the latest List.
+	listed []bool
+}
+
+// NewFanOutSnapStore returns a snapstore which writes every snapshot to all the given snapstores. The policy decides
+// to how many of them a snapshot has to be saved, the names identify the snapstores in logs and metrics.
+func NewFanOutSnapStore(stores []brtypes.SnapStore, names []string, policy string) (*FanOutSnapStore, error) {
+	return newFanOutSnapStore(stores, policy, newFanOutRepairQueue(names))
+}
+
+func newFanOutSnapStore(stores []brtypes.SnapStore, policy string, repairs *fanOutRepairQueue) (*FanOutSnapStore, error) {
+	if len(stores) == 0 {
+		return nil, fmt.Errorf("fan-out requires at least one snapstore")
+	}
+	if len(repairs.names) != len(stores) {
+		return nil, fmt.Errorf("fan-out requires a name for each of the %d snapstores", len(stores))
+	}
+	switch policy {
+	case brtypes.FanOutPolicyAll, brtypes.FanOutPolicyAny, brtypes.FanOutPolicyMajority:
+	default:
+		return nil, fmt.Errorf("unsupported fan-out policy %q", policy)
+	}
+	return &FanOutSnapStore{
+		stores:  stores,
+		policy:  policy,
+		repairs: repairs,
+		snaps:   map[string]map[int]brtypes.Snapshot{},
+		listed:  make([]bool, len(stores)),
+	}, nil
+}
+
+// newFanOutSnapStoreFromConfig returns a snapstore fanning out to the given primary snapstore and the snapstores of the fan-out config.
+func newFanOutSnapStoreFromConfig(config *brtypes.SnapstoreConfig, primary brtypes.SnapStore) (*FanOutSnapStore, error) {
+	stores := []brtypes.SnapStore{primary}
+	names := []string{fanOutStoreName(config)}
+	for _, storeConfig := range config.FanOut.StoreConfigs {
+		store, err := getSnapstore(storeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create fan-out snapstore %s: %w", fanOutStoreName(storeConfig), err)
+		}
+		stores = append(stores, store)
+		names = append(names, fanOutStoreName(storeConfig))
+	}
+	return newFanOutSnapStore(stores, config.FanOut.Policy, getFanOutRepairQueue(config.FanOut, names))
+}
+
+func fanOutStoreName(config *brtypes.SnapstoreConfig) string {
+	provider := config.Provider
+	if provider == "" {
+		provider = brtypes.SnapstoreProviderLocal
+	}
+	return path.Join(provider, config.Container)
+}
+
+func fanOutSnapshotKey(snap brtypes.Snapshot) string {
+	return path.Join(snap.SnapDir, snap.SnapName)
+}
+
+// requiredSaves returns to how many snapstores a snapshot has to be saved according to the policy.
+func (f *FanOutSnapStore) requiredSaves() int {
+	switch f.policy {
+	case brtypes.FanOutPolicyAny:
+		return 1
+	case brtypes.FanOutPolicyMajority:
+		return len(f.stores)/2 + 1
+	default:
+		return len(f.stores)
+	}
+}
+
+// Save writes the snapshot to all snapstores in parallel. It fails if the snapshot could not be saved to as many
+// snapstores as the policy requires, in which case the snapshot is removed from the snapstores it was saved to.
+// Otherwise the snapstores which failed are repaired later.
+func (f *FanOutSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) error {
+	defer rc.Close()
+	key := fanOutSnapshotKey(snap)
+	f.repairs.start(key)
+	defer f.repairs.finish(key)
+
+	writers := make([]*io.PipeWriter, len(f.stores))
+	errs := make([]error, len(f.stores))
+	var wg sync.WaitGroup
+	for i, store := range f.stores {
+		pr, pw := io.Pipe()
+		writers[i] = pw
+		wg.Add(1)
+		go func(i int, store brtypes.SnapStore, pr *io.PipeReader) {
+			defer wg.Done()
+			errs[i] = store.Save(snap, pr)
+			pr.CloseWithError(errFanOutSaveReturned)
+		}(i, store, pr)
+	}
+	readErr := teeToPipes(rc, writers)
+	wg.Wait()
+	if readErr != nil {
+		f.rollbackSave(snap, errs)
+		return fmt.Errorf("failed to read snapshot %s: %w", snap.SnapName, readErr)
+	}
+
+	saved := 0
+	for i, err := range errs {
+		if err == nil {
+			saved++
+			f.repairs.remove(i, key)
+		}
+	}
+	if saved < f.requiredSaves() {
+		f.rollbackSave(snap, errs)
+		return fmt.Errorf("failed to save snapshot %s to %d of the %d snapstores required by the %s policy: %w", snap.SnapName, f.requiredSaves()-saved, f.requiredSaves(), f.policy, errors.Join(errs...))
+	}
+	for i, err := range errs {
+		if err != nil {
+			logrus.Warnf("Failed to save snapshot %s to snapstore %s, it will be repaired later: %v", snap.SnapName, f.repairs.names[i], err)
+			f.repairs.add(i, key, fanOutRepair{snap: snap}, true)
+		}
+	}
+	return nil
+}
+
+// teeToPipes copies the reader to all writers. A writer which fails is skipped from then on.
+func teeToPipes(r io.Reader, writers []*io.PipeWriter) error {
+	buf := make([]byte, 32*1024)
+	failed := make([]bool, len(writers))
+	active := len(writers)
+	for active > 0 {
+		n, err := r.Read(buf)
+		if n > 0 {
+			for i, w := range writers {
+				if failed[i] {
+					continue
+				}
+				if _, werr := w.Write(buf[:n]); werr != nil {
+					failed[i] = true
+					active--
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			for _, w := range writers {
+				w.CloseWithError(err)
+			}
+			return err
+		}
+	}
+	for _, w := range writers {
+		w.Close()
+	}
+	return nil
+}
+
+// rollbackSave deletes a snapshot which failed to be saved as a whole from the snapstores it was saved to,
+// so that the snapstores do not diverge from what the snapshotter considers saved.
+func (f *FanOutSnapStore) rollbackSave(snap brtypes.Snapshot, errs []error) {
+	var saved []int
+	for i, err := range errs {
+		if err == nil {
+			saved = append(saved, i)
+		}
+	}
+	if len(saved) == 0 {
+		return
+	}
+	snaps, _, err := f.refresh(true)
+	if err != nil {
+		logrus.Warnf("Failed to list snapstores to roll back snapshot %s: %v", snap.SnapName, err)
+		return
+	}
+	key := fanOutSnapshotKey(snap)
+	for _, i := range saved {
+		holderSnap, ok := snaps[key][i]
+		if !ok {
+			continue
+		}
+		if err := f.stores[i].Delete(holderSnap); err != nil {
+			logrus.Warnf("Failed to roll back snapshot %s from snapstore %s, it will be repaired later: %v", snap.SnapName, f.repairs.names[i], err)
+			f.repairs.add(i, key, fanOutRepair{snap: holderSnap, delete: true}, true)
+		}
+	}
+}
+
+// List returns the union of the snapshots of all snapstores. It only fails if none of the snapstores could be listed.
+func (f *FanOutSnapStore) List(includeAll bool) (brtypes.SnapList, error) {
+	lists := make([]brtypes.SnapList, len(f.stores))
+	errs := make([]error, len(f.stores))
+	var wg sync.WaitGroup
+	for i, store := range f.stores {
+		wg.Add(1)
+		go func(i int, store brtypes.SnapStore) {
+			defer wg.Done()
+			lists[i], errs[i] = store.List(includeAll)
+		}(i, store)
+	}
+	wg.Wait()
+
+	snaps := map[string]map[int]brtypes.Snapshot{}
+	snapList := brtypes.SnapList{}
+	listed := make([]bool, len(f.stores))
+	listedCount := 0
+	for i := range f.stores {
+		if errs[i] != nil {
+			logrus.Warnf("Failed to list snapshots of snapstore %s: %v", f.repairs.names[i], errs[i])
+			continue
+		}
+		listed[i] = true
+		listedCount++
+		for _, snap := range lists[i] {
+			key := fanOutSnapshotKey(*snap)
+			if _, ok := snaps[key]; !ok {
+				snaps[key] = map[int]brtypes.Snapshot{}
+				snapList = append(snapList, snap)
+			}
+			snaps[key][i] = *snap
+		}
+	}
+	if listedCount == 0 {
+		return nil, fmt.Errorf("failed to list snapshots of all snapstores: %w", errors.Join(errs...))
+	}
+
+	f.snapsMutex.Lock()
+	f.snaps = snaps
+	f.listed = listed
+	f.snapsMutex.Unlock()
+
+	sort.Sort(snapList)
+	return snapList, nil
+}
+
+// refresh lists the snapstores and returns the snapshots found, keyed by the snapshot and the index of the snapstore,
+// together with whether each snapstore could be listed.
+func (f *FanOutSnapStore) refresh(includeAll bool) (map[string]map[int]brtypes.Snapshot, []bool, error) {
+	if _, err := f.List(includeAll); err != nil {
+		return nil, nil, err
+	}
+	f.snapsMutex.Lock()
+	defer f.snapsMutex.Unlock()
+	return f.snaps, f.listed, nil
+}
+
+// holders returns the indices of the snapstores holding the snapshot in ascending order, together with the
+// snapshot as listed by each of them. The snapstores are listed again if the snapshot is not known yet.
+func (f *FanOutSnapStore) holders(snap brtypes.Snapshot) ([]int, map[int]brtypes.Snapshot, error) {
+	key := fanOutSnapshotKey(snap)
+	f.snapsMutex.Lock()
+	holderSnaps, ok := f.snaps[key]
+	f.snapsMutex.Unlock()
+	if !ok {
+		snaps, _, err := f.refresh(true)
+		if err != nil {
+			return nil, nil, err
+		}
+		if holderSnaps, ok = snaps[key]; !ok {
+			return nil, nil, fmt.Errorf("snapshot %s not found in any snapstore", snap.SnapName)
+		}
+	}
+	indices := make([]int, 0, len(holderSnaps))
+	for i := range holderSnaps {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices, holderSnaps, nil
+}
+
+// Fetch opens a reader for the snapshot from the first snapstore holding it which can be read.
+func (f *FanOutSnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
+	indices, holderSnaps, err := f.holders(snap)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, i := range indices {
+		rc, err := f.stores[i].Fetch(holderSnaps[i])
+		if err == nil {
+			return rc, nil
+		}
+		logrus.Warnf("Failed to fetch snapshot %s from snapstore %s: %v", snap.SnapName, f.repairs.names[i], err)
+		errs = append(errs, err)
+	}
+	return nil, fmt.Errorf("failed to fetch snapshot %s from all snapstores: %w", snap.SnapName, errors.Join(errs...))
+}
+
+// Size returns the size of the snapshot from the first snapstore holding it which supports ranged fetches.
+// It returns brtypes.ErrRangeFetchUnsupported if none of the snapstores holding it supports ranged fetches.
+func (f *FanOutSnapStore) Size(snap brtypes.Snapshot) (int64, error) {
+	indices, holderSnaps, err := f.holders(snap)
+	if err != nil {
+		return -1, err
+	}
+	var errs []error
+	for _, i := range indices {
+		rf, ok := f.stores[i].(brtypes.RangeFetcher)
+		if !ok {
+			continue
+		}
+		size, err := rf.Size(holderSnaps[i])
+		if err == nil {
+			return size, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return -1, brtypes.ErrRangeFetchUnsupported
+	}
+	return -1, fmt.Errorf("failed to get size of snapshot %s from all snapstores: %w", snap.SnapName, errors.Join(errs...))
+}
+
+// FetchRange opens a reader for a byte range of the snapshot from the first snapstore holding it which supports ranged fetches.
+func (f *FanOutSnapStore) FetchRange(snap brtypes.Snapshot, offset, length int64) (io.ReadCloser, error) {
+	indices, holderSnaps, err := f.holders(snap)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, i := range indices {
+		rf, ok := f.stores[i].(brtypes.RangeFetcher)
+		if !ok {
+			continue
+		}
+		rc, err := rf.FetchRange(holderSnaps[i], offset, length)
+		if err == nil {
+			return rc, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, brtypes.ErrRangeFetchUnsupported
+	}
+	return nil, fmt.Errorf("failed to fetch range of snapshot %s from all snapstores: %w", snap.SnapName, errors.Join(errs...))
+}
+
+// FetchMetadata returns the metadata of the snapshot from the first snapstore holding it which stores metadata.
+func (f *FanOutSnapStore) FetchMetadata(snap brtypes.Snapshot) (map[string]string, error) {
+	indices, holderSnaps, err := f.holders(snap)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, i := range indices {
+		mf, ok := f.stores[i].(brtypes.MetadataFetcher)
+		if !ok {
+			continue
+		}
+		metadata, err := mf.FetchMetadata(holderSnaps[i])
+		if err == nil {
+			return metadata, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, brtypes.ErrMetadataFetchUnsupported
+	}
+	return nil, fmt.Errorf("failed to fetch metadata of snapshot %s from all snapstores: %w", snap.SnapName, errors.Join(errs...))
+}
+
+// Delete deletes the snapshot from all snapstores holding it. It fails if the snapshot is immutable in any of the
+// snapstores or could not be deleted from any of them. Otherwise the snapstores which failed are repaired later.
+func (f *FanOutSnapStore) Delete(snap brtypes.Snapshot) error {
+	indices, holderSnaps, err := f.holders(snap)
+	if err != nil {
+		return err
+	}
+	key := fanOutSnapshotKey(snap)
+	f.repairs.start(key)
+	defer f.repairs.finish(key)
+	for i := range f.stores {
+		if _, ok := holderSnaps[i]; !ok {
+			// a pending save of a deleted snapshot must not be repaired anymore
+			f.repairs.remove(i, key)
+		}
+	}
+
+	var (
+		errs         []error
+		immutableErr error
+		deleted      int
+	)
+	for _, i := range indices {
+		err := f.stores[i].Delete(holderSnaps[i])
+		switch {
+		case err == nil:
+			deleted++
+			f.repairs.remove(i, key)
+			f.snapsMutex.Lock()
+			delete(f.snaps[key], i)
+			f.snapsMutex.Unlock()
+		case errors.Is(err, brtypes.ErrSnapshotDeleteFailDueToImmutability):
+			immutableErr = err
+		default:
+			errs = append(errs, err)
+			logrus.Warnf("Failed to delete snapshot %s from snapstore %s, it will be repaired later: %v", snap.SnapName, f.repairs.names[i], err)
+			f.repairs.add(i, key, fanOutRepair{snap: holderSnaps[i], delete: true}, true)
+		}
+	}
+	if immutableErr != nil {
+		return immutableErr
+	}
+	if deleted == 0 {
+		return fmt.Errorf("failed to delete snapshot %s from all snapstores: %w", snap.SnapName, errors.Join(errs...))
+	}
+	return nil
+}
+
+// PendingRepairs returns the number of snapshots which could not be saved to or deleted from each snapstore yet.
+func (f *FanOutSnapStore) PendingRepairs() []int {
+	pending := make([]int, len(f.stores))
+	for i := range f.stores {
+		pending[i] = f.repairs.len(i)
+	}
+	return pending
+}
+
+// Repair repeats the saves and deletes which failed on some of the snapstores. Failed saves are repaired by
+// copying the snapshot from another snapstore holding it. Repairs which fail again are kept for the next attempt.
+// Snapshots missing in some of the snapstores without a pending repair, e.g. since the pending repairs were lost
+// on a restart, are copied as well.
+func (f *FanOutSnapStore) Repair() error {
+	if err := f.queueMissingCopies(); err != nil {
+		return err
+	}
+	pending := f.repairs.take()
+	total := 0
+	for i, repairs := range pending {
+		total += len(repairs)
+		f.repairs.mutex.Lock()
+		f.repairs.updateMetrics(i)
+		f.repairs.mutex.Unlock()
+	}
+	if total == 0 {
+		return nil
+	}
+
+	snaps, _, err := f.refresh(true)
+	if err != nil {
+		for i, repairs := range pending {
+			for key, repair := range repairs {
+				f.repairs.add(i, key, repair, false)
+			}
+		}
+		return err
+	}
+
+	var errs []error
+	for i, repairs := range pending {
+		for key, repair := range repairs {
+			holderSnap, held := snaps[key][i]
+			var err error
+			switch {
+			case repair.delete && held:
+				err = f.stores[i].Delete(holderSnap)
+				if errors.Is(err, brtypes.ErrSnapshotDeleteFailDueToImmutability) {
+					// the garbage collector deletes it once its immutability expired
+					continue
+				}
+			case !repair.delete && !held:
+				err = f.copySnapshot(snaps[key], i)
+			default:
+				continue
+			}
+			if err != nil {
+				f.repairs.add(i, key, repair, false)
+				errs = append(errs, fmt.Errorf("failed to repair snapshot %s in snapstore %s: %w", repair.snap.SnapName, f.repairs.names[i], err))
+				continue
+			}
+			logrus.Infof("Repaired snapshot %s in snapstore %s", repair.snap.SnapName, f.repairs.names[i])
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// queueMissingCopies compares the snapshots of all snapstores and queues a copy of each snapshot to the listed
+// snapstores missing it. Snapshots which are saved or deleted meanwhile, or whose delete is pending, are skipped.
+func (f *FanOutSnapStore) queueMissingCopies() error {
+	snaps, listed, err := f.refresh(false)
+	if err != nil {
+		return err
+	}
+	for key, holderSnaps := range snaps {
+		if len(holderSnaps) == len(f.stores) || f.repairs.busy(key) || f.repairs.pendingDelete(key) {
+			continue
+		}
+		var snap brtypes.Snapshot
+		for _, snap = range holderSnaps {
+			break
+		}
+		for i := range f.stores {
+			if _, held := holderSnaps[i]; held || !listed[i] {
+				continue
+			}
+			f.repairs.add(i, key, fanOutRepair{snap: snap}, false)
+		}
+	}
+	return nil
+}
+
+// copySnapshot saves the snapshot to the snapstore with the given index by fetching it from another snapstore holding it.
+// A snapshot which is not held by any snapstore anymore has been deleted meanwhile and needs no copy.
+func (f *FanOutSnapStore) copySnapshot(holderSnaps map[int]brtypes.Snapshot, store int) error {
+	var errs []error
+	for i := range f.stores {
+		snap, ok := holderSnaps[i]
+		if !ok || i == store {
+			continue
+		}
+		if mf, ok := f.stores[i].(brtypes.MetadataFetcher); ok && snap.Kind != brtypes.SnapshotKindDelta && snap.Metadata == nil {
+			metadata, err := mf.FetchMetadata(snap)
+			if err != nil && !errors.Is(err, brtypes.ErrMetadataFetchUnsupported) {
+				errs = append(errs, err)
+				continue
+			}
+			snap.Metadata = metadata
+		}
+		rc, err := f.stores[i].Fetch(snap)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		defer rc.Close()
+		return f.stores[store].Save(snap, rc)
+	}
+	return errors.Join(errs...)
+}
+
+// RepairPeriodically repairs the snapstores in the given period until the context is done.
+func (f *FanOutSnapStore) RepairPeriodically(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := f.Repair(); err != nil {
+				logrus.Warnf("Failed to repair fan-out snapstores: %v", err)
+			}
+		}
+	}
+}
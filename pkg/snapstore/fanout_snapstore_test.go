@@ -0,0 +1,274 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	. "github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// flakySnapStore is a snapstore whose saves, deletes and lists fail while the corresponding flag is set.
+type flakySnapStore struct {
+	*LocalSnapStore
+	failSave, failDelete, failList bool
+}
+
+func (f *flakySnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) error {
+	if f.failSave {
+		return fmt.Errorf("failed to save snapshot %s", snap.SnapName)
+	}
+	return f.LocalSnapStore.Save(snap, rc)
+}
+
+func (f *flakySnapStore) Delete(snap brtypes.Snapshot) error {
+	if f.failDelete {
+		return fmt.Errorf("failed to delete snapshot %s", snap.SnapName)
+	}
+	return f.LocalSnapStore.Delete(snap)
+}
+
+func (f *flakySnapStore) List(includeAll bool) (brtypes.SnapList, error) {
+	if f.failList {
+		return nil, errors.New("failed to list snapshots")
+	}
+	return f.LocalSnapStore.List(includeAll)
+}
+
+var _ = Describe("FanOutSnapStore", func() {
+	const snapshotData = "fan-out snapshot data"
+	var (
+		stores []*flakySnapStore
+		snap   brtypes.Snapshot
+	)
+
+	newFanOut := func(policy string) *FanOutSnapStore {
+		snapStores := make([]brtypes.SnapStore, len(stores))
+		names := make([]string, len(stores))
+		for i, store := range stores {
+			snapStores[i] = store
+			names[i] = fmt.Sprintf("store-%d", i)
+		}
+		fanOut, err := NewFanOutSnapStore(snapStores, names, policy)
+		Expect(err).ShouldNot(HaveOccurred())
+		return fanOut
+	}
+
+	listStore := func(i int) brtypes.SnapList {
+		snapList, err := stores[i].LocalSnapStore.List(false)
+		Expect(err).ShouldNot(HaveOccurred())
+		return snapList
+	}
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		stores = nil
+		for i := 0; i < 3; i++ {
+			localStore, err := NewLocalSnapStore(path.Join(dir, fmt.Sprintf("store-%d", i), "v2"))
+			Expect(err).ShouldNot(HaveOccurred())
+			stores = append(stores, &flakySnapStore{LocalSnapStore: localStore})
+		}
+		snap = brtypes.Snapshot{
+			Kind:          brtypes.SnapshotKindFull,
+			StartRevision: 0,
+			LastRevision:  10,
+			CreatedOn:     time.Now(),
+		}
+		snap.GenerateSnapshotName()
+	})
+
+	Context("#Save", func() {
+		It("should save the snapshot to all snapstores", func() {
+			fanOut := newFanOut(brtypes.FanOutPolicyAll)
+			Expect(fanOut.Save(snap, io.NopCloser(strings.NewReader(snapshotData)))).Should(Succeed())
+			for i := range stores {
+				Expect(listStore(i)).Should(HaveLen(1))
+			}
+			Expect(fanOut.PendingRepairs()).Should(Equal([]int{0, 0, 0}))
+		})
+
+		It("should fail and roll back the snapshot if the policy is not met", func() {
+			stores[2].failSave = true
+			fanOut := newFanOut(brtypes.FanOutPolicyAll)
+			Expect(fanOut.Save(snap, io.NopCloser(strings.NewReader(snapshotData)))).ShouldNot(Succeed())
+			for i := range stores {
+				Expect(listStore(i)).Should(BeEmpty())
+			}
+			Expect(fanOut.PendingRepairs()).Should(Equal([]int{0, 0, 0}))
+		})
+
+		It("should succeed and repair the failed snapstores later if a majority saved the snapshot", func() {
+			stores[2].failSave = true
+			fanOut := newFanOut(brtypes.FanOutPolicyMajority)
+			Expect(fanOut.Save(snap, io.NopCloser(strings.NewReader(snapshotData)))).Should(Succeed())
+			Expect(listStore(2)).Should(BeEmpty())
+			Expect(fanOut.PendingRepairs()).Should(Equal([]int{0, 0, 1}))
+
+			Expect(fanOut.Repair()).ShouldNot(Succeed())
+			Expect(fanOut.PendingRepairs()).Should(Equal([]int{0, 0, 1}))
+
+			stores[2].failSave = false
+			Expect(fanOut.Repair()).Should(Succeed())
+			Expect(fanOut.PendingRepairs()).Should(Equal([]int{0, 0, 0}))
+			snapList := listStore(2)
+			Expect(snapList).Should(HaveLen(1))
+			rc, err := stores[2].Fetch(*snapList[0])
+			Expect(err).ShouldNot(HaveOccurred())
+			defer rc.Close()
+			Expect(io.ReadAll(rc)).Should(BeEquivalentTo(snapshotData))
+		})
+
+		It("should succeed if any snapstore saved the snapshot", func() {
+			stores[0].failSave = true
+			stores[2].failSave = true
+			fanOut := newFanOut(brtypes.FanOutPolicyAny)
+			Expect(fanOut.Save(snap, io.NopCloser(strings.NewReader(snapshotData)))).Should(Succeed())
+			Expect(fanOut.PendingRepairs()).Should(Equal([]int{1, 0, 1}))
+		})
+	})
+
+	Context("#List and #Fetch", func() {
+		It("should merge the snapshots of all snapstores and fetch them from any snapstore holding them", func() {
+			stores[0].failSave = true
+			fanOut := newFanOut(brtypes.FanOutPolicyAny)
+			Expect(fanOut.Save(snap, io.NopCloser(strings.NewReader(snapshotData)))).Should(Succeed())
+			stores[1].failList = true
+
+			snapList, err := fanOut.List(false)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(snapList).Should(HaveLen(1))
+			Expect(snapList[0].SnapName).Should(Equal(snap.SnapName))
+
+			rc, err := fanOut.Fetch(*snapList[0])
+			Expect(err).ShouldNot(HaveOccurred())
+			defer rc.Close()
+			Expect(io.ReadAll(rc)).Should(BeEquivalentTo(snapshotData))
+
+			size, err := fanOut.Size(*snapList[0])
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(size).Should(Equal(int64(len(snapshotData))))
+			rangeRC, err := fanOut.FetchRange(*snapList[0], 8, 4)
+			Expect(err).ShouldNot(HaveOccurred())
+			defer rangeRC.Close()
+			Expect(io.ReadAll(rangeRC)).Should(BeEquivalentTo("snap"))
+		})
+
+		It("should fail if none of the snapstores can be listed", func() {
+			for _, store := range stores {
+				store.failList = true
+			}
+			_, err := newFanOut(brtypes.FanOutPolicyAll).List(false)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("#Delete", func() {
+		It("should delete the snapshot from all snapstores and repair the failed snapstores later", func() {
+			fanOut := newFanOut(brtypes.FanOutPolicyAll)
+			Expect(fanOut.Save(snap, io.NopCloser(strings.NewReader(snapshotData)))).Should(Succeed())
+			stores[1].failDelete = true
+
+			snapList, err := fanOut.List(false)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(fanOut.Delete(*snapList[0])).Should(Succeed())
+			Expect(listStore(0)).Should(BeEmpty())
+			Expect(listStore(1)).Should(HaveLen(1))
+			Expect(fanOut.PendingRepairs()).Should(Equal([]int{0, 1, 0}))
+
+			Expect(fanOut.Repair()).ShouldNot(Succeed())
+			Expect(listStore(0)).Should(BeEmpty())
+			Expect(listStore(2)).Should(BeEmpty())
+
+			stores[1].failDelete = false
+			Expect(fanOut.Repair()).Should(Succeed())
+			Expect(listStore(1)).Should(BeEmpty())
+			Expect(fanOut.PendingRepairs()).Should(Equal([]int{0, 0, 0}))
+		})
+
+		It("should not repair a failed save of a deleted snapshot", func() {
+			stores[2].failSave = true
+			fanOut := newFanOut(brtypes.FanOutPolicyAny)
+			Expect(fanOut.Save(snap, io.NopCloser(strings.NewReader(snapshotData)))).Should(Succeed())
+			Expect(fanOut.Delete(snap)).Should(Succeed())
+			Expect(fanOut.PendingRepairs()).Should(Equal([]int{0, 0, 0}))
+
+			stores[2].failSave = false
+			Expect(fanOut.Repair()).Should(Succeed())
+			Expect(listStore(2)).Should(BeEmpty())
+		})
+	})
+
+	Context("#Repair", func() {
+		It("should copy snapshots missing in some of the snapstores without a pending repair", func() {
+			Expect(stores[1].Save(snap, io.NopCloser(strings.NewReader(snapshotData)))).Should(Succeed())
+			stores[2].failList = true
+			fanOut := newFanOut(brtypes.FanOutPolicyAll)
+			Expect(fanOut.PendingRepairs()).Should(Equal([]int{0, 0, 0}))
+
+			Expect(fanOut.Repair()).Should(Succeed())
+			Expect(fanOut.PendingRepairs()).Should(Equal([]int{0, 0, 0}))
+			Expect(listStore(2)).Should(BeEmpty())
+			snapList := listStore(0)
+			Expect(snapList).Should(HaveLen(1))
+			rc, err := stores[0].Fetch(*snapList[0])
+			Expect(err).ShouldNot(HaveOccurred())
+			defer rc.Close()
+			Expect(io.ReadAll(rc)).Should(BeEquivalentTo(snapshotData))
+
+			stores[2].failList = false
+			Expect(fanOut.Repair()).Should(Succeed())
+			Expect(listStore(2)).Should(HaveLen(1))
+		})
+	})
+
+	Context("#GetSnapstore", func() {
+		const outputDir = "../../../test/output/fan-out"
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(outputDir)).Should(Succeed())
+		})
+
+		It("should return a fan-out snapstore if fan-out snapstores are configured", func() {
+			config := &brtypes.SnapstoreConfig{
+				Provider:  brtypes.SnapstoreProviderLocal,
+				Container: path.Join(outputDir, "primary"),
+				TempDir:   "/tmp",
+				FanOut: &brtypes.FanOutConfig{
+					Policy: brtypes.FanOutPolicyAll,
+					StoreConfigs: []*brtypes.SnapstoreConfig{
+						{Provider: brtypes.SnapstoreProviderLocal, Container: path.Join(outputDir, "secondary")},
+					},
+				},
+			}
+			store, err := GetSnapstore(config)
+			Expect(err).ShouldNot(HaveOccurred())
+			fanOut, ok := store.(*FanOutSnapStore)
+			Expect(ok).Should(BeTrue())
+			Expect(fanOut.PendingRepairs()).Should(HaveLen(2))
+		})
+
+		It("should not return a fan-out snapstore if no fan-out snapstores are configured", func() {
+			config := &brtypes.SnapstoreConfig{
+				Provider:  brtypes.SnapstoreProviderLocal,
+				Container: path.Join(outputDir, "primary"),
+				TempDir:   "/tmp",
+				FanOut:    &brtypes.FanOutConfig{Policy: brtypes.FanOutPolicyAll},
+			}
+			store, err := GetSnapstore(config)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(store).Should(BeAssignableToTypeOf(&LocalSnapStore{}))
+		})
+	})
+})
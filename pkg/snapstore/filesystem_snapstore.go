@@ -0,0 +1,511 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+	"github.com/gardener/etcd-backup-restore/pkg/wrappers"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// fsMetadataSuffix is the suffix of the metadata file stored next to each snapshot.
+	fsMetadataSuffix = ".meta"
+	// fsLockSuffix is the suffix of the lock file held next to a snapshot while it is saved or deleted.
+	fsLockSuffix = ".lock"
+	// fsTempPrefix is the prefix of the temporary files snapshots are written to before they are renamed.
+	fsTempPrefix = ".tmp-"
+	// fsRetentionPolicyFile is the name of the file in the root directory of the snapstore which defines for how long
+	// saved snapshots are immutable.
+	fsRetentionPolicyFile = ".retention-policy.json"
+
+	// fsLockRefreshInterval is the interval in which a held lock file is touched, so that it is not considered stale.
+	fsLockRefreshInterval = 10 * time.Second
+	// fsLockStaleAfter is the time after which a lock file which has not been touched is considered stale,
+	// e.g. because the process holding it died.
+	fsLockStaleAfter = time.Minute
+	// fsLockRetryInterval is the interval in which acquiring a held lock file is retried.
+	fsLockRetryInterval = 500 * time.Millisecond
+)
+
+// FilesystemSnapStore is a snapstore on a mounted filesystem, e.g. an NFS or SMB share such as Azure Files. Snapshots
+// are written to temporary files which are synced and renamed, so that a snapshot is either saved completely or not at
+// all, also if several processes write to the same share. Each snapshot is locked by a lock file while it is saved or
+// deleted. The metadata of a snapshot, which can exclude it from listing or make it immutable, is stored in a file next
+// to it.
+type FilesystemSnapStore struct {
+	root   string
+	prefix string
+	owner  string
+}
+
+// fsSnapshotMetadata is the metadata stored next to a snapshot.
+type fsSnapshotMetadata struct {
+	// Metadata holds the metadata of the snapshot, such as the brtypes.ExcludeSnapshotMetadataKey.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// RetainUntil is the time until which the snapshot is immutable.
+	RetainUntil *time.Time `json:"retainUntil,omitempty"`
+}
+
+// fsRetentionPolicy defines for how long saved snapshots are immutable.
+type fsRetentionPolicy struct {
+	// RetentionPeriod is the period for which a snapshot is immutable after it has been saved.
+	RetentionPeriod wrappers.Duration `json:"retentionPeriod"`
+}
+
+// NewFilesystemSnapStore returns a snapstore in the prefix of the directory given as container, which is usually the
+// mount point of a network share.
+func NewFilesystemSnapStore(config *brtypes.SnapstoreConfig) (*FilesystemSnapStore, error) {
+	info, err := os.Stat(config.Container)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access snapstore directory %s: %w", config.Container, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("snapstore directory %s is not a directory", config.Container)
+	}
+	prefix := path.Join(config.Container, config.Prefix)
+	if err := os.MkdirAll(prefix, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create snapstore prefix %s: %w", prefix, err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return &FilesystemSnapStore{
+		root:   config.Container,
+		prefix: prefix,
+		owner:  fmt.Sprintf("%s/%d", hostname, os.Getpid()),
+	}, nil
+}
+
+// snapshotPath returns the path of the snapshot as listed by the snapstore.
+func snapshotPath(snap brtypes.Snapshot) string {
+	return path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)
+}
+
+// Fetch should open reader for the snapshot file from store
+func (s *FilesystemSnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
+	return os.Open(snapshotPath(snap))
+}
+
+// FetchRange should open reader for a byte range of the snapshot file from store.
+func (s *FilesystemSnapStore) FetchRange(snap brtypes.Snapshot, offset, length int64) (io.ReadCloser, error) {
+	f, err := os.Open(snapshotPath(snap))
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.NewSectionReader(f, offset, length), f}, nil
+}
+
+// Size should return size of the snapshot file from store
+func (s *FilesystemSnapStore) Size(snap brtypes.Snapshot) (int64, error) {
+	info, err := os.Stat(snapshotPath(snap))
+	if err != nil {
+		return -1, err
+	}
+	return info.Size(), nil
+}
+
+// FetchMetadata returns the metadata stored next to the snapshot.
+func (s *FilesystemSnapStore) FetchMetadata(snap brtypes.Snapshot) (map[string]string, error) {
+	if _, err := os.Stat(snapshotPath(snap)); err != nil {
+		return nil, err
+	}
+	metadata, err := readFSMetadata(snapshotPath(snap))
+	if err != nil {
+		return nil, err
+	}
+	return lowercaseMetadataKeys(metadata.Metadata), nil
+}
+
+// Save writes the snapshot to a temporary file, syncs it and renames it to the snapshot, so that it is saved atomically.
+// The snapshot is immutable for the retention period of the retention policy of the snapstore, if there is one.
+func (s *FilesystemSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) error {
+	defer rc.Close()
+	target := path.Join(s.prefix, snap.SnapDir, snap.SnapName)
+	dir := filepath.Dir(target)
+	if err := s.mkdirAll(dir); err != nil {
+		return err
+	}
+
+	unlock, err := s.lock(target)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	previous, err := readFSMetadata(target)
+	if err != nil {
+		return err
+	}
+	if previous.RetainUntil != nil && time.Now().Before(*previous.RetainUntil) {
+		return fmt.Errorf("snapshot %s is immutable until %s", snap.SnapName, previous.RetainUntil)
+	}
+	metadata := &fsSnapshotMetadata{Metadata: maps.Clone(previous.Metadata), RetainUntil: previous.RetainUntil}
+
+	f, err := os.CreateTemp(dir, fsTempPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for snapshot %s: %w", snap.SnapName, err)
+	}
+	defer func() {
+		// the file has been renamed if the snapshot was saved.
+		if err := os.Remove(f.Name()); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("Failed to remove temporary file %s: %v", f.Name(), err)
+		}
+	}()
+	if err := writeFSFile(f, rc); err != nil {
+		return fmt.Errorf("failed to write snapshot %s: %w", snap.SnapName, err)
+	}
+
+	policy, err := s.retentionPolicy()
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		retainUntil := time.Now().Add(policy.RetentionPeriod.Duration).UTC()
+		metadata.RetainUntil = &retainUntil
+	}
+	for key, value := range snap.Metadata {
+		if metadata.Metadata == nil {
+			metadata.Metadata = make(map[string]string, len(snap.Metadata))
+		}
+		metadata.Metadata[key] = value
+	}
+	if policy != nil || len(snap.Metadata) > 0 {
+		// the metadata is written first, so that the snapshot is never saved without being immutable.
+		if err := writeFSMetadata(target, metadata); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(f.Name(), target); err != nil {
+		if policy != nil || len(snap.Metadata) > 0 {
+			// the previous metadata is restored, so that the snapshot which was not saved does not become immutable.
+			if err := restoreFSMetadata(target, previous); err != nil {
+				logrus.Warnf("Failed to restore metadata of snapshot %s: %v", snap.SnapName, err)
+			}
+		}
+		return fmt.Errorf("failed to rename snapshot %s: %w", snap.SnapName, err)
+	}
+	return syncDir(dir)
+}
+
+// writeFSFile writes the data to the file, syncs and closes it.
+func writeFSFile(f *os.File, r io.Reader) error {
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// mkdirAll creates the directory and its parents, and syncs the parents of the directories it created.
+func (s *FilesystemSnapStore) mkdirAll(dir string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	parent := filepath.Dir(dir)
+	if parent != dir {
+		if err := s.mkdirAll(parent); err != nil {
+			return err
+		}
+	}
+	if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return syncDir(parent)
+}
+
+// syncDir syncs the directory, so that renames and removals of its entries are persisted.
+// Filesystems which cannot sync directories, such as SMB shares, persist them without.
+func syncDir(dir string) error {
+	d, err := os.Open(dir) // #nosec G304 -- this is a directory of the snapstore.
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTSUP) {
+		return fmt.Errorf("failed to sync directory %s: %w", dir, err)
+	}
+	return nil
+}
+
+// lock acquires the lock file of the snapshot at the given path and returns the function releasing it. The lock file
+// is touched while it is held, so that the lock file of a process which died is taken over once it became stale.
+func (s *FilesystemSnapStore) lock(target string) (func(), error) {
+	lockPath := target + fsLockSuffix
+	for {
+		f, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) // #nosec G304 -- this is a file of the snapstore.
+		if err == nil {
+			_, err = f.WriteString(s.owner)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(lockPath)
+				return nil, fmt.Errorf("failed to write lock file %s: %w", lockPath, err)
+			}
+			return s.holdLock(lockPath), nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file %s: %w", lockPath, err)
+		}
+
+		info, err := os.Stat(lockPath)
+		if err != nil {
+			// the lock has been released in the meantime.
+			continue
+		}
+		if time.Since(info.ModTime()) > fsLockStaleAfter {
+			if err := removeStaleLock(lockPath, info); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		time.Sleep(fsLockRetryInterval)
+	}
+}
+
+// removeStaleLock removes the stale lock file with the given info. It is renamed to a unique name first, so that only
+// one process takes it over. If the renamed lock file is not the stale one anymore, because another process took it
+// over and created a new lock file meanwhile, the new lock file is restored.
+func removeStaleLock(lockPath string, info os.FileInfo) error {
+	stalePath := filepath.Join(filepath.Dir(lockPath), fmt.Sprintf("%sstale-%s-%d-%d", fsTempPrefix, filepath.Base(lockPath), os.Getpid(), time.Now().UnixNano()))
+	if err := os.Rename(lockPath, stalePath); err != nil {
+		if os.IsNotExist(err) {
+			// the lock has been released or taken over in the meantime.
+			return nil
+		}
+		return fmt.Errorf("failed to rename stale lock file %s: %w", lockPath, err)
+	}
+
+	staleInfo, err := os.Stat(stalePath)
+	if err != nil {
+		return fmt.Errorf("failed to access renamed lock file %s: %w", stalePath, err)
+	}
+	if !os.SameFile(info, staleInfo) || time.Since(staleInfo.ModTime()) <= fsLockStaleAfter {
+		// hard links do not replace a lock file created in the meantime, filesystems without them fall back to a rename.
+		if err := os.Link(stalePath, lockPath); err != nil && !os.IsExist(err) {
+			if err := os.Rename(stalePath, lockPath); err != nil {
+				return fmt.Errorf("failed to restore lock file %s: %w", lockPath, err)
+			}
+			return nil
+		}
+		return os.Remove(stalePath)
+	}
+
+	owner, _ := os.ReadFile(stalePath) // #nosec G304 -- this is a file of the snapstore.
+	logrus.Warnf("Taking over stale lock file %s of %s", lockPath, owner)
+	if err := os.Remove(stalePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale lock file %s: %w", stalePath, err)
+	}
+	return nil
+}
+
+// holdLock touches the lock file until the returned function is called, which removes the lock file.
+func (s *FilesystemSnapStore) holdLock(lockPath string) func() {
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		ticker := time.NewTicker(fsLockRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				now := time.Now()
+				if err := os.Chtimes(lockPath, now, now); err != nil {
+					logrus.Warnf("Failed to refresh lock file %s: %v", lockPath, err)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(stopCh)
+		<-doneCh
+		if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("Failed to remove lock file %s: %v", lockPath, err)
+		}
+	}
+}
+
+// retentionPolicy returns the retention policy of the snapstore, or nil if there is none.
+func (s *FilesystemSnapStore) retentionPolicy() (*fsRetentionPolicy, error) {
+	data, err := os.ReadFile(filepath.Join(s.root, fsRetentionPolicyFile)) // #nosec G304 -- this is a file of the snapstore.
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retention policy: %w", err)
+	}
+	policy := &fsRetentionPolicy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse retention policy: %w", err)
+	}
+	if policy.RetentionPeriod.Duration <= 0 {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+// readFSMetadata reads the metadata of the snapshot at the given path.
+func readFSMetadata(target string) (*fsSnapshotMetadata, error) {
+	metadata := &fsSnapshotMetadata{}
+	data, err := os.ReadFile(target + fsMetadataSuffix) // #nosec G304 -- this is a file of the snapstore.
+	if os.IsNotExist(err) {
+		return metadata, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata of snapshot %s: %w", target, err)
+	}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata of snapshot %s: %w", target, err)
+	}
+	return metadata, nil
+}
+
+// writeFSMetadata writes the metadata of the snapshot at the given path atomically.
+func writeFSMetadata(target string, metadata *fsSnapshotMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(target), fsTempPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for metadata of snapshot %s: %w", target, err)
+	}
+	defer os.Remove(f.Name())
+	if err := writeFSFile(f, strings.NewReader(string(data))); err != nil {
+		return fmt.Errorf("failed to write metadata of snapshot %s: %w", target, err)
+	}
+	return os.Rename(f.Name(), target+fsMetadataSuffix)
+}
+
+// restoreFSMetadata restores the metadata of the snapshot at the given path to the metadata read before it was written.
+// The metadata file is removed if there was none.
+func restoreFSMetadata(target string, metadata *fsSnapshotMetadata) error {
+	if metadata.Metadata == nil && metadata.RetainUntil == nil {
+		if err := os.Remove(target + fsMetadataSuffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return writeFSMetadata(target, metadata)
+}
+
+// isFSSnapshotFile returns whether the file is a snapshot, and not a temporary, lock or metadata file.
+func isFSSnapshotFile(name string) bool {
+	return !strings.HasPrefix(name, ".") && !strings.HasSuffix(name, fsLockSuffix) && !strings.HasSuffix(name, fsMetadataSuffix)
+}
+
+// List will return sorted list with all snapshot files on store. Snapshots excluded by their metadata are only listed
+// if includeAll is set.
+func (s *FilesystemSnapStore) List(includeAll bool) (brtypes.SnapList, error) {
+	// consider the parent of the backup version level, which is required for backward compatibility.
+	prefix := filepath.Dir(s.prefix)
+
+	snapList := brtypes.SnapList{}
+	err := filepath.WalkDir(prefix, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isFSSnapshotFile(d.Name()) {
+			return nil
+		}
+		if !strings.Contains(p, backupVersionV1) && !strings.Contains(p, backupVersionV2) {
+			return nil
+		}
+		snap, err := ParseSnapshot(p)
+		if err != nil {
+			logrus.Warnf("Invalid snapshot found. Ignoring it: %s", p)
+			return nil
+		}
+		metadata, err := readFSMetadata(p)
+		if err != nil {
+			return err
+		}
+		if !includeAll && metadata.Metadata[brtypes.ExcludeSnapshotMetadataKey] == "true" {
+			logrus.Infof("Ignoring snapshot %s due to the exclude tag %q in the snapshot metadata", snap.SnapName, brtypes.ExcludeSnapshotMetadataKey)
+			return nil
+		}
+		if metadata.RetainUntil != nil {
+			snap.ImmutabilityExpiryTime = *metadata.RetainUntil
+		}
+		snapList = append(snapList, snap)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking the path %q: %v", prefix, err)
+	}
+
+	sort.Sort(snapList)
+	return snapList, nil
+}
+
+// Delete should delete the snapshot file from store. It fails with brtypes.ErrSnapshotDeleteFailDueToImmutability
+// while the snapshot is immutable.
+func (s *FilesystemSnapStore) Delete(snap brtypes.Snapshot) error {
+	target := snapshotPath(snap)
+	dir := filepath.Dir(target)
+	if err := s.delete(snap, target); err != nil {
+		return err
+	}
+	if err := syncDir(dir); err != nil {
+		return err
+	}
+	// remove the directory of a chunked or v1 snapshot once it is empty.
+	if filepath.Clean(dir) != filepath.Clean(snap.Prefix) {
+		if err := os.Remove(dir); err == nil {
+			return syncDir(filepath.Dir(dir))
+		}
+	}
+	return nil
+}
+
+func (s *FilesystemSnapStore) delete(snap brtypes.Snapshot, target string) error {
+	unlock, err := s.lock(target)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	metadata, err := readFSMetadata(target)
+	if err != nil {
+		return err
+	}
+	if metadata.RetainUntil != nil && time.Now().Before(*metadata.RetainUntil) {
+		return brtypes.ErrSnapshotDeleteFailDueToImmutability
+	}
+	if err := os.Remove(target); err != nil {
+		return err
+	}
+	if err := os.Remove(target + fsMetadataSuffix); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("Failed to remove metadata of deleted snapshot %s: %v", snap.SnapName, err)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	. "github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FilesystemSnapStore", func() {
+	var (
+		root  string
+		store *FilesystemSnapStore
+	)
+
+	newSnapshot := func(lastRevision int64) brtypes.Snapshot {
+		snap := brtypes.Snapshot{
+			Kind:         brtypes.SnapshotKindFull,
+			LastRevision: lastRevision,
+			CreatedOn:    time.Unix(lastRevision, 0).UTC(),
+		}
+		snap.GenerateSnapshotName()
+		return snap
+	}
+
+	save := func(snap brtypes.Snapshot, data string) {
+		Expect(store.Save(snap, io.NopCloser(strings.NewReader(data)))).Should(Succeed())
+	}
+
+	list := func(includeAll bool) brtypes.SnapList {
+		snapList, err := store.List(includeAll)
+		Expect(err).ShouldNot(HaveOccurred())
+		return snapList
+	}
+
+	snapshotFile := func(snap brtypes.Snapshot) string {
+		return filepath.Join(root, "v2", snap.SnapName)
+	}
+
+	BeforeEach(func() {
+		var err error
+		root = GinkgoT().TempDir()
+		store, err = NewFilesystemSnapStore(&brtypes.SnapstoreConfig{Container: root, Prefix: "v2"})
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should fail if the directory does not exist", func() {
+		_, err := NewFilesystemSnapStore(&brtypes.SnapstoreConfig{Container: filepath.Join(root, "missing"), Prefix: "v2"})
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should save, list, fetch and delete snapshots", func() {
+		snap := newSnapshot(1)
+		save(snap, "snapshot")
+
+		snapList := list(false)
+		Expect(snapList).Should(HaveLen(1))
+		Expect(snapList[0].SnapName).Should(Equal(snap.SnapName))
+
+		rc, err := store.Fetch(*snapList[0])
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(io.ReadAll(rc)).Should(Equal([]byte("snapshot")))
+		Expect(rc.Close()).Should(Succeed())
+
+		size, err := store.Size(*snapList[0])
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(size).Should(Equal(int64(len("snapshot"))))
+		rc, err = store.FetchRange(*snapList[0], 4, 4)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(io.ReadAll(rc)).Should(Equal([]byte("shot")))
+		Expect(rc.Close()).Should(Succeed())
+
+		Expect(store.Delete(*snapList[0])).Should(Succeed())
+		Expect(list(true)).Should(BeEmpty())
+	})
+
+	It("should not leave partially written snapshots behind", func() {
+		snap := newSnapshot(1)
+		failing := io.NopCloser(io.MultiReader(strings.NewReader("partial"), &failingReader{}))
+		Expect(store.Save(snap, failing)).ShouldNot(Succeed())
+
+		entries, err := os.ReadDir(filepath.Join(root, "v2"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(entries).Should(BeEmpty())
+	})
+
+	It("should not list temporary, lock and metadata files", func() {
+		snap := newSnapshot(1)
+		save(snap, "snapshot")
+		Expect(os.WriteFile(filepath.Join(root, "v2", ".tmp-123"), []byte("partial"), 0600)).Should(Succeed())
+		Expect(os.WriteFile(snapshotFile(newSnapshot(2))+".lock", nil, 0600)).Should(Succeed())
+		Expect(os.WriteFile(snapshotFile(snap)+".meta", []byte("{}"), 0600)).Should(Succeed())
+
+		snapList := list(true)
+		Expect(snapList).Should(HaveLen(1))
+		Expect(snapList[0].SnapName).Should(Equal(snap.SnapName))
+	})
+
+	It("should only list excluded snapshots if all snapshots are requested", func() {
+		excluded, included := newSnapshot(1), newSnapshot(2)
+		save(excluded, "excluded")
+		save(included, "included")
+		metadata := `{"metadata":{"` + brtypes.ExcludeSnapshotMetadataKey + `":"true"}}`
+		Expect(os.WriteFile(snapshotFile(excluded)+".meta", []byte(metadata), 0600)).Should(Succeed())
+
+		snapList := list(false)
+		Expect(snapList).Should(HaveLen(1))
+		Expect(snapList[0].SnapName).Should(Equal(included.SnapName))
+		Expect(list(true)).Should(HaveLen(2))
+	})
+
+	It("should not delete snapshots which are still retained by the retention policy", func() {
+		Expect(os.WriteFile(filepath.Join(root, ".retention-policy.json"), []byte(`{"retentionPeriod":"1h"}`), 0600)).Should(Succeed())
+		snap := newSnapshot(1)
+		save(snap, "snapshot")
+
+		snapList := list(false)
+		Expect(snapList).Should(HaveLen(1))
+		Expect(snapList[0].ImmutabilityExpiryTime).Should(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+		Expect(snapList[0].IsDeletable()).Should(BeFalse())
+		Expect(store.Delete(*snapList[0])).Should(MatchError(brtypes.ErrSnapshotDeleteFailDueToImmutability))
+		Expect(store.Save(snap, io.NopCloser(strings.NewReader("overwritten")))).ShouldNot(Succeed())
+
+		metadata := `{"retainUntil":"` + time.Now().Add(-time.Minute).UTC().Format(time.RFC3339) + `"}`
+		Expect(os.WriteFile(snapshotFile(snap)+".meta", []byte(metadata), 0600)).Should(Succeed())
+		Expect(store.Delete(*list(false)[0])).Should(Succeed())
+		Expect(list(true)).Should(BeEmpty())
+		Expect(snapshotFile(snap) + ".meta").ShouldNot(BeAnExistingFile())
+	})
+
+	It("should store the metadata of snapshots next to them", func() {
+		Expect(os.WriteFile(filepath.Join(root, ".retention-policy.json"), []byte(`{"retentionPeriod":"1h"}`), 0600)).Should(Succeed())
+		snap := newSnapshot(1)
+		snap.Metadata = map[string]string{brtypes.EtcdVersionMetadataKey: "3.5.21"}
+		save(snap, "snapshot")
+
+		snapList := list(false)
+		Expect(snapList).Should(HaveLen(1))
+		Expect(snapList[0].IsDeletable()).Should(BeFalse())
+		metadata, err := store.FetchMetadata(*snapList[0])
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(metadata).Should(Equal(map[string]string{brtypes.EtcdVersionMetadataKey: "3.5.21"}))
+
+		_, err = store.FetchMetadata(newSnapshot(2))
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should not leave the metadata of a snapshot which could not be saved", func() {
+		Expect(os.WriteFile(filepath.Join(root, ".retention-policy.json"), []byte(`{"retentionPeriod":"1h"}`), 0600)).Should(Succeed())
+		snap := newSnapshot(1)
+		// a snapshot can't be renamed to a directory which is not empty.
+		Expect(os.MkdirAll(filepath.Join(snapshotFile(snap), "blocked"), 0700)).Should(Succeed())
+
+		Expect(store.Save(snap, io.NopCloser(strings.NewReader("snapshot")))).ShouldNot(Succeed())
+		Expect(snapshotFile(snap) + ".meta").ShouldNot(BeAnExistingFile())
+	})
+
+	It("should wait for snapshots locked by another process", func() {
+		snap := newSnapshot(1)
+		lockFile := snapshotFile(snap) + ".lock"
+		Expect(os.WriteFile(lockFile, []byte("other"), 0600)).Should(Succeed())
+		go func() {
+			defer GinkgoRecover()
+			time.Sleep(time.Second)
+			Expect(os.Remove(lockFile)).Should(Succeed())
+		}()
+
+		start := time.Now()
+		save(snap, "snapshot")
+		Expect(time.Since(start)).Should(BeNumerically(">=", time.Second))
+		Expect(lockFile).ShouldNot(BeAnExistingFile())
+	})
+
+	It("should take over stale lock files", func() {
+		snap := newSnapshot(1)
+		lockFile := snapshotFile(snap) + ".lock"
+		Expect(os.WriteFile(lockFile, []byte("other"), 0600)).Should(Succeed())
+		past := time.Now().Add(-time.Hour)
+		Expect(os.Chtimes(lockFile, past, past)).Should(Succeed())
+
+		save(snap, "snapshot")
+		data, err := os.ReadFile(snapshotFile(snap))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(data).Should(Equal([]byte("snapshot")))
+		Expect(lockFile).ShouldNot(BeAnExistingFile())
+	})
+
+	It("should take over a stale lock file by only one process at a time", func() {
+		snap := newSnapshot(1)
+		lockFile := snapshotFile(snap) + ".lock"
+		Expect(os.WriteFile(lockFile, []byte("other"), 0600)).Should(Succeed())
+		past := time.Now().Add(-time.Hour)
+		Expect(os.Chtimes(lockFile, past, past)).Should(Succeed())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				save(snap, "snapshot")
+			}()
+		}
+		wg.Wait()
+		Expect(lockFile).ShouldNot(BeAnExistingFile())
+		entries, err := os.ReadDir(filepath.Join(root, "v2"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(entries).Should(HaveLen(1))
+		Expect(entries[0].Name()).Should(Equal(snap.SnapName))
+	})
+
+	It("should save chunks in the directory of their snapshot and remove it once it is empty", func() {
+		snap := newSnapshot(1)
+		chunk := brtypes.Snapshot{SnapDir: snap.SnapName, SnapName: "0000000001"}
+		Expect(store.Save(chunk, io.NopCloser(bytes.NewReader([]byte("chunk"))))).Should(Succeed())
+
+		snapList := list(true)
+		Expect(snapList).Should(HaveLen(1))
+		Expect(snapList[0].IsChunk).Should(BeTrue())
+		Expect(store.Delete(*snapList[0])).Should(Succeed())
+		Expect(filepath.Join(root, "v2", snap.SnapName)).ShouldNot(BeADirectory())
+	})
+})
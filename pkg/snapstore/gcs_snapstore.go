@@ -25,6 +25,7 @@ import (
 	"time"
 
 	stiface "github.com/gardener/etcd-backup-restore/pkg/snapstore/gcs"
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
 	"cloud.google.com/go/storage"
@@ -197,8 +198,37 @@ func (s *GCSSnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
 	return s.client.Bucket(s.bucket).Object(objectName).NewReader(ctx)
 }
 
+// Size returns the size of the snapshot file in the store.
+func (s *GCSSnapStore) Size(snap brtypes.Snapshot) (int64, error) {
+	objectName := path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)
+	attrs, err := s.client.Bucket(s.bucket).Object(objectName).Attrs(context.TODO())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the attributes of the object %s: %w", objectName, err)
+	}
+	return attrs.Size, nil
+}
+
+// FetchMetadata returns the user-defined metadata stored with the snapshot.
+func (s *GCSSnapStore) FetchMetadata(snap brtypes.Snapshot) (map[string]string, error) {
+	objectName := path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)
+	attrs, err := s.client.Bucket(s.bucket).Object(objectName).Attrs(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the attributes of the object %s: %w", objectName, err)
+	}
+	return lowercaseMetadataKeys(attrs.Metadata), nil
+}
+
+// FetchRange should open reader for a byte range of the snapshot file from store.
+func (s *GCSSnapStore) FetchRange(snap brtypes.Snapshot, offset, length int64) (io.ReadCloser, error) {
+	objectName := path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)
+	return s.client.Bucket(s.bucket).Object(objectName).NewRangeReader(context.TODO(), offset, length)
+}
+
 // Save will write the snapshot to store.
 func (s *GCSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error) {
+	saveCtx, span := startSaveSpan(brtypes.SnapstoreProviderGCS, &snap)
+	defer func() { tracing.End(span, err) }()
+
 	tempFile, size, err := writeSnapshotToTempFile(s.tempDir, rc)
 	if err != nil {
 		return err
@@ -232,7 +262,7 @@ func (s *GCSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 
 	for range s.maxParallelChunkUploads {
 		wg.Add(1)
-		go s.componentUploader(&wg, cancelCh, &snap, tempFile, chunkUploadCh, resCh)
+		go s.componentUploader(saveCtx, &wg, cancelCh, &snap, tempFile, chunkUploadCh, resCh)
 	}
 
 	logrus.Infof("Uploading snapshot of size: %d, chunkSize: %d, noOfChunks: %d", size, chunkSize, noOfChunks)
@@ -247,7 +277,7 @@ func (s *GCSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 	}
 	logrus.Infof("Triggered chunk upload for all chunks, total: %d", noOfChunks)
 
-	snapshotErr := collectChunkUploadError(chunkUploadCh, resCh, cancelCh, noOfChunks)
+	snapshotErr := collectChunkUploadError(chunkUploadCh, resCh, cancelCh, noOfChunks, uploadedChunkReporter(rc, size))
 	wg.Wait()
 
 	if snapshotErr != nil {
@@ -265,6 +295,7 @@ func (s *GCSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 	name := path.Join(prefix, snap.SnapDir, snap.SnapName)
 	obj := bh.Object(name)
 	c := obj.ComposerFrom(subObjects...)
+	c.ObjectAttrs().Metadata = snap.Metadata
 	ctx, cancel := context.WithTimeout(context.TODO(), chunkUploadTimeout)
 	defer cancel()
 	if _, err := c.Run(ctx); err != nil {
@@ -301,7 +332,7 @@ func (s *GCSSnapStore) uploadComponent(snap *brtypes.Snapshot, file *os.File, of
 	return w.Close()
 }
 
-func (s *GCSSnapStore) componentUploader(wg *sync.WaitGroup, stopCh <-chan struct{}, snap *brtypes.Snapshot, file *os.File, chunkUploadCh chan chunk, errCh chan<- chunkUploadResult) {
+func (s *GCSSnapStore) componentUploader(ctx context.Context, wg *sync.WaitGroup, stopCh <-chan struct{}, snap *brtypes.Snapshot, file *os.File, chunkUploadCh chan chunk, errCh chan<- chunkUploadResult) {
 	defer wg.Done()
 	for {
 		select {
@@ -312,7 +343,9 @@ func (s *GCSSnapStore) componentUploader(wg *sync.WaitGroup, stopCh <-chan struc
 				return
 			}
 			logrus.Infof("Uploading chunk with offset : %d, attempt: %d", uploadChunk.offset, uploadChunk.attempt)
+			_, span := startChunkUploadSpan(ctx, brtypes.SnapstoreProviderGCS, snap, uploadChunk)
 			err := s.uploadComponent(snap, file, uploadChunk.offset, uploadChunk.size)
+			tracing.End(span, err)
 			errCh <- chunkUploadResult{
 				err:   err,
 				chunk: &uploadChunk,
@@ -396,10 +429,14 @@ func GetGCSCredentialsLastModifiedTime() (time.Time, error) {
 			projectIDFileName = "projectID"
 		)
 		dirPath := path.Dir(credentialsFilePath)
-		credentialFiles = append(credentialFiles,
-			path.Join(dirPath, tokenFileName),
-			path.Join(dirPath, projectIDFileName),
-		)
+		credentialFiles = append(credentialFiles, path.Join(dirPath, projectIDFileName))
+
+		// the token is read from its credential source whenever the access token expires
+		gcsTimeStamp, err := getWorkloadIdentityCredentialsModifiedTime(credentialFiles, path.Join(dirPath, tokenFileName))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to fetch file information of the GCS credentials files %q with error: %w", strings.Join(credentialFiles, ","), err)
+		}
+		return gcsTimeStamp, nil
 	}
 
 	gcsTimeStamp, err := getLatestCredentialsModifiedTime(credentialFiles)
@@ -75,6 +75,24 @@ func (m *mockObjectHandle) NewReader(_ context.Context) (stiface.Reader, error)
 	return nil, fmt.Errorf("object %s not found", m.object)
 }
 
+func (m *mockObjectHandle) NewRangeReader(_ context.Context, offset, length int64) (stiface.Reader, error) {
+	m.client.objectMutex.Lock()
+	defer m.client.objectMutex.Unlock()
+	if value, ok := m.client.objects[m.object]; ok {
+		return &mockObjectReader{reader: io.NopCloser(bytes.NewReader(rangeOf(*value, fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))))}, nil
+	}
+	return nil, fmt.Errorf("object %s not found", m.object)
+}
+
+func (m *mockObjectHandle) Attrs(context.Context) (*storage.ObjectAttrs, error) {
+	m.client.objectMutex.Lock()
+	defer m.client.objectMutex.Unlock()
+	if value, ok := m.client.objects[m.object]; ok {
+		return &storage.ObjectAttrs{Name: m.object, Size: int64(len(*value)), Metadata: m.client.objectTags[m.object]}, nil
+	}
+	return nil, fmt.Errorf("object %s not found", m.object)
+}
+
 func (m *mockObjectHandle) NewWriter(context.Context) stiface.Writer {
 	return &mockObjectWriter{object: m.object, client: m.client}
 }
@@ -123,6 +141,11 @@ type mockComposer struct {
 	client        *mockGCSClient
 	dst           *mockObjectHandle
 	objectHandles []stiface.ObjectHandle
+	attrs         storage.ObjectAttrs
+}
+
+func (m *mockComposer) ObjectAttrs() *storage.ObjectAttrs {
+	return &m.attrs
 }
 
 func (m *mockComposer) Run(ctx context.Context) (*storage.ObjectAttrs, error) {
@@ -137,8 +160,14 @@ func (m *mockComposer) Run(ctx context.Context) (*storage.ObjectAttrs, error) {
 			return nil, err
 		}
 	}
+	if m.attrs.Metadata != nil {
+		m.client.objectMutex.Lock()
+		m.client.objectTags[m.dst.object] = m.attrs.Metadata
+		m.client.objectMutex.Unlock()
+	}
 	return &storage.ObjectAttrs{
-		Name: m.dst.object,
+		Name:     m.dst.object,
+		Metadata: m.attrs.Metadata,
 	}, nil
 }
 
@@ -15,6 +15,11 @@ func NewSnapstoreConfig() *brtypes.SnapstoreConfig {
 		MaxParallelChunkUploads: 5,
 		MinChunkSize:            brtypes.MinChunkSize,
 		TempDir:                 "/tmp",
+		SnapshotCacheSize:       brtypes.DefaultSnapshotCacheSize,
+		FanOut: &brtypes.FanOutConfig{
+			Policy:       brtypes.FanOutPolicyAll,
+			RepairPeriod: wrappers.Duration{Duration: brtypes.DefaultFanOutRepairPeriod},
+		},
 	}
 }
 
@@ -42,6 +42,18 @@ func (s *LocalSnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
 	return os.Open(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName))
 }
 
+// FetchRange should open reader for a byte range of the snapshot file from store.
+func (s *LocalSnapStore) FetchRange(snap brtypes.Snapshot, offset, length int64) (io.ReadCloser, error) {
+	f, err := os.Open(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName))
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.NewSectionReader(f, offset, length), f}, nil
+}
+
 // Save will write the snapshot to store
 func (s *LocalSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) error {
 	defer rc.Close()
@@ -117,7 +129,7 @@ func (s *LocalSnapStore) Delete(snap brtypes.Snapshot) error {
 
 // Size should return size of the snapshot file from store
 func (s *LocalSnapStore) Size(snap brtypes.Snapshot) (int64, error) {
-	fileInfo, err := os.Stat(path.Join(s.prefix, snap.SnapDir, snap.SnapName))
+	fileInfo, err := os.Stat(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName))
 	if err != nil {
 		return -1, err
 	}
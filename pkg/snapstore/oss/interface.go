@@ -6,6 +6,7 @@ package oss
 
 import (
 	"io"
+	"net/http"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 )
@@ -14,6 +15,10 @@ import (
 type OSSBucket interface {
 	// GetObject downloads the object.
 	GetObject(objectKey string, options ...oss.Option) (io.ReadCloser, error)
+	// GetObjectMeta gets the object's meta information, including its size.
+	GetObjectMeta(objectKey string, options ...oss.Option) (http.Header, error)
+	// GetObjectDetailedMeta gets all of the object's meta information, including its user-defined metadata.
+	GetObjectDetailedMeta(objectKey string, options ...oss.Option) (http.Header, error)
 	// ListObjects lists the objects under the current bucket.
 	ListObjects(options ...oss.Option) (oss.ListObjectsResult, error)
 	// DeleteObject deletes the object.
@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Environment variables set by the ack-pod-identity-webhook for RAM Roles for Service Accounts (RRSA).
+	aliRoleARN         = "ALIBABA_CLOUD_ROLE_ARN"
+	aliOIDCProviderARN = "ALIBABA_CLOUD_OIDC_PROVIDER_ARN"
+	aliOIDCTokenFile   = "ALIBABA_CLOUD_OIDC_TOKEN_FILE" // #nosec G101 -- This is not a hardcoded password, but only a path to the token.
+
+	defaultAliSTSEndpoint = "sts.aliyuncs.com"
+	aliRoleSessionName    = "etcd-backup-restore"
+	// aliCredentialsDuration is the duration of the credentials requested from STS, which is the minimum allowed.
+	aliCredentialsDuration = 15 * time.Minute
+	// aliCredentialsRefreshWindow is the time before the expiry of the credentials at which they are refreshed.
+	aliCredentialsRefreshWindow = 3 * time.Minute
+)
+
+// ossCredentials are the temporary credentials of an assumed RAM role.
+type ossCredentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	AccessKeySecret string `json:"AccessKeySecret"`
+	SecurityToken   string `json:"SecurityToken"`
+	Expiration      string `json:"Expiration"`
+}
+
+// GetAccessKeyID returns the access key ID.
+func (c *ossCredentials) GetAccessKeyID() string { return c.AccessKeyID }
+
+// GetAccessKeySecret returns the access key secret.
+func (c *ossCredentials) GetAccessKeySecret() string { return c.AccessKeySecret }
+
+// GetSecurityToken returns the security token.
+func (c *ossCredentials) GetSecurityToken() string { return c.SecurityToken }
+
+// rrsaCredentialsProvider provides the credentials of a RAM role which is assumed with the OIDC token of the service
+// account. The token file is re-read, and the role assumed again, shortly before the credentials expire, so that the
+// OSS client never has to be recreated for a rotated token.
+type rrsaCredentialsProvider struct {
+	stsURL          string
+	roleARN         string
+	oidcProviderARN string
+	tokenPath       string
+	httpClient      *http.Client
+
+	mutex       sync.Mutex
+	credentials *ossCredentials
+	expiration  time.Time
+}
+
+// newRRSACredentialsProvider creates a new RRSA credentials provider from the auth options.
+func newRRSACredentialsProvider(ao authOptions) *rrsaCredentialsProvider {
+	stsURL := ao.STSEndpoint
+	if stsURL == "" {
+		stsURL = defaultAliSTSEndpoint
+	}
+	if !strings.Contains(stsURL, "://") {
+		stsURL = "https://" + stsURL
+	}
+	return &rrsaCredentialsProvider{
+		stsURL:          stsURL,
+		roleARN:         ao.RoleARN,
+		oidcProviderARN: ao.OIDCProviderARN,
+		tokenPath:       ao.TokenPath,
+		httpClient:      &http.Client{Timeout: providerConnectionTimeout},
+	}
+}
+
+// GetCredentials returns the credentials, the OSS client only calls it if the provider does not implement
+// oss.CredentialsProviderE.
+func (p *rrsaCredentialsProvider) GetCredentials() oss.Credentials {
+	credentials, err := p.GetCredentialsE()
+	if err != nil {
+		logrus.Errorf("Failed to get the credentials of RAM role %s: %v", p.roleARN, err)
+		return &ossCredentials{}
+	}
+	return credentials
+}
+
+// GetCredentialsE returns the cached credentials, or assumes the RAM role again if they are about to expire.
+func (p *rrsaCredentialsProvider) GetCredentialsE() (oss.Credentials, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.credentials != nil && time.Until(p.expiration) > aliCredentialsRefreshWindow {
+		return p.credentials, nil
+	}
+
+	credentials, err := p.assumeRoleWithOIDC()
+	if err != nil {
+		return nil, err
+	}
+	expiration, err := time.Parse(time.RFC3339, credentials.Expiration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the expiration %q of the credentials: %w", credentials.Expiration, err)
+	}
+	p.credentials, p.expiration = credentials, expiration
+	return p.credentials, nil
+}
+
+// assumeRoleWithOIDC assumes the RAM role with the OIDC token, which does not have to be signed with an access key.
+func (p *rrsaCredentialsProvider) assumeRoleWithOIDC() (*ossCredentials, error) {
+	token, err := os.ReadFile(p.tokenPath) // #nosec G304 -- this is a trusted file, obtained via user input.
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the OIDC token: %w", err)
+	}
+
+	form := url.Values{
+		"Action":          {"AssumeRoleWithOIDC"},
+		"Format":          {"JSON"},
+		"Version":         {"2015-04-01"},
+		"Timestamp":       {time.Now().UTC().Format(time.RFC3339)},
+		"RoleArn":         {p.roleARN},
+		"OIDCProviderArn": {p.oidcProviderARN},
+		"OIDCToken":       {strings.TrimSpace(string(token))},
+		"RoleSessionName": {aliRoleSessionName},
+		"DurationSeconds": {fmt.Sprint(int(aliCredentialsDuration.Seconds()))},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), providerConnectionTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.stsURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume RAM role %s: %w", p.roleARN, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response of STS: %w", err)
+	}
+
+	var result struct {
+		Code        string          `json:"Code"`
+		Message     string          `json:"Message"`
+		Credentials *ossCredentials `json:"Credentials"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse the response of STS with status %s: %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK || result.Credentials == nil {
+		return nil, fmt.Errorf("failed to assume RAM role %s with status %s: %s: %s", p.roleARN, resp.Status, result.Code, result.Message)
+	}
+	return result.Credentials, nil
+}
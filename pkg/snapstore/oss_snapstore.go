@@ -5,6 +5,7 @@
 package snapstore
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,11 +15,13 @@ import (
 	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	stiface "github.com/gardener/etcd-backup-restore/pkg/snapstore/oss"
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
@@ -38,6 +41,12 @@ type authOptions struct {
 	AccessID   string `json:"accessKeyID"`
 	AccessKey  string `json:"accessKeySecret"`
 	BucketName string `json:"bucketName"`
+	// RoleARN, OIDCProviderARN and TokenPath configure RAM Roles for Service Accounts (RRSA), they default to the
+	// environment variables ALIBABA_CLOUD_ROLE_ARN, ALIBABA_CLOUD_OIDC_PROVIDER_ARN and ALIBABA_CLOUD_OIDC_TOKEN_FILE.
+	RoleARN         string `json:"roleARN,omitempty"`
+	OIDCProviderARN string `json:"oidcProviderARN,omitempty"`
+	TokenPath       string `json:"tokenPath,omitempty"`
+	STSEndpoint     string `json:"stsEndpoint,omitempty"`
 }
 
 // OSSSnapStore is snapstore with Alicloud OSS object store as backend
@@ -61,7 +70,11 @@ func NewOSSSnapStore(config *brtypes.SnapstoreConfig) (*OSSSnapStore, error) {
 }
 
 func newOSSFromAuthOpt(bucket, prefix, tempDir string, maxParallelChunkUploads uint, minChunkSize int64, ao authOptions) (*OSSSnapStore, error) {
-	client, err := oss.New(ao.Endpoint, ao.AccessID, ao.AccessKey)
+	var options []oss.ClientOption
+	if ao.AccessID == "" {
+		options = append(options, oss.SetCredentialsProvider(newRRSACredentialsProvider(ao)))
+	}
+	client, err := oss.New(ao.Endpoint, ao.AccessID, ao.AccessKey, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -96,8 +109,40 @@ func (s *OSSSnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
 	return body, nil
 }
 
+// Size returns the size of the snapshot file in the store.
+func (s *OSSSnapStore) Size(snap brtypes.Snapshot) (int64, error) {
+	header, err := s.bucket.GetObjectMeta(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(header.Get(oss.HTTPHeaderContentLength), 10, 64)
+}
+
+// FetchMetadata returns the user-defined metadata stored with the snapshot.
+func (s *OSSSnapStore) FetchMetadata(snap brtypes.Snapshot) (map[string]string, error) {
+	header, err := s.bucket.GetObjectDetailedMeta(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName))
+	if err != nil {
+		return nil, err
+	}
+	metadata := map[string]string{}
+	for key := range header {
+		if strings.HasPrefix(key, oss.HTTPHeaderOssMetaPrefix) {
+			metadata[strings.TrimPrefix(key, oss.HTTPHeaderOssMetaPrefix)] = header.Get(key)
+		}
+	}
+	return lowercaseMetadataKeys(metadata), nil
+}
+
+// FetchRange should open reader for a byte range of the snapshot file from store.
+func (s *OSSSnapStore) FetchRange(snap brtypes.Snapshot, offset, length int64) (io.ReadCloser, error) {
+	return s.bucket.GetObject(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName), oss.Range(offset, offset+length-1))
+}
+
 // Save will write the snapshot to store
 func (s *OSSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error) {
+	saveCtx, span := startSaveSpan(brtypes.SnapstoreProviderOSS, &snap)
+	defer func() { tracing.End(span, err) }()
+
 	tempFile, size, err := writeSnapshotToTempFile(s.tempDir, rc)
 	if err != nil {
 		return err
@@ -132,7 +177,11 @@ func (s *OSSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 		return err
 	}
 
-	imur, err := s.bucket.InitiateMultipartUpload(path.Join(adaptPrefix(&snap, s.prefix), snap.SnapDir, snap.SnapName))
+	var options []oss.Option
+	for key, value := range snap.Metadata {
+		options = append(options, oss.Meta(key, value))
+	}
+	imur, err := s.bucket.InitiateMultipartUpload(path.Join(adaptPrefix(&snap, s.prefix), snap.SnapDir, snap.SnapName), options...)
 	if err != nil {
 		return err
 	}
@@ -147,7 +196,7 @@ func (s *OSSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 
 	for i := uint(0); i < s.maxParallelChunkUploads; i++ {
 		wg.Add(1)
-		go s.partUploader(&wg, imur, tempFile, completedParts, chunkUploadCh, cancelCh, resCh)
+		go s.partUploader(saveCtx, &wg, &snap, imur, tempFile, completedParts, chunkUploadCh, cancelCh, resCh)
 	}
 
 	for _, ossChunk := range ossChunks {
@@ -161,7 +210,7 @@ func (s *OSSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 	}
 
 	logrus.Infof("Triggered chunk upload for all chunks, total: %d", noOfChunks)
-	snapshotErr := collectChunkUploadError(chunkUploadCh, resCh, cancelCh, noOfChunks)
+	snapshotErr := collectChunkUploadError(chunkUploadCh, resCh, cancelCh, noOfChunks, uploadedChunkReporter(rc, size))
 	wg.Wait()
 
 	if snapshotErr == nil {
@@ -181,7 +230,7 @@ func (s *OSSSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 	return nil
 }
 
-func (s *OSSSnapStore) partUploader(wg *sync.WaitGroup, imur oss.InitiateMultipartUploadResult, file *os.File, completedParts []oss.UploadPart, chunkUploadCh <-chan chunk, stopCh <-chan struct{}, errCh chan<- chunkUploadResult) {
+func (s *OSSSnapStore) partUploader(ctx context.Context, wg *sync.WaitGroup, snap *brtypes.Snapshot, imur oss.InitiateMultipartUploadResult, file *os.File, completedParts []oss.UploadPart, chunkUploadCh <-chan chunk, stopCh <-chan struct{}, errCh chan<- chunkUploadResult) {
 	defer wg.Done()
 	for {
 		select {
@@ -192,7 +241,9 @@ func (s *OSSSnapStore) partUploader(wg *sync.WaitGroup, imur oss.InitiateMultipa
 				return
 			}
 			logrus.Infof("Uploading chunk with id: %d, offset: %d, size: %d", uploadChunk.id, uploadChunk.offset, uploadChunk.size)
+			_, span := startChunkUploadSpan(ctx, brtypes.SnapstoreProviderOSS, snap, uploadChunk)
 			err := s.uploadPart(imur, file, completedParts, uploadChunk.offset, uploadChunk.size, uploadChunk.id)
+			tracing.End(span, err)
 			errCh <- chunkUploadResult{
 				err:   err,
 				chunk: &uploadChunk,
@@ -326,6 +377,7 @@ func aliCredentialsFromJSON(jsonData []byte) (*authOptions, error) {
 	if err := json.Unmarshal(jsonData, aliConfig); err != nil {
 		return nil, err
 	}
+	setRRSAOptionsFromEnv(aliConfig)
 
 	return aliConfig, nil
 }
@@ -357,8 +409,29 @@ func readALICredentialFiles(dirname string) (*authOptions, error) {
 				return nil, err
 			}
 			aliConfig.AccessID = string(data)
+		} else if file.Name() == "roleARN" {
+			data, err := os.ReadFile(dirname + "/roleARN") // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			aliConfig.RoleARN = string(data)
+		} else if file.Name() == "oidcProviderARN" {
+			data, err := os.ReadFile(dirname + "/oidcProviderARN") // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			aliConfig.OIDCProviderARN = string(data)
+		} else if file.Name() == "token" {
+			aliConfig.TokenPath = dirname + "/token"
+		} else if file.Name() == "stsEndpoint" {
+			data, err := os.ReadFile(dirname + "/stsEndpoint") // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			aliConfig.STSEndpoint = string(data)
 		}
 	}
+	setRRSAOptionsFromEnv(aliConfig)
 
 	if err := isOSSConfigEmpty(aliConfig); err != nil {
 		return nil, err
@@ -383,15 +456,31 @@ func GetOSSCredentialsLastModifiedTime() (time.Time, error) {
 
 	if dir, isSet := os.LookupEnv(aliCredentialDirectory); isSet {
 		// credential files which are essential for creating the OSS snapstore
-		credentialFiles := []string{"accessKeyID", "accessKeySecret", "storageEndpoint"}
-		for i := range credentialFiles {
-			credentialFiles[i] = filepath.Join(dir, credentialFiles[i])
+		// either static or RRSA credential files should be set, unless RRSA is configured by the environment
+		var (
+			staticCredentialsFiles = []string{filepath.Join(dir, "accessKeyID"), filepath.Join(dir, "accessKeySecret"), filepath.Join(dir, "storageEndpoint")}
+			rrsaCredentialsFiles   = []string{filepath.Join(dir, "roleARN"), filepath.Join(dir, "oidcProviderARN"), filepath.Join(dir, "storageEndpoint")}
+		)
+
+		aliTimeStamp, err1 := getLatestCredentialsModifiedTime(staticCredentialsFiles)
+		if err1 == nil {
+			return aliTimeStamp, nil
 		}
-		aliTimeStamp, err := getLatestCredentialsModifiedTime(credentialFiles)
-		if err != nil {
-			return time.Time{}, fmt.Errorf("failed to get OSS credential timestamp from the directory %v with error: %v", dir, err)
+
+		aliTimeStamp, err2 := getWorkloadIdentityCredentialsModifiedTime(rrsaCredentialsFiles, filepath.Join(dir, "token"))
+		if err2 == nil {
+			return aliTimeStamp, nil
 		}
-		return aliTimeStamp, nil
+
+		if _, isSet := os.LookupEnv(aliOIDCTokenFile); isSet {
+			aliTimeStamp, err3 := getLatestCredentialsModifiedTime([]string{filepath.Join(dir, "storageEndpoint")})
+			if err3 == nil {
+				return aliTimeStamp, nil
+			}
+			err2 = errors.Join(err2, err3)
+		}
+
+		return time.Time{}, fmt.Errorf("failed to get OSS credential timestamp from the directory %v with error: %v", dir, errors.Join(err1, err2))
 	}
 
 	if filename, isSet := os.LookupEnv(aliCredentialJSONFile); isSet {
@@ -410,5 +499,25 @@ func isOSSConfigEmpty(config *authOptions) error {
 	if len(config.AccessID) != 0 && len(config.AccessKey) != 0 && len(config.Endpoint) != 0 {
 		return nil
 	}
-	return fmt.Errorf("aliCloud OSS credentials: accessKeyID, accessKeySecret or storageEndpoint is missing")
+	if len(config.RoleARN) != 0 && len(config.OIDCProviderARN) != 0 && len(config.TokenPath) != 0 && len(config.Endpoint) != 0 {
+		return nil
+	}
+	return fmt.Errorf("aliCloud OSS credentials: either set accessKeyID and accessKeySecret or roleARN, oidcProviderARN and token, and storageEndpoint")
+}
+
+// setRRSAOptionsFromEnv sets the RRSA options which are not set in the credentials from the environment variables set
+// by the ack-pod-identity-webhook, unless static credentials are used.
+func setRRSAOptionsFromEnv(config *authOptions) {
+	if len(config.AccessID) != 0 {
+		return
+	}
+	if len(config.RoleARN) == 0 {
+		config.RoleARN = os.Getenv(aliRoleARN)
+	}
+	if len(config.OIDCProviderARN) == 0 {
+		config.OIDCProviderARN = os.Getenv(aliOIDCProviderARN)
+	}
+	if len(config.TokenPath) == 0 {
+		config.TokenPath = os.Getenv(aliOIDCTokenFile)
+	}
 }
@@ -8,11 +8,14 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"net/http"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	stiface "github.com/gardener/etcd-backup-restore/pkg/snapstore/oss"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 )
@@ -40,6 +43,7 @@ func (slice uploadParts) Swap(i, j int) {
 type mockOSSBucket struct {
 	objects               map[string]*[]byte
 	multiPartUploads      map[string]*[][]byte
+	objectMetadata        map[string]http.Header
 	prefix                string
 	bucketName            string
 	multiPartUploadsMutex sync.Mutex
@@ -62,19 +66,55 @@ func getOSSMockBucket(m *mockOSSClient) *mockOSSBucket {
 }
 
 // GetObject returns the object from map for mock test
-func (m *mockOSSBucket) GetObject(objectKey string, _ ...oss.Option) (io.ReadCloser, error) {
+func (m *mockOSSBucket) GetObject(objectKey string, options ...oss.Option) (io.ReadCloser, error) {
 	if m.objects[objectKey] == nil {
 		return nil, fmt.Errorf("object not found")
 	}
-	out := io.NopCloser(bytes.NewReader(*m.objects[objectKey]))
+	httpRange, err := oss.FindOption(options, oss.HTTPHeaderRange, "")
+	if err != nil {
+		return nil, err
+	}
+	out := io.NopCloser(bytes.NewReader(rangeOf(*m.objects[objectKey], httpRange.(string))))
 	return out, nil
 }
 
+// GetObjectMeta returns the size of the object from map for mock test
+func (m *mockOSSBucket) GetObjectMeta(objectKey string, _ ...oss.Option) (http.Header, error) {
+	if m.objects[objectKey] == nil {
+		return nil, fmt.Errorf("object not found")
+	}
+	header := http.Header{}
+	header.Set(oss.HTTPHeaderContentLength, strconv.Itoa(len(*m.objects[objectKey])))
+	return header, nil
+}
+
+// GetObjectDetailedMeta returns the size and the metadata of the object from map for mock test
+func (m *mockOSSBucket) GetObjectDetailedMeta(objectKey string, options ...oss.Option) (http.Header, error) {
+	header, err := m.GetObjectMeta(objectKey, options...)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range m.objectMetadata[objectKey] {
+		header[key] = values
+	}
+	return header, nil
+}
+
 // InitiateMultipartUpload returns the multi-parts needed to upload for mock test
-func (m *mockOSSBucket) InitiateMultipartUpload(objectKey string, _ ...oss.Option) (oss.InitiateMultipartUploadResult, error) {
+func (m *mockOSSBucket) InitiateMultipartUpload(objectKey string, options ...oss.Option) (oss.InitiateMultipartUploadResult, error) {
 	uploadID := time.Now().String()
 	var parts [][]byte
 	m.multiPartUploads[uploadID] = &parts
+	// the options cannot be inspected, so only the metadata keys written by the snapshotter are stored
+	if m.objectMetadata == nil {
+		m.objectMetadata = map[string]http.Header{}
+	}
+	m.objectMetadata[objectKey] = http.Header{}
+	for _, key := range []string{brtypes.EtcdVersionMetadataKey, brtypes.EtcdClusterIDMetadataKey} {
+		if value, err := oss.FindOption(options, oss.HTTPHeaderOssMetaPrefix+key, nil); err == nil && value != nil {
+			m.objectMetadata[objectKey].Set(oss.HTTPHeaderOssMetaPrefix+key, value.(string))
+		}
+	}
 	return oss.InitiateMultipartUploadResult{
 		UploadID: uploadID,
 		Key:      objectKey,
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/gardener/etcd-backup-restore/pkg/snapstore/internal/s3api"
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -45,6 +46,11 @@ const (
 	s3NoOfChunk            int64 = 9999
 	awsCredentialDirectory       = "AWS_APPLICATION_CREDENTIALS"
 	awsCredentialJSONFile        = "AWS_APPLICATION_CREDENTIALS_JSON"
+
+	// Environment variables set by EKS for IAM roles for service accounts (IRSA) and EKS Pod Identity.
+	awsRoleARN                     = "AWS_ROLE_ARN"
+	awsWebIdentityTokenFile        = "AWS_WEB_IDENTITY_TOKEN_FILE"
+	awsContainerCredentialsFullURI = "AWS_CONTAINER_CREDENTIALS_FULL_URI"
 )
 
 type awsCredentials struct {
@@ -81,6 +87,7 @@ type S3SnapStore struct {
 	// maxParallelChunkUploads hold the maximum number of parallel chunk uploads allowed.
 	maxParallelChunkUploads uint
 	minChunkSize            int64
+	versioning              S3VersioningOptions
 }
 
 // NewS3SnapStore create new S3SnapStore from shared configuration with specified bucket
@@ -199,12 +206,18 @@ func awsCredentialsFromConfig(awsConfig *awsCredentials) ([]func(*awsconfig.Load
 		credentialsProvider aws.CredentialsProvider
 	)
 
-	if awsConfig.AccessKeyID != "" {
+	switch {
+	case awsConfig.AccessKeyID != "":
 		credentialsProvider = credentials.NewStaticCredentialsProvider(awsConfig.AccessKeyID, awsConfig.SecretAccessKey, "")
-	} else {
+	case awsConfig.RoleARN != "" || awsConfig.TokenPath != "" || !isAWSWorkloadIdentityConfigured():
+		// The token file is re-read whenever the cached credentials expire.
 		credentialsProvider = stscreds.NewWebIdentityRoleProvider(sts.NewFromConfig(aws.Config{Region: awsConfig.Region}), awsConfig.RoleARN, stscreds.IdentityTokenFile(awsConfig.TokenPath))
 	}
-	cfgOpts = append(cfgOpts, awsconfig.WithCredentialsProvider(aws.NewCredentialsCache(credentialsProvider)))
+	// Without a credentials provider, the default credentials chain of the AWS SDK resolves and refreshes the
+	// credentials of IRSA or EKS Pod Identity.
+	if credentialsProvider != nil {
+		cfgOpts = append(cfgOpts, awsconfig.WithCredentialsProvider(aws.NewCredentialsCache(credentialsProvider)))
+	}
 
 	if awsConfig.Endpoint != nil {
 		cfgOpts = append(cfgOpts, awsconfig.WithBaseEndpoint(*awsConfig.Endpoint))
@@ -380,9 +393,55 @@ func NewS3FromClient(bucket, prefix, tempDir string, maxParallelChunkUploads uin
 
 // Fetch should open reader for the snapshot file from store
 func (s *S3SnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
+	return s.getObject(snap, nil)
+}
+
+// Size returns the size of the snapshot file in the store.
+func (s *S3SnapStore) Size(snap brtypes.Snapshot) (int64, error) {
+	headObjectOutput, err := s.headObject(snap)
+	if err != nil {
+		return 0, err
+	}
+	return aws.ToInt64(headObjectOutput.ContentLength), nil
+}
+
+// FetchMetadata returns the user-defined metadata stored with the snapshot.
+func (s *S3SnapStore) FetchMetadata(snap brtypes.Snapshot) (map[string]string, error) {
+	headObjectOutput, err := s.headObject(snap)
+	if err != nil {
+		return nil, err
+	}
+	return lowercaseMetadataKeys(headObjectOutput.Metadata), nil
+}
+
+func (s *S3SnapStore) headObject(snap brtypes.Snapshot) (*s3.HeadObjectOutput, error) {
+	headObjectInput := &s3.HeadObjectInput{
+		Bucket:    aws.String(s.bucket),
+		Key:       aws.String(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)),
+		VersionId: snap.VersionID,
+	}
+	if s.sseCustomerKey != "" {
+		headObjectInput.SSECustomerAlgorithm = aws.String(s.sseCustomerAlgorithm)
+		headObjectInput.SSECustomerKey = aws.String(s.sseCustomerKey)
+		headObjectInput.SSECustomerKeyMD5 = aws.String(s.sseCustomerKeyMD5)
+	}
+	headObjectOutput, err := s.client.HeadObject(context.TODO(), headObjectInput)
+	if err != nil {
+		return nil, fmt.Errorf("error while accessing %s: %v", path.Join(snap.Prefix, snap.SnapDir, snap.SnapName), err)
+	}
+	return headObjectOutput, nil
+}
+
+// FetchRange should open reader for a byte range of the snapshot file from store.
+func (s *S3SnapStore) FetchRange(snap brtypes.Snapshot, offset, length int64) (io.ReadCloser, error) {
+	return s.getObject(snap, aws.String(httpRange(offset, length)))
+}
+
+func (s *S3SnapStore) getObject(snap brtypes.Snapshot, byteRange *string) (io.ReadCloser, error) {
 	getObjectInput := &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)),
+		Range:  byteRange,
 	}
 
 	if snap.VersionID != nil {
@@ -404,6 +463,9 @@ func (s *S3SnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
 
 // Save will write the snapshot to store
 func (s *S3SnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error) {
+	saveCtx, span := startSaveSpan(brtypes.SnapstoreProviderS3, &snap)
+	defer func() { tracing.End(span, err) }()
+
 	tempFile, size, err := writeSnapshotToTempFile(s.tempDir, rc)
 	if err != nil {
 		return err
@@ -430,8 +492,9 @@ func (s *S3SnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 	prefix := adaptPrefix(&snap, s.prefix)
 
 	createMultipartUploadInput := &s3.CreateMultipartUploadInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(path.Join(prefix, snap.SnapDir, snap.SnapName)),
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(path.Join(prefix, snap.SnapDir, snap.SnapName)),
+		Metadata: snap.Metadata,
 	}
 	if s.sseCustomerKey != "" {
 		// Customer managed Server Side Encryption
@@ -462,7 +525,7 @@ func (s *S3SnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 
 	for i := uint(0); i < s.maxParallelChunkUploads; i++ {
 		wg.Add(1)
-		go s.partUploader(&wg, cancelCh, &snap, tempFile, uploadOutput.UploadId, completedParts, chunkUploadCh, resCh)
+		go s.partUploader(saveCtx, &wg, cancelCh, &snap, tempFile, uploadOutput.UploadId, completedParts, chunkUploadCh, resCh)
 	}
 	logrus.Infof("Uploading snapshot of size: %d, chunkSize: %d, noOfChunks: %d", size, chunkSize, noOfChunks)
 
@@ -477,7 +540,7 @@ func (s *S3SnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error)
 		index++
 	}
 	logrus.Infof("Triggered chunk upload for all chunks, total: %d", noOfChunks)
-	snapshotErr := collectChunkUploadError(chunkUploadCh, resCh, cancelCh, noOfChunks)
+	snapshotErr := collectChunkUploadError(chunkUploadCh, resCh, cancelCh, noOfChunks, uploadedChunkReporter(rc, size))
 	wg.Wait()
 
 	if snapshotErr != nil {
@@ -554,7 +617,7 @@ func (s *S3SnapStore) uploadPart(snap *brtypes.Snapshot, file *os.File, uploadID
 	return err
 }
 
-func (s *S3SnapStore) partUploader(wg *sync.WaitGroup, stopCh <-chan struct{}, snap *brtypes.Snapshot, file *os.File, uploadID *string, completedParts []s3types.CompletedPart, chunkUploadCh <-chan chunk, errCh chan<- chunkUploadResult) {
+func (s *S3SnapStore) partUploader(ctx context.Context, wg *sync.WaitGroup, stopCh <-chan struct{}, snap *brtypes.Snapshot, file *os.File, uploadID *string, completedParts []s3types.CompletedPart, chunkUploadCh <-chan chunk, errCh chan<- chunkUploadResult) {
 	defer wg.Done()
 	for {
 		select {
@@ -565,7 +628,9 @@ func (s *S3SnapStore) partUploader(wg *sync.WaitGroup, stopCh <-chan struct{}, s
 				return
 			}
 			logrus.Infof("Uploading chunk with id: %d, offset: %d, attempt: %d", uploadChunk.id, uploadChunk.offset, uploadChunk.attempt)
+			_, span := startChunkUploadSpan(ctx, brtypes.SnapstoreProviderS3, snap, uploadChunk)
 			err := s.uploadPart(snap, file, uploadID, completedParts, uploadChunk.offset, uploadChunk.size)
+			tracing.End(span, err)
 			errCh <- chunkUploadResult{
 				err:   err,
 				chunk: &uploadChunk,
@@ -637,6 +702,11 @@ func (s *S3SnapStore) List(includeAll bool) (brtypes.SnapList, error) {
 		// allDeleteMarkersInfo contains key of all delete markers present(if any) in the S3 bucket.
 		allDeleteMarkersInfo := make(map[string]struct{})
 
+		// deletedSnapKeys contains the keys of all snapshots whose latest version is a delete marker.
+		deletedSnapKeys := make(map[string]struct{})
+		// newestVersionTimes contains the creation time of the newest version of all snapshot keys.
+		newestVersionTimes := make(map[string]time.Time)
+
 		paginator := s3.NewListObjectVersionsPaginator(s.client, in)
 		for paginator.HasMorePages() {
 			page, err := paginator.NextPage(context.TODO())
@@ -647,6 +717,10 @@ func (s *S3SnapStore) List(includeAll bool) (brtypes.SnapList, error) {
 			for _, version := range page.Versions {
 				snapKey := (*version.Key)[len(*page.Prefix):]
 				if strings.Contains(snapKey, backupVersionV1) || strings.Contains(snapKey, backupVersionV2) {
+					if newest, ok := newestVersionTimes[*version.Key]; !ok || (*version.LastModified).After(newest) {
+						newestVersionTimes[*version.Key] = *version.LastModified
+					}
+
 					// Add snapshot key to map
 					//   - if snapshot key not found to be already present in map
 					//   - or if the incoming version of snapshot key is older
@@ -672,11 +746,21 @@ func (s *S3SnapStore) List(includeAll bool) (brtypes.SnapList, error) {
 				deletionKey := (*deletionMarker.Key)[len(*page.Prefix):]
 				if strings.Contains(deletionKey, backupVersionV1) || strings.Contains(deletionKey, backupVersionV2) {
 					allDeleteMarkersInfo[*deletionMarker.Key] = struct{}{}
+					if deletionMarker.IsLatest != nil && *deletionMarker.IsLatest {
+						deletedSnapKeys[*deletionMarker.Key] = struct{}{}
+					}
 				}
 			}
 		}
 
 		for key, val := range allSnapKeyMapToSnapshotInfo {
+			// A snapshot whose latest version is a delete marker has been deleted. It is listed by its noncurrent
+			// versions only for garbage collection, or to recover it.
+			if _, isDeleted := deletedSnapKeys[key]; isDeleted && s.versioning.DeleteMarkerAware && !includeAll && !s.versioning.ListNoncurrentVersions {
+				logrus.Infof("Snapshot: %s has been deleted, ignoring its noncurrent versions", key)
+				continue
+			}
+
 			// If a snapshot key has a delete marker present in the bucket,
 			// check whether that snapshot object is marked to be ignored.
 			if _, isDeleteMarkerPresent := allDeleteMarkersInfo[key]; isDeleteMarkerPresent && !includeAll {
@@ -698,6 +782,12 @@ func (s *S3SnapStore) List(includeAll bool) (brtypes.SnapList, error) {
 					// To avoid API calls for each snapshot, backup-restore is calculating the "ImmutabilityExpiryTime" using bucket retention period.
 					// ImmutabilityExpiryTime = SnapshotCreationTime + ObjectRetentionTimeInDays
 					snap.ImmutabilityExpiryTime = snap.CreatedOn.Add(time.Duration(*bucketImmutableExpiryTimeInDays) * 24 * time.Hour)
+					if s.versioning.PurgeVersions {
+						// all versions are deleted with the snapshot, so it is immutable as long as its newest version is.
+						if newest := newestVersionTimes[key]; newest.After(snap.CreatedOn) {
+							snap.ImmutabilityExpiryTime = newest.Add(time.Duration(*bucketImmutableExpiryTimeInDays) * 24 * time.Hour)
+						}
+					}
 				}
 				snapList = append(snapList, snap)
 			}
@@ -738,6 +828,10 @@ func (s *S3SnapStore) List(includeAll bool) (brtypes.SnapList, error) {
 
 // Delete should delete the snapshot file from store
 func (s *S3SnapStore) Delete(snap brtypes.Snapshot) error {
+	if s.versioning.PurgeVersions && snap.VersionID != nil {
+		return s.purgeVersions(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName))
+	}
+
 	deleteObjectInput := &s3.DeleteObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)),
@@ -771,10 +865,10 @@ func GetS3CredentialsLastModifiedTime() (time.Time, error) {
 
 	if dir, isSet := os.LookupEnv(awsCredentialDirectory); isSet {
 		// credential files which are essential for creating the S3 snapstore
-		// either static or web identity credential files should be set
+		// either static or web identity credential files should be set, unless IRSA or EKS Pod Identity is used
 		var (
 			staticCredentialsFiles      = []string{filepath.Join(dir, "region"), filepath.Join(dir, "accessKeyID"), filepath.Join(dir, "secretAccessKey")}
-			webIdentityCredentialsFiles = []string{filepath.Join(dir, "region"), filepath.Join(dir, "roleARN"), filepath.Join(dir, "token")}
+			webIdentityCredentialsFiles = []string{filepath.Join(dir, "region"), filepath.Join(dir, "roleARN")}
 		)
 
 		awsTimestamp, err1 := getLatestCredentialsModifiedTime(staticCredentialsFiles)
@@ -782,11 +876,19 @@ func GetS3CredentialsLastModifiedTime() (time.Time, error) {
 			return awsTimestamp, nil
 		}
 
-		awsTimestamp, err2 := getLatestCredentialsModifiedTime(webIdentityCredentialsFiles)
+		awsTimestamp, err2 := getWorkloadIdentityCredentialsModifiedTime(webIdentityCredentialsFiles, filepath.Join(dir, "token"))
 		if err2 == nil {
 			return awsTimestamp, nil
 		}
 
+		if isAWSWorkloadIdentityConfigured() {
+			awsTimestamp, err3 := getLatestCredentialsModifiedTime([]string{filepath.Join(dir, "region")})
+			if err3 == nil {
+				return awsTimestamp, nil
+			}
+			err2 = errors.Join(err2, err3)
+		}
+
 		return time.Time{}, fmt.Errorf("failed to get AWS credential timestamp from the directory %v with error: %w", dir, errors.Join(err1, err2))
 	}
 
@@ -815,7 +917,18 @@ func isAWSConfigEmpty(config *awsCredentials) error {
 		return nil
 	}
 
-	return fmt.Errorf("aws s3 credentials: either set secretAccessKey and accessKeyID or roleARN and token")
+	if len(config.RoleARN) == 0 && len(config.TokenPath) == 0 && isAWSWorkloadIdentityConfigured() {
+		return nil
+	}
+
+	return fmt.Errorf("aws s3 credentials: either set secretAccessKey and accessKeyID or roleARN and token, or use IRSA or EKS Pod Identity")
+}
+
+// isAWSWorkloadIdentityConfigured returns true if the environment is configured for IAM roles for service accounts
+// (IRSA) or EKS Pod Identity, whose credentials are resolved and refreshed by the default credentials chain of the AWS SDK.
+func isAWSWorkloadIdentityConfigured() bool {
+	irsa := os.Getenv(awsRoleARN) != "" && os.Getenv(awsWebIdentityTokenFile) != ""
+	return irsa || os.Getenv(awsContainerCredentialsFullURI) != ""
 }
 
 // Creates SSE Credentials that are included in the S3 API calls for customer managed SSE
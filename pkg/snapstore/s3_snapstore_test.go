@@ -29,6 +29,7 @@ var _ s3api.Client = (*mockS3Client)(nil)
 type mockS3Client struct {
 	objects               map[string]*[]byte
 	multiPartUploads      map[string]*[][]byte
+	objectMetadata        map[string]map[string]string
 	prefix                string
 	multiPartUploadsMutex sync.Mutex
 }
@@ -40,15 +41,27 @@ func (m *mockS3Client) GetObject(_ context.Context, in *s3.GetObjectInput, _ ...
 	}
 	// Only need to return mocked response output
 	out := s3.GetObjectOutput{
-		Body: io.NopCloser(bytes.NewReader(*m.objects[*in.Key])),
+		Body: io.NopCloser(bytes.NewReader(rangeOf(*m.objects[*in.Key], aws.ToString(in.Range)))),
 	}
 	return &out, nil
 }
 
+// HeadObject returns the size of the object from map for mock test
+func (m *mockS3Client) HeadObject(_ context.Context, in *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	if m.objects[*in.Key] == nil {
+		return nil, fmt.Errorf("object not found")
+	}
+	return &s3.HeadObjectOutput{ContentLength: aws.Int64(int64(len(*m.objects[*in.Key]))), Metadata: m.objectMetadata[*in.Key]}, nil
+}
+
 func (m *mockS3Client) CreateMultipartUpload(_ context.Context, in *s3.CreateMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
 	uploadID := time.Now().String()
 	var parts [][]byte
 	m.multiPartUploads[uploadID] = &parts
+	if m.objectMetadata == nil {
+		m.objectMetadata = map[string]map[string]string{}
+	}
+	m.objectMetadata[*in.Key] = in.Metadata
 	out := &s3.CreateMultipartUploadOutput{
 		Bucket:   in.Bucket,
 		UploadId: &uploadID,
@@ -0,0 +1,448 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/pkg/sftp"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	sftpCredentialDirectory = "SFTP_APPLICATION_CREDENTIALS" // #nosec G101 -- This is not a hardcoded password, but only a path to the credentials.
+	// sftpDefaultPort is the port used if the host in the credentials does not have one.
+	sftpDefaultPort = "22"
+	// sftpDialTimeout is the timeout for establishing the SSH connection.
+	sftpDialTimeout = 30 * time.Second
+	// sftpTempPrefix is the prefix of the temporary files snapshots are uploaded to before they are renamed.
+	sftpTempPrefix = ".tmp-"
+	// sftpUploadBufferSize is the size of the writes snapshots are uploaded with.
+	sftpUploadBufferSize = 1024 * 1024
+)
+
+// sftpCredentials are the credentials to access the SFTP server.
+type sftpCredentials struct {
+	// Host is the host of the SFTP server, optionally with a port.
+	Host string
+	// User is the user to authenticate as.
+	User string
+	// PrivateKey is the PEM encoded private key to authenticate with.
+	PrivateKey []byte
+	// Passphrase is the passphrase of the private key, if it is encrypted.
+	Passphrase []byte
+	// KnownHosts are the public keys of the SFTP server in the format of a known_hosts file.
+	KnownHosts []byte
+	// InsecureSkipVerify skips the verification of the public key of the SFTP server.
+	InsecureSkipVerify bool
+}
+
+// SFTPSnapStore is snapstore with a directory on an SFTP server as backend.
+type SFTPSnapStore struct {
+	prefix       string
+	addr         string
+	clientConfig *ssh.ClientConfig
+
+	mutex     sync.Mutex
+	sshClient *ssh.Client
+	client    *sftp.Client
+}
+
+// NewSFTPSnapStore creates a new SFTPSnapStore in the prefix of the directory on the SFTP server given as container,
+// using the credentials in the directory given by the SFTP_APPLICATION_CREDENTIALS environment variable.
+func NewSFTPSnapStore(config *brtypes.SnapstoreConfig) (*SFTPSnapStore, error) {
+	dir, isSet := os.LookupEnv(getEnvPrefixString(config) + sftpCredentialDirectory)
+	if !isSet {
+		return nil, fmt.Errorf("environment variable %s is not set", getEnvPrefixString(config)+sftpCredentialDirectory)
+	}
+	creds, err := readSFTPCredentialFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error getting credentials from %v directory with error %w", dir, err)
+	}
+	return newSFTPSnapStoreFromCredentials(config.Container, config.Prefix, creds)
+}
+
+func newSFTPSnapStoreFromCredentials(dir, prefix string, creds *sftpCredentials) (*SFTPSnapStore, error) {
+	clientConfig, err := sftpClientConfig(creds)
+	if err != nil {
+		return nil, err
+	}
+	addr := creds.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, sftpDefaultPort)
+	}
+	s := &SFTPSnapStore{
+		prefix:       path.Join(dir, prefix),
+		addr:         addr,
+		clientConfig: clientConfig,
+	}
+	err = s.withClient(func(client *sftp.Client) error {
+		return client.MkdirAll(s.prefix)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapstore prefix %s on SFTP server %s: %w", s.prefix, addr, err)
+	}
+	return s, nil
+}
+
+func sftpClientConfig(creds *sftpCredentials) (*ssh.ClientConfig, error) {
+	if creds.Host == "" || creds.User == "" || len(creds.PrivateKey) == 0 {
+		return nil, fmt.Errorf("host, user and privateKey are required to access the SFTP server")
+	}
+	var (
+		signer ssh.Signer
+		err    error
+	)
+	if len(creds.Passphrase) > 0 {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(creds.PrivateKey, creds.Passphrase)
+	} else {
+		signer, err = ssh.ParsePrivateKey(creds.PrivateKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	switch {
+	case len(creds.KnownHosts) > 0:
+		hostKeyCallback, err = knownHostsCallback(creds.KnownHosts)
+		if err != nil {
+			return nil, err
+		}
+	case creds.InsecureSkipVerify:
+		hostKeyCallback = ssh.InsecureIgnoreHostKey() // #nosec G106 -- explicitly requested by the user.
+	default:
+		return nil, fmt.Errorf("knownHosts are required to verify the SFTP server, unless insecureSkipVerify is set")
+	}
+
+	return &ssh.ClientConfig{
+		User:            creds.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sftpDialTimeout,
+	}, nil
+}
+
+// knownHostsCallback returns a callback verifying host keys against the given known_hosts data.
+func knownHostsCallback(data []byte) (ssh.HostKeyCallback, error) {
+	// knownhosts only reads files, so the data is written to a temporary one.
+	f, err := os.CreateTemp("", "known_hosts")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	callback, err := knownhosts.New(f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse knownHosts: %w", err)
+	}
+	return callback, nil
+}
+
+func readSFTPCredentialFiles(dirname string) (*sftpCredentials, error) {
+	creds := &sftpCredentials{}
+
+	files, err := os.ReadDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		switch file.Name() {
+		case "host":
+			data, err := os.ReadFile(filepath.Join(dirname, "host")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			creds.Host = strings.TrimSpace(string(data))
+		case "user":
+			data, err := os.ReadFile(filepath.Join(dirname, "user")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			creds.User = strings.TrimSpace(string(data))
+		case "privateKey":
+			data, err := os.ReadFile(filepath.Join(dirname, "privateKey")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			creds.PrivateKey = data
+		case "passphrase":
+			data, err := os.ReadFile(filepath.Join(dirname, "passphrase")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			creds.Passphrase = data
+		case "knownHosts":
+			data, err := os.ReadFile(filepath.Join(dirname, "knownHosts")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			creds.KnownHosts = data
+		case "insecureSkipVerify":
+			data, err := os.ReadFile(filepath.Join(dirname, "insecureSkipVerify")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			val, err := strconv.ParseBool(strings.TrimSpace(string(data)))
+			if err != nil {
+				return nil, err
+			}
+			creds.InsecureSkipVerify = val
+		}
+	}
+	return creds, nil
+}
+
+// GetSFTPCredentialsLastModifiedTime returns the latest modification timestamp of the SFTP credential files.
+func GetSFTPCredentialsLastModifiedTime() (time.Time, error) {
+	dir, isSet := os.LookupEnv(sftpCredentialDirectory)
+	if !isSet {
+		return time.Time{}, nil
+	}
+	// credential files which are essential for creating the SFTP snapstore
+	credentialFiles := []string{filepath.Join(dir, "host"), filepath.Join(dir, "user"), filepath.Join(dir, "privateKey")}
+	for _, optional := range []string{"passphrase", "knownHosts", "insecureSkipVerify"} {
+		if _, err := os.Stat(filepath.Join(dir, optional)); err == nil {
+			credentialFiles = append(credentialFiles, filepath.Join(dir, optional))
+		}
+	}
+	sftpTimeStamp, err := getLatestCredentialsModifiedTime(credentialFiles)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get SFTP credential timestamp from the directory %v with error: %v", dir, err)
+	}
+	return sftpTimeStamp, nil
+}
+
+// withClient calls the function with the SFTP client, and connects to the SFTP server if the store is not connected.
+// If the function fails because the connection has been lost, the store reconnects and calls it once more.
+func (s *SFTPSnapStore) withClient(f func(client *sftp.Client) error) error {
+	for attempt := 0; ; attempt++ {
+		client, err := s.getClient()
+		if err != nil {
+			return err
+		}
+		err = f(client)
+		if attempt > 0 || !isSFTPConnectionLost(err) {
+			return err
+		}
+		logrus.Warnf("Lost connection to SFTP server %s, reconnecting: %v", s.addr, err)
+		s.resetClient(client)
+	}
+}
+
+func (s *SFTPSnapStore) getClient() (*sftp.Client, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.client != nil {
+		return s.client, nil
+	}
+	sshClient, err := ssh.Dial("tcp", s.addr, s.clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SFTP server %s: %w", s.addr, err)
+	}
+	client, err := sftp.NewClient(sshClient, sftp.UseConcurrentWrites(true))
+	if err != nil {
+		sshClient.Close()
+		return nil, fmt.Errorf("failed to start SFTP session on %s: %w", s.addr, err)
+	}
+	s.sshClient, s.client = sshClient, client
+	return client, nil
+}
+
+// resetClient closes the given client, unless it has already been replaced by another one.
+func (s *SFTPSnapStore) resetClient(client *sftp.Client) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.client != client {
+		return
+	}
+	s.client.Close()
+	s.sshClient.Close()
+	s.client, s.sshClient = nil, nil
+}
+
+func isSFTPConnectionLost(err error) bool {
+	return errors.Is(err, sftp.ErrSSHFxConnectionLost) || errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed)
+}
+
+// Fetch should open reader for the snapshot file from store
+func (s *SFTPSnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
+	var f *sftp.File
+	err := s.withClient(func(client *sftp.Client) (err error) {
+		f, err = client.Open(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName))
+		return err
+	})
+	return f, err
+}
+
+// Size returns the size of the snapshot file in the store.
+func (s *SFTPSnapStore) Size(snap brtypes.Snapshot) (int64, error) {
+	var info os.FileInfo
+	err := s.withClient(func(client *sftp.Client) (err error) {
+		info, err = client.Stat(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName))
+		return err
+	})
+	if err != nil {
+		return -1, err
+	}
+	return info.Size(), nil
+}
+
+// FetchRange should open reader for a byte range of the snapshot file from store.
+func (s *SFTPSnapStore) FetchRange(snap brtypes.Snapshot, offset, length int64) (io.ReadCloser, error) {
+	f, err := s.Fetch(snap)
+	if err != nil {
+		return nil, err
+	}
+	file := f.(*sftp.File)
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.NewSectionReader(file, offset, length), file}, nil
+}
+
+// Save uploads the snapshot to a temporary file and renames it to the snapshot once it has been uploaded completely.
+func (s *SFTPSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error) {
+	_, span := startSaveSpan(brtypes.SnapstoreProviderSFTP, &snap)
+	defer func() { tracing.End(span, err) }()
+	defer rc.Close()
+
+	target := path.Join(s.prefix, snap.SnapDir, snap.SnapName)
+	dir := path.Dir(target)
+	// the snapshot can only be uploaded once, so the upload is not retried after reconnecting.
+	client, err := s.getClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if isSFTPConnectionLost(err) {
+			s.resetClient(client)
+		}
+	}()
+
+	if err := client.MkdirAll(dir); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	temp := path.Join(dir, fmt.Sprintf("%s%s-%d", sftpTempPrefix, snap.SnapName, time.Now().UnixNano()))
+	f, err := client.OpenFile(temp, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file %s: %w", temp, err)
+	}
+	defer func() {
+		if err != nil {
+			if err1 := client.Remove(temp); err1 != nil && !os.IsNotExist(err1) {
+				logrus.Warnf("Failed to remove temporary file %s: %v", temp, err1)
+			}
+		}
+	}()
+
+	// File.ReadFrom treats io.ErrUnexpectedEOF of the reader as its end, which would save a truncated snapshot, so the
+	// snapshot is copied with large writes instead, which are sent concurrently.
+	if _, err := io.CopyBuffer(struct{ io.Writer }{f}, struct{ io.Reader }{rc}, make([]byte, sftpUploadBufferSize)); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to upload snapshot %s: %w", snap.SnapName, err)
+	}
+	if _, ok := client.HasExtension("fsync@openssh.com"); ok {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to sync snapshot %s: %w", snap.SnapName, err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot %s: %w", snap.SnapName, err)
+	}
+
+	if _, ok := client.HasExtension("posix-rename@openssh.com"); ok {
+		err = client.PosixRename(temp, target)
+	} else {
+		// the rename of SFTPv3 fails if the target exists, so a snapshot which is saved again is removed first.
+		if err = client.Remove(target); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove previous snapshot %s: %w", snap.SnapName, err)
+		}
+		err = client.Rename(temp, target)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to rename snapshot %s: %w", snap.SnapName, err)
+	}
+	return nil
+}
+
+// List will return sorted list with all snapshot files on store.
+func (s *SFTPSnapStore) List(_ bool) (brtypes.SnapList, error) {
+	// consider the parent of the backup version level, which is required for backward compatibility.
+	prefix := path.Dir(s.prefix)
+
+	var snapList brtypes.SnapList
+	err := s.withClient(func(client *sftp.Client) error {
+		snapList = brtypes.SnapList{}
+		walker := client.Walk(prefix)
+		for walker.Step() {
+			if err := walker.Err(); err != nil {
+				return err
+			}
+			p := walker.Path()
+			if walker.Stat().IsDir() || strings.HasPrefix(path.Base(p), sftpTempPrefix) {
+				continue
+			}
+			if !strings.Contains(p, backupVersionV1) && !strings.Contains(p, backupVersionV2) {
+				continue
+			}
+			snap, err := ParseSnapshot(p)
+			if err != nil {
+				logrus.Warnf("Invalid snapshot found. Ignoring it: %s", p)
+				continue
+			}
+			snapList = append(snapList, snap)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking the path %q: %v", prefix, err)
+	}
+
+	sort.Sort(snapList)
+	return snapList, nil
+}
+
+// Delete should delete the snapshot file from store
+func (s *SFTPSnapStore) Delete(snap brtypes.Snapshot) error {
+	target := path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)
+	return s.withClient(func(client *sftp.Client) error {
+		if err := client.Remove(target); err != nil {
+			return err
+		}
+		// remove the directory of a chunked or v1 snapshot once it is empty.
+		dir := path.Dir(target)
+		if path.Clean(dir) != path.Clean(snap.Prefix) {
+			if entries, err := client.ReadDir(dir); err == nil && len(entries) == 0 {
+				return client.RemoveDirectory(dir)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,277 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	. "github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sftpServer is an in-process SSH server serving the SFTP subsystem.
+type sftpServer struct {
+	listener net.Listener
+	hostKey  ssh.Signer
+
+	mutex sync.Mutex
+	conns []net.Conn
+}
+
+// newSFTPServer starts an SFTP server accepting the given client key.
+func newSFTPServer(clientKey ssh.PublicKey) *sftpServer {
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).ShouldNot(HaveOccurred())
+	hostKey, err := ssh.NewSignerFromKey(hostPriv)
+	Expect(err).ShouldNot(HaveOccurred())
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ShouldNot(HaveOccurred())
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, io.ErrUnexpectedEOF
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	s := &sftpServer{listener: listener, hostKey: hostKey}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mutex.Lock()
+			s.conns = append(s.conns, conn)
+			s.mutex.Unlock()
+			go s.serve(conn, config)
+		}
+	}()
+	return s
+}
+
+func (s *sftpServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				isSFTP := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(isSFTP, nil)
+				if isSFTP {
+					server, err := sftp.NewServer(channel)
+					if err != nil {
+						return
+					}
+					_ = server.Serve()
+					_ = channel.Close()
+				}
+			}
+		}()
+	}
+}
+
+// disconnect closes all connections to the server.
+func (s *sftpServer) disconnect() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *sftpServer) close() {
+	s.listener.Close()
+	s.disconnect()
+}
+
+var _ = Describe("SFTPSnapStore", func() {
+	var (
+		root      string
+		credsDir  string
+		server    *sftpServer
+		clientKey ed25519.PrivateKey
+		store     *SFTPSnapStore
+	)
+
+	writeCredential := func(name, value string) {
+		Expect(os.WriteFile(filepath.Join(credsDir, name), []byte(value), 0600)).Should(Succeed())
+	}
+
+	newStore := func() (*SFTPSnapStore, error) {
+		return NewSFTPSnapStore(&brtypes.SnapstoreConfig{Container: root, Prefix: "v2"})
+	}
+
+	newSnapshot := func(lastRevision int64) brtypes.Snapshot {
+		snap := brtypes.Snapshot{
+			Kind:         brtypes.SnapshotKindFull,
+			LastRevision: lastRevision,
+			CreatedOn:    time.Unix(lastRevision, 0).UTC(),
+		}
+		snap.GenerateSnapshotName()
+		return snap
+	}
+
+	list := func() brtypes.SnapList {
+		snapList, err := store.List(false)
+		Expect(err).ShouldNot(HaveOccurred())
+		return snapList
+	}
+
+	BeforeEach(func() {
+		var (
+			clientPub ed25519.PublicKey
+			err       error
+		)
+		clientPub, clientKey, err = ed25519.GenerateKey(rand.Reader)
+		Expect(err).ShouldNot(HaveOccurred())
+		sshClientPub, err := ssh.NewPublicKey(clientPub)
+		Expect(err).ShouldNot(HaveOccurred())
+		server = newSFTPServer(sshClientPub)
+		DeferCleanup(server.close)
+
+		root = GinkgoT().TempDir()
+		credsDir = GinkgoT().TempDir()
+		block, err := ssh.MarshalPrivateKey(clientKey, "")
+		Expect(err).ShouldNot(HaveOccurred())
+		addr := server.listener.Addr().String()
+		writeCredential("host", addr)
+		writeCredential("user", "etcd-backup")
+		writeCredential("privateKey", string(pem.EncodeToMemory(block)))
+		writeCredential("knownHosts", knownhosts.Line([]string{knownhosts.Normalize(addr)}, server.hostKey.PublicKey())+"\n")
+		Expect(os.Setenv("SFTP_APPLICATION_CREDENTIALS", credsDir)).Should(Succeed())
+		DeferCleanup(os.Unsetenv, "SFTP_APPLICATION_CREDENTIALS")
+
+		store, err = newStore()
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should save, list, fetch and delete snapshots", func() {
+		snap := newSnapshot(1)
+		Expect(store.Save(snap, io.NopCloser(strings.NewReader("snapshot")))).Should(Succeed())
+		Expect(filepath.Join(root, "v2", snap.SnapName)).Should(BeAnExistingFile())
+
+		snapList := list()
+		Expect(snapList).Should(HaveLen(1))
+		Expect(snapList[0].SnapName).Should(Equal(snap.SnapName))
+
+		rc, err := store.Fetch(*snapList[0])
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(io.ReadAll(rc)).Should(Equal([]byte("snapshot")))
+		Expect(rc.Close()).Should(Succeed())
+
+		size, err := store.Size(*snapList[0])
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(size).Should(Equal(int64(len("snapshot"))))
+		rc, err = store.FetchRange(*snapList[0], 4, 4)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(io.ReadAll(rc)).Should(Equal([]byte("shot")))
+		Expect(rc.Close()).Should(Succeed())
+
+		Expect(store.Delete(*snapList[0])).Should(Succeed())
+		Expect(list()).Should(BeEmpty())
+	})
+
+	It("should not leave partially uploaded snapshots behind", func() {
+		snap := newSnapshot(1)
+		failing := io.NopCloser(io.MultiReader(strings.NewReader("partial"), &failingReader{}))
+		Expect(store.Save(snap, failing)).ShouldNot(Succeed())
+
+		entries, err := os.ReadDir(filepath.Join(root, "v2"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(entries).Should(BeEmpty())
+	})
+
+	It("should overwrite snapshots on servers without POSIX renames", func() {
+		Expect(sftp.SetSFTPExtensions("hardlink@openssh.com", "statvfs@openssh.com")).Should(Succeed())
+		DeferCleanup(sftp.SetSFTPExtensions, "hardlink@openssh.com", "posix-rename@openssh.com", "statvfs@openssh.com")
+		var err error
+		store, err = newStore()
+		Expect(err).ShouldNot(HaveOccurred())
+
+		snap := newSnapshot(1)
+		Expect(store.Save(snap, io.NopCloser(strings.NewReader("snapshot")))).Should(Succeed())
+		Expect(store.Save(snap, io.NopCloser(strings.NewReader("new snapshot")))).Should(Succeed())
+		Expect(os.ReadFile(filepath.Join(root, "v2", snap.SnapName))).Should(Equal([]byte("new snapshot")))
+	})
+
+	It("should save chunks in the directory of their snapshot and remove it once it is empty", func() {
+		snap := newSnapshot(1)
+		chunk := brtypes.Snapshot{SnapDir: snap.SnapName, SnapName: "0000000001"}
+		Expect(store.Save(chunk, io.NopCloser(strings.NewReader("chunk")))).Should(Succeed())
+
+		snapList := list()
+		Expect(snapList).Should(HaveLen(1))
+		Expect(snapList[0].IsChunk).Should(BeTrue())
+		Expect(store.Delete(*snapList[0])).Should(Succeed())
+		Expect(filepath.Join(root, "v2", snap.SnapName)).ShouldNot(BeADirectory())
+	})
+
+	It("should reconnect after the connection has been lost", func() {
+		snap := newSnapshot(1)
+		Expect(store.Save(snap, io.NopCloser(strings.NewReader("snapshot")))).Should(Succeed())
+		server.disconnect()
+		Expect(list()).Should(HaveLen(1))
+	})
+
+	It("should not connect to servers with an unknown host key", func() {
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		Expect(err).ShouldNot(HaveOccurred())
+		otherSigner, err := ssh.NewSignerFromKey(otherKey)
+		Expect(err).ShouldNot(HaveOccurred())
+		writeCredential("knownHosts", knownhosts.Line([]string{knownhosts.Normalize(server.listener.Addr().String())}, otherSigner.PublicKey())+"\n")
+
+		_, err = newStore()
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should report the latest modification time of the credentials", func() {
+		later := time.Now().Add(time.Hour).Truncate(time.Second)
+		Expect(os.Chtimes(filepath.Join(credsDir, "privateKey"), later, later)).Should(Succeed())
+		modifiedTime, err := GetSnapstoreSecretModifiedTime(brtypes.SnapstoreProviderSFTP)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(modifiedTime).Should(BeTemporally("==", later))
+	})
+
+	It("should report the modification time of the insecureSkipVerify credential", func() {
+		writeCredential("insecureSkipVerify", "false")
+		later := time.Now().Add(time.Hour).Truncate(time.Second)
+		Expect(os.Chtimes(filepath.Join(credsDir, "insecureSkipVerify"), later, later)).Should(Succeed())
+		modifiedTime, err := GetSnapstoreSecretModifiedTime(brtypes.SnapstoreProviderSFTP)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(modifiedTime).Should(BeTemporally("==", later))
+	})
+})
@@ -94,6 +94,8 @@ func ParseSnapshot(snapPath string) (*brtypes.Snapshot, error) {
 		s.Kind = brtypes.SnapshotKindFull
 	case brtypes.SnapshotKindDelta:
 		s.Kind = brtypes.SnapshotKindDelta
+	case brtypes.SnapshotKindPageDiff:
+		s.Kind = brtypes.SnapshotKindPageDiff
 	default:
 		return nil, fmt.Errorf("unknown snapshot kind: %s", tokens[0])
 	}
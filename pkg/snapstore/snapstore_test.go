@@ -14,6 +14,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
@@ -398,8 +399,100 @@ var _ = Describe("Save, List, Fetch, Delete from mock snapstore", func() {
 			}
 		})
 	})
+
+	Describe("When fetching byte ranges", func() {
+		It("should return the size and the byte ranges of a snapshot", func() {
+			for provider, snapStore := range snapstores {
+				resetObjectMap()
+				setObjectMap(provider, brtypes.SnapList{&snap4})
+				expectedBytes := []byte(generateContentsForSnapshot(&snap4))
+
+				logrus.Infof("Running mock tests for %s when fetching byte ranges", provider)
+
+				rangeFetcher, ok := snapStore.SnapStore.(brtypes.RangeFetcher)
+				Expect(ok).To(BeTrue())
+
+				size, err := rangeFetcher.Size(snap4)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(size).To(Equal(int64(len(expectedBytes))))
+
+				for _, offset := range []int64{0, 5, size - 5} {
+					rc, err := rangeFetcher.FetchRange(snap4, offset, 5)
+					Expect(err).ShouldNot(HaveOccurred())
+					buf := new(bytes.Buffer)
+					_, err = io.Copy(buf, rc)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(rc.Close()).To(Succeed())
+					Expect(buf.Bytes()).To(Equal(expectedBytes[offset : offset+5]))
+				}
+			}
+		})
+	})
+
+	Describe("When storing the metadata of a snapshot", func() {
+		It("should return the metadata the snapshot was saved with", func() {
+			for provider, snapStore := range snapstores {
+				resetObjectMap()
+				logrus.Infof("Running mock tests for %s when storing the metadata of a snapshot", provider)
+
+				metadataFetcher, ok := snapStore.SnapStore.(brtypes.MetadataFetcher)
+				if provider == brtypes.SnapstoreProviderSwift {
+					Expect(ok).To(BeFalse())
+					continue
+				}
+				Expect(ok).To(BeTrue())
+
+				snap := snap4
+				snap.Metadata = map[string]string{
+					brtypes.EtcdVersionMetadataKey:   "3.5.21",
+					brtypes.EtcdClusterIDMetadataKey: "cdf818194e3a8c32",
+				}
+				Expect(snapStore.Save(snap, io.NopCloser(strings.NewReader(generateContentsForSnapshot(&snap))))).To(Succeed())
+
+				metadata, err := metadataFetcher.FetchMetadata(snap4)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(metadata).To(Equal(snap.Metadata))
+			}
+		})
+	})
+
+	Describe("When tracking the upload progress of a snapshot", func() {
+		It("should count the bytes of the uploaded chunks instead of the bytes staged", func() {
+			for provider, snapStore := range snapstores {
+				resetObjectMap()
+				logrus.Infof("Running mock tests for %s when tracking the upload progress of a snapshot", provider)
+
+				contents := generateContentsForSnapshot(&snap4)
+				rc := &testUploadProgressReader{ReadCloser: io.NopCloser(strings.NewReader(contents))}
+				Expect(snapStore.Save(snap4, rc)).To(Succeed())
+				Expect(rc.readBytes).To(BeZero())
+				Expect(rc.uploadedBytes).To(Equal(int64(len(contents))))
+			}
+		})
+	})
 })
 
+// testUploadProgressReader records the bytes read from it, and the bytes counted as uploaded by the snapstore.
+type testUploadProgressReader struct {
+	io.ReadCloser
+	readBytes     int64
+	uploadedBytes int64
+}
+
+func (t *testUploadProgressReader) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	t.readBytes += int64(n)
+	return n, err
+}
+
+func (t *testUploadProgressReader) StagingReader() io.ReadCloser {
+	return t.ReadCloser
+}
+
+func (t *testUploadProgressReader) AddUploadedBytes(n int64) {
+	atomic.AddInt64(&t.uploadedBytes, n)
+}
+
 type CredentialTestConfig struct {
 	EnvVariable       string
 	SnapstoreProvider string
@@ -883,6 +976,18 @@ func setObjectMap(provider string, snapshots brtypes.SnapList) int {
 	return numberSnapshotsAdded
 }
 
+// rangeOf returns the bytes of the given data selected by the value of an HTTP Range header of the form "bytes=<first>-<last>".
+func rangeOf(data []byte, httpRange string) []byte {
+	if httpRange == "" {
+		return data
+	}
+	var first, last int
+	if _, err := fmt.Sscanf(httpRange, "bytes=%d-%d", &first, &last); err != nil || first > last || first >= len(data) {
+		return nil
+	}
+	return data[first:min(last+1, len(data))]
+}
+
 func resetObjectMap() {
 	for k := range objectMap {
 		delete(objectMap, k)
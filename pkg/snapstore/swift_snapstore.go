@@ -6,6 +6,7 @@ package snapstore
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -20,6 +21,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
 	"github.com/gophercloud/gophercloud"
@@ -341,9 +343,27 @@ func (s *SwiftSnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
 	return resp.Body, resp.Err
 }
 
+// Size returns the size of the snapshot file in the store.
+func (s *SwiftSnapStore) Size(snap brtypes.Snapshot) (int64, error) {
+	header, err := objects.Get(s.client, s.bucket, path.Join(snap.Prefix, snap.SnapDir, snap.SnapName), nil).Extract()
+	if err != nil {
+		return 0, err
+	}
+	return header.ContentLength, nil
+}
+
+// FetchRange should open reader for a byte range of the snapshot file from store.
+func (s *SwiftSnapStore) FetchRange(snap brtypes.Snapshot, offset, length int64) (io.ReadCloser, error) {
+	resp := objects.Download(s.client, s.bucket, path.Join(snap.Prefix, snap.SnapDir, snap.SnapName), objects.DownloadOpts{Range: httpRange(offset, length)})
+	return resp.Body, resp.Err
+}
+
 // Save will write the snapshot to store, as a DLO (dynamic large object), as described
 // in https://docs.openstack.org/swift/latest/overview_large_objects.html
 func (s *SwiftSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error) {
+	saveCtx, span := startSaveSpan(brtypes.SnapstoreProviderSwift, &snap)
+	defer func() { tracing.End(span, err) }()
+
 	tempFile, size, err := writeSnapshotToTempFile(s.tempDir, rc)
 	if err != nil {
 		return err
@@ -377,7 +397,7 @@ func (s *SwiftSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err erro
 
 	for i := uint(0); i < s.maxParallelChunkUploads; i++ {
 		wg.Add(1)
-		go s.chunkUploader(&wg, cancelCh, &snap, tempFile, chunkUploadCh, resCh)
+		go s.chunkUploader(saveCtx, &wg, cancelCh, &snap, tempFile, chunkUploadCh, resCh)
 	}
 
 	logrus.Infof("Uploading snapshot of size: %d, chunkSize: %d, noOfChunks: %d", size, chunkSize, noOfChunks)
@@ -392,7 +412,7 @@ func (s *SwiftSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err erro
 	}
 	logrus.Infof("Triggered chunk upload for all chunks, total: %d", noOfChunks)
 
-	snapshotErr := collectChunkUploadError(chunkUploadCh, resCh, cancelCh, noOfChunks)
+	snapshotErr := collectChunkUploadError(chunkUploadCh, resCh, cancelCh, noOfChunks, uploadedChunkReporter(rc, size))
 	wg.Wait()
 
 	if snapshotErr != nil {
@@ -434,7 +454,7 @@ func (s *SwiftSnapStore) uploadChunk(snap *brtypes.Snapshot, file *os.File, offs
 	return res.Err
 }
 
-func (s *SwiftSnapStore) chunkUploader(wg *sync.WaitGroup, stopCh <-chan struct{}, snap *brtypes.Snapshot, file *os.File, chunkUploadCh chan chunk, errCh chan<- chunkUploadResult) {
+func (s *SwiftSnapStore) chunkUploader(ctx context.Context, wg *sync.WaitGroup, stopCh <-chan struct{}, snap *brtypes.Snapshot, file *os.File, chunkUploadCh chan chunk, errCh chan<- chunkUploadResult) {
 	defer wg.Done()
 	for {
 		select {
@@ -445,7 +465,9 @@ func (s *SwiftSnapStore) chunkUploader(wg *sync.WaitGroup, stopCh <-chan struct{
 				return
 			}
 			logrus.Infof("Uploading chunk with offset : %d, attempt: %d", uploadChunk.offset, uploadChunk.attempt)
+			_, span := startChunkUploadSpan(ctx, brtypes.SnapstoreProviderSwift, snap, uploadChunk)
 			err := s.uploadChunk(snap, file, uploadChunk.offset, uploadChunk.size)
+			tracing.End(span, err)
 			errCh <- chunkUploadResult{
 				err:   err,
 				chunk: &uploadChunk,
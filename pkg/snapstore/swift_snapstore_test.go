@@ -16,6 +16,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/gophercloud/gophercloud/openstack/objectstorage/v1/objects"
 	th "github.com/gophercloud/gophercloud/testhelper"
@@ -39,6 +40,9 @@ func initializeMockSwiftServer(t *testing.T) {
 			} else {
 				handleDownloadObject(w, r)
 			}
+		case "HEAD":
+			th.TestMethod(t, r, "HEAD")
+			handleDownloadObject(w, r)
 		case "PUT":
 			th.TestMethod(t, r, "PUT")
 			handleCreateTextObject(w, r)
@@ -101,7 +105,7 @@ func handleCreateTextObject(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleDownloadObject creates an HTTP handler at `/testContainer/testObject` on the test handler mux that
-// responds with a `Download` response.
+// responds with a `Download` response, or with a `Get` response for HEAD requests. Range requests are served as well.
 func handleDownloadObject(w http.ResponseWriter, r *http.Request) {
 	objectMapMutex.Lock()
 	defer objectMapMutex.Unlock()
@@ -122,7 +126,7 @@ func handleDownloadObject(w http.ResponseWriter, r *http.Request) {
 		contents = append(contents, data...)
 	}
 
-	_, _ = w.Write(contents)
+	http.ServeContent(w, r, prefix, time.Time{}, bytes.NewReader(contents))
 }
 
 // handleListObjectNames creates an HTTP handler at `/testContainer` on the test handler mux that
@@ -5,6 +5,7 @@
 package snapstore
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -15,9 +16,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
 	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -28,8 +31,35 @@ const (
 	sourcePrefixString        = "SOURCE_"
 )
 
-// GetSnapstore returns the snapstore object for give storageProvider with specified container
+// GetSnapstore returns the snapstore object for give storageProvider with specified container.
+// If deduplication is configured, the returned snapstore stores full snapshots as content-defined chunks.
+// If a snapshot cache is configured, the returned snapstore fetches the snapshots it saved from a local directory.
+// If a fan-out is configured, the returned snapstore writes every snapshot to the fan-out snapstores as well.
 func GetSnapstore(config *brtypes.SnapstoreConfig) (brtypes.SnapStore, error) {
+	store, err := getSnapstore(config)
+	if err != nil || config.FanOut == nil || len(config.FanOut.StoreConfigs) == 0 {
+		return store, err
+	}
+	return newFanOutSnapStoreFromConfig(config, store)
+}
+
+// getSnapstore returns the snapstore for the given config, which deduplicates full snapshots and caches snapshots
+// locally if configured.
+func getSnapstore(config *brtypes.SnapstoreConfig) (brtypes.SnapStore, error) {
+	store, err := newSnapstore(config)
+	if err != nil {
+		return nil, err
+	}
+	if config.Deduplication {
+		store = NewDedupSnapStore(store, dedupLocation(config), config.MaxParallelChunkUploads)
+	}
+	if config.SnapshotCacheDir == "" {
+		return store, nil
+	}
+	return NewCacheSnapStore(store, cacheDir(config), config.SnapshotCacheSize)
+}
+
+func newSnapstore(config *brtypes.SnapstoreConfig) (brtypes.SnapStore, error) {
 	if config.Prefix == "" {
 		config.Prefix = backupVersion
 	}
@@ -90,6 +120,16 @@ func GetSnapstore(config *brtypes.SnapstoreConfig) (brtypes.SnapStore, error) {
 		return NewECSSnapStore(config)
 	case brtypes.SnapstoreProviderOCS:
 		return NewOCSSnapStore(config)
+	case brtypes.SnapstoreProviderFilesystem:
+		return NewFilesystemSnapStore(config)
+	case brtypes.SnapstoreProviderSFTP:
+		return NewSFTPSnapStore(config)
+	case brtypes.SnapstoreProviderWebDAV:
+		return NewWebDAVSnapStore(config)
+	case brtypes.SnapstoreProviderMinIO:
+		return NewMinIOSnapStore(config)
+	case brtypes.SnapstoreProviderRGW:
+		return NewRGWSnapStore(config)
 	case brtypes.SnapstoreProviderFakeFailed:
 		return NewFailedSnapStore(), nil
 	default:
@@ -119,7 +159,8 @@ func GetEnvVarToBool(varName string) (bool, error) {
 }
 
 // collectChunkUploadError collects the error from all go routine to upload individual chunks
-func collectChunkUploadError(chunkUploadCh chan<- chunk, resCh <-chan chunkUploadResult, stopCh chan struct{}, noOfChunks int64) *chunkUploadResult {
+// and reports each chunk which has been uploaded successfully to onUploaded.
+func collectChunkUploadError(chunkUploadCh chan<- chunk, resCh <-chan chunkUploadResult, stopCh chan struct{}, noOfChunks int64, onUploaded func(*chunk)) *chunkUploadResult {
 	remainingChunks := noOfChunks
 	logrus.Infof("No of Chunks:= %d", noOfChunks)
 	for chunkRes := range resCh {
@@ -144,6 +185,7 @@ func collectChunkUploadError(chunkUploadCh chan<- chunk, resCh <-chan chunkUploa
 				}
 			})
 		} else {
+			onUploaded(chunkRes.chunk)
 			remainingChunks--
 			if remainingChunks == 0 {
 				logrus.Infof("Received successful chunk result for all chunks. Stopping workers.")
@@ -177,9 +219,9 @@ func adaptPrefix(snap *brtypes.Snapshot, snapstorePrefix string) string {
 // Returns an error if fetching the timestamp of the access credential files fails.
 func GetSnapstoreSecretModifiedTime(snapstoreProvider string) (time.Time, error) {
 	switch snapstoreProvider {
-	case brtypes.SnapstoreProviderLocal:
+	case brtypes.SnapstoreProviderLocal, brtypes.SnapstoreProviderFilesystem:
 		return time.Time{}, nil
-	case brtypes.SnapstoreProviderS3:
+	case brtypes.SnapstoreProviderS3, brtypes.SnapstoreProviderMinIO, brtypes.SnapstoreProviderRGW:
 		return GetS3CredentialsLastModifiedTime()
 	case brtypes.SnapstoreProviderABS:
 		return GetABSCredentialsLastModifiedTime()
@@ -191,6 +233,10 @@ func GetSnapstoreSecretModifiedTime(snapstoreProvider string) (time.Time, error)
 		return GetOSSCredentialsLastModifiedTime()
 	case brtypes.SnapstoreProviderOCS:
 		return GetOCSCredentialsLastModifiedTime()
+	case brtypes.SnapstoreProviderSFTP:
+		return GetSFTPCredentialsLastModifiedTime()
+	case brtypes.SnapstoreProviderWebDAV:
+		return GetWebDAVCredentialsLastModifiedTime()
 	default:
 		return time.Time{}, nil
 	}
@@ -217,6 +263,16 @@ func getLatestCredentialsModifiedTime(credentialFiles []string) (time.Time, erro
 	return latestModifiedTime, nil
 }
 
+// getWorkloadIdentityCredentialsModifiedTime returns the latest file modification time from a list of files, after
+// checking that the token file exists. The token file itself is not considered, since the short-lived token is
+// rotated regularly and re-read by the credentials provider, which does not require the snapstore to be recreated.
+func getWorkloadIdentityCredentialsModifiedTime(credentialFiles []string, tokenFile string) (time.Time, error) {
+	if _, err := os.Stat(tokenFile); err != nil {
+		return time.Time{}, err
+	}
+	return getLatestCredentialsModifiedTime(credentialFiles)
+}
+
 // findFileWithExtensionInDir checks whether there is any file present in a given directory with given file extension.
 func findFileWithExtensionInDir(dir, extension string) (string, error) {
 	dirEntries, err := os.ReadDir(dir)
@@ -255,8 +311,12 @@ func getJSONCredentialModifiedTime(dir string) (time.Time, error) {
 }
 
 // writeSnapshotToTempFile writes the snapshot to a temporary file and returns the file handle.
+// Writing the snapshot to the file does not count towards its upload progress.
 // The caller must ensure that the file is closed and removed after use.
 func writeSnapshotToTempFile(tempDir string, rc io.ReadCloser) (tempFile *os.File, written int64, err error) {
+	if pr, ok := rc.(brtypes.UploadProgressReader); ok {
+		rc = pr.StagingReader()
+	}
 	defer func() {
 		if err1 := rc.Close(); err1 != nil {
 			err = errors.Join(err, fmt.Errorf("failed to close snapshot reader: %v", err1))
@@ -276,3 +336,56 @@ func writeSnapshotToTempFile(tempDir string, rc io.ReadCloser) (tempFile *os.Fil
 
 	return
 }
+
+// uploadedChunkReporter returns the function which counts the bytes of an uploaded chunk of a staged snapshot of
+// the given size towards the upload progress of the snapshot reader, if its progress is tracked.
+func uploadedChunkReporter(rc io.ReadCloser, size int64) func(*chunk) {
+	pr, ok := rc.(brtypes.UploadProgressReader)
+	if !ok {
+		return func(*chunk) {}
+	}
+	return func(c *chunk) {
+		pr.AddUploadedBytes(min(c.size, size-c.offset))
+	}
+}
+
+// uploadCountingReader counts the bytes read from a staged snapshot towards the upload progress of the snapshot reader.
+type uploadCountingReader struct {
+	io.Reader
+	pr brtypes.UploadProgressReader
+}
+
+func (u *uploadCountingReader) Read(p []byte) (int, error) {
+	n, err := u.Reader.Read(p)
+	u.pr.AddUploadedBytes(int64(n))
+	return n, err
+}
+
+// startSaveSpan starts the span of saving the snapshot to the snapstore of the given provider.
+func startSaveSpan(provider string, snap *brtypes.Snapshot) (context.Context, trace.Span) {
+	return tracing.Start(context.TODO(), "snapstore.Save", append(tracing.SnapshotAttributes(snap), tracing.AttributeSnapStoreProvider.String(provider))...)
+}
+
+// startChunkUploadSpan starts the span of uploading a chunk of a snapshot to the snapstore of the given provider.
+func startChunkUploadSpan(ctx context.Context, provider string, snap *brtypes.Snapshot, c chunk) (context.Context, trace.Span) {
+	attributes := append(tracing.SnapshotAttributes(snap), tracing.AttributeSnapStoreProvider.String(provider))
+	return tracing.Start(ctx, "snapstore.UploadChunk", append(attributes, tracing.ChunkAttributes(c.id, c.offset, c.size, c.attempt)...)...)
+}
+
+// httpRange returns the value of the HTTP Range header for the given number of bytes, starting at the given offset.
+func httpRange(offset, length int64) string {
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+}
+
+// lowercaseMetadataKeys returns the user-defined metadata of a snapshot with lowercase keys, since some providers
+// canonicalize the case of the keys when they are read back.
+func lowercaseMetadataKeys(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	lowercased := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		lowercased[strings.ToLower(key)] = value
+	}
+	return lowercased
+}
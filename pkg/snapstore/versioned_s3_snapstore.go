@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gardener/etcd-backup-restore/pkg/snapstore/internal/s3api"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// S3VersioningOptions configure how an S3SnapStore handles the versions of snapshots in a versioned bucket.
+type S3VersioningOptions struct {
+	// DeleteMarkerAware makes listing ignore snapshots whose latest version is a delete marker, since they have been
+	// deleted. They are still listed if all snapshots are requested, so that they are garbage collected.
+	DeleteMarkerAware bool
+	// PurgeVersions makes deleting a snapshot delete all its versions and delete markers, instead of only its listed version.
+	PurgeVersions bool
+	// ListNoncurrentVersions lists snapshots whose latest version is a delete marker by their noncurrent versions, so
+	// that they can be recovered.
+	ListNoncurrentVersions bool
+}
+
+// NewMinIOSnapStore creates a new S3SnapStore for a MinIO bucket, which handles the versions of snapshots if the
+// bucket is versioned.
+func NewMinIOSnapStore(config *brtypes.SnapstoreConfig) (*S3SnapStore, error) {
+	return newVersionedS3SnapStore(config)
+}
+
+// NewRGWSnapStore creates a new S3SnapStore for a Ceph RADOS Gateway bucket, which handles the versions of snapshots
+// if the bucket is versioned.
+func NewRGWSnapStore(config *brtypes.SnapstoreConfig) (*S3SnapStore, error) {
+	return newVersionedS3SnapStore(config)
+}
+
+// newVersionedS3SnapStore creates a new S3SnapStore from the S3 credentials, which uses path-style addressing unless
+// configured otherwise and handles the versions of snapshots.
+func newVersionedS3SnapStore(config *brtypes.SnapstoreConfig) (*S3SnapStore, error) {
+	cfgOpts, cliOpts, sseCreds, err := getConfigOpts(getEnvPrefixString(config))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(context.TODO(), cfgOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("new AWS config failed: %w", err)
+	}
+
+	// MinIO and RGW are usually not addressed by virtual host, the option set in the credentials takes precedence.
+	cliOpts = append([]func(*s3.Options){func(o *s3.Options) { o.UsePathStyle = true }}, cliOpts...)
+	cli := s3.NewFromConfig(cfg, cliOpts...)
+	return NewVersionedS3FromClient(config.Container, config.Prefix, config.TempDir, config.MaxParallelChunkUploads, config.MinChunkSize, cli, sseCreds, S3VersioningOptions{
+		DeleteMarkerAware:      true,
+		PurgeVersions:          config.PurgeSnapshotVersions,
+		ListNoncurrentVersions: config.ListNoncurrentVersions,
+	}), nil
+}
+
+// NewVersionedS3FromClient will create the new S3 snapstore object from S3 client, which handles the versions of
+// snapshots according to the versioning options.
+func NewVersionedS3FromClient(bucket, prefix, tempDir string, maxParallelChunkUploads uint, minChunkSize int64, cli s3api.Client, sseCreds SSECredentials, versioning S3VersioningOptions) *S3SnapStore {
+	s := NewS3FromClient(bucket, prefix, tempDir, maxParallelChunkUploads, minChunkSize, cli, sseCreds)
+	s.versioning = versioning
+	return s
+}
+
+// purgeVersions deletes all versions of the object with the given key. The delete markers are only deleted once all
+// versions have been deleted, so that a version which cannot be deleted yet stays deleted.
+func (s *S3SnapStore) purgeVersions(key string) error {
+	var versions, deleteMarkers []*string
+
+	paginator := s3.NewListObjectVersionsPaginator(s.client, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(key),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return fmt.Errorf("failed to list versions of %s: %w", key, err)
+		}
+		for _, version := range page.Versions {
+			if aws.ToString(version.Key) == key {
+				versions = append(versions, version.VersionId)
+			}
+		}
+		for _, deleteMarker := range page.DeleteMarkers {
+			if aws.ToString(deleteMarker.Key) == key {
+				deleteMarkers = append(deleteMarkers, deleteMarker.VersionId)
+			}
+		}
+	}
+
+	var errs []error
+	for _, versionID := range versions {
+		if err := s.deleteVersion(key, versionID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	for _, versionID := range deleteMarkers {
+		if err := s.deleteVersion(key, versionID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	logrus.Infof("Purged %d versions and %d delete markers of %s", len(versions), len(deleteMarkers), key)
+	return errors.Join(errs...)
+}
+
+func (s *S3SnapStore) deleteVersion(key string, versionID *string) error {
+	_, err := s.client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+		Bucket:    aws.String(s.bucket),
+		Key:       aws.String(key),
+		VersionId: versionID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete version %s of %s: %w", aws.ToString(versionID), key, err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	. "github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// objectVersion is a version or delete marker of an object in a versioned bucket.
+type objectVersion struct {
+	key            string
+	versionID      string
+	lastModified   time.Time
+	isDeleteMarker bool
+	isLocked       bool
+}
+
+// versionedS3Client is a mock of a versioned bucket with a default retention period of a day.
+type versionedS3Client struct {
+	*mockS3Client
+	versions []*objectVersion
+}
+
+func (c *versionedS3Client) GetBucketVersioning(context.Context, *s3.GetBucketVersioningInput, ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+	return &s3.GetBucketVersioningOutput{Status: s3types.BucketVersioningStatusEnabled}, nil
+}
+
+func (c *versionedS3Client) GetObjectLockConfiguration(context.Context, *s3.GetObjectLockConfigurationInput, ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error) {
+	return &s3.GetObjectLockConfigurationOutput{
+		ObjectLockConfiguration: &s3types.ObjectLockConfiguration{
+			ObjectLockEnabled: s3types.ObjectLockEnabledEnabled,
+			Rule:              &s3types.ObjectLockRule{DefaultRetention: &s3types.DefaultRetention{Days: aws.Int32(1)}},
+		},
+	}, nil
+}
+
+func (c *versionedS3Client) GetObjectTagging(context.Context, *s3.GetObjectTaggingInput, ...func(*s3.Options)) (*s3.GetObjectTaggingOutput, error) {
+	return &s3.GetObjectTaggingOutput{}, nil
+}
+
+func (c *versionedS3Client) ListObjectVersions(_ context.Context, in *s3.ListObjectVersionsInput, _ ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error) {
+	latest := map[string]*objectVersion{}
+	for _, v := range c.versions {
+		if l, ok := latest[v.key]; !ok || v.lastModified.After(l.lastModified) {
+			latest[v.key] = v
+		}
+	}
+	out := &s3.ListObjectVersionsOutput{Prefix: in.Prefix}
+	for _, v := range c.versions {
+		if !strings.HasPrefix(v.key, aws.ToString(in.Prefix)) {
+			continue
+		}
+		if v.isDeleteMarker {
+			out.DeleteMarkers = append(out.DeleteMarkers, s3types.DeleteMarkerEntry{
+				Key: aws.String(v.key), VersionId: aws.String(v.versionID), LastModified: aws.Time(v.lastModified), IsLatest: aws.Bool(latest[v.key] == v),
+			})
+			continue
+		}
+		out.Versions = append(out.Versions, s3types.ObjectVersion{
+			Key: aws.String(v.key), VersionId: aws.String(v.versionID), LastModified: aws.Time(v.lastModified), IsLatest: aws.Bool(latest[v.key] == v),
+		})
+	}
+	return out, nil
+}
+
+func (c *versionedS3Client) DeleteObject(_ context.Context, in *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	for i, v := range c.versions {
+		if v.key == aws.ToString(in.Key) && v.versionID == aws.ToString(in.VersionId) {
+			if v.isLocked {
+				return nil, fmt.Errorf("version %s of %s is locked", v.versionID, v.key)
+			}
+			c.versions = append(c.versions[:i], c.versions[i+1:]...)
+			return &s3.DeleteObjectOutput{}, nil
+		}
+	}
+	return nil, fmt.Errorf("version %s of %s not found", aws.ToString(in.VersionId), aws.ToString(in.Key))
+}
+
+// versionIDs returns the IDs of all versions and delete markers.
+func (c *versionedS3Client) versionIDs() []string {
+	var keys []string
+	for _, v := range c.versions {
+		keys = append(keys, v.versionID)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var _ = Describe("Versioned S3SnapStore", func() {
+	var (
+		client               *versionedS3Client
+		deleted, overwritten string
+	)
+
+	newStore := func(versioning S3VersioningOptions) *S3SnapStore {
+		return NewVersionedS3FromClient("mock-bucket", "mock/v2", "/tmp", 5, brtypes.MinChunkSize, client, SSECredentials{}, versioning)
+	}
+
+	snapshotKey := func(lastRevision int64, createdOn time.Time) string {
+		snap := brtypes.Snapshot{Kind: brtypes.SnapshotKindFull, LastRevision: lastRevision, CreatedOn: createdOn}
+		snap.GenerateSnapshotName()
+		return "mock/v2/" + snap.SnapName
+	}
+
+	list := func(store *S3SnapStore, includeAll bool) []string {
+		snapList, err := store.List(includeAll)
+		Expect(err).ShouldNot(HaveOccurred())
+		var keys []string
+		for _, snap := range snapList {
+			keys = append(keys, snap.Prefix+snap.SnapName)
+		}
+		return keys
+	}
+
+	BeforeEach(func() {
+		now := time.Now().Truncate(time.Second)
+		deleted = snapshotKey(1, now.Add(-3*time.Hour))
+		overwritten = snapshotKey(2, now.Add(-2*time.Hour))
+		client = &versionedS3Client{
+			mockS3Client: &mockS3Client{},
+			versions: []*objectVersion{
+				{key: deleted, versionID: "deleted-1", lastModified: now.Add(-3 * time.Hour)},
+				{key: deleted, versionID: "deleted-marker", lastModified: now.Add(-time.Hour), isDeleteMarker: true},
+				{key: overwritten, versionID: "overwritten-1", lastModified: now.Add(-2 * time.Hour)},
+				{key: overwritten, versionID: "overwritten-2", lastModified: now.Add(-time.Minute)},
+			},
+		}
+	})
+
+	It("should not list deleted snapshots unless all snapshots are requested", func() {
+		store := newStore(S3VersioningOptions{DeleteMarkerAware: true})
+		Expect(list(store, false)).Should(ConsistOf(overwritten))
+		Expect(list(store, true)).Should(ConsistOf(overwritten, deleted))
+	})
+
+	It("should list deleted snapshots by their noncurrent versions to recover them", func() {
+		store := newStore(S3VersioningOptions{DeleteMarkerAware: true, ListNoncurrentVersions: true})
+		snapList, err := store.List(false)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(snapList).Should(HaveLen(2))
+		Expect(*snapList[0].VersionID).Should(Equal("deleted-1"))
+	})
+
+	It("should list deleted snapshots as before if it is not aware of delete markers", func() {
+		store := newStore(S3VersioningOptions{})
+		Expect(list(store, false)).Should(ConsistOf(overwritten, deleted))
+	})
+
+	It("should only delete the listed version if it does not purge versions", func() {
+		store := newStore(S3VersioningOptions{DeleteMarkerAware: true})
+		snapList, err := store.List(true)
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, snap := range snapList {
+			Expect(store.Delete(*snap)).Should(Succeed())
+		}
+		Expect(client.versionIDs()).Should(ConsistOf("deleted-marker", "overwritten-2"))
+	})
+
+	It("should delete all versions and delete markers if it purges versions", func() {
+		store := newStore(S3VersioningOptions{DeleteMarkerAware: true, PurgeVersions: true})
+		snapList, err := store.List(true)
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, snap := range snapList {
+			Expect(store.Delete(*snap)).Should(Succeed())
+		}
+		Expect(client.versionIDs()).Should(BeEmpty())
+		Expect(list(store, true)).Should(BeEmpty())
+	})
+
+	It("should keep the delete markers of snapshots whose versions cannot all be deleted", func() {
+		client.versions[0].isLocked = true
+		store := newStore(S3VersioningOptions{DeleteMarkerAware: true, PurgeVersions: true})
+		snapList, err := store.List(true)
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, snap := range snapList {
+			if snap.Prefix+snap.SnapName == deleted {
+				Expect(store.Delete(*snap)).ShouldNot(Succeed())
+			}
+		}
+		Expect(client.versionIDs()).Should(ContainElements("deleted-1", "deleted-marker"))
+		Expect(list(store, false)).Should(ConsistOf(overwritten))
+	})
+
+	It("should consider snapshots immutable until their newest version is no longer immutable if it purges versions", func() {
+		expiryTimes := func(store *S3SnapStore) map[string]time.Time {
+			snapList, err := store.List(false)
+			Expect(err).ShouldNot(HaveOccurred())
+			times := map[string]time.Time{}
+			for _, snap := range snapList {
+				times[snap.Prefix+snap.SnapName] = snap.ImmutabilityExpiryTime
+			}
+			return times
+		}
+
+		Expect(expiryTimes(newStore(S3VersioningOptions{DeleteMarkerAware: true}))[overwritten]).Should(BeTemporally("~", time.Now().Add(22*time.Hour), time.Minute))
+		Expect(expiryTimes(newStore(S3VersioningOptions{DeleteMarkerAware: true, PurgeVersions: true}))[overwritten]).Should(BeTemporally("~", time.Now().Add(24*time.Hour), 2*time.Minute))
+	})
+})
@@ -0,0 +1,580 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	webdavCredentialDirectory = "WEBDAV_APPLICATION_CREDENTIALS" // #nosec G101 -- This is not a hardcoded password, but only a path to the credentials.
+	// webdavTempPrefix is the prefix of the temporary resources snapshots are uploaded to before they are moved.
+	webdavTempPrefix = ".tmp-"
+	// webdavPropfindBody requests the resource type of the members of a collection.
+	webdavPropfindBody = `<?xml version="1.0" encoding="utf-8"?><d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/></d:prop></d:propfind>`
+)
+
+// webdavCredentials are the credentials to access the WebDAV or HTTP server.
+type webdavCredentials struct {
+	// URL is the base URL of the server.
+	URL string
+	// Username and Password are used for basic authentication.
+	Username string
+	Password string
+	// Token is used for bearer authentication.
+	Token string
+	// TrustedCaCert is the PEM encoded CA certificate the certificate of the server is verified with.
+	TrustedCaCert []byte
+	// InsecureSkipVerify skips the verification of the certificate of the server.
+	InsecureSkipVerify bool
+	// ChunkedUpload streams snapshots to the server with chunked transfer encoding, instead of buffering them in a
+	// temporary file to upload them with their length.
+	ChunkedUpload bool
+}
+
+// WebDAVSnapStore is snapstore with a collection on a WebDAV server, or a server supporting HTTP PUT, GET, DELETE and
+// PROPFIND, as backend.
+type WebDAVSnapStore struct {
+	baseURL       *url.URL
+	prefix        string
+	tempDir       string
+	client        *http.Client
+	credentials   *webdavCredentials
+	isDAV         bool
+	collections   sync.Map
+	chunkedUpload bool
+}
+
+// NewWebDAVSnapStore creates a new WebDAVSnapStore in the prefix of the collection given as container, using the
+// credentials in the directory given by the WEBDAV_APPLICATION_CREDENTIALS environment variable.
+func NewWebDAVSnapStore(config *brtypes.SnapstoreConfig) (*WebDAVSnapStore, error) {
+	dir, isSet := os.LookupEnv(getEnvPrefixString(config) + webdavCredentialDirectory)
+	if !isSet {
+		return nil, fmt.Errorf("environment variable %s is not set", getEnvPrefixString(config)+webdavCredentialDirectory)
+	}
+	creds, err := readWebDAVCredentialFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error getting credentials from %v directory with error %w", dir, err)
+	}
+	return newWebDAVSnapStoreFromCredentials(config.Container, config.Prefix, config.TempDir, creds)
+}
+
+func newWebDAVSnapStoreFromCredentials(container, prefix, tempDir string, creds *webdavCredentials) (*WebDAVSnapStore, error) {
+	if creds.URL == "" {
+		return nil, fmt.Errorf("url is required to access the WebDAV server")
+	}
+	baseURL, err := url.Parse(creds.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url %s: %w", creds.URL, err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: creds.InsecureSkipVerify, // #nosec G402 -- explicitly requested by the user.
+	}
+	if len(creds.TrustedCaCert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(creds.TrustedCaCert) {
+			return nil, fmt.Errorf("failed to parse trustedCaCert")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	s := &WebDAVSnapStore{
+		baseURL:       baseURL,
+		prefix:        path.Join(container, prefix),
+		tempDir:       tempDir,
+		client:        &http.Client{Transport: transport},
+		credentials:   creds,
+		chunkedUpload: creds.ChunkedUpload,
+	}
+	if s.isDAV, err = s.detectDAV(); err != nil {
+		return nil, err
+	}
+	if s.isDAV {
+		if err := s.mkcolAll(s.prefix); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func readWebDAVCredentialFiles(dirname string) (*webdavCredentials, error) {
+	creds := &webdavCredentials{}
+
+	files, err := os.ReadDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		switch file.Name() {
+		case "url":
+			data, err := os.ReadFile(filepath.Join(dirname, "url")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			creds.URL = strings.TrimSpace(string(data))
+		case "username":
+			data, err := os.ReadFile(filepath.Join(dirname, "username")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			creds.Username = strings.TrimSpace(string(data))
+		case "password":
+			data, err := os.ReadFile(filepath.Join(dirname, "password")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			creds.Password = strings.TrimSpace(string(data))
+		case "token":
+			data, err := os.ReadFile(filepath.Join(dirname, "token")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			creds.Token = strings.TrimSpace(string(data))
+		case "trustedCaCert":
+			data, err := os.ReadFile(filepath.Join(dirname, "trustedCaCert")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			creds.TrustedCaCert = data
+		case "insecureSkipVerify":
+			data, err := os.ReadFile(filepath.Join(dirname, "insecureSkipVerify")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			val, err := strconv.ParseBool(strings.TrimSpace(string(data)))
+			if err != nil {
+				return nil, err
+			}
+			creds.InsecureSkipVerify = val
+		case "chunkedUpload":
+			data, err := os.ReadFile(filepath.Join(dirname, "chunkedUpload")) // #nosec G304 -- this is a trusted file, obtained via user input.
+			if err != nil {
+				return nil, err
+			}
+			val, err := strconv.ParseBool(strings.TrimSpace(string(data)))
+			if err != nil {
+				return nil, err
+			}
+			creds.ChunkedUpload = val
+		}
+	}
+	return creds, nil
+}
+
+// GetWebDAVCredentialsLastModifiedTime returns the latest modification timestamp of the WebDAV credential files.
+func GetWebDAVCredentialsLastModifiedTime() (time.Time, error) {
+	dir, isSet := os.LookupEnv(webdavCredentialDirectory)
+	if !isSet {
+		return time.Time{}, nil
+	}
+	// the url is essential for creating the WebDAV snapstore, the other credential files are optional.
+	credentialFiles := []string{filepath.Join(dir, "url")}
+	for _, optional := range []string{"username", "password", "token", "trustedCaCert"} {
+		if _, err := os.Stat(filepath.Join(dir, optional)); err == nil {
+			credentialFiles = append(credentialFiles, filepath.Join(dir, optional))
+		}
+	}
+	webdavTimeStamp, err := getLatestCredentialsModifiedTime(credentialFiles)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get WebDAV credential timestamp from the directory %v with error: %v", dir, err)
+	}
+	return webdavTimeStamp, nil
+}
+
+// resourceURL returns the URL of the resource at the given path, relative to the base URL.
+func (s *WebDAVSnapStore) resourceURL(p string) string {
+	return s.baseURL.JoinPath(p).String()
+}
+
+// collectionURL returns the URL of the collection at the given path, relative to the base URL.
+func (s *WebDAVSnapStore) collectionURL(p string) string {
+	u := s.baseURL.JoinPath(p)
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/"
+	return u.String()
+}
+
+// do sends an authenticated request and checks that the response has one of the expected status codes.
+// The body of the returned response must be closed by the caller.
+func (s *WebDAVSnapStore) do(req *http.Request, expected ...int) (*http.Response, error) {
+	switch {
+	case s.credentials.Token != "":
+		req.Header.Set("Authorization", "Bearer "+s.credentials.Token)
+	case s.credentials.Username != "":
+		req.SetBasicAuth(s.credentials.Username, s.credentials.Password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			return resp, nil
+		}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return nil, &webdavStatusError{method: req.Method, url: req.URL.Redacted(), statusCode: resp.StatusCode, body: strings.TrimSpace(string(body))}
+}
+
+// webdavStatusError is returned for responses with an unexpected status code.
+type webdavStatusError struct {
+	method     string
+	url        string
+	statusCode int
+	body       string
+}
+
+func (e *webdavStatusError) Error() string {
+	return fmt.Sprintf("%s %s failed with status %d: %s", e.method, e.url, e.statusCode, e.body)
+}
+
+func isWebDAVStatus(err error, statusCode int) bool {
+	var statusErr *webdavStatusError
+	return errors.As(err, &statusErr) && statusErr.statusCode == statusCode
+}
+
+// detectDAV returns whether the server supports WebDAV, as opposed to plain HTTP.
+func (s *WebDAVSnapStore) detectDAV() (bool, error) {
+	req, err := http.NewRequest(http.MethodOptions, s.baseURL.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := s.do(req, http.StatusOK, http.StatusNoContent, http.StatusMethodNotAllowed, http.StatusNotFound)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to WebDAV server %s: %w", s.baseURL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+	return resp.Header.Get("DAV") != "", nil
+}
+
+// mkcolAll creates the collection at the given path and its parents, unless they have been created before.
+func (s *WebDAVSnapStore) mkcolAll(p string) error {
+	var current string
+	for _, segment := range strings.Split(strings.Trim(p, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		current = path.Join(current, segment)
+		if _, ok := s.collections.Load(current); ok {
+			continue
+		}
+		req, err := http.NewRequest("MKCOL", s.collectionURL(current), nil)
+		if err != nil {
+			return err
+		}
+		// a collection which exists already is reported as not allowed.
+		resp, err := s.do(req, http.StatusCreated, http.StatusMethodNotAllowed)
+		if err != nil {
+			return fmt.Errorf("failed to create collection %s: %w", current, err)
+		}
+		resp.Body.Close()
+		s.collections.Store(current, struct{}{})
+	}
+	return nil
+}
+
+// Fetch should open reader for the snapshot file from store
+func (s *WebDAVSnapStore) Fetch(snap brtypes.Snapshot) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, s.resourceURL(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Size returns the size of the snapshot file in the store. It returns brtypes.ErrRangeFetchUnsupported if the server
+// does not serve byte ranges of it.
+func (s *WebDAVSnapStore) Size(snap brtypes.Snapshot) (int64, error) {
+	req, err := http.NewRequest(http.MethodHead, s.resourceURL(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)), nil)
+	if err != nil {
+		return -1, err
+	}
+	resp, err := s.do(req, http.StatusOK)
+	if err != nil {
+		return -1, err
+	}
+	resp.Body.Close()
+	if resp.Header.Get("Accept-Ranges") != "bytes" || resp.ContentLength < 0 {
+		return -1, brtypes.ErrRangeFetchUnsupported
+	}
+	return resp.ContentLength, nil
+}
+
+// FetchRange should open reader for a byte range of the snapshot file from store.
+func (s *WebDAVSnapStore) FetchRange(snap brtypes.Snapshot, offset, length int64) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, s.resourceURL(path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", httpRange(offset, length))
+	resp, err := s.do(req, http.StatusPartialContent)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Save will write the snapshot to store. On WebDAV servers, the snapshot is uploaded to a temporary resource which is
+// moved to the snapshot once it has been uploaded completely.
+func (s *WebDAVSnapStore) Save(snap brtypes.Snapshot, rc io.ReadCloser) (err error) {
+	_, span := startSaveSpan(brtypes.SnapstoreProviderWebDAV, &snap)
+	defer func() { tracing.End(span, err) }()
+
+	target := path.Join(s.prefix, snap.SnapDir, snap.SnapName)
+	dir := path.Dir(target)
+	if s.isDAV {
+		if err := s.mkcolAll(dir); err != nil {
+			rc.Close()
+			return err
+		}
+	}
+
+	var (
+		body   io.Reader
+		length int64 = -1
+	)
+	if s.chunkedUpload {
+		defer rc.Close()
+		body = rc
+	} else {
+		tempFile, size, err := writeSnapshotToTempFile(s.tempDir, rc)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err1 := tempFile.Close()
+			if err1 != nil {
+				err1 = fmt.Errorf("failed to close snapshot tempfile: %v", err1)
+			}
+			err2 := os.Remove(tempFile.Name())
+			if err2 != nil {
+				err2 = fmt.Errorf("failed to remove snapshot tempfile: %v", err2)
+			}
+			err = errors.Join(err, err1, err2)
+		}()
+		if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		body, length = tempFile, size
+		if pr, ok := rc.(brtypes.UploadProgressReader); ok {
+			body = &uploadCountingReader{Reader: tempFile, pr: pr}
+		}
+	}
+
+	uploadPath := target
+	if s.isDAV {
+		uploadPath = path.Join(dir, fmt.Sprintf("%s%s-%d", webdavTempPrefix, snap.SnapName, time.Now().UnixNano()))
+	}
+	req, err := http.NewRequest(http.MethodPut, s.resourceURL(uploadPath), body)
+	if err != nil {
+		return err
+	}
+	// a length of -1 makes the client use chunked transfer encoding.
+	req.ContentLength = length
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := s.do(req, http.StatusOK, http.StatusCreated, http.StatusNoContent)
+	if err != nil {
+		if s.isDAV {
+			s.deleteResource(uploadPath)
+		}
+		return fmt.Errorf("failed to upload snapshot %s: %w", snap.SnapName, err)
+	}
+	resp.Body.Close()
+	if !s.isDAV {
+		return nil
+	}
+
+	req, err = http.NewRequest("MOVE", s.resourceURL(uploadPath), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Destination", s.resourceURL(target))
+	req.Header.Set("Overwrite", "T")
+	resp, err = s.do(req, http.StatusCreated, http.StatusNoContent)
+	if err != nil {
+		s.deleteResource(uploadPath)
+		return fmt.Errorf("failed to move uploaded snapshot %s: %w", snap.SnapName, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// deleteResource deletes a temporary resource, logging failures.
+func (s *WebDAVSnapStore) deleteResource(p string) {
+	req, err := http.NewRequest(http.MethodDelete, s.resourceURL(p), nil)
+	if err == nil {
+		var resp *http.Response
+		if resp, err = s.do(req, http.StatusOK, http.StatusNoContent, http.StatusNotFound); err == nil {
+			resp.Body.Close()
+		}
+	}
+	if err != nil {
+		logrus.Warnf("Failed to delete temporary resource %s: %v", p, err)
+	}
+}
+
+// webdavMultistatus is the response to a PROPFIND request.
+type webdavMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Prop struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"DAV: collection"`
+				} `xml:"DAV: resourcetype"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// webdavMember is a member of a collection.
+type webdavMember struct {
+	path         string
+	isCollection bool
+}
+
+// propfind returns the members of the collection at the given path, relative to the base URL.
+func (s *WebDAVSnapStore) propfind(p string) ([]webdavMember, error) {
+	req, err := http.NewRequest("PROPFIND", s.collectionURL(p), strings.NewReader(webdavPropfindBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Depth", "1")
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	resp, err := s.do(req, http.StatusMultiStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	multistatus := &webdavMultistatus{}
+	if err := xml.NewDecoder(resp.Body).Decode(multistatus); err != nil {
+		return nil, fmt.Errorf("failed to parse PROPFIND response of %s: %w", p, err)
+	}
+	basePath := strings.TrimSuffix(s.baseURL.Path, "/")
+	var members []webdavMember
+	for _, response := range multistatus.Responses {
+		href, err := url.Parse(response.Href)
+		if err != nil {
+			return nil, fmt.Errorf("invalid href %q in PROPFIND response of %s: %w", response.Href, p, err)
+		}
+		memberPath := strings.Trim(strings.TrimPrefix(href.Path, basePath), "/")
+		// the collection itself is part of the response.
+		if memberPath == strings.Trim(p, "/") {
+			continue
+		}
+		member := webdavMember{path: memberPath}
+		for _, propstat := range response.Propstat {
+			if propstat.Prop.ResourceType.Collection != nil {
+				member.isCollection = true
+			}
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// List will return sorted list with all snapshot files on store.
+func (s *WebDAVSnapStore) List(_ bool) (brtypes.SnapList, error) {
+	// consider the parent of the backup version level, which is required for backward compatibility.
+	prefix := path.Dir(s.prefix)
+	if prefix == "." {
+		prefix = ""
+	}
+
+	snapList := brtypes.SnapList{}
+	collections := []string{prefix}
+	for len(collections) > 0 {
+		collection := collections[0]
+		collections = collections[1:]
+		members, err := s.propfind(collection)
+		if err != nil {
+			return nil, fmt.Errorf("error listing the collection %q: %w", collection, err)
+		}
+		for _, member := range members {
+			if member.isCollection {
+				collections = append(collections, member.path)
+				continue
+			}
+			if strings.HasPrefix(path.Base(member.path), webdavTempPrefix) {
+				continue
+			}
+			if !strings.Contains(member.path, backupVersionV1) && !strings.Contains(member.path, backupVersionV2) {
+				continue
+			}
+			snap, err := ParseSnapshot(member.path)
+			if err != nil {
+				logrus.Warnf("Invalid snapshot found. Ignoring it: %s", member.path)
+				continue
+			}
+			snapList = append(snapList, snap)
+		}
+	}
+
+	sort.Sort(snapList)
+	return snapList, nil
+}
+
+// Delete should delete the snapshot file from store
+func (s *WebDAVSnapStore) Delete(snap brtypes.Snapshot) error {
+	target := path.Join(snap.Prefix, snap.SnapDir, snap.SnapName)
+	req, err := http.NewRequest(http.MethodDelete, s.resourceURL(target), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, http.StatusOK, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	// remove the collection of a chunked or v1 snapshot once it is empty.
+	dir := path.Dir(target)
+	if !s.isDAV || path.Clean(dir) == path.Clean(snap.Prefix) {
+		return nil
+	}
+	members, err := s.propfind(dir)
+	if err != nil {
+		if isWebDAVStatus(err, http.StatusNotFound) {
+			return nil
+		}
+		logrus.Warnf("Failed to list collection %s of deleted snapshot %s: %v", dir, snap.SnapName, err)
+		return nil
+	}
+	if len(members) == 0 {
+		s.deleteResource(dir + "/")
+		s.collections.Delete(dir)
+	}
+	return nil
+}
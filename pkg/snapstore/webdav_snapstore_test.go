@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore_test
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	. "github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/webdav"
+)
+
+var _ = Describe("WebDAVSnapStore", func() {
+	var (
+		davFS          webdav.FileSystem
+		server         *httptest.Server
+		credsDir       string
+		withoutDAV     bool
+		chunkedUploads atomic.Int32
+		store          *WebDAVSnapStore
+	)
+
+	writeCredential := func(name, value string) {
+		Expect(os.WriteFile(filepath.Join(credsDir, name), []byte(value), 0600)).Should(Succeed())
+	}
+
+	newStore := func() (*WebDAVSnapStore, error) {
+		return NewWebDAVSnapStore(&brtypes.SnapstoreConfig{Container: "etcd", Prefix: "v2", TempDir: GinkgoT().TempDir()})
+	}
+
+	newSnapshot := func(lastRevision int64) brtypes.Snapshot {
+		snap := brtypes.Snapshot{
+			Kind:         brtypes.SnapshotKindFull,
+			LastRevision: lastRevision,
+			CreatedOn:    time.Unix(lastRevision, 0).UTC(),
+		}
+		snap.GenerateSnapshotName()
+		return snap
+	}
+
+	list := func() brtypes.SnapList {
+		snapList, err := store.List(false)
+		Expect(err).ShouldNot(HaveOccurred())
+		return snapList
+	}
+
+	BeforeEach(func() {
+		davFS = webdav.NewMemFS()
+		withoutDAV = false
+		chunkedUploads.Store(0)
+		handler := &webdav.Handler{Prefix: "/dav", FileSystem: davFS, LockSystem: webdav.NewMemLS()}
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, ok := r.BasicAuth()
+			if (!ok || user != "etcd" || password != "secret") && r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if withoutDAV && r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodPut &&
+				r.Method != http.MethodDelete && r.Method != "PROPFIND" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if r.Method == http.MethodPut && r.ContentLength < 0 {
+				chunkedUploads.Add(1)
+			}
+			handler.ServeHTTP(w, r)
+		}))
+		DeferCleanup(server.Close)
+
+		credsDir = GinkgoT().TempDir()
+		writeCredential("url", server.URL+"/dav")
+		writeCredential("username", "etcd")
+		writeCredential("password", "secret")
+		writeCredential("trustedCaCert", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})))
+		Expect(os.Setenv("WEBDAV_APPLICATION_CREDENTIALS", credsDir)).Should(Succeed())
+		DeferCleanup(os.Unsetenv, "WEBDAV_APPLICATION_CREDENTIALS")
+
+		var err error
+		store, err = newStore()
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should save, list, fetch and delete snapshots", func() {
+		snap := newSnapshot(1)
+		Expect(store.Save(snap, io.NopCloser(strings.NewReader("snapshot")))).Should(Succeed())
+		Expect(chunkedUploads.Load()).Should(BeZero())
+
+		snapList := list()
+		Expect(snapList).Should(HaveLen(1))
+		Expect(snapList[0].SnapName).Should(Equal(snap.SnapName))
+
+		rc, err := store.Fetch(*snapList[0])
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(io.ReadAll(rc)).Should(Equal([]byte("snapshot")))
+		Expect(rc.Close()).Should(Succeed())
+
+		size, err := store.Size(*snapList[0])
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(size).Should(Equal(int64(len("snapshot"))))
+		rc, err = store.FetchRange(*snapList[0], 4, 4)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(io.ReadAll(rc)).Should(Equal([]byte("shot")))
+		Expect(rc.Close()).Should(Succeed())
+
+		Expect(store.Delete(*snapList[0])).Should(Succeed())
+		Expect(list()).Should(BeEmpty())
+	})
+
+	It("should not leave partially uploaded snapshots behind", func() {
+		writeCredential("chunkedUpload", "true")
+		var err error
+		store, err = newStore()
+		Expect(err).ShouldNot(HaveOccurred())
+
+		failing := io.NopCloser(io.MultiReader(strings.NewReader("partial"), &failingReader{}))
+		Expect(store.Save(newSnapshot(1), failing)).ShouldNot(Succeed())
+		Eventually(func() []os.FileInfo {
+			f, err := davFS.OpenFile(context.TODO(), "/etcd/v2", os.O_RDONLY, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+			defer f.Close()
+			infos, err := f.Readdir(-1)
+			Expect(err).ShouldNot(HaveOccurred())
+			return infos
+		}).Should(BeEmpty())
+	})
+
+	It("should stream snapshots with chunked uploads", func() {
+		writeCredential("chunkedUpload", "true")
+		var err error
+		store, err = newStore()
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(store.Save(newSnapshot(1), io.NopCloser(strings.NewReader("snapshot")))).Should(Succeed())
+		Expect(chunkedUploads.Load()).Should(BeNumerically(">", 0))
+		Expect(list()).Should(HaveLen(1))
+	})
+
+	It("should save chunks in the collection of their snapshot and remove it once it is empty", func() {
+		snap := newSnapshot(1)
+		chunk := brtypes.Snapshot{SnapDir: snap.SnapName, SnapName: "0000000001"}
+		Expect(store.Save(chunk, io.NopCloser(strings.NewReader("chunk")))).Should(Succeed())
+
+		snapList := list()
+		Expect(snapList).Should(HaveLen(1))
+		Expect(snapList[0].IsChunk).Should(BeTrue())
+		Expect(store.Delete(*snapList[0])).Should(Succeed())
+		_, err := davFS.Stat(context.TODO(), "/etcd/v2/"+snap.SnapName)
+		Expect(os.IsNotExist(err)).Should(BeTrue())
+	})
+
+	It("should upload directly to servers without WebDAV", func() {
+		withoutDAV = true
+		var err error
+		store, err = newStore()
+		Expect(err).ShouldNot(HaveOccurred())
+
+		snap := newSnapshot(1)
+		Expect(store.Save(snap, io.NopCloser(strings.NewReader("snapshot")))).Should(Succeed())
+		snapList := list()
+		Expect(snapList).Should(HaveLen(1))
+		Expect(snapList[0].SnapName).Should(Equal(snap.SnapName))
+	})
+
+	It("should authenticate with a bearer token", func() {
+		Expect(os.Remove(filepath.Join(credsDir, "username"))).Should(Succeed())
+		Expect(os.Remove(filepath.Join(credsDir, "password"))).Should(Succeed())
+		writeCredential("token", "token")
+		var err error
+		store, err = newStore()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(store.Save(newSnapshot(1), io.NopCloser(strings.NewReader("snapshot")))).Should(Succeed())
+
+		writeCredential("token", "invalid")
+		_, err = newStore()
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should not connect to servers with an untrusted certificate", func() {
+		Expect(os.Remove(filepath.Join(credsDir, "trustedCaCert"))).Should(Succeed())
+		_, err := newStore()
+		Expect(err).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,238 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snapstore_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	. "github.com/gardener/etcd-backup-restore/pkg/snapstore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Workload identity", func() {
+	Describe("access credential rotation", func() {
+		type workloadIdentityTestConfig struct {
+			EnvVariable       string
+			SnapstoreProvider string
+			// CredentialFile is set if the environment variable points to a file in the credential directory.
+			CredentialFile  string
+			CredentialFiles []string
+		}
+
+		for _, config := range []workloadIdentityTestConfig{
+			{
+				EnvVariable:       "AWS_APPLICATION_CREDENTIALS",
+				SnapstoreProvider: brtypes.SnapstoreProviderS3,
+				CredentialFiles:   []string{"region", "roleARN"},
+			},
+			{
+				EnvVariable:       "AZURE_APPLICATION_CREDENTIALS",
+				SnapstoreProvider: brtypes.SnapstoreProviderABS,
+				CredentialFiles:   []string{"storageAccount", "clientID", "tenantID"},
+			},
+			{
+				EnvVariable:       "GOOGLE_APPLICATION_CREDENTIALS",
+				SnapstoreProvider: brtypes.SnapstoreProviderGCS,
+				CredentialFile:    "credentialsConfig",
+				CredentialFiles:   []string{"credentialsConfig", "projectID"},
+			},
+			{
+				EnvVariable:       "ALICLOUD_APPLICATION_CREDENTIALS",
+				SnapstoreProvider: brtypes.SnapstoreProviderOSS,
+				CredentialFiles:   []string{"roleARN", "oidcProviderARN", "storageEndpoint"},
+			},
+		} {
+			Describe(fmt.Sprintf("for %q", config.SnapstoreProvider), func() {
+				var (
+					credentialDirectory string
+					lastCreationTime    time.Time
+				)
+
+				BeforeEach(func() {
+					credentialDirectory = GinkgoT().TempDir()
+					GinkgoT().Setenv(config.EnvVariable, credentialDirectory)
+					if config.CredentialFile != "" {
+						GinkgoT().Setenv(config.EnvVariable, filepath.Join(credentialDirectory, config.CredentialFile))
+					}
+					var err error
+					lastCreationTime, err = createCredentialFilesInDirectory(credentialDirectory, append(config.CredentialFiles, "token"))
+					Expect(err).ShouldNot(HaveOccurred())
+					if config.SnapstoreProvider == brtypes.SnapstoreProviderGCS {
+						Expect(os.WriteFile(filepath.Join(credentialDirectory, "credentialsConfig"), []byte(`{"type": "external_account"}`), 0600)).Should(Succeed())
+						info, err := os.Stat(filepath.Join(credentialDirectory, "credentialsConfig"))
+						Expect(err).ShouldNot(HaveOccurred())
+						lastCreationTime = info.ModTime()
+					}
+				})
+
+				It("should not consider the rotated token", func() {
+					Expect(modifyCredentialFileInDirectory(credentialDirectory, "token")).Should(Succeed())
+					newSecretModifiedTime, err := GetSnapstoreSecretModifiedTime(config.SnapstoreProvider)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(newSecretModifiedTime).Should(BeTemporally("==", lastCreationTime))
+				})
+
+				It("should return error when the token is missing", func() {
+					Expect(os.Remove(filepath.Join(credentialDirectory, "token"))).Should(Succeed())
+					_, err := GetSnapstoreSecretModifiedTime(config.SnapstoreProvider)
+					Expect(err).Should(HaveOccurred())
+				})
+
+				for _, credentialFile := range config.CredentialFiles {
+					It(fmt.Sprintf("should return the modification time of the credential file %q", credentialFile), func() {
+						Expect(modifyCredentialFileInDirectory(credentialDirectory, credentialFile)).Should(Succeed())
+						newSecretModifiedTime, err := GetSnapstoreSecretModifiedTime(config.SnapstoreProvider)
+						Expect(err).ShouldNot(HaveOccurred())
+						Expect(newSecretModifiedTime).Should(BeTemporally(">", lastCreationTime))
+					})
+				}
+			})
+		}
+
+		It("should return the modification time of the region for IRSA configured by the environment", func() {
+			credentialDirectory := GinkgoT().TempDir()
+			GinkgoT().Setenv("AWS_APPLICATION_CREDENTIALS", credentialDirectory)
+			lastCreationTime, err := createCredentialFilesInDirectory(credentialDirectory, []string{"region"})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, err = GetSnapstoreSecretModifiedTime(brtypes.SnapstoreProviderS3)
+			Expect(err).Should(HaveOccurred())
+
+			GinkgoT().Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/etcd-backup")
+			GinkgoT().Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", filepath.Join(GinkgoT().TempDir(), "token"))
+			newSecretModifiedTime, err := GetSnapstoreSecretModifiedTime(brtypes.SnapstoreProviderS3)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(newSecretModifiedTime).Should(BeTemporally("==", lastCreationTime))
+		})
+	})
+
+	Describe("AWS", func() {
+		BeforeEach(func() {
+			credentialDirectory := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(credentialDirectory, "region"), []byte("eu-west-1"), 0600)).Should(Succeed())
+			GinkgoT().Setenv("AWS_APPLICATION_CREDENTIALS", credentialDirectory)
+			// the shared configuration of the AWS SDK must not be taken from the environment of the test
+			GinkgoT().Setenv("AWS_CA_BUNDLE", "")
+		})
+
+		It("should require credentials unless IRSA or EKS Pod Identity is used", func() {
+			_, err := NewS3SnapStore(&brtypes.SnapstoreConfig{Container: "etcd", Prefix: "v2"})
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should use the default credentials chain for IRSA", func() {
+			GinkgoT().Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/etcd-backup")
+			GinkgoT().Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", filepath.Join(GinkgoT().TempDir(), "token"))
+			_, err := NewS3SnapStore(&brtypes.SnapstoreConfig{Container: "etcd", Prefix: "v2"})
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should use the default credentials chain for EKS Pod Identity", func() {
+			GinkgoT().Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", "http://169.254.170.23/v1/credentials")
+			_, err := NewS3SnapStore(&brtypes.SnapstoreConfig{Container: "etcd", Prefix: "v2"})
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Describe("Alibaba RRSA", func() {
+		var (
+			credentialDirectory string
+			mutex               sync.Mutex
+			oidcTokens          []string
+			securityTokens      []string
+			expiresIn           time.Duration
+			store               *OSSSnapStore
+		)
+
+		BeforeEach(func() {
+			oidcTokens, securityTokens, expiresIn = nil, nil, time.Hour
+
+			sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.ParseForm()).Should(Succeed())
+				if r.Form.Get("Action") != "AssumeRoleWithOIDC" || r.Form.Get("RoleArn") != "acs:ram::1:role/etcd-backup" ||
+					r.Form.Get("OIDCProviderArn") != "acs:ram::1:oidc-provider/ack-rrsa" {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"Code": "InvalidParameter", "Message": "invalid request"}`))
+					return
+				}
+				mutex.Lock()
+				defer mutex.Unlock()
+				oidcTokens = append(oidcTokens, r.Form.Get("OIDCToken"))
+				Expect(json.NewEncoder(w).Encode(map[string]any{
+					"Credentials": map[string]string{
+						"AccessKeyId":     "STS.id",
+						"AccessKeySecret": "secret",
+						"SecurityToken":   fmt.Sprintf("security-token-%d", len(oidcTokens)),
+						"Expiration":      time.Now().Add(expiresIn).UTC().Format(time.RFC3339),
+					},
+				})).Should(Succeed())
+			}))
+			DeferCleanup(sts.Close)
+
+			oss := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				securityTokens = append(securityTokens, r.Header.Get("X-Oss-Security-Token"))
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			DeferCleanup(oss.Close)
+
+			credentialDirectory = GinkgoT().TempDir()
+			for name, value := range map[string]string{
+				"storageEndpoint": oss.URL,
+				"stsEndpoint":     sts.URL,
+				"roleARN":         "acs:ram::1:role/etcd-backup",
+				"oidcProviderARN": "acs:ram::1:oidc-provider/ack-rrsa",
+				"token":           "oidc-token-1",
+			} {
+				Expect(os.WriteFile(filepath.Join(credentialDirectory, name), []byte(value), 0600)).Should(Succeed())
+			}
+			GinkgoT().Setenv("ALICLOUD_APPLICATION_CREDENTIALS", credentialDirectory)
+
+			var err error
+			store, err = NewOSSSnapStore(&brtypes.SnapstoreConfig{Container: "etcd", Prefix: "v2"})
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		deleteSnapshot := func() {
+			Expect(store.Delete(brtypes.Snapshot{Prefix: "v2", SnapName: "Full-00000000-00000001-1"})).Should(Succeed())
+		}
+
+		It("should sign requests with the credentials of the assumed role until they are about to expire", func() {
+			deleteSnapshot()
+			deleteSnapshot()
+			Expect(oidcTokens).Should(Equal([]string{"oidc-token-1"}))
+			Expect(securityTokens).Should(Equal([]string{"security-token-1", "security-token-1"}))
+		})
+
+		It("should assume the role with the rotated token once the credentials are about to expire", func() {
+			expiresIn = time.Minute
+			deleteSnapshot()
+			Expect(os.WriteFile(filepath.Join(credentialDirectory, "token"), []byte("oidc-token-2"), 0600)).Should(Succeed())
+			deleteSnapshot()
+			Expect(oidcTokens).Should(Equal([]string{"oidc-token-1", "oidc-token-2"}))
+			Expect(securityTokens).Should(Equal([]string{"security-token-1", "security-token-2"}))
+		})
+
+		It("should fail requests if the role cannot be assumed", func() {
+			Expect(os.WriteFile(filepath.Join(credentialDirectory, "roleARN"), []byte("acs:ram::1:role/other"), 0600)).Should(Succeed())
+			var err error
+			store, err = NewOSSSnapStore(&brtypes.SnapstoreConfig{Container: "etcd", Prefix: "v2"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(store.Delete(brtypes.Snapshot{Prefix: "v2", SnapName: "Full-00000000-00000001-1"})).ShouldNot(Succeed())
+			Expect(securityTokens).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AttributeBytesRead is the number of bytes read from a stream.
+const AttributeBytesRead = attribute.Key("io.bytes_read")
+
+// spanReadCloser ends a span once the wrapped stream has been consumed, has failed, or has been closed.
+type spanReadCloser struct {
+	io.ReadCloser
+	span      trace.Span
+	bytesRead int64
+	once      sync.Once
+}
+
+// NewSpanReadCloser returns a ReadCloser which ends the span once the stream has been read to the end,
+// reading it has failed, or it has been closed. This allows tracing streamed operations which complete
+// in the reader of the stream, like the transfer of the etcd snapshot or its compression.
+func NewSpanReadCloser(rc io.ReadCloser, span trace.Span) io.ReadCloser {
+	return &spanReadCloser{ReadCloser: rc, span: span}
+}
+
+func (s *spanReadCloser) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	s.bytesRead += int64(n)
+	if errors.Is(err, io.EOF) {
+		s.end(nil)
+	} else if err != nil {
+		s.end(err)
+	}
+	return n, err
+}
+
+func (s *spanReadCloser) Close() error {
+	err := s.ReadCloser.Close()
+	s.end(err)
+	return err
+}
+
+func (s *spanReadCloser) end(err error) {
+	s.once.Do(func() {
+		s.span.SetAttributes(AttributeBytesRead.Int64(s.bytesRead))
+		End(s.span, err)
+	})
+}
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tracing provides the OpenTelemetry tracing of the backup and restore operations.
+// Spans are recorded with the global tracer provider, which does nothing unless tracing
+// has been set up with NewTracerProvider.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// tracerName is the name of the tracer recording the spans.
+	tracerName = "github.com/gardener/etcd-backup-restore"
+
+	// AttributeSnapshotName is the name of the snapshot.
+	AttributeSnapshotName = attribute.Key("snapshot.name")
+	// AttributeSnapshotKind is the kind of the snapshot, either Full or Incr.
+	AttributeSnapshotKind = attribute.Key("snapshot.kind")
+	// AttributeSnapshotStartRevision is the start revision of the snapshot.
+	AttributeSnapshotStartRevision = attribute.Key("snapshot.start_revision")
+	// AttributeSnapshotLastRevision is the last revision of the snapshot.
+	AttributeSnapshotLastRevision = attribute.Key("snapshot.last_revision")
+	// AttributeSnapStoreProvider is the provider of the snapstore.
+	AttributeSnapStoreProvider = attribute.Key("snapstore.provider")
+	// AttributeChunkID is the ID of an uploaded chunk.
+	AttributeChunkID = attribute.Key("chunk.id")
+	// AttributeChunkOffset is the offset of an uploaded chunk in the snapshot.
+	AttributeChunkOffset = attribute.Key("chunk.offset")
+	// AttributeChunkSize is the size of an uploaded chunk.
+	AttributeChunkSize = attribute.Key("chunk.size")
+	// AttributeChunkAttempt is the attempt of an uploaded chunk.
+	AttributeChunkAttempt = attribute.Key("chunk.attempt")
+	// AttributeCompressionPolicy is the compression policy of a snapshot.
+	AttributeCompressionPolicy = attribute.Key("compression.policy")
+)
+
+// Tracer returns the tracer of etcd-backup-restore.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Start starts a span with the given attributes.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End records the error, if any, on the span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SnapshotAttributes returns the attributes describing the snapshot.
+func SnapshotAttributes(snap *brtypes.Snapshot) []attribute.KeyValue {
+	if snap == nil {
+		return nil
+	}
+	return []attribute.KeyValue{
+		AttributeSnapshotName.String(snap.SnapName),
+		AttributeSnapshotKind.String(snap.Kind),
+		AttributeSnapshotStartRevision.Int64(snap.StartRevision),
+		AttributeSnapshotLastRevision.Int64(snap.LastRevision),
+	}
+}
+
+// ChunkAttributes returns the attributes describing an uploaded chunk.
+func ChunkAttributes(id int, offset, size int64, attempt uint) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		AttributeChunkID.Int(id),
+		AttributeChunkOffset.Int64(offset),
+		AttributeChunkSize.Int64(size),
+		AttributeChunkAttempt.Int(int(attempt)), // #nosec G115 -- the number of attempts is small.
+	}
+}
+
+// NewTracerProvider creates a tracer provider exporting the spans to the configured OTLP endpoint
+// and sets it as global tracer provider. The returned provider must be shut down to flush the spans.
+func NewTracerProvider(ctx context.Context, config *brtypes.TracingConfig) (*sdktrace.TracerProvider, error) {
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.OTLPEndpoint)}
+	if config.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %v", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(config.ServiceName),
+		semconv.K8SPodName(os.Getenv("POD_NAME")),
+		semconv.K8SNamespaceName(os.Getenv("POD_NAMESPACE")),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %v", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SamplingRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp, nil
+}
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gardener/etcd-backup-restore/pkg/tracing"
+	brtypes "github.com/gardener/etcd-backup-restore/pkg/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+var _ = Describe("Tracing", func() {
+	var recorder *tracetest.SpanRecorder
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	})
+
+	AfterEach(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+
+	attributes := func(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+		m := map[attribute.Key]attribute.Value{}
+		for _, kv := range span.Attributes() {
+			m[kv.Key] = kv.Value
+		}
+		return m
+	}
+
+	It("should record the snapshot attributes and the error", func() {
+		snap := &brtypes.Snapshot{Kind: brtypes.SnapshotKindDelta, SnapName: "Incr-00000011-00000020-1748858400", StartRevision: 11, LastRevision: 20}
+		ctx, parent := tracing.Start(context.TODO(), "parent")
+		_, span := tracing.Start(ctx, "child", tracing.SnapshotAttributes(snap)...)
+		tracing.End(span, errors.New("upload failed"))
+		tracing.End(parent, nil)
+
+		Expect(recorder.Ended()).To(HaveLen(2))
+		child := recorder.Ended()[0]
+		Expect(child.Name()).To(Equal("child"))
+		Expect(child.Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()))
+		Expect(child.Status().Code).To(Equal(codes.Error))
+		Expect(child.Status().Description).To(Equal("upload failed"))
+		Expect(attributes(child)).To(HaveKeyWithValue(tracing.AttributeSnapshotName, attribute.StringValue(snap.SnapName)))
+		Expect(attributes(child)).To(HaveKeyWithValue(tracing.AttributeSnapshotLastRevision, attribute.Int64Value(20)))
+		Expect(recorder.Ended()[1].Status().Code).To(Equal(codes.Unset))
+	})
+
+	It("should end the span of a stream once it has been consumed", func() {
+		_, span := tracing.Start(context.TODO(), "stream")
+		rc := tracing.NewSpanReadCloser(io.NopCloser(strings.NewReader("snapshot data")), span)
+
+		data, err := io.ReadAll(rc)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(data)).To(Equal("snapshot data"))
+		Expect(recorder.Ended()).To(HaveLen(1))
+		Expect(attributes(recorder.Ended()[0])).To(HaveKeyWithValue(tracing.AttributeBytesRead, attribute.Int64Value(13)))
+
+		Expect(rc.Close()).To(Succeed())
+		Expect(recorder.Ended()).To(HaveLen(1))
+	})
+
+	It("should end the span of a stream which is closed before it has been consumed", func() {
+		_, span := tracing.Start(context.TODO(), "stream")
+		rc := tracing.NewSpanReadCloser(io.NopCloser(strings.NewReader("snapshot data")), span)
+
+		Expect(recorder.Ended()).To(BeEmpty())
+		Expect(rc.Close()).To(Succeed())
+		Expect(recorder.Ended()).To(HaveLen(1))
+		Expect(attributes(recorder.Ended()[0])).To(HaveKeyWithValue(tracing.AttributeBytesRead, attribute.Int64Value(0)))
+	})
+})
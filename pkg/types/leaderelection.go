@@ -20,6 +20,12 @@ const (
 	DefaultReelectionPeriod = 5 * time.Second
 	// DefaultEtcdStatusConnecTimeout defines default ConnectionTimeout for etcd client to get Etcd endpoint status.
 	DefaultEtcdStatusConnecTimeout = 5 * time.Second
+	// DefaultLearnerMaxApplyLag defines default number of raft entries a learner may lag behind the leader to be promoted.
+	DefaultLearnerMaxApplyLag = 1000
+	// DefaultLearnerCatchUpTimeout defines default time period within which a learner is expected to catch up with the leader.
+	DefaultLearnerCatchUpTimeout = 30 * time.Minute
+	// DefaultQuorumLossThreshold defines default time period after which the absence of an etcd leader is reported as quorum loss.
+	DefaultQuorumLossThreshold = 5 * time.Minute
 )
 
 // LeaderCallbacks are callbacks that are triggered to start/stop the snapshottter when leader's currentState changes.
@@ -38,6 +44,12 @@ type MemberLeaseCallbacks struct {
 	StopLeaseRenewal func()
 }
 
+// QuorumLossCallback is callback which is triggered after every leadership status check answered by the etcd member,
+// with whether the member knows of a leader, so that a sustained loss of the etcd quorum can be detected.
+type QuorumLossCallback struct {
+	Observe func(ctx context.Context, quorumLost bool, logger *logrus.Entry)
+}
+
 // EtcdMemberStatusCallbackFunc is type declaration for callback function to Check Etcd member Status.
 type EtcdMemberStatusCallbackFunc func(context.Context, *EtcdConnectionConfig, time.Duration, *logrus.Entry) (bool, bool, error)
 
@@ -52,6 +64,14 @@ type Config struct {
 	ReelectionPeriod wrappers.Duration `json:"reelectionPeriod,omitempty"`
 	// EtcdConnectionTimeout defines the timeout duration for etcd client connection during leader election.
 	EtcdConnectionTimeout wrappers.Duration `json:"etcdConnectionTimeout,omitempty"`
+	// LearnerMaxApplyLag defines the number of raft entries a learner may lag behind the leader to be promoted.
+	LearnerMaxApplyLag uint64 `json:"learnerMaxApplyLag,omitempty"`
+	// LearnerCatchUpTimeout defines the time period within which a learner is expected to catch up with the leader.
+	LearnerCatchUpTimeout wrappers.Duration `json:"learnerCatchUpTimeout,omitempty"`
+	// QuorumLossThreshold defines the time period after which the absence of an etcd leader is reported as quorum loss.
+	QuorumLossThreshold wrappers.Duration `json:"quorumLossThreshold,omitempty"`
+	// EnableQuorumLossRecovery defines whether the cluster is recovered automatically once a quorum loss has been detected.
+	EnableQuorumLossRecovery bool `json:"enableQuorumLossRecovery,omitempty"`
 }
 
 // NewLeaderElectionConfig returns the Config.
@@ -59,6 +79,9 @@ func NewLeaderElectionConfig() *Config {
 	return &Config{
 		ReelectionPeriod:      wrappers.Duration{Duration: DefaultReelectionPeriod},
 		EtcdConnectionTimeout: wrappers.Duration{Duration: DefaultEtcdStatusConnecTimeout},
+		LearnerMaxApplyLag:    DefaultLearnerMaxApplyLag,
+		LearnerCatchUpTimeout: wrappers.Duration{Duration: DefaultLearnerCatchUpTimeout},
+		QuorumLossThreshold:   wrappers.Duration{Duration: DefaultQuorumLossThreshold},
 	}
 }
 
@@ -66,6 +89,10 @@ func NewLeaderElectionConfig() *Config {
 func (c *Config) AddFlags(fs *flag.FlagSet) {
 	fs.DurationVar(&c.EtcdConnectionTimeout.Duration, "etcd-connection-timeout-leader-election", c.EtcdConnectionTimeout.Duration, "timeout duration of etcd client connection during leader election")
 	fs.DurationVar(&c.ReelectionPeriod.Duration, "reelection-period", c.ReelectionPeriod.Duration, "period after which election will be re-triggered to check the leadership status")
+	fs.Uint64Var(&c.LearnerMaxApplyLag, "learner-max-apply-lag", c.LearnerMaxApplyLag, "maximum number of raft entries the learner may lag behind the leader to be promoted to a voting member")
+	fs.DurationVar(&c.LearnerCatchUpTimeout.Duration, "learner-catch-up-timeout", c.LearnerCatchUpTimeout.Duration, "time period within which the learner is expected to catch up with the leader before promotion is reported as failed")
+	fs.DurationVar(&c.QuorumLossThreshold.Duration, "quorum-loss-threshold", c.QuorumLossThreshold.Duration, "time period after which the absence of an etcd leader is reported as quorum loss and a recovery is recommended")
+	fs.BoolVar(&c.EnableQuorumLossRecovery, "enable-quorum-loss-recovery", c.EnableQuorumLossRecovery, "enable the automatic recovery of the etcd cluster from the member with the latest data once a quorum loss has been detected")
 }
 
 // Validate validates the Config.
@@ -78,5 +105,13 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("etcd connection timeout during leader election should be greater than 1 second")
 	}
 
+	if c.LearnerCatchUpTimeout.Duration <= c.ReelectionPeriod.Duration {
+		return fmt.Errorf("learner catch-up timeout should be greater than the reelection period")
+	}
+
+	if c.QuorumLossThreshold.Duration <= c.ReelectionPeriod.Duration {
+		return fmt.Errorf("quorum loss threshold should be greater than the reelection period")
+	}
+
 	return nil
 }
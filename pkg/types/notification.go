@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/gardener/etcd-backup-restore/pkg/wrappers"
+
+	flag "github.com/spf13/pflag"
+)
+
+const (
+	// NotificationFormatJSON is the notification format which posts the plain event as JSON.
+	NotificationFormatJSON = "json"
+	// NotificationFormatCloudEvents is the notification format which posts the event as structured CloudEvent.
+	NotificationFormatCloudEvents = "cloudevents"
+
+	// DefaultNotificationMaxRetries is the default number of retries of a failed notification.
+	DefaultNotificationMaxRetries = 5
+	// DefaultNotificationRetryBackoff is the default initial backoff between retries of a failed notification.
+	DefaultNotificationRetryBackoff = 1 * time.Second
+	// DefaultNotificationTimeout is the default timeout of a single notification request.
+	DefaultNotificationTimeout = 10 * time.Second
+)
+
+// NotificationConfig holds the configuration of the notifications of backup lifecycle events.
+type NotificationConfig struct {
+	// WebhookURLs are the HTTP endpoints the notifications are posted to. Notifications are disabled if empty.
+	WebhookURLs []string `json:"webhookURLs,omitempty"`
+	// Format is the format of the notifications, either "json" or "cloudevents".
+	Format string `json:"format,omitempty"`
+	// SigningSecretFile is the path of the file holding the secret used to sign the notifications with HMAC-SHA256.
+	SigningSecretFile string `json:"signingSecretFile,omitempty"`
+	// MaxRetries is the number of retries of a failed notification.
+	MaxRetries uint `json:"maxRetries,omitempty"`
+	// RetryBackoff is the initial backoff between retries, which doubles with every retry.
+	RetryBackoff wrappers.Duration `json:"retryBackoff,omitempty"`
+	// Timeout is the timeout of a single notification request.
+	Timeout wrappers.Duration `json:"timeout,omitempty"`
+	// KubernetesEventsEnabled enables recording Kubernetes events and pod conditions for the etcd pod.
+	KubernetesEventsEnabled bool `json:"kubernetesEventsEnabled,omitempty"`
+}
+
+// NewNotificationConfig returns the notification config.
+func NewNotificationConfig() *NotificationConfig {
+	return &NotificationConfig{
+		Format:       NotificationFormatJSON,
+		MaxRetries:   DefaultNotificationMaxRetries,
+		RetryBackoff: wrappers.Duration{Duration: DefaultNotificationRetryBackoff},
+		Timeout:      wrappers.Duration{Duration: DefaultNotificationTimeout},
+	}
+}
+
+// AddFlags adds the flags to flagset.
+func (c *NotificationConfig) AddFlags(fs *flag.FlagSet) {
+	fs.StringSliceVar(&c.WebhookURLs, "notification-webhook-urls", c.WebhookURLs, "comma separated list of HTTP endpoints to post backup lifecycle notifications to")
+	fs.StringVar(&c.Format, "notification-format", c.Format, "format of the notifications, one of \"json\" or \"cloudevents\"")
+	fs.StringVar(&c.SigningSecretFile, "notification-signing-secret-file", c.SigningSecretFile, "path of the file holding the secret used to sign the notifications with HMAC-SHA256")
+	fs.UintVar(&c.MaxRetries, "notification-max-retries", c.MaxRetries, "number of retries of a failed notification")
+	fs.DurationVar(&c.RetryBackoff.Duration, "notification-retry-backoff", c.RetryBackoff.Duration, "initial backoff between retries of a failed notification")
+	fs.DurationVar(&c.Timeout.Duration, "notification-timeout", c.Timeout.Duration, "timeout of a single notification request")
+	fs.BoolVar(&c.KubernetesEventsEnabled, "enable-kubernetes-events", c.KubernetesEventsEnabled, "enable recording Kubernetes events and pod conditions for restoration, member and snapshot lifecycle events")
+}
+
+// Validate validates the notification config.
+func (c *NotificationConfig) Validate() error {
+	for _, webhookURL := range c.WebhookURLs {
+		u, err := url.Parse(webhookURL)
+		if err != nil {
+			return fmt.Errorf("invalid notification webhook url %s: %v", webhookURL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("notification webhook url %s must use http or https scheme", webhookURL)
+		}
+	}
+	if c.Format != NotificationFormatJSON && c.Format != NotificationFormatCloudEvents {
+		return fmt.Errorf("unsupported notification format %s, must be one of %s or %s", c.Format, NotificationFormatJSON, NotificationFormatCloudEvents)
+	}
+	if len(c.SigningSecretFile) > 0 {
+		if _, err := os.Stat(c.SigningSecretFile); err != nil {
+			return fmt.Errorf("notification signing secret file is invalid: %v", err)
+		}
+	}
+	if c.RetryBackoff.Duration <= 0 {
+		return fmt.Errorf("notification retry backoff should be greater than zero")
+	}
+	if c.Timeout.Duration <= 0 {
+		return fmt.Errorf("notification timeout should be greater than zero")
+	}
+	return nil
+}
+
+// Enabled returns true if at least one webhook is configured.
+func (c *NotificationConfig) Enabled() bool {
+	return len(c.WebhookURLs) > 0
+}
@@ -15,6 +15,7 @@ import (
 	flag "github.com/spf13/pflag"
 	"go.etcd.io/etcd/clientv3"
 	"go.etcd.io/etcd/pkg/types"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
 )
 
 const (
@@ -28,6 +29,14 @@ const (
 	defaultEmbeddedEtcdQuotaBytes   = 8 * 1024 * 1024 * 1024 //8Gib
 	defaultAutoCompactionMode       = "periodic"             // only 2 mode is supported: 'periodic' or 'revision'
 	defaultAutoCompactionRetention  = "30m"
+	defaultFetchedSnapshotsBudget   = 1024 * 1024 * 1024 //1Gib
+	defaultMaxParallelDownloads     = 4
+	defaultDownloadPartSize         = 64 * 1024 * 1024 //64Mib
+
+	// DeltaApplyEngineEmbeddedEtcd applies the delta snapshots through an embedded etcd.
+	DeltaApplyEngineEmbeddedEtcd = "embedded-etcd"
+	// DeltaApplyEngineOffline applies the delta snapshots directly into the bolt database of the restored data directory.
+	DeltaApplyEngineOffline = "offline"
 )
 
 // NewClientFactoryFunc allows to define how to create a client.Factory
@@ -64,6 +73,12 @@ type RestorationConfig struct {
 	EmbeddedEtcdQuotaBytes   int64    `json:"embeddedEtcdQuotaBytes,omitempty"`
 	MaxFetchers              uint     `json:"maxFetchers,omitempty"`
 	SkipHashCheck            bool     `json:"skipHashCheck,omitempty"`
+	DeltaApplyEngine         string   `json:"deltaApplyEngine,omitempty"`
+	BatchDeltaRevisions      bool     `json:"batchDeltaRevisions,omitempty"`
+	TargetEtcdVersion        string   `json:"targetEtcdVersion,omitempty"`
+	FetchedSnapshotsBudget   int64    `json:"fetchedSnapshotsBudget,omitempty"`
+	MaxParallelDownloads     uint     `json:"maxParallelDownloads,omitempty"`
+	DownloadPartSize         int64    `json:"downloadPartSize,omitempty"`
 }
 
 // NewRestorationConfig returns the restoration config.
@@ -83,6 +98,10 @@ func NewRestorationConfig() *RestorationConfig {
 		EmbeddedEtcdQuotaBytes:   int64(defaultEmbeddedEtcdQuotaBytes),
 		AutoCompactionMode:       defaultAutoCompactionMode,
 		AutoCompactionRetention:  defaultAutoCompactionRetention,
+		DeltaApplyEngine:         DeltaApplyEngineEmbeddedEtcd,
+		FetchedSnapshotsBudget:   defaultFetchedSnapshotsBudget,
+		MaxParallelDownloads:     defaultMaxParallelDownloads,
+		DownloadPartSize:         defaultDownloadPartSize,
 	}
 }
 
@@ -102,6 +121,12 @@ func (c *RestorationConfig) AddFlags(fs *flag.FlagSet) {
 	fs.Int64Var(&c.EmbeddedEtcdQuotaBytes, "embedded-etcd-quota-bytes", c.EmbeddedEtcdQuotaBytes, "maximum backend quota for the embedded etcd used for applying delta snapshots")
 	fs.StringVar(&c.AutoCompactionMode, "auto-compaction-mode", c.AutoCompactionMode, "mode for auto-compaction: 'periodic' for duration based retention. 'revision' for revision number based retention.")
 	fs.StringVar(&c.AutoCompactionRetention, "auto-compaction-retention", c.AutoCompactionRetention, "Auto-compaction retention length.")
+	fs.StringVar(&c.DeltaApplyEngine, "delta-apply-engine", c.DeltaApplyEngine, "engine to apply delta snapshots during restoration: 'embedded-etcd' replays the events through an embedded etcd, 'offline' writes them directly into the restored bolt database")
+	fs.BoolVar(&c.BatchDeltaRevisions, "batch-delta-revisions", c.BatchDeltaRevisions, "apply the events of delta snapshots with the embedded etcd in transactions bounded by max-txn-ops and max-request-bytes, coalescing small revisions and splitting large ones, instead of one transaction per revision. The restored etcd does not keep the revisions of the backed up etcd")
+	fs.StringVar(&c.TargetEtcdVersion, "target-etcd-version", c.TargetEtcdVersion, "version of the etcd the data directory is restored for, e.g. 3.5.21. A warning is logged if the base snapshot was taken of a newer version of etcd. The check is skipped if empty")
+	fs.Int64Var(&c.FetchedSnapshotsBudget, "fetched-snapshots-budget", c.FetchedSnapshotsBudget, "maximum total size in bytes of the fetched delta snapshots waiting to be applied during restoration, fetchers are throttled once it is exceeded. 0 disables the budget")
+	fs.UintVar(&c.MaxParallelDownloads, "max-parallel-downloads", c.MaxParallelDownloads, "maximum number of parts of the base snapshot that are downloaded in parallel during restoration, 1 downloads the base snapshot in a single stream")
+	fs.Int64Var(&c.DownloadPartSize, "download-part-size", c.DownloadPartSize, "size in bytes of the parts of the base snapshot that are downloaded in parallel during restoration")
 }
 
 // Validate validates the config.
@@ -124,6 +149,23 @@ func (c *RestorationConfig) Validate() error {
 	if c.AutoCompactionMode != "periodic" && c.AutoCompactionMode != "revision" {
 		return fmt.Errorf("UnSupported auto-compaction-mode")
 	}
+	if c.FetchedSnapshotsBudget < 0 {
+		return fmt.Errorf("fetched snapshots budget must not be negative")
+	}
+	if c.MaxParallelDownloads <= 0 {
+		return fmt.Errorf("max parallel downloads should be greater than zero")
+	}
+	if c.DownloadPartSize <= 0 {
+		return fmt.Errorf("download part size should be greater than zero")
+	}
+	if len(c.TargetEtcdVersion) != 0 {
+		if _, err := utilversion.ParseGeneric(c.TargetEtcdVersion); err != nil {
+			return fmt.Errorf("invalid target etcd version %s: %v", c.TargetEtcdVersion, err)
+		}
+	}
+	if c.DeltaApplyEngine != DeltaApplyEngineEmbeddedEtcd && c.DeltaApplyEngine != DeltaApplyEngineOffline {
+		return fmt.Errorf("unsupported delta-apply-engine %s, must be one of %s or %s", c.DeltaApplyEngine, DeltaApplyEngineEmbeddedEtcd, DeltaApplyEngineOffline)
+	}
 	c.DataDir = path.Clean(c.DataDir)
 	c.TempSnapshotsDir = path.Clean(c.TempSnapshotsDir)
 	return nil
@@ -182,6 +224,7 @@ type FetcherInfo struct {
 type ApplierInfo struct {
 	SnapFilePath string
 	SnapIndex    int
+	SnapFileSize int64
 }
 
 // DeepCopyInto copies the structure deeply from in to out.
@@ -41,6 +41,14 @@ const (
 
 	// DeltaSnapshotIntervalThreshold is interval between delta snapshot
 	DeltaSnapshotIntervalThreshold = time.Second
+
+	// DeltaSnapshotFormatJSON is the format of delta snapshots as a JSON array of events, which all versions can read.
+	DeltaSnapshotFormatJSON = "json"
+	// DeltaSnapshotFormatBinary is the format of delta snapshots with length-prefixed protobuf encoded events.
+	DeltaSnapshotFormatBinary = "binary"
+	// DefaultDeltaSnapshotFormat is the default format of delta snapshots. The binary format is opt-in, since
+	// older versions cannot read it.
+	DefaultDeltaSnapshotFormat = DeltaSnapshotFormatJSON
 )
 
 // SnapshotterState denotes the state the snapshotter would be in.
@@ -55,6 +63,8 @@ type SnapshotterConfig struct {
 	GarbageCollectionPeriod      wrappers.Duration `json:"garbageCollectionPeriod,omitempty"`
 	MaxBackups                   uint              `json:"maxBackups,omitempty"`
 	DeltaSnapshotRetentionPeriod wrappers.Duration `json:"deltaSnapshotRetentionPeriod,omitempty"`
+	DeltaSnapshotFormat          string            `json:"deltaSnapshotFormat,omitempty"`
+	MaxPageDiffSnapshots         uint              `json:"maxPageDiffSnapshots,omitempty"`
 }
 
 // AddFlags adds the flags to flagset.
@@ -66,6 +76,8 @@ func (c *SnapshotterConfig) AddFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.GarbageCollectionPolicy, "garbage-collection-policy", c.GarbageCollectionPolicy, "Policy for garbage collecting old backups")
 	fs.UintVarP(&c.MaxBackups, "max-backups", "m", c.MaxBackups, "maximum number of previous backups to keep")
 	fs.DurationVar(&c.DeltaSnapshotRetentionPeriod.Duration, "delta-snapshot-retention-period", c.DeltaSnapshotRetentionPeriod.Duration, "Defines the retention period for older delta snapshots, excluding the latest snapshot set which is always retained for data safety.")
+	fs.UintVar(&c.MaxPageDiffSnapshots, "max-page-diff-snapshots", c.MaxPageDiffSnapshots, "maximum number of scheduled full snapshots taken as page diff snapshots against the previous full snapshot, before a full snapshot is taken again. 0 disables page diff snapshots")
+	fs.StringVar(&c.DeltaSnapshotFormat, "delta-snapshot-format", c.DeltaSnapshotFormat, "format of the delta snapshots: 'json' for JSON event arrays, 'binary' for length-prefixed protobuf encoded events, which older versions cannot read")
 }
 
 // Validate validates the config.
@@ -93,5 +105,11 @@ func (c *SnapshotterConfig) Validate() error {
 	} else if c.DeltaSnapshotMemoryLimit > math.MaxInt {
 		return fmt.Errorf("delta snapshot memory limit %d bytes is greater than %d bytes", c.DeltaSnapshotMemoryLimit, math.MaxInt)
 	}
+
+	if len(c.DeltaSnapshotFormat) == 0 {
+		c.DeltaSnapshotFormat = DefaultDeltaSnapshotFormat
+	} else if c.DeltaSnapshotFormat != DeltaSnapshotFormatJSON && c.DeltaSnapshotFormat != DeltaSnapshotFormatBinary {
+		return fmt.Errorf("invalid delta snapshot format: %s", c.DeltaSnapshotFormat)
+	}
 	return nil
 }
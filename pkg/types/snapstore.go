@@ -5,6 +5,7 @@
 package types
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"path"
@@ -33,6 +34,16 @@ const (
 	SnapstoreProviderECS = "ECS"
 	// SnapstoreProviderOCS is constant for OpenShift Container Storage S3 storage provider.
 	SnapstoreProviderOCS = "OCS"
+	// SnapstoreProviderFilesystem is constant for a mounted filesystem, such as an NFS or SMB share, as storage provider.
+	SnapstoreProviderFilesystem = "Filesystem"
+	// SnapstoreProviderSFTP is constant for a directory on an SFTP server as storage provider.
+	SnapstoreProviderSFTP = "SFTP"
+	// SnapstoreProviderWebDAV is constant for a collection on a WebDAV or plain HTTP server as storage provider.
+	SnapstoreProviderWebDAV = "WebDAV"
+	// SnapstoreProviderMinIO is constant for MinIO storage provider.
+	SnapstoreProviderMinIO = "MinIO"
+	// SnapstoreProviderRGW is constant for Ceph RADOS Gateway storage provider.
+	SnapstoreProviderRGW = "RGW"
 	// SnapstoreProviderFakeFailed is constant for fake failed storage provider.
 	SnapstoreProviderFakeFailed = "FAILED"
 
@@ -42,6 +53,9 @@ const (
 	SnapshotKindDelta = "Incr"
 	// SnapshotKindChunk is constant for chunk snapshot kind.
 	SnapshotKindChunk = "Chunk"
+	// SnapshotKindPageDiff is constant for page diff snapshot kind, the changed pages of a full snapshot
+	// against a previous full snapshot.
+	SnapshotKindPageDiff = "PageDiff"
 
 	// AzureBlobStorageGlobalDomain is the default domain for azure blob storage service.
 	AzureBlobStorageGlobalDomain = "blob.core.windows.net"
@@ -62,13 +76,36 @@ const (
 	// Note: applicable for storage provider: ABS, GCS and S3.
 	ExcludeSnapshotMetadataKey = "x-etcd-snapshot-exclude"
 
+	// EtcdVersionMetadataKey is the metadata key of the version of the etcd server a full snapshot was taken of.
+	// The metadata keys consist of lowercase letters only, since some object stores do not preserve the case or do not
+	// allow hyphens in metadata keys.
+	// Note: applicable for storage provider: ABS, Filesystem, GCS, OSS and the S3 compatible providers.
+	EtcdVersionMetadataKey = "etcdversion"
+	// EtcdClusterIDMetadataKey is the metadata key of the ID of the etcd cluster a full snapshot was taken of.
+	EtcdClusterIDMetadataKey = "etcdclusterid"
+
 	// DefaultSecondaryBackupSyncPeriod is the default period for secondary backup sync operations.
 	DefaultSecondaryBackupSyncPeriod = 1 * time.Hour
+
+	// FanOutPolicyAll requires a snapshot to be saved to all snapstores of a fan-out.
+	FanOutPolicyAll = "all"
+	// FanOutPolicyAny requires a snapshot to be saved to at least one snapstore of a fan-out.
+	FanOutPolicyAny = "any"
+	// FanOutPolicyMajority requires a snapshot to be saved to a majority of the snapstores of a fan-out.
+	FanOutPolicyMajority = "majority"
+	// DefaultFanOutRepairPeriod is the default period in which failed operations on the snapstores of a fan-out are repaired.
+	DefaultFanOutRepairPeriod = 5 * time.Minute
+	// DefaultSnapshotCacheSize is the default maximum size of the snapshot cache in bytes.
+	DefaultSnapshotCacheSize = 10 * 1024 * 1024 * 1024
 )
 
 var (
 	// ErrSnapshotDeleteFailDueToImmutability is the error returned when the Delete call fails due to immutability
 	ErrSnapshotDeleteFailDueToImmutability = fmt.Errorf("ErrSnapshotDeleteFailDueToImmutability")
+	// ErrRangeFetchUnsupported is returned by a RangeFetcher which cannot fetch byte ranges of the requested snapshot.
+	ErrRangeFetchUnsupported = errors.New("fetching byte ranges of the snapshot is not supported")
+	// ErrMetadataFetchUnsupported is returned by a MetadataFetcher which cannot fetch the metadata of the requested snapshot.
+	ErrMetadataFetchUnsupported = errors.New("fetching the metadata of the snapshot is not supported")
 )
 
 // SnapStore is the interface to be implemented for different
@@ -89,6 +126,32 @@ type SnapStore interface {
 	Delete(Snapshot) error
 }
 
+// RangeFetcher is implemented by snapstores which can fetch byte ranges of snapshots, so that large snapshots
+// can be downloaded in parallel parts.
+type RangeFetcher interface {
+	// Size returns the size in bytes of the snapshot file in the store.
+	Size(Snapshot) (int64, error)
+	// FetchRange should open reader for the given number of bytes of the snapshot file from store, starting at the given offset.
+	FetchRange(snap Snapshot, offset, length int64) (io.ReadCloser, error)
+}
+
+// MetadataFetcher is implemented by snapstores which store the metadata of a snapshot with the snapshot.
+type MetadataFetcher interface {
+	// FetchMetadata returns the metadata stored with the snapshot, with lowercase keys.
+	FetchMetadata(Snapshot) (map[string]string, error)
+}
+
+// UploadProgressReader is implemented by readers of snapshots being saved whose upload progress is tracked.
+// The bytes read from it are counted as uploaded. Snapstores which stage snapshots in temporary files before
+// uploading them read the snapshot from the StagingReader instead, and count the bytes they upload with AddUploadedBytes.
+type UploadProgressReader interface {
+	io.ReadCloser
+	// StagingReader returns a reader of the snapshot whose bytes are not counted as uploaded.
+	StagingReader() io.ReadCloser
+	// AddUploadedBytes counts the given number of bytes as uploaded.
+	AddUploadedBytes(n int64)
+}
+
 // Snapshot structure represents the metadata of snapshot.
 type Snapshot struct {
 	CreatedOn              time.Time `json:"createdOn"`
@@ -103,6 +166,9 @@ type Snapshot struct {
 	LastRevision           int64     `json:"lastRevision"` // latest revision of snapshot
 	IsChunk                bool      `json:"isChunk"`
 	IsFinal                bool      `json:"isFinal"`
+	// Metadata is stored with the snapshot by snapstores which implement MetadataFetcher. It is only set when saving
+	// snapshots, or once it has been fetched.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // IsDeletable determines if the snapshot can be deleted.
@@ -206,11 +272,29 @@ type SnapstoreConfig struct {
 	// EnvPrefix is the prefix to be used for environment variables.
 	// It is used to differentiate between primary and secondary snapstore configs.
 	EnvPrefix string `json:"envPrefix,omitempty"`
+	// Deduplication enables storing full snapshots as content-defined chunks, each of which is stored only once.
+	Deduplication bool `json:"deduplication,omitempty"`
+	// FanOut holds the snapstores every snapshot is written to in addition to this snapstore.
+	FanOut *FanOutConfig `json:"fanOut,omitempty"`
+	// SnapshotCacheDir is the local directory in which saved snapshots are cached, so that they do not need to be
+	// downloaded when they are fetched. The snapshot cache is disabled if it is empty.
+	SnapshotCacheDir string `json:"snapshotCacheDir,omitempty"`
+	// SnapshotCacheSize is the maximum size of the snapshot cache in bytes.
+	SnapshotCacheSize int64 `json:"snapshotCacheSize,omitempty"`
+	// PurgeSnapshotVersions makes deleting a snapshot from a versioned bucket delete all its versions and delete markers.
+	// It is supported by the MinIO and RGW storage providers.
+	PurgeSnapshotVersions bool `json:"purgeSnapshotVersions,omitempty"`
+	// ListNoncurrentVersions lists the noncurrent versions of snapshots which have been deleted from a versioned bucket,
+	// so that they can be recovered. It is supported by the MinIO and RGW storage providers.
+	ListNoncurrentVersions bool `json:"listNoncurrentVersions,omitempty"`
 }
 
 // AddFlags adds the flags to flagset.
 func (c *SnapstoreConfig) AddFlags(fs *flag.FlagSet) {
 	c.addFlags(fs, "")
+	if c.FanOut != nil {
+		c.FanOut.AddFlags(fs)
+	}
 }
 
 // AddSourceFlags adds the flags to flagset using `source-` prefix for all parameters.
@@ -225,6 +309,11 @@ func (c *SnapstoreConfig) addFlags(fs *flag.FlagSet, parameterPrefix string) {
 	fs.UintVar(&c.MaxParallelChunkUploads, parameterPrefix+"max-parallel-chunk-uploads", c.MaxParallelChunkUploads, "maximum number of parallel chunk uploads allowed")
 	fs.Int64Var(&c.MinChunkSize, parameterPrefix+"min-chunk-size", c.MinChunkSize, "Minimum size for multipart chunk upload")
 	fs.StringVar(&c.TempDir, parameterPrefix+"snapstore-temp-directory", c.TempDir, "temporary directory for processing")
+	fs.BoolVar(&c.Deduplication, parameterPrefix+"deduplicate-full-snapshots", c.Deduplication, "store full snapshots as content-defined chunks, each of which is stored only once")
+	fs.StringVar(&c.SnapshotCacheDir, parameterPrefix+"snapshot-cache-dir", c.SnapshotCacheDir, "local directory in which saved snapshots are cached for later fetches, the snapshot cache is disabled if empty")
+	fs.Int64Var(&c.SnapshotCacheSize, parameterPrefix+"snapshot-cache-size", c.SnapshotCacheSize, "maximum size of the snapshot cache in bytes, the least recently used snapshots are evicted beyond it")
+	fs.BoolVar(&c.PurgeSnapshotVersions, parameterPrefix+"purge-snapshot-versions", c.PurgeSnapshotVersions, "delete all versions and delete markers of a snapshot when deleting it from a versioned bucket, supported by the MinIO and RGW storage providers")
+	fs.BoolVar(&c.ListNoncurrentVersions, parameterPrefix+"list-noncurrent-versions", c.ListNoncurrentVersions, "list snapshots deleted from a versioned bucket by their noncurrent versions to recover them, supported by the MinIO and RGW storage providers")
 }
 
 // Validate validates the config.
@@ -235,6 +324,12 @@ func (c *SnapstoreConfig) Validate() error {
 	if c.MinChunkSize < MinChunkSize {
 		return fmt.Errorf("min chunk size for multi-part chunk upload should be greater than or equal to 5 MiB")
 	}
+	if c.SnapshotCacheDir != "" && c.SnapshotCacheSize <= 0 {
+		return fmt.Errorf("snapshot cache size should be greater than zero")
+	}
+	if c.FanOut != nil {
+		return c.FanOut.Validate()
+	}
 	return nil
 }
 
@@ -245,6 +340,20 @@ func (c *SnapstoreConfig) Complete() {
 	if c.TempDir == "" {
 		c.TempDir = "/tmp"
 	}
+	if c.FanOut != nil {
+		for _, storeConfig := range c.FanOut.StoreConfigs {
+			if storeConfig.MaxParallelChunkUploads == 0 {
+				storeConfig.MaxParallelChunkUploads = c.MaxParallelChunkUploads
+			}
+			if storeConfig.MinChunkSize == 0 {
+				storeConfig.MinChunkSize = c.MinChunkSize
+			}
+			if storeConfig.TempDir == "" {
+				storeConfig.TempDir = c.TempDir
+			}
+			storeConfig.Complete()
+		}
+	}
 }
 
 // MergeWith completes the config based on other config
@@ -262,6 +371,47 @@ func (c *SnapstoreConfig) MergeWith(other *SnapstoreConfig) {
 	}
 }
 
+// FanOutConfig defines the snapstores every snapshot is written to in addition to the snapstore it is configured for.
+type FanOutConfig struct {
+	// StoreConfigs holds the configs of the additional snapstores. The credentials of each snapstore are read from
+	// the environment variables with its EnvPrefix.
+	StoreConfigs []*SnapstoreConfig `json:"storeConfigs,omitempty"`
+	// Policy defines to how many snapstores a snapshot has to be saved: all, any or majority.
+	Policy string `json:"policy,omitempty"`
+	// RepairPeriod defines the period in which snapshots which could not be saved to or deleted from a snapstore are repaired.
+	RepairPeriod wrappers.Duration `json:"repairPeriod,omitempty"`
+}
+
+// AddFlags adds the flags to flagset.
+func (c *FanOutConfig) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Policy, "fan-out-policy", c.Policy, "policy defining to how many snapstores of the fan-out a snapshot has to be saved: all, any or majority")
+	fs.DurationVar(&c.RepairPeriod.Duration, "fan-out-repair-period", c.RepairPeriod.Duration, "period in which snapshots which could not be saved to or deleted from a snapstore of the fan-out are repaired")
+}
+
+// Validate validates the config.
+func (c *FanOutConfig) Validate() error {
+	if len(c.StoreConfigs) == 0 {
+		return nil
+	}
+	switch c.Policy {
+	case FanOutPolicyAll, FanOutPolicyAny, FanOutPolicyMajority:
+	default:
+		return fmt.Errorf("unsupported fan-out policy %q, should be one of %s, %s or %s", c.Policy, FanOutPolicyAll, FanOutPolicyAny, FanOutPolicyMajority)
+	}
+	if c.RepairPeriod.Duration <= 0 {
+		return fmt.Errorf("fan-out repair period should be greater than zero")
+	}
+	for _, storeConfig := range c.StoreConfigs {
+		if storeConfig.Provider == "" {
+			return fmt.Errorf("storage provider of the fan-out snapstores should not be empty")
+		}
+		if storeConfig.FanOut != nil && len(storeConfig.FanOut.StoreConfigs) > 0 {
+			return fmt.Errorf("fan-out snapstores should not fan out themselves")
+		}
+	}
+	return nil
+}
+
 type SecondarySnapstoreConfig struct {
 	StoreConfig       *SnapstoreConfig
 	BackupSyncEnabled bool              `json:"backupSyncEnabled,omitempty"`
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+	flag "github.com/spf13/pflag"
+)
+
+const (
+	// DefaultSquashMinDeltaSnapshots is the default minimum number of consecutive delta snapshots which are squashed.
+	DefaultSquashMinDeltaSnapshots = 10
+	// DefaultSquashMaxDeltaSnapshots is the default maximum number of delta snapshots which are squashed into one.
+	DefaultSquashMaxDeltaSnapshots = 100
+	// DefaultSquashMaxSnapshotSize is the default maximum size in bytes of the events of a squashed delta snapshot.
+	DefaultSquashMaxSnapshotSize = 100 * 1024 * 1024 //100Mib
+)
+
+// SquasherConfig holds the configuration of the delta snapshot squasher.
+type SquasherConfig struct {
+	// Schedule is the cron schedule to squash the delta snapshots of the latest full snapshot. Periodic squashing is disabled if empty.
+	Schedule string `json:"schedule,omitempty"`
+	// MinDeltaSnapshots is the minimum number of consecutive delta snapshots which are squashed.
+	MinDeltaSnapshots uint `json:"minDeltaSnapshots,omitempty"`
+	// MaxDeltaSnapshots is the maximum number of delta snapshots which are squashed into one.
+	MaxDeltaSnapshots uint `json:"maxDeltaSnapshots,omitempty"`
+	// MaxSnapshotSize is the maximum size in bytes of the uncompressed events of a squashed delta snapshot.
+	MaxSnapshotSize uint `json:"maxSnapshotSize,omitempty"`
+}
+
+// NewSquasherConfig returns the squasher config.
+func NewSquasherConfig() *SquasherConfig {
+	return &SquasherConfig{
+		MinDeltaSnapshots: DefaultSquashMinDeltaSnapshots,
+		MaxDeltaSnapshots: DefaultSquashMaxDeltaSnapshots,
+		MaxSnapshotSize:   DefaultSquashMaxSnapshotSize,
+	}
+}
+
+// AddFlags adds the flags to flagset.
+func (c *SquasherConfig) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Schedule, "delta-squash-schedule", c.Schedule, "schedule to squash the delta snapshots of the latest full snapshot, periodic squashing is disabled if empty")
+	fs.UintVar(&c.MinDeltaSnapshots, "delta-squash-min-snapshots", c.MinDeltaSnapshots, "minimum number of consecutive delta snapshots which are squashed")
+	fs.UintVar(&c.MaxDeltaSnapshots, "delta-squash-max-snapshots", c.MaxDeltaSnapshots, "maximum number of delta snapshots which are squashed into one")
+	fs.UintVar(&c.MaxSnapshotSize, "delta-squash-max-snapshot-size", c.MaxSnapshotSize, "maximum size in bytes of the uncompressed events of a squashed delta snapshot")
+}
+
+// Validate validates the squasher config.
+func (c *SquasherConfig) Validate() error {
+	if c.Enabled() {
+		if _, err := cron.ParseStandard(c.Schedule); err != nil {
+			return fmt.Errorf("invalid delta squash schedule: %v", err)
+		}
+	}
+	if c.MinDeltaSnapshots < 2 {
+		return fmt.Errorf("minimum number of delta snapshots to squash should be at least 2")
+	}
+	if c.MaxDeltaSnapshots < c.MinDeltaSnapshots {
+		return fmt.Errorf("maximum number of delta snapshots to squash should not be less than the minimum number %d", c.MinDeltaSnapshots)
+	}
+	if c.MaxSnapshotSize < 1 {
+		return fmt.Errorf("maximum size of a squashed delta snapshot should be greater than 0")
+	}
+	return nil
+}
+
+// Enabled returns true if a schedule for periodic squashing is configured.
+func (c *SquasherConfig) Enabled() bool {
+	return len(c.Schedule) > 0
+}
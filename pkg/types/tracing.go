@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+
+	flag "github.com/spf13/pflag"
+)
+
+const (
+	// DefaultTracingServiceName is the default service name reported with the spans.
+	DefaultTracingServiceName = "etcd-backup-restore"
+	// DefaultTracingSamplingRatio is the default ratio of traces which are sampled.
+	DefaultTracingSamplingRatio = 1.0
+)
+
+// TracingConfig holds the configuration of the OpenTelemetry tracing.
+type TracingConfig struct {
+	// OTLPEndpoint is the host:port of the OTLP gRPC endpoint the spans are exported to. Tracing is disabled if empty.
+	OTLPEndpoint string `json:"otlpEndpoint,omitempty"`
+	// Insecure disables TLS for the connection to the OTLP endpoint.
+	Insecure bool `json:"insecure,omitempty"`
+	// ServiceName is the service name reported with the spans.
+	ServiceName string `json:"serviceName,omitempty"`
+	// SamplingRatio is the ratio of traces which are sampled, between 0 and 1.
+	SamplingRatio float64 `json:"samplingRatio,omitempty"`
+}
+
+// NewTracingConfig returns the tracing config.
+func NewTracingConfig() *TracingConfig {
+	return &TracingConfig{
+		ServiceName:   DefaultTracingServiceName,
+		SamplingRatio: DefaultTracingSamplingRatio,
+	}
+}
+
+// AddFlags adds the flags to flagset.
+func (c *TracingConfig) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.OTLPEndpoint, "tracing-otlp-endpoint", c.OTLPEndpoint, "host:port of the OTLP gRPC endpoint to export OpenTelemetry spans to, tracing is disabled if empty")
+	fs.BoolVar(&c.Insecure, "tracing-insecure", c.Insecure, "disable TLS for the connection to the OTLP endpoint")
+	fs.StringVar(&c.ServiceName, "tracing-service-name", c.ServiceName, "service name reported with the spans")
+	fs.Float64Var(&c.SamplingRatio, "tracing-sampling-ratio", c.SamplingRatio, "ratio of traces which are sampled, between 0 and 1")
+}
+
+// Validate validates the tracing config.
+func (c *TracingConfig) Validate() error {
+	if c.SamplingRatio < 0 || c.SamplingRatio > 1 {
+		return fmt.Errorf("tracing sampling ratio should be between 0 and 1")
+	}
+	if c.Enabled() && len(c.ServiceName) == 0 {
+		return fmt.Errorf("tracing service name should not be empty")
+	}
+	return nil
+}
+
+// Enabled returns true if an OTLP endpoint is configured.
+func (c *TracingConfig) Enabled() bool {
+	return len(c.OTLPEndpoint) > 0
+}